@@ -10,6 +10,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/innomon/whatsadk/internal/config"
@@ -32,6 +33,13 @@ type importCmd struct{}
 func (c *importCmd) Name() string        { return "import" }
 func (c *importCmd) Description() string { return "Import database contents from a JSONL file" }
 
+type exportTableCmd struct{}
+
+func (c *exportTableCmd) Name() string { return "export-table" }
+func (c *exportTableCmd) Description() string {
+	return "Export the blacklist or pending verifications as JSON or CSV"
+}
+
 // JSONL format structures
 type ExportRecord struct {
 	Type string          `json:"type"`
@@ -203,6 +211,37 @@ func (c *exportCmd) Run(ctx context.Context, s *store.Store, args []string) erro
 	return nil
 }
 
+func (c *exportTableCmd) Run(ctx context.Context, s *store.Store, args []string) error {
+	fs := flag.NewFlagSet("export-table", flag.ContinueOnError)
+	table := fs.String("table", store.ExportTableBlacklist, "Table to export: "+strings.Join(store.ExportTables, ", "))
+	format := fs.String("format", store.ExportCSV, "Output format: json or csv")
+	outPath := fs.String("out", "", "Output path (default <table>.<format>, use '-' for stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *outPath == "" {
+		*outPath = *table + "." + *format
+	}
+
+	var writer io.Writer
+	if *outPath == "-" {
+		writer = os.Stdout
+	} else {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return fmt.Errorf("create export file: %w", err)
+		}
+		defer f.Close()
+		writer = f
+	}
+
+	bufferedWriter := bufio.NewWriter(writer)
+	if err := s.ExportTable(ctx, bufferedWriter, *table, *format); err != nil {
+		return err
+	}
+	return bufferedWriter.Flush()
+}
+
 func (c *importCmd) Run(ctx context.Context, s *store.Store, args []string) error {
 	fs := flag.NewFlagSet("import", flag.ContinueOnError)
 	inPath := fs.String("in", "export.jsonl", "Input path for JSONL import (use '-' for stdin)")
@@ -328,6 +367,8 @@ func main() {
 		cmd = &exportCmd{}
 	case "import":
 		cmd = &importCmd{}
+	case "export-table":
+		cmd = &exportTableCmd{}
 	default:
 		fmt.Printf("Error: unknown command %q\n", cmdName)
 		printUsage()
@@ -358,7 +399,21 @@ func main() {
 	os.Args = origArgs
 
 	// Open the database store using the configured DatabaseURL
-	s, err := store.Open(cfg.Verification.DatabaseURL)
+	migrateBackoff, err := time.ParseDuration(cfg.Store.MigrateBackoff)
+	if err != nil {
+		log.Fatalf("Invalid store migrate_backoff %q: %v", cfg.Store.MigrateBackoff, err)
+	}
+	replicaLag, err := time.ParseDuration(cfg.Store.ReplicaLag)
+	if err != nil {
+		log.Fatalf("Invalid store replica_lag %q: %v", cfg.Store.ReplicaLag, err)
+	}
+	s, err := store.OpenWith(cfg.Verification.DatabaseURL, store.Options{
+		MigrateAttempts: cfg.Store.MigrateAttempts,
+		MigrateBackoff:  migrateBackoff,
+		ReadDSN:         cfg.Store.ReadDSN,
+		ReplicaLag:      replicaLag,
+		SchemaUpgrade:   cfg.Store.SchemaUpgrade,
+	})
 	if err != nil {
 		log.Fatalf("Failed to open database store: %v", err)
 	}
@@ -370,7 +425,8 @@ func main() {
 		log.Fatalf("Command failed: %v", err)
 	}
 
-	fmt.Println("Success!")
+	// Status goes to stderr so "-out -" output stays clean.
+	fmt.Fprintln(os.Stderr, "Success!")
 }
 
 func printUsage() {
@@ -378,5 +434,6 @@ func printUsage() {
 	fmt.Println("Commands:")
 	fmt.Println("  export   Export database contents to a JSONL file")
 	fmt.Println("  import   Import database contents from a JSONL file")
+	fmt.Println("  export-table  Export the blacklist or pending verifications as JSON or CSV")
 	fmt.Println("Use 'dbutil <command> -help' for command options.")
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"github.com/innomon/whatsadk/internal/adminapi"
+	"github.com/innomon/whatsadk/internal/auth"
+	"github.com/innomon/whatsadk/internal/config"
+	"github.com/innomon/whatsadk/internal/store"
+	"github.com/innomon/whatsadk/internal/whatsapp"
+)
+
+// adminGateway adapts the WhatsApp client to adminapi.Gateway.
+type adminGateway struct {
+	client *whatsapp.Client
+}
+
+func (g adminGateway) State() adminapi.State {
+	st := g.client.ConnectionState()
+	state := adminapi.State{Connected: st.Connected, LoggedIn: st.LoggedIn, JID: st.JID}
+	if !st.ConnectedAt.IsZero() {
+		state.ConnectedSince = &st.ConnectedAt
+	}
+	return state
+}
+
+func (g adminGateway) Stats() adminapi.Stats {
+	st := g.client.Stats()
+	return adminapi.Stats{
+		State:             g.State(),
+		StartedAt:         st.StartedAt,
+		UptimeSeconds:     int64(time.Since(st.StartedAt).Seconds()),
+		MessagesProcessed: st.MessagesProcessed,
+		AgentCalls:        st.AgentCalls,
+		AgentErrors:       st.AgentErrors,
+		AvgAgentLatencyMS: st.AvgAgentLatency.Milliseconds(),
+	}
+}
+
+// Block and Unblock take bare numbers from the API; RemoteBlock and
+// RemoteUnblock need user JIDs.
+func (g adminGateway) Block(phone string) error {
+	return g.client.RemoteBlock(types.NewJID(phone, types.DefaultUserServer).String())
+}
+
+func (g adminGateway) Unblock(phone string) error {
+	return g.client.RemoteUnblock(types.NewJID(phone, types.DefaultUserServer).String())
+}
+
+func (g adminGateway) Relogin(ctx context.Context) (string, error) {
+	return g.client.Relogin(ctx)
+}
+
+// startAdminAPI serves the admin HTTP API on cfg.Gateway.AdminAddr behind
+// the auth.admin credentials. The caller closes the returned server.
+func startAdminAPI(cfg *config.Config, gwStore *store.Store, client *whatsapp.Client) (*http.Server, error) {
+	admin := cfg.Auth.Admin
+	maxSkew, err := time.ParseDuration(admin.MaxSkew)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth admin max_skew %q: %w", admin.MaxSkew, err)
+	}
+	authn := auth.NewRequestAuthenticator(admin.BearerToken, []byte(admin.HMACSecret), maxSkew)
+	handler := authn.Middleware(adminapi.NewHandler(gwStore, adminGateway{client: client}))
+
+	server := &http.Server{Addr: cfg.Gateway.AdminAddr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("⚠️ Admin API server error: %v", err)
+		}
+	}()
+	return server, nil
+}
@@ -33,6 +33,11 @@ func main() {
 	}
 	appLogger.Info("Structured logging initialized")
 
+	outboundTLS, err := cfg.TLS.TLSConfig()
+	if err != nil {
+		log.Fatalf("Invalid TLS config: %v", err)
+	}
+
 	if cfg.ADK.Endpoint == "" {
 		fmt.Println("Error: ADK endpoint is required")
 		fmt.Println("Set it in config.yaml or via ADK_ENDPOINT environment variable")
@@ -59,9 +64,43 @@ func main() {
 		if err != nil {
 			log.Fatalf("Failed to initialize JWT auth: %v", err)
 		}
+		if cfg.Auth.JWT.KeyID != "" {
+			jwtGen.SetKeyID(cfg.Auth.JWT.KeyID)
+		}
+		if err := jwtGen.AddPublishedKeys(cfg.Auth.JWT.PublishedKeyPaths); err != nil {
+			log.Fatalf("Failed to load published JWT keys: %v", err)
+		}
+		if cfg.Auth.JWT.JWKSAddr != "" {
+			mux := http.NewServeMux()
+			mux.Handle(auth.JWKSPath, auth.JWKSHandler(jwtGen.JWKS()))
+			jwksServer := &http.Server{Addr: cfg.Auth.JWT.JWKSAddr, Handler: mux}
+			go func() {
+				if err := jwksServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.Printf("⚠️ JWKS server error: %v", err)
+				}
+			}()
+			defer jwksServer.Close()
+			fmt.Printf("🔑 JWKS published on %s%s\n", cfg.Auth.JWT.JWKSAddr, auth.JWKSPath)
+		}
 		fmt.Println("🔐 JWT authentication enabled (RS256)")
 	}
 
+	migrateBackoff, err := time.ParseDuration(cfg.Store.MigrateBackoff)
+	if err != nil {
+		log.Fatalf("Invalid store migrate_backoff %q: %v", cfg.Store.MigrateBackoff, err)
+	}
+	replicaLag, err := time.ParseDuration(cfg.Store.ReplicaLag)
+	if err != nil {
+		log.Fatalf("Invalid store replica_lag %q: %v", cfg.Store.ReplicaLag, err)
+	}
+	storeOpts := store.Options{
+		MigrateAttempts: cfg.Store.MigrateAttempts,
+		MigrateBackoff:  migrateBackoff,
+		ReadDSN:         cfg.Store.ReadDSN,
+		ReplicaLag:      replicaLag,
+		SchemaUpgrade:   cfg.Store.SchemaUpgrade,
+	}
+
 	var gwStore *store.Store
 	var verifyHandler *verification.Handler
 	if cfg.Verification.Enabled {
@@ -73,7 +112,7 @@ func main() {
 			log.Fatalf("Verification requires JWT auth to be enabled (private_key_path must be set) ")
 		}
 
-		gwStore, err = store.Open(cfg.Verification.DatabaseURL)
+		gwStore, err = store.OpenWith(cfg.Verification.DatabaseURL, storeOpts)
 		if err != nil {
 			log.Fatalf("Failed to open gateway store: %v", err)
 		}
@@ -87,21 +126,61 @@ func main() {
 		verifyHandler = verification.NewHandler(
 			keyRegistry,
 			jwtGen,
-			gwStore,
+			gwStore.PrimaryBlacklist(),
 			cfg.Verification,
-			&http.Client{Timeout: timeout},
+			verification.NewCallbackClient(timeout, outboundTLS),
 			appLogger,
 		)
+		appClients, err := verification.NewAppClients(cfg.Verification, timeout, outboundTLS)
+		if err != nil {
+			log.Fatalf("Failed to build verification callback clients: %v", err)
+		}
+		verifyHandler.SetAppClients(appClients)
+		verifyHandler.SetFailOpen(cfg.Store.FailOpen())
+		verifyHandler.SetUserAgent(cfg.Gateway.UserAgent)
+		if grace := cfg.Verification.DevOpsExpiryGrace; grace != "" {
+			d, err := time.ParseDuration(grace)
+			if err != nil {
+				log.Fatalf("Invalid verification devops_expiry_grace %q: %v", grace, err)
+			}
+			verifyHandler.SetDevOpsExpiryGrace(d)
+		}
+		if single := cfg.Verification.SingleActive; single.Enabled {
+			ttl, err := time.ParseDuration(single.TTL)
+			if err != nil {
+				log.Fatalf("Invalid verification single_active ttl %q: %v", single.TTL, err)
+			}
+			var exempt []string
+			for name, app := range cfg.Verification.Apps {
+				if app.SingleActiveExempt {
+					exempt = append(exempt, name)
+				}
+			}
+			verifyHandler.SetPendingLocks(verification.NewPendingLocks(gwStore, ttl, exempt))
+		}
 		fmt.Printf("🔑 Verification enabled (%d app(s) registered)\n", len(cfg.Verification.Apps))
 	} else {
 		// Initialize store for global blacklist even if verification is disabled
-		gwStore, err = store.Open(cfg.Verification.DatabaseURL)
+		gwStore, err = store.OpenWith(cfg.Verification.DatabaseURL, storeOpts)
 		if err != nil {
 			log.Fatalf("Failed to open gateway store: %v", err)
 		}
 		defer gwStore.Close()
 	}
 
+	// Blacklist changes made through the gateway (admin commands, link
+	// auto-blacklist, admin API) notify the same webhooks as the MCP tools.
+	if len(cfg.Blacklist.NotifyURLs) > 0 {
+		timeout, err := time.ParseDuration(cfg.Blacklist.NotifyTimeout)
+		if err != nil {
+			log.Fatalf("Invalid blacklist notify_timeout %q: %v", cfg.Blacklist.NotifyTimeout, err)
+		}
+		notifier := store.NewWebhookNotifier(cfg.Blacklist.NotifyURLs, timeout, appLogger)
+		notifier.SetTLSConfig(outboundTLS)
+		defer notifier.Wait()
+		gwStore.SetBlacklistNotifier(notifier)
+	}
+
 	// Initialize Cron Heartbeats
 	if cfg.Cron.Enabled {
 		cronStore := cron.NewStore(gwStore)
@@ -131,6 +210,19 @@ func main() {
 			log.Fatalf("Failed to initialize OAuth token generator: %v", err)
 		}
 		oauthHandler = auth.NewOAuthHandler(tokenGen, cfg.Auth.OAuth.SPAURL, cfg.Auth.OAuth.RateLimit)
+		oauthHandler.SetLogReplies(cfg.Auth.OAuth.LogReplies)
+		if err := oauthHandler.SetCommandFormats(cfg.Auth.OAuth.CommandFormats); err != nil {
+			log.Fatalf("Invalid OAuth command_formats: %v", err)
+		}
+		if cfg.Auth.OAuth.PersistBindings {
+			bindingTTL, err := time.ParseDuration(cfg.Auth.OAuth.BindingTTL)
+			if err != nil {
+				log.Fatalf("Invalid OAuth binding TTL %q: %v", cfg.Auth.OAuth.BindingTTL, err)
+			}
+			bindings := auth.NewNonceBindings(gwStore, bindingTTL)
+			oauthHandler.SetNonceBindings(bindings)
+			go bindings.RunPruner(ctx, bindingTTL)
+		}
 		fmt.Println("🔑 WhatsApp OAuth enabled (EdDSA)")
 	}
 
@@ -139,6 +231,8 @@ func main() {
 	fmt.Printf("🤖 Agent: %s\n", cfg.ADK.AppName)
 
 	adkClient := agent.NewClient(&cfg.ADK, jwtGen)
+	adkClient.SetTLSConfig(outboundTLS)
+	adkClient.SetUserAgent(cfg.Gateway.UserAgent)
 
 	client, err := whatsapp.New(ctx, cfg, adkClient, verifyHandler, oauthHandler, gwStore)
 
@@ -150,6 +244,15 @@ func main() {
 		log.Fatalf("Failed to connect to WhatsApp: %v", err)
 	}
 
+	if cfg.Gateway.AdminAddr != "" {
+		adminServer, err := startAdminAPI(cfg, gwStore, client)
+		if err != nil {
+			log.Fatalf("Failed to start admin API: %v", err)
+		}
+		defer adminServer.Close()
+		fmt.Printf("🛠️ Admin API listening on %s/admin/\n", cfg.Gateway.AdminAddr)
+	}
+
 	if err := client.Run(ctx); err != nil {
 		log.Fatalf("Gateway error: %v", err)
 	}
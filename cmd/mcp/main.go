@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -202,6 +205,277 @@ func BlacklistRemove(ctx context.Context, s *store.Store, args BlacklistRemoveAr
 	}, nil, nil
 }
 
+type AppealsListArgs struct {
+	Status string `json:"status"`
+}
+
+func AppealsList(ctx context.Context, s *store.Store, args AppealsListArgs) (*mcp.CallToolResult, any, error) {
+	appeals, err := s.ListAppeals(ctx, args.Status)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := json.MarshalIndent(appeals, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode appeals: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(data),
+			},
+		},
+	}, nil, nil
+}
+
+type AppealResolveArgs struct {
+	Phone    string `json:"phone"`
+	Decision string `json:"decision"`
+}
+
+// AppealResolve accepts an appeal by unblocking the number (locally and
+// remotely) and discarding the appeal, or rejects it. A rejected appeal is
+// kept, so the user still waits out the appeal interval.
+func AppealResolve(ctx context.Context, s *store.Store, args AppealResolveArgs) (*mcp.CallToolResult, any, error) {
+	appeal, err := s.GetAppeal(ctx, args.Phone)
+	if err != nil {
+		return nil, nil, err
+	}
+	if appeal == nil {
+		return nil, nil, fmt.Errorf("no appeal from %s", args.Phone)
+	}
+
+	switch args.Decision {
+	case "accept":
+		result, _, err := BlacklistRemove(ctx, s, BlacklistRemoveArgs{Phone: args.Phone})
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := s.DeleteAppeal(ctx, args.Phone); err != nil {
+			return nil, nil, fmt.Errorf("failed to delete appeal: %w", err)
+		}
+		return result, nil, nil
+	case "reject":
+		appeal.Status = store.AppealRejected
+		if err := s.PutAppeal(ctx, *appeal); err != nil {
+			return nil, nil, fmt.Errorf("failed to update appeal: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Rejected appeal from %s; the number stays blacklisted", args.Phone),
+				},
+			},
+		}, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("decision must be \"accept\" or \"reject\", got %q", args.Decision)
+	}
+}
+
+type GroupArgs struct {
+	JID string `json:"jid"`
+}
+
+// groupJID accepts a group ID with or without the "@g.us" server.
+func groupJID(jid string) string {
+	jid = strings.TrimSpace(jid)
+	if jid != "" && !strings.Contains(jid, "@") {
+		jid += "@g.us"
+	}
+	return jid
+}
+
+// GroupAllow lets the agent answer in a group without editing the config.
+// The gateway still needs whatsapp.groups.enabled.
+func GroupAllow(ctx context.Context, s *store.Store, args GroupArgs) (*mcp.CallToolResult, any, error) {
+	jid := groupJID(args.JID)
+	if jid == "" {
+		return nil, nil, fmt.Errorf("jid is required")
+	}
+	if err := s.AddAllowedGroup(ctx, jid); err != nil {
+		return nil, nil, fmt.Errorf("failed to allow group: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Allowed group %s", jid),
+			},
+		},
+	}, nil, nil
+}
+
+func GroupDisallow(ctx context.Context, s *store.Store, args GroupArgs) (*mcp.CallToolResult, any, error) {
+	jid := groupJID(args.JID)
+	if jid == "" {
+		return nil, nil, fmt.Errorf("jid is required")
+	}
+	if err := s.RemoveAllowedGroup(ctx, jid); err != nil {
+		return nil, nil, fmt.Errorf("failed to disallow group: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Removed group %s from the runtime allowlist", jid),
+			},
+		},
+	}, nil, nil
+}
+
+type GroupListArgs struct{}
+
+func GroupList(ctx context.Context, s *store.Store, _ GroupListArgs) (*mcp.CallToolResult, any, error) {
+	groups, err := s.ListAllowedGroups(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode groups: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(data),
+			},
+		},
+	}, nil, nil
+}
+
+type BroadcastRecipient struct {
+	JID  string            `json:"jid"`
+	Vars map[string]string `json:"vars,omitempty"`
+}
+
+type BroadcastSendArgs struct {
+	Template   string               `json:"template"`
+	Recipients []BroadcastRecipient `json:"recipients"`
+}
+
+// BroadcastSend stores a broadcast with a pending delivery per recipient
+// and asks the gateway to send it. The gateway paces the messages by
+// whatsapp.broadcasts.interval and records each recipient's status.
+func BroadcastSend(ctx context.Context, s *store.Store, args BroadcastSendArgs) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(args.Template) == "" {
+		return nil, nil, fmt.Errorf("template is required")
+	}
+	if len(args.Recipients) == 0 {
+		return nil, nil, fmt.Errorf("at least one recipient is required")
+	}
+	if _, err := template.New("broadcast").Parse(args.Template); err != nil {
+		return nil, nil, fmt.Errorf("invalid template: %w", err)
+	}
+	seen := make(map[string]bool, len(args.Recipients))
+	deliveries := make([]store.BroadcastDelivery, 0, len(args.Recipients))
+	for _, r := range args.Recipients {
+		jid := strings.TrimPrefix(strings.TrimSpace(r.JID), "+")
+		if jid == "" {
+			return nil, nil, fmt.Errorf("recipient without jid")
+		}
+		if seen[jid] {
+			continue
+		}
+		seen[jid] = true
+		deliveries = append(deliveries, store.BroadcastDelivery{JID: jid, Vars: r.Vars})
+	}
+
+	now := time.Now().UTC()
+	b := store.Broadcast{ID: fmt.Sprintf("bc_%d", now.UnixNano()), Template: args.Template, CreatedAt: now}
+	if err := s.CreateBroadcast(ctx, b, deliveries); err != nil {
+		return nil, nil, err
+	}
+
+	cmdID, err := s.EnqueueCommand(ctx, "broadcast", map[string]string{"id": b.ID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to enqueue broadcast: %w", err)
+	}
+	cmd, err := s.WaitForCommand(ctx, cmdID, 10*time.Second)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Broadcast %s to %d recipient(s) stored, but the Gateway response timed out. It will start when the Gateway is online.", b.ID, len(deliveries)),
+				},
+			},
+		}, nil, nil
+	}
+	if cmd.Status == "failed" {
+		return nil, nil, fmt.Errorf("broadcast %s stored but not started: %s", b.ID, string(cmd.Result))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Broadcast %s to %d recipient(s) started. Check progress with broadcast_status.", b.ID, len(deliveries)),
+			},
+		},
+	}, nil, nil
+}
+
+type BroadcastStatusArgs struct {
+	ID string `json:"id"`
+}
+
+// BroadcastResume restarts a broadcast interrupted by a gateway restart.
+// Recipients already sent to or failed are skipped.
+func BroadcastResume(ctx context.Context, s *store.Store, args BroadcastStatusArgs) (*mcp.CallToolResult, any, error) {
+	cmdID, err := s.EnqueueCommand(ctx, "broadcast", map[string]string{"id": args.ID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to enqueue broadcast: %w", err)
+	}
+	cmd, err := s.WaitForCommand(ctx, cmdID, 10*time.Second)
+	if err != nil {
+		return nil, nil, fmt.Errorf("request timed out: %w", err)
+	}
+	if cmd.Status == "failed" {
+		return nil, nil, fmt.Errorf("broadcast %s not resumed: %s", args.ID, string(cmd.Result))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Broadcast %s resumed", args.ID),
+			},
+		},
+	}, nil, nil
+}
+
+// BroadcastStatus reports how many recipients of a broadcast are pending,
+// sent and failed, and lists the failures.
+func BroadcastStatus(ctx context.Context, s *store.Store, args BroadcastStatusArgs) (*mcp.CallToolResult, any, error) {
+	b, err := s.GetBroadcast(ctx, args.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b == nil {
+		return nil, nil, fmt.Errorf("broadcast %q not found", args.ID)
+	}
+	deliveries, err := s.ListBroadcastDeliveries(ctx, args.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts := make(map[string]int)
+	var failed []store.BroadcastDelivery
+	for _, d := range deliveries {
+		counts[d.Status]++
+		if d.Status == store.BroadcastFailed {
+			failed = append(failed, d)
+		}
+	}
+	data, err := json.MarshalIndent(map[string]any{
+		"id":         b.ID,
+		"created_at": b.CreatedAt,
+		"counts":     counts,
+		"failed":     failed,
+	}, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode broadcast status: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: string(data),
+			},
+		},
+	}, nil, nil
+}
+
 type BlacklistGetRemoteArgs struct{}
 
 func BlacklistGetRemote(ctx context.Context, s *store.Store, _ BlacklistGetRemoteArgs) (*mcp.CallToolResult, any, error) {
@@ -567,12 +841,41 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	s, err := store.Open(cfg.WhatsApp.StoreDSN)
+	migrateBackoff, err := time.ParseDuration(cfg.Store.MigrateBackoff)
+	if err != nil {
+		log.Fatalf("Invalid store migrate_backoff %q: %v", cfg.Store.MigrateBackoff, err)
+	}
+	replicaLag, err := time.ParseDuration(cfg.Store.ReplicaLag)
+	if err != nil {
+		log.Fatalf("Invalid store replica_lag %q: %v", cfg.Store.ReplicaLag, err)
+	}
+	s, err := store.OpenWith(cfg.WhatsApp.StoreDSN, store.Options{
+		MigrateAttempts: cfg.Store.MigrateAttempts,
+		MigrateBackoff:  migrateBackoff,
+		ReadDSN:         cfg.Store.ReadDSN,
+		ReplicaLag:      replicaLag,
+		SchemaUpgrade:   cfg.Store.SchemaUpgrade,
+	})
 	if err != nil {
 		log.Fatalf("Failed to open store: %v", err)
 	}
 	defer s.Close()
 
+	if len(cfg.Blacklist.NotifyURLs) > 0 {
+		timeout, err := time.ParseDuration(cfg.Blacklist.NotifyTimeout)
+		if err != nil {
+			log.Fatalf("Invalid blacklist notify_timeout %q: %v", cfg.Blacklist.NotifyTimeout, err)
+		}
+		outboundTLS, err := cfg.TLS.TLSConfig()
+		if err != nil {
+			log.Fatalf("Invalid tls config: %v", err)
+		}
+		notifier := store.NewWebhookNotifier(cfg.Blacklist.NotifyURLs, timeout, slog.Default())
+		notifier.SetTLSConfig(outboundTLS)
+		defer notifier.Wait()
+		s.SetBlacklistNotifier(notifier)
+	}
+
 	ctx := context.Background()
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "whatsadk",
@@ -594,6 +897,62 @@ func main() {
 		return BlacklistRemove(ctx, s, args)
 	})
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "appeals_list",
+		Description: "List appeals from blacklisted users, optionally filtered by status (pending or rejected)",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args AppealsListArgs) (*mcp.CallToolResult, any, error) {
+		return AppealsList(ctx, s, args)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "appeal_resolve",
+		Description: "Resolve a blacklist appeal: decision \"accept\" unblocks the number, \"reject\" keeps it blocked",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args AppealResolveArgs) (*mcp.CallToolResult, any, error) {
+		return AppealResolve(ctx, s, args)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "group_allow",
+		Description: "Let the agent answer in a WhatsApp group (JID, with or without @g.us) without editing the config",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args GroupArgs) (*mcp.CallToolResult, any, error) {
+		return GroupAllow(ctx, s, args)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "group_disallow",
+		Description: "Remove a WhatsApp group added with group_allow; groups listed in the config stay allowed",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args GroupArgs) (*mcp.CallToolResult, any, error) {
+		return GroupDisallow(ctx, s, args)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "group_list",
+		Description: "List WhatsApp groups allowed at runtime with group_allow",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args GroupListArgs) (*mcp.CallToolResult, any, error) {
+		return GroupList(ctx, s, args)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "broadcast_send",
+		Description: "Send a templated text message to many users. template uses Go text/template syntax, e.g. \"Hi {{.name}}\", rendered with each recipient's vars. Messages are paced by the gateway; returns the broadcast id.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args BroadcastSendArgs) (*mcp.CallToolResult, any, error) {
+		return BroadcastSend(ctx, s, args)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "broadcast_status",
+		Description: "Show how many recipients of a broadcast are pending, sent or failed, with the failure reasons",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args BroadcastStatusArgs) (*mcp.CallToolResult, any, error) {
+		return BroadcastStatus(ctx, s, args)
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "broadcast_resume",
+		Description: "Continue a broadcast interrupted by a gateway restart; recipients already handled are skipped",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args BroadcastStatusArgs) (*mcp.CallToolResult, any, error) {
+		return BroadcastResume(ctx, s, args)
+	})
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "blacklist_get_remote",
 		Description: "Fetch the official blocklist from WhatsApp servers",
@@ -74,7 +74,23 @@ func runExport() {
 		os.Exit(1)
 	}
 
-	s, err := store.Open(cfg.WhatsApp.StoreDSN)
+	migrateBackoff, err := time.ParseDuration(cfg.Store.MigrateBackoff)
+	if err != nil {
+		fmt.Printf("Invalid store migrate_backoff %q: %v\n", cfg.Store.MigrateBackoff, err)
+		os.Exit(1)
+	}
+	replicaLag, err := time.ParseDuration(cfg.Store.ReplicaLag)
+	if err != nil {
+		fmt.Printf("Invalid store replica_lag %q: %v\n", cfg.Store.ReplicaLag, err)
+		os.Exit(1)
+	}
+	s, err := store.OpenWith(cfg.WhatsApp.StoreDSN, store.Options{
+		MigrateAttempts: cfg.Store.MigrateAttempts,
+		MigrateBackoff:  migrateBackoff,
+		ReadDSN:         cfg.Store.ReadDSN,
+		ReplicaLag:      replicaLag,
+		SchemaUpgrade:   cfg.Store.SchemaUpgrade,
+	})
 	if err != nil {
 		fmt.Printf("Error opening store: %v\n", err)
 		os.Exit(1)
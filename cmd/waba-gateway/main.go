@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -36,7 +37,21 @@ func main() {
 	}
 
 	// Initialize Store
-	s, err := store.Open(cfg.WhatsApp.StoreDSN)
+	migrateBackoff, err := time.ParseDuration(cfg.Store.MigrateBackoff)
+	if err != nil {
+		log.Fatalf("Invalid store migrate_backoff %q: %v", cfg.Store.MigrateBackoff, err)
+	}
+	replicaLag, err := time.ParseDuration(cfg.Store.ReplicaLag)
+	if err != nil {
+		log.Fatalf("Invalid store replica_lag %q: %v", cfg.Store.ReplicaLag, err)
+	}
+	s, err := store.OpenWith(cfg.WhatsApp.StoreDSN, store.Options{
+		MigrateAttempts: cfg.Store.MigrateAttempts,
+		MigrateBackoff:  migrateBackoff,
+		ReadDSN:         cfg.Store.ReadDSN,
+		ReplicaLag:      replicaLag,
+		SchemaUpgrade:   cfg.Store.SchemaUpgrade,
+	})
 	if err != nil {
 		log.Fatalf("Failed to open store: %v", err)
 	}
@@ -55,11 +70,24 @@ func main() {
 		if err != nil {
 			log.Fatalf("Failed to initialize JWT: %v", err)
 		}
+		if cfg.Auth.JWT.KeyID != "" {
+			jwtGen.SetKeyID(cfg.Auth.JWT.KeyID)
+		}
+		if err := jwtGen.AddPublishedKeys(cfg.Auth.JWT.PublishedKeyPaths); err != nil {
+			log.Fatalf("Failed to load published JWT keys: %v", err)
+		}
 	}
 
 	// Initialize Clients
+	outboundTLS, err := cfg.TLS.TLSConfig()
+	if err != nil {
+		log.Fatalf("Invalid TLS config: %v", err)
+	}
 	adkClient := agent.NewClient(&cfg.ADK, jwtGen)
+	adkClient.SetTLSConfig(outboundTLS)
+	adkClient.SetUserAgent(cfg.Gateway.UserAgent)
 	wabaClient := waba.NewClient(&cfg.WABA)
+	wabaClient.SetTLSConfig(outboundTLS)
 	mediaProc := whatsapp.NewProcessor()
 
 	// Webhook handler logic
@@ -129,7 +157,12 @@ func main() {
 		respParts, err := adkClient.ChatParts(ctx, sender, processedParts)
 		if err != nil {
 			appLogger.Error("ADK Error", "error", err)
-			wabaClient.SendText(ctx, sender, "Sorry, I encountered an error processing your message.")
+			reply := "Sorry, I encountered an error processing your message."
+			var rl *agent.RateLimitedError
+			if errors.As(err, &rl) {
+				reply = cfg.ADK.RateLimit.BusyMessage
+			}
+			wabaClient.SendText(ctx, sender, reply)
 			return
 		}
 
@@ -208,6 +241,18 @@ func main() {
 	fmt.Printf("🚀 WABA Gateway listening on %s/webhook\n", addr)
 
 	http.Handle("/webhook", handler)
+	if jwtGen != nil {
+		http.Handle(auth.JWKSPath, auth.JWKSHandler(jwtGen.JWKS()))
+	}
+	if admin := cfg.Auth.Admin; admin.BearerToken != "" || admin.HMACSecret != "" {
+		maxSkew, err := time.ParseDuration(admin.MaxSkew)
+		if err != nil {
+			log.Fatalf("Invalid auth admin max_skew %q: %v", admin.MaxSkew, err)
+		}
+		authn := auth.NewRequestAuthenticator(admin.BearerToken, []byte(admin.HMACSecret), maxSkew)
+		http.Handle("/admin/send_template", authn.Middleware(waba.NewTemplateSendHandler(wabaClient)))
+		fmt.Printf("📨 Proactive template sends enabled on %s/admin/send_template\n", addr)
+	}
 
 	server := &http.Server{
 		Addr: addr,
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/adminapi"
+	"github.com/innomon/whatsadk/internal/config"
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// env is what commands act on. lists is the gateway store, or the admin
+// API when api is set.
+type env struct {
+	lists  adminapi.Store
+	api    *adminapi.Client
+	out    io.Writer
+	asJSON bool
+}
+
+type command struct {
+	usage string
+	// apiOnly commands act on the running gateway and need -api.
+	apiOnly bool
+	run     func(ctx context.Context, e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"blacklist list": {
+		usage: "blacklist list",
+		run: func(ctx context.Context, e *env, args []string) error {
+			numbers, err := e.lists.ListBlacklist(ctx)
+			if err != nil {
+				return err
+			}
+			return e.table(numbers, "PHONE\tREASON\tADDED", func(w io.Writer) {
+				for _, n := range numbers {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", n.Phone, n.Reason, n.CreatedAt.Format(time.RFC3339))
+				}
+			})
+		},
+	},
+	"blacklist add": {
+		usage: "blacklist add <phone> [reason...]",
+		run: func(ctx context.Context, e *env, args []string) error {
+			if len(args) == 0 {
+				return errUsage
+			}
+			phone, err := adminapi.NormalizePhone(args[0])
+			if err != nil {
+				return err
+			}
+			return e.changed(e.lists.AddBlacklist(ctx, phone, strings.Join(args[1:], " ")), "Blacklisted "+phone)
+		},
+	},
+	"blacklist remove": {
+		usage: "blacklist remove <phone>",
+		run: func(ctx context.Context, e *env, args []string) error {
+			phone, err := phoneArg(args)
+			if err != nil {
+				return err
+			}
+			return e.changed(e.lists.RemoveBlacklist(ctx, phone), "Removed "+phone+" from the blacklist")
+		},
+	},
+	"whitelist list": {
+		usage: "whitelist list",
+		run: func(ctx context.Context, e *env, args []string) error {
+			users, err := e.lists.ListWhitelist(ctx)
+			if err != nil {
+				return err
+			}
+			return e.table(users, "PHONE\tADDED", func(w io.Writer) {
+				for _, u := range users {
+					fmt.Fprintf(w, "%s\t%s\n", u.Phone, u.CreatedAt.Format(time.RFC3339))
+				}
+			})
+		},
+	},
+	"whitelist add": {
+		usage: "whitelist add <phone>",
+		run: func(ctx context.Context, e *env, args []string) error {
+			phone, err := phoneArg(args)
+			if err != nil {
+				return err
+			}
+			return e.changed(e.lists.AddWhitelist(ctx, phone), "Whitelisted "+phone)
+		},
+	},
+	"whitelist remove": {
+		usage: "whitelist remove <phone>",
+		run: func(ctx context.Context, e *env, args []string) error {
+			phone, err := phoneArg(args)
+			if err != nil {
+				return err
+			}
+			return e.changed(e.lists.RemoveWhitelist(ctx, phone), "Removed "+phone+" from the whitelist")
+		},
+	},
+	"verifications list": {
+		usage: "verifications list",
+		run: func(ctx context.Context, e *env, args []string) error {
+			pending, err := e.lists.ListPendingVerifications(ctx)
+			if err != nil {
+				return err
+			}
+			return e.table(pending, "PHONE\tAPP\tCHALLENGE\tEXPIRES", func(w io.Writer) {
+				for _, p := range pending {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Phone, p.AppName, p.ChallengeID, p.ExpiresAt.Format(time.RFC3339))
+				}
+			})
+		},
+	},
+	"verifications cancel": {
+		usage: "verifications cancel <phone>",
+		run: func(ctx context.Context, e *env, args []string) error {
+			phone, err := phoneArg(args)
+			if err != nil {
+				return err
+			}
+			return e.changed(e.lists.DeletePendingVerification(ctx, phone), "Cancelled the pending verification of "+phone)
+		},
+	},
+	"status": {
+		usage:   "status",
+		apiOnly: true,
+		run: func(ctx context.Context, e *env, args []string) error {
+			st, err := e.api.State(ctx)
+			if err != nil {
+				return err
+			}
+			if e.asJSON {
+				return json.NewEncoder(e.out).Encode(st)
+			}
+			fmt.Fprintf(e.out, "connected: %t\nlogged in: %t\n", st.Connected, st.LoggedIn)
+			if st.JID != "" {
+				fmt.Fprintf(e.out, "jid: %s\n", st.JID)
+			}
+			if st.ConnectedSince != nil {
+				fmt.Fprintf(e.out, "connected since: %s\n", st.ConnectedSince.Format(time.RFC3339))
+			}
+			return nil
+		},
+	},
+	"stats": {
+		usage:   "stats",
+		apiOnly: true,
+		run: func(ctx context.Context, e *env, args []string) error {
+			st, err := e.api.Stats(ctx)
+			if err != nil {
+				return err
+			}
+			if e.asJSON {
+				return json.NewEncoder(e.out).Encode(st)
+			}
+			fmt.Fprintf(e.out, "connected: %t\nuptime: %s\nmessages processed: %d\nagent calls: %d (%d failed)\navg agent latency: %dms\n",
+				st.Connected, time.Duration(st.UptimeSeconds)*time.Second, st.MessagesProcessed, st.AgentCalls, st.AgentErrors, st.AvgAgentLatencyMS)
+			return nil
+		},
+	},
+	"relogin": {
+		usage:   "relogin",
+		apiOnly: true,
+		run: func(ctx context.Context, e *env, args []string) error {
+			res, err := e.api.Relogin(ctx)
+			if err != nil {
+				return err
+			}
+			if e.asJSON {
+				return json.NewEncoder(e.out).Encode(res)
+			}
+			if res.QRCode == "" {
+				fmt.Fprintln(e.out, "Reconnected using the existing session.")
+				return nil
+			}
+			fmt.Fprintf(e.out, "No session: scan this pairing code as a QR code with WhatsApp (it is also shown on the gateway's terminal):\n%s\n", res.QRCode)
+			return nil
+		},
+	},
+}
+
+var errUsage = errors.New("missing arguments")
+
+// table writes v as JSON with -json, and as the header and rows otherwise.
+func (e *env) table(v any, header string, rows func(w io.Writer)) error {
+	if e.asJSON {
+		return json.NewEncoder(e.out).Encode(v)
+	}
+	w := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	rows(w)
+	return w.Flush()
+}
+
+// changed reports the outcome of a list change. A WhatsApp blocklist
+// failure is only a warning: the list itself was changed.
+func (e *env) changed(err error, done string) error {
+	var waErr adminapi.WhatsAppError
+	if errors.As(err, &waErr) {
+		fmt.Fprintf(e.out, "%s\nWarning: %v\n", done, waErr)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(e.out, done)
+	return nil
+}
+
+func phoneArg(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errUsage
+	}
+	return adminapi.NormalizePhone(args[0])
+}
+
+// lookup finds the command named by the leading words of args and returns
+// it with the remaining arguments.
+func lookup(args []string) (command, []string, bool) {
+	if len(args) >= 2 {
+		if cmd, ok := commands[args[0]+" "+args[1]]; ok {
+			return cmd, args[2:], true
+		}
+	}
+	if len(args) >= 1 {
+		if cmd, ok := commands[args[0]]; ok {
+			return cmd, args[1:], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func main() {
+	fs := flag.NewFlagSet("whatsadkctl", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to config file (store mode; with -api, only its tls settings are used)")
+	apiURL := fs.String("api", "", "admin API base URL, e.g. http://127.0.0.1:8082; credentials come from ADMIN_HMAC_SECRET or ADMIN_TOKEN")
+	asJSON := fs.Bool("json", false, "print results as JSON")
+	fs.Usage = printUsage
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+
+	cmd, args, ok := lookup(fs.Args())
+	if !ok {
+		printUsage()
+		os.Exit(1)
+	}
+
+	if err := run(cmd, args, *configPath, *apiURL, *asJSON); err != nil {
+		if errors.Is(err, errUsage) {
+			log.Fatalf("Usage: whatsadkctl %s", cmd.usage)
+		}
+		log.Fatal(err)
+	}
+}
+
+// run runs cmd against the admin API at apiURL, or against the gateway
+// store from the config file when apiURL is empty. It returns instead of
+// exiting so that the store is closed and queued blacklist notifications
+// are delivered whether or not the command succeeds.
+func run(cmd command, args []string, configPath, apiURL string, asJSON bool) error {
+	ctx := context.Background()
+	e := &env{out: os.Stdout, asJSON: asJSON}
+	if apiURL != "" {
+		e.api = adminapi.NewClient(apiURL, os.Getenv("ADMIN_TOKEN"), []byte(os.Getenv("ADMIN_HMAC_SECRET")))
+		// With -config, API calls follow the config's outbound tls settings.
+		if configPath != "" {
+			cfg, err := loadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			outboundTLS, err := cfg.TLS.TLSConfig()
+			if err != nil {
+				return fmt.Errorf("invalid tls config: %w", err)
+			}
+			e.api.SetTLSConfig(outboundTLS)
+		}
+		e.lists = e.api
+	} else {
+		if cmd.apiOnly {
+			return fmt.Errorf("%q acts on the running gateway and needs -api", cmd.usage)
+		}
+		cfg, err := loadConfig(configPath)
+		if err != nil {
+			return err
+		}
+		migrateBackoff, err := time.ParseDuration(cfg.Store.MigrateBackoff)
+		if err != nil {
+			return fmt.Errorf("invalid store migrate_backoff %q: %w", cfg.Store.MigrateBackoff, err)
+		}
+		replicaLag, err := time.ParseDuration(cfg.Store.ReplicaLag)
+		if err != nil {
+			return fmt.Errorf("invalid store replica_lag %q: %w", cfg.Store.ReplicaLag, err)
+		}
+		s, err := store.OpenWith(cfg.Verification.DatabaseURL, store.Options{
+			MigrateAttempts: cfg.Store.MigrateAttempts,
+			MigrateBackoff:  migrateBackoff,
+			ReadDSN:         cfg.Store.ReadDSN,
+			ReplicaLag:      replicaLag,
+			SchemaUpgrade:   cfg.Store.SchemaUpgrade,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to open database store: %w", err)
+		}
+		defer s.Close()
+		if len(cfg.Blacklist.NotifyURLs) > 0 {
+			timeout, err := time.ParseDuration(cfg.Blacklist.NotifyTimeout)
+			if err != nil {
+				return fmt.Errorf("invalid blacklist notify_timeout %q: %w", cfg.Blacklist.NotifyTimeout, err)
+			}
+			outboundTLS, err := cfg.TLS.TLSConfig()
+			if err != nil {
+				return fmt.Errorf("invalid tls config: %w", err)
+			}
+			notifier := store.NewWebhookNotifier(cfg.Blacklist.NotifyURLs, timeout, slog.Default())
+			notifier.SetTLSConfig(outboundTLS)
+			// Deliver queued notifications before the command exits.
+			defer notifier.Wait()
+			s.SetBlacklistNotifier(notifier)
+		}
+		e.lists = s
+	}
+
+	if err := cmd.run(ctx, e, args); err != nil {
+		if errors.Is(err, errUsage) {
+			return err
+		}
+		return fmt.Errorf("%s failed: %w", cmd.usage, err)
+	}
+	return nil
+}
+
+// loadConfig loads the config file at path, or the default config when path
+// is empty.
+func loadConfig(path string) (*config.Config, error) {
+	// config.Load parses -config from os.Args itself.
+	os.Args = []string{os.Args[0]}
+	if path != "" {
+		os.Args = append(os.Args, "-config", path)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+func printUsage() {
+	fmt.Println("Usage: whatsadkctl [-config file] [-api URL] [-json] <command> [args]")
+	fmt.Println("Commands:")
+	fmt.Println("  blacklist list")
+	fmt.Println("  blacklist add <phone> [reason...]")
+	fmt.Println("  blacklist remove <phone>")
+	fmt.Println("  whitelist list")
+	fmt.Println("  whitelist add <phone>")
+	fmt.Println("  whitelist remove <phone>")
+	fmt.Println("  verifications list")
+	fmt.Println("  verifications cancel <phone>")
+	fmt.Println("  status      WhatsApp connection state (-api only)")
+	fmt.Println("  stats       Messages processed, agent calls, errors and latency (-api only)")
+	fmt.Println("  relogin     Reconnect, or start pairing without a session (-api only)")
+	fmt.Println("Without -api, commands act on the gateway store from the config file.")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/adminapi"
+	"github.com/innomon/whatsadk/internal/store"
+	"github.com/innomon/whatsadk/internal/verification"
+)
+
+type fakeLists struct {
+	blacklist map[string]string
+	whitelist map[string]bool
+	blockErr  error
+}
+
+func (f *fakeLists) AddBlacklist(_ context.Context, phone, reason string) error {
+	f.blacklist[phone] = reason
+	return f.blockErr
+}
+
+func (f *fakeLists) RemoveBlacklist(_ context.Context, phone string) error {
+	delete(f.blacklist, phone)
+	return f.blockErr
+}
+
+func (f *fakeLists) ListBlacklist(context.Context) ([]store.BlacklistedNumber, error) {
+	var out []store.BlacklistedNumber
+	for phone, reason := range f.blacklist {
+		out = append(out, store.BlacklistedNumber{Phone: phone, Reason: reason})
+	}
+	return out, nil
+}
+
+func (f *fakeLists) AddWhitelist(_ context.Context, phone string) error {
+	f.whitelist[phone] = true
+	return nil
+}
+
+func (f *fakeLists) RemoveWhitelist(_ context.Context, phone string) error {
+	delete(f.whitelist, phone)
+	return nil
+}
+
+func (f *fakeLists) ListWhitelist(context.Context) ([]store.WhitelistedUser, error) {
+	var out []store.WhitelistedUser
+	for phone := range f.whitelist {
+		out = append(out, store.WhitelistedUser{Phone: phone})
+	}
+	return out, nil
+}
+
+func (f *fakeLists) ListPendingVerifications(context.Context) ([]verification.PendingVerification, error) {
+	return nil, nil
+}
+
+func (f *fakeLists) DeletePendingVerification(context.Context, string) error { return nil }
+
+func TestCommands(t *testing.T) {
+	ctx := context.Background()
+	lists := &fakeLists{blacklist: map[string]string{}, whitelist: map[string]bool{}}
+	var out bytes.Buffer
+	e := &env{lists: lists, out: &out}
+
+	run := func(args ...string) error {
+		t.Helper()
+		out.Reset()
+		cmd, rest, ok := lookup(args)
+		if !ok {
+			t.Fatalf("no command for %q", args)
+		}
+		return cmd.run(ctx, e, rest)
+	}
+
+	if err := run("blacklist", "add", "+919811111111", "spam", "calls"); err != nil {
+		t.Fatalf("blacklist add: %v", err)
+	}
+	if lists.blacklist["919811111111"] != "spam calls" {
+		t.Errorf("blacklist = %v", lists.blacklist)
+	}
+	if err := run("blacklist", "list"); err != nil || !strings.Contains(out.String(), "919811111111  spam calls") {
+		t.Errorf("blacklist list: %v\n%s", err, out.String())
+	}
+
+	lists.blockErr = adminapi.WhatsAppError("not connected")
+	if err := run("blacklist", "remove", "919811111111@s.whatsapp.net"); err != nil {
+		t.Fatalf("blacklist remove with WhatsApp error: %v", err)
+	}
+	if len(lists.blacklist) != 0 || !strings.Contains(out.String(), "Warning:") {
+		t.Errorf("blacklist remove: %v\n%s", lists.blacklist, out.String())
+	}
+
+	if err := run("whitelist", "add", "919822222222"); err != nil || !lists.whitelist["919822222222"] {
+		t.Errorf("whitelist add: %v, %v", err, lists.whitelist)
+	}
+	e.asJSON = true
+	if err := run("whitelist", "list"); err != nil || !strings.Contains(out.String(), `"phone":"919822222222"`) {
+		t.Errorf("whitelist list -json: %v\n%s", err, out.String())
+	}
+
+	if err := run("whitelist", "add"); !errors.Is(err, errUsage) {
+		t.Errorf("whitelist add without phone: %v, want errUsage", err)
+	}
+	if err := run("whitelist", "add", "abc"); err == nil {
+		t.Error("whitelist add abc: no error")
+	}
+	if cmd, _, ok := lookup([]string{"status"}); !ok || !cmd.apiOnly {
+		t.Error("status is not an API-only command")
+	}
+	if _, _, ok := lookup([]string{"blacklist"}); ok {
+		t.Error("bare blacklist matched a command")
+	}
+}
+
+func TestRunReturnsErrors(t *testing.T) {
+	cmd, args, _ := lookup([]string{"status"})
+	if err := run(cmd, args, "", "", false); err == nil || !strings.Contains(err.Error(), "needs -api") {
+		t.Errorf("run(status) without -api = %v, want a needs -api error", err)
+	}
+}
@@ -14,6 +14,7 @@ require (
 	github.com/modelcontextprotocol/go-sdk v1.4.1
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
 	github.com/robfig/cron/v3 v3.0.1
+	github.com/surrealdb/surrealdb.go v1.4.0
 	go.mau.fi/whatsmeow v0.0.0-20260305215846-fc65416c22c4
 	golang.org/x/image v0.38.0
 	google.golang.org/adk v1.4.0
@@ -97,7 +98,6 @@ require (
 	github.com/sashabaranov/go-openai v1.41.2 // indirect
 	github.com/segmentio/asm v1.2.1 // indirect
 	github.com/segmentio/encoding v0.5.4 // indirect
-	github.com/surrealdb/surrealdb.go v1.4.0 // indirect
 	github.com/tidwall/gjson v1.18.0 // indirect
 	github.com/tidwall/match v1.2.0 // indirect
 	github.com/tidwall/pretty v1.2.1 // indirect
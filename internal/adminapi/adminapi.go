@@ -0,0 +1,276 @@
+// Package adminapi serves the gateway's admin HTTP API: blacklist,
+// whitelist and pending verification management, runtime stats, the
+// WhatsApp connection state and re-login, so operators need neither
+// database access nor a restart. Client calls it.
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+	"github.com/innomon/whatsadk/internal/verification"
+)
+
+// Store is the store surface the API manages. store.Store implements it.
+type Store interface {
+	AddBlacklist(ctx context.Context, phone, reason string) error
+	RemoveBlacklist(ctx context.Context, phone string) error
+	ListBlacklist(ctx context.Context) ([]store.BlacklistedNumber, error)
+	AddWhitelist(ctx context.Context, phone string) error
+	RemoveWhitelist(ctx context.Context, phone string) error
+	ListWhitelist(ctx context.Context) ([]store.WhitelistedUser, error)
+	ListPendingVerifications(ctx context.Context) ([]verification.PendingVerification, error)
+	DeletePendingVerification(ctx context.Context, phone string) error
+}
+
+// State describes the WhatsApp connection.
+type State struct {
+	Connected bool `json:"connected"`
+	// LoggedIn is false when the device has no session, e.g. after being
+	// logged out from the phone; a re-login then needs a QR code scan.
+	LoggedIn       bool       `json:"logged_in"`
+	JID            string     `json:"jid,omitempty"`
+	ConnectedSince *time.Time `json:"connected_since,omitempty"`
+}
+
+// Stats are the gateway's runtime counters since StartedAt.
+type Stats struct {
+	State
+	StartedAt         time.Time `json:"started_at"`
+	UptimeSeconds     int64     `json:"uptime_seconds"`
+	MessagesProcessed int64     `json:"messages_processed"`
+	AgentCalls        int64     `json:"agent_calls"`
+	AgentErrors       int64     `json:"agent_errors"`
+	// AvgAgentLatencyMS is zero before the first agent call.
+	AvgAgentLatencyMS int64 `json:"avg_agent_latency_ms"`
+}
+
+// Gateway is the running WhatsApp client.
+type Gateway interface {
+	State() State
+	Stats() Stats
+	// Block and Unblock update WhatsApp's own blocklist.
+	Block(phone string) error
+	Unblock(phone string) error
+	// Relogin reconnects, or starts pairing when there is no session and
+	// returns the QR code to scan.
+	Relogin(ctx context.Context) (qrCode string, err error)
+}
+
+// PhoneRequest is the body of POST /admin/blacklist and /admin/whitelist.
+// Reason only applies to the blacklist.
+type PhoneRequest struct {
+	Phone  string `json:"phone"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PhoneResult reports a list change. WhatsAppError is set when the
+// blacklist was updated but WhatsApp's blocklist was not.
+type PhoneResult struct {
+	Phone         string `json:"phone"`
+	WhatsAppError string `json:"whatsapp_error,omitempty"`
+}
+
+// ReloginResult reports a re-login. QRCode is set when the gateway had no
+// session and is waiting for the code to be scanned.
+type ReloginResult struct {
+	Status string `json:"status"`
+	QRCode string `json:"qr_code,omitempty"`
+}
+
+// NewHandler returns the admin API:
+//
+//	GET    /admin/blacklist              list blacklisted numbers
+//	POST   /admin/blacklist              blacklist and block a number
+//	DELETE /admin/blacklist/{phone}      remove a number and unblock it
+//	GET    /admin/whitelist              list numbers whitelisted at runtime
+//	POST   /admin/whitelist              whitelist a number
+//	DELETE /admin/whitelist/{phone}      remove a runtime whitelist entry
+//	GET    /admin/verifications          list pending verifications
+//	DELETE /admin/verifications/{phone}  cancel a pending verification
+//	GET    /admin/status                 WhatsApp connection state
+//	GET    /admin/stats                  runtime counters and uptime
+//	POST   /admin/relogin                reconnect or start pairing
+//
+// Callers must wrap it in authentication.
+func NewHandler(s Store, gw Gateway) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/blacklist", func(w http.ResponseWriter, r *http.Request) {
+		numbers, err := s.ListBlacklist(r.Context())
+		if err != nil {
+			slog.Error("admin api: list blacklist failed", "error", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+		if numbers == nil {
+			numbers = []store.BlacklistedNumber{}
+		}
+		writeJSON(w, http.StatusOK, numbers)
+	})
+	mux.HandleFunc("POST /admin/blacklist", func(w http.ResponseWriter, r *http.Request) {
+		req, phone, ok := decodePhoneRequest(w, r)
+		if !ok {
+			return
+		}
+		if err := s.AddBlacklist(r.Context(), phone, req.Reason); err != nil {
+			slog.Error("admin api: blacklist failed", "phone", phone, "error", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+		res := PhoneResult{Phone: phone}
+		if err := gw.Block(phone); err != nil {
+			res.WhatsAppError = err.Error()
+		}
+		slog.Info("admin api: blacklisted", "phone", phone, "reason", req.Reason)
+		writeJSON(w, http.StatusOK, res)
+	})
+	mux.HandleFunc("DELETE /admin/blacklist/{phone}", func(w http.ResponseWriter, r *http.Request) {
+		phone, err := NormalizePhone(r.PathValue("phone"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.RemoveBlacklist(r.Context(), phone); err != nil {
+			slog.Error("admin api: unblacklist failed", "phone", phone, "error", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+		res := PhoneResult{Phone: phone}
+		if err := gw.Unblock(phone); err != nil {
+			res.WhatsAppError = err.Error()
+		}
+		slog.Info("admin api: removed from blacklist", "phone", phone)
+		writeJSON(w, http.StatusOK, res)
+	})
+	mux.HandleFunc("GET /admin/whitelist", func(w http.ResponseWriter, r *http.Request) {
+		users, err := s.ListWhitelist(r.Context())
+		if err != nil {
+			slog.Error("admin api: list whitelist failed", "error", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+		if users == nil {
+			users = []store.WhitelistedUser{}
+		}
+		writeJSON(w, http.StatusOK, users)
+	})
+	mux.HandleFunc("POST /admin/whitelist", func(w http.ResponseWriter, r *http.Request) {
+		_, phone, ok := decodePhoneRequest(w, r)
+		if !ok {
+			return
+		}
+		if err := s.AddWhitelist(r.Context(), phone); err != nil {
+			slog.Error("admin api: whitelist failed", "phone", phone, "error", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+		slog.Info("admin api: whitelisted", "phone", phone)
+		writeJSON(w, http.StatusOK, PhoneResult{Phone: phone})
+	})
+	mux.HandleFunc("DELETE /admin/whitelist/{phone}", func(w http.ResponseWriter, r *http.Request) {
+		phone, err := NormalizePhone(r.PathValue("phone"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.RemoveWhitelist(r.Context(), phone); err != nil {
+			slog.Error("admin api: unwhitelist failed", "phone", phone, "error", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+		slog.Info("admin api: removed from whitelist", "phone", phone)
+		writeJSON(w, http.StatusOK, PhoneResult{Phone: phone})
+	})
+	mux.HandleFunc("GET /admin/verifications", func(w http.ResponseWriter, r *http.Request) {
+		pending, err := s.ListPendingVerifications(r.Context())
+		if err != nil {
+			slog.Error("admin api: list verifications failed", "error", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+		if pending == nil {
+			pending = []verification.PendingVerification{}
+		}
+		writeJSON(w, http.StatusOK, pending)
+	})
+	mux.HandleFunc("DELETE /admin/verifications/{phone}", func(w http.ResponseWriter, r *http.Request) {
+		phone, err := NormalizePhone(r.PathValue("phone"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.DeletePendingVerification(r.Context(), phone); err != nil {
+			slog.Error("admin api: delete verification failed", "phone", phone, "error", err)
+			http.Error(w, "store error", http.StatusInternalServerError)
+			return
+		}
+		slog.Info("admin api: cancelled pending verification", "phone", phone)
+		writeJSON(w, http.StatusOK, PhoneResult{Phone: phone})
+	})
+	mux.HandleFunc("GET /admin/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, gw.State())
+	})
+	mux.HandleFunc("GET /admin/stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, gw.Stats())
+	})
+	mux.HandleFunc("POST /admin/relogin", func(w http.ResponseWriter, r *http.Request) {
+		code, err := gw.Relogin(r.Context())
+		if err != nil {
+			slog.Error("admin api: relogin failed", "error", err)
+			http.Error(w, "relogin failed: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		res := ReloginResult{Status: "reconnected"}
+		if code != "" {
+			res = ReloginResult{Status: "pairing", QRCode: code}
+		}
+		slog.Info("admin api: relogin", "status", res.Status)
+		writeJSON(w, http.StatusOK, res)
+	})
+	return mux
+}
+
+// decodePhoneRequest reads a PhoneRequest and its normalized phone,
+// answering 400 itself when either is invalid.
+func decodePhoneRequest(w http.ResponseWriter, r *http.Request) (PhoneRequest, string, bool) {
+	var req PhoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return req, "", false
+	}
+	phone, err := NormalizePhone(req.Phone)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req, "", false
+	}
+	return req, phone, true
+}
+
+// NormalizePhone accepts E.164 digits with or without "+" or a user JID
+// and returns the digits.
+func NormalizePhone(arg string) (string, error) {
+	phone := strings.TrimSuffix(strings.TrimPrefix(arg, "+"), "@s.whatsapp.net")
+	if phone == "" {
+		return "", fmt.Errorf("invalid phone number %q", arg)
+	}
+	for _, r := range phone {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("invalid phone number %q", arg)
+		}
+	}
+	return phone, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("admin api: write response failed", "error", err)
+	}
+}
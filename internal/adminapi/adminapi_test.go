@@ -0,0 +1,181 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+	"github.com/innomon/whatsadk/internal/verification"
+)
+
+type fakeStore struct {
+	numbers     map[string]string
+	whitelisted map[string]bool
+	pending     []verification.PendingVerification
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{numbers: map[string]string{}, whitelisted: map[string]bool{}}
+}
+
+func (f *fakeStore) AddBlacklist(_ context.Context, phone, reason string) error {
+	f.numbers[phone] = reason
+	return nil
+}
+
+func (f *fakeStore) RemoveBlacklist(_ context.Context, phone string) error {
+	delete(f.numbers, phone)
+	return nil
+}
+
+func (f *fakeStore) ListBlacklist(_ context.Context) ([]store.BlacklistedNumber, error) {
+	var out []store.BlacklistedNumber
+	for phone, reason := range f.numbers {
+		out = append(out, store.BlacklistedNumber{Phone: phone, Reason: reason})
+	}
+	return out, nil
+}
+
+func (f *fakeStore) AddWhitelist(_ context.Context, phone string) error {
+	f.whitelisted[phone] = true
+	return nil
+}
+
+func (f *fakeStore) RemoveWhitelist(_ context.Context, phone string) error {
+	delete(f.whitelisted, phone)
+	return nil
+}
+
+func (f *fakeStore) ListWhitelist(_ context.Context) ([]store.WhitelistedUser, error) {
+	var out []store.WhitelistedUser
+	for phone := range f.whitelisted {
+		out = append(out, store.WhitelistedUser{Phone: phone})
+	}
+	return out, nil
+}
+
+func (f *fakeStore) ListPendingVerifications(_ context.Context) ([]verification.PendingVerification, error) {
+	return f.pending, nil
+}
+
+func (f *fakeStore) DeletePendingVerification(_ context.Context, phone string) error {
+	var kept []verification.PendingVerification
+	for _, p := range f.pending {
+		if p.Phone != phone {
+			kept = append(kept, p)
+		}
+	}
+	f.pending = kept
+	return nil
+}
+
+type fakeGateway struct {
+	state   State
+	blocked map[string]bool
+	qr      string
+}
+
+func (g *fakeGateway) State() State { return g.state }
+
+func (g *fakeGateway) Stats() Stats {
+	return Stats{State: g.state, UptimeSeconds: 60, MessagesProcessed: 3, AgentCalls: 2, AgentErrors: 1, AvgAgentLatencyMS: 250}
+}
+
+func (g *fakeGateway) Block(phone string) error {
+	if !g.state.Connected {
+		return errors.New("not connected")
+	}
+	g.blocked[phone] = true
+	return nil
+}
+
+func (g *fakeGateway) Unblock(phone string) error {
+	delete(g.blocked, phone)
+	return nil
+}
+
+func (g *fakeGateway) Relogin(context.Context) (string, error) { return g.qr, nil }
+
+func TestHandler(t *testing.T) {
+	since := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	st := newFakeStore()
+	gw := &fakeGateway{state: State{Connected: true, LoggedIn: true, JID: "919800000000@s.whatsapp.net", ConnectedSince: &since}, blocked: map[string]bool{}}
+	h := NewHandler(st, gw)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/admin/blacklist", `{"phone": "+919811111111", "reason": "spam"}`)
+	var res PhoneResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("add: %d %s", rec.Code, rec.Body)
+	}
+	if res.Phone != "919811111111" || res.WhatsAppError != "" || st.numbers["919811111111"] != "spam" || !gw.blocked["919811111111"] {
+		t.Errorf("add result %+v, store %v, blocked %v", res, st.numbers, gw.blocked)
+	}
+	if rec := do(http.MethodPost, "/admin/blacklist", `{"phone": "abc"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid phone: status %d, want 400", rec.Code)
+	}
+
+	rec = do(http.MethodGet, "/admin/blacklist", "")
+	var numbers []store.BlacklistedNumber
+	if err := json.Unmarshal(rec.Body.Bytes(), &numbers); err != nil || len(numbers) != 1 || numbers[0].Phone != "919811111111" {
+		t.Errorf("list: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = do(http.MethodDelete, "/admin/blacklist/919811111111@s.whatsapp.net", "")
+	if rec.Code != http.StatusOK || len(st.numbers) != 0 || gw.blocked["919811111111"] {
+		t.Errorf("remove: %d %s, store %v", rec.Code, rec.Body, st.numbers)
+	}
+	if rec := do(http.MethodGet, "/admin/blacklist", ""); strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("empty list = %s, want []", rec.Body)
+	}
+
+	gw.state.Connected = false
+	rec = do(http.MethodPost, "/admin/blacklist", `{"phone": "919822222222"}`)
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || res.WhatsAppError != "not connected" || st.numbers["919822222222"] != "" {
+		t.Errorf("add while disconnected: %d %s", rec.Code, rec.Body)
+	}
+	if _, ok := st.numbers["919822222222"]; !ok {
+		t.Error("number not blacklisted while disconnected")
+	}
+
+	rec = do(http.MethodGet, "/admin/status", "")
+	var state State
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil || state.Connected || !state.LoggedIn || state.ConnectedSince == nil {
+		t.Errorf("status: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = do(http.MethodGet, "/admin/stats", "")
+	var stats Stats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil || stats.MessagesProcessed != 3 || stats.AgentErrors != 1 || stats.AvgAgentLatencyMS != 250 || !stats.LoggedIn {
+		t.Errorf("stats: %d %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), `"connected":false`) {
+		t.Errorf("stats lacks the connection state: %s", rec.Body)
+	}
+
+	var relogin ReloginResult
+	rec = do(http.MethodPost, "/admin/relogin", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &relogin); err != nil || relogin.Status != "reconnected" {
+		t.Errorf("relogin: %d %s", rec.Code, rec.Body)
+	}
+	gw.qr = "2@abc"
+	rec = do(http.MethodPost, "/admin/relogin", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &relogin); err != nil || relogin.Status != "pairing" || relogin.QRCode != "2@abc" {
+		t.Errorf("relogin without session: %d %s", rec.Code, rec.Body)
+	}
+
+	if rec := do(http.MethodGet, "/admin/relogin", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET relogin: status %d, want 405", rec.Code)
+	}
+}
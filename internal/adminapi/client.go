@@ -0,0 +1,175 @@
+package adminapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/auth"
+	"github.com/innomon/whatsadk/internal/store"
+	"github.com/innomon/whatsadk/internal/verification"
+)
+
+// WhatsAppError is returned by Client.AddBlacklist and RemoveBlacklist when
+// the blacklist was changed but WhatsApp's own blocklist was not.
+type WhatsAppError string
+
+func (e WhatsAppError) Error() string {
+	return "blacklist updated, but not WhatsApp's blocklist: " + string(e)
+}
+
+// Client calls the admin API. It implements Store, so tools can manage the
+// lists through the API or the store alike.
+type Client struct {
+	base   string
+	bearer string
+	secret []byte
+	http   *http.Client
+	now    func() time.Time
+}
+
+// NewClient returns a client for the API at baseURL (e.g.
+// "http://127.0.0.1:8082"). Requests are signed with secret when it is set,
+// and carry bearer otherwise.
+func NewClient(baseURL, bearer string, secret []byte) *Client {
+	return &Client{
+		base:   strings.TrimSuffix(baseURL, "/"),
+		bearer: bearer,
+		secret: secret,
+		http:   &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// SetTLSConfig applies outbound TLS restrictions (minimum version, cipher
+// suites) to API requests.
+func (c *Client) SetTLSConfig(tlsCfg *tls.Config) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg.Clone()
+	c.http.Transport = transport
+}
+
+// do sends in as JSON, if not nil, and decodes the answer into out, if not
+// nil. Non-2xx answers are returned as errors with the response text.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(c.secret) > 0 {
+		ts := c.now()
+		req.Header.Set(auth.SignatureTimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+		req.Header.Set(auth.SignatureHeader, auth.SignRequest(c.secret, method, req.URL.RequestURI(), ts, body))
+	} else if c.bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearer)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if err != nil {
+			return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// phoneChange runs a list change and reports a WhatsApp blocklist failure.
+func (c *Client) phoneChange(ctx context.Context, method, path string, in any) error {
+	var res PhoneResult
+	if err := c.do(ctx, method, path, in, &res); err != nil {
+		return err
+	}
+	if res.WhatsAppError != "" {
+		return WhatsAppError(res.WhatsAppError)
+	}
+	return nil
+}
+
+func (c *Client) AddBlacklist(ctx context.Context, phone, reason string) error {
+	return c.phoneChange(ctx, http.MethodPost, "/admin/blacklist", PhoneRequest{Phone: phone, Reason: reason})
+}
+
+func (c *Client) RemoveBlacklist(ctx context.Context, phone string) error {
+	return c.phoneChange(ctx, http.MethodDelete, "/admin/blacklist/"+url.PathEscape(phone), nil)
+}
+
+func (c *Client) ListBlacklist(ctx context.Context) ([]store.BlacklistedNumber, error) {
+	var numbers []store.BlacklistedNumber
+	err := c.do(ctx, http.MethodGet, "/admin/blacklist", nil, &numbers)
+	return numbers, err
+}
+
+func (c *Client) AddWhitelist(ctx context.Context, phone string) error {
+	return c.phoneChange(ctx, http.MethodPost, "/admin/whitelist", PhoneRequest{Phone: phone})
+}
+
+func (c *Client) RemoveWhitelist(ctx context.Context, phone string) error {
+	return c.phoneChange(ctx, http.MethodDelete, "/admin/whitelist/"+url.PathEscape(phone), nil)
+}
+
+func (c *Client) ListWhitelist(ctx context.Context) ([]store.WhitelistedUser, error) {
+	var users []store.WhitelistedUser
+	err := c.do(ctx, http.MethodGet, "/admin/whitelist", nil, &users)
+	return users, err
+}
+
+func (c *Client) ListPendingVerifications(ctx context.Context) ([]verification.PendingVerification, error) {
+	var pending []verification.PendingVerification
+	err := c.do(ctx, http.MethodGet, "/admin/verifications", nil, &pending)
+	return pending, err
+}
+
+func (c *Client) DeletePendingVerification(ctx context.Context, phone string) error {
+	return c.phoneChange(ctx, http.MethodDelete, "/admin/verifications/"+url.PathEscape(phone), nil)
+}
+
+// State returns the WhatsApp connection state.
+func (c *Client) State(ctx context.Context) (State, error) {
+	var st State
+	err := c.do(ctx, http.MethodGet, "/admin/status", nil, &st)
+	return st, err
+}
+
+// Stats returns the gateway's runtime counters.
+func (c *Client) Stats(ctx context.Context) (Stats, error) {
+	var st Stats
+	err := c.do(ctx, http.MethodGet, "/admin/stats", nil, &st)
+	return st, err
+}
+
+// Relogin asks the gateway to reconnect or start pairing.
+func (c *Client) Relogin(ctx context.Context) (ReloginResult, error) {
+	var res ReloginResult
+	err := c.do(ctx, http.MethodPost, "/admin/relogin", nil, &res)
+	return res, err
+}
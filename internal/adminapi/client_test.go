@@ -0,0 +1,87 @@
+package adminapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/auth"
+	"github.com/innomon/whatsadk/internal/verification"
+)
+
+func TestClient(t *testing.T) {
+	st := newFakeStore()
+	st.pending = []verification.PendingVerification{{Phone: "919811111111", AppName: "app-a", ChallengeID: "c1"}}
+	gw := &fakeGateway{state: State{Connected: true, LoggedIn: true}, blocked: map[string]bool{}}
+	secret := []byte("s3cret")
+	authn := auth.NewRequestAuthenticator("token", secret, time.Minute)
+	srv := httptest.NewServer(authn.Middleware(NewHandler(st, gw)))
+	defer srv.Close()
+	ctx := context.Background()
+
+	for name, c := range map[string]*Client{
+		"signed": NewClient(srv.URL+"/", "", secret),
+		"bearer": NewClient(srv.URL, "token", nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := c.AddWhitelist(ctx, "+919822222222"); err != nil {
+				t.Fatalf("AddWhitelist() error: %v", err)
+			}
+			users, err := c.ListWhitelist(ctx)
+			if err != nil || len(users) != 1 || users[0].Phone != "919822222222" {
+				t.Errorf("ListWhitelist() = %+v, %v", users, err)
+			}
+			if err := c.RemoveWhitelist(ctx, "919822222222"); err != nil || len(st.whitelisted) != 0 {
+				t.Errorf("RemoveWhitelist() error %v, store %v", err, st.whitelisted)
+			}
+			if state, err := c.State(ctx); err != nil || !state.Connected {
+				t.Errorf("State() = %+v, %v", state, err)
+			}
+		})
+	}
+
+	c := NewClient(srv.URL, "", secret)
+	pending, err := c.ListPendingVerifications(ctx)
+	if err != nil || len(pending) != 1 || pending[0].ChallengeID != "c1" {
+		t.Errorf("ListPendingVerifications() = %+v, %v", pending, err)
+	}
+	if err := c.DeletePendingVerification(ctx, "919811111111"); err != nil || len(st.pending) != 0 {
+		t.Errorf("DeletePendingVerification() error %v, left %+v", err, st.pending)
+	}
+
+	gw.state.Connected = false
+	var waErr WhatsAppError
+	if err := c.AddBlacklist(ctx, "919833333333", "spam"); !errors.As(err, &waErr) || st.numbers["919833333333"] != "spam" {
+		t.Errorf("AddBlacklist() while disconnected = %v, store %v; want WhatsAppError", err, st.numbers)
+	}
+
+	if _, err := NewClient(srv.URL, "wrong", nil).ListBlacklist(ctx); err == nil {
+		t.Error("expected error for a wrong token")
+	}
+}
+
+func TestClientSetTLSConfig(t *testing.T) {
+	st := newFakeStore()
+	srv := httptest.NewUnstartedServer(NewHandler(st, &fakeGateway{blocked: map[string]bool{}}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	ctx := context.Background()
+
+	c := NewClient(srv.URL, "", nil)
+	c.SetTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})
+	if _, err := c.ListWhitelist(ctx); err != nil {
+		t.Errorf("ListWhitelist() over TLS 1.2: %v", err)
+	}
+
+	c.SetTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS13})
+	if _, err := c.ListWhitelist(ctx); err == nil {
+		t.Error("ListWhitelist() succeeded against a TLS 1.2 server with min_version 1.3")
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/innomon/whatsadk/internal/auth"
@@ -22,18 +26,87 @@ type Client struct {
 	streaming  bool
 	httpClient *http.Client
 	jwtGen     *auth.JWTGenerator
+	userAgent  string
+	// sessions coalesces concurrent EnsureSession calls per user; nil when
+	// coalescing is disabled.
+	sessions *flightGroup
+	// tags holds channel attribution merged into every state delta; nil
+	// when disabled. Shared with clients derived via ForApp.
+	tags *sessionTags
+	// snippetLen bounds the body excerpt quoted in InvalidResponseError.
+	snippetLen int
+	// seed supplies initial state for new sessions; optional.
+	seed SessionSeeder
+	// sessionKey maps conversations to sessions for ChatConversation; nil
+	// means PerUserSession.
+	sessionKey SessionKey
+	// initialState supplies SessionRequest.State for sessions created by
+	// ChatConversation; optional.
+	initialState SessionStater
+	// delivery selects how ConfirmDelivery reports delivered replies.
+	delivery config.DeliveryConfirmationConfig
+	// rateRetries and rateMaxWait bound retries of 429 responses; sleep
+	// waits between them.
+	rateRetries int
+	rateMaxWait time.Duration
+	sleep       func(ctx context.Context, d time.Duration) error
+	// recreateSessions recreates a session ADK reports as missing and
+	// retries the run once.
+	recreateSessions bool
+	// namespaceSessions prefixes session IDs with the app name.
+	namespaceSessions bool
+	// generation asks the agent for replies matching a response schema;
+	// nil when no schema is configured.
+	generation *GenerationConfig
+	// lastReply sends each session's previous reply with the next turn;
+	// nil when disabled.
+	lastReply *lastReplies
+
+	// sseUnsupported is set once /run_sse answers 405, or 404 for the route
+	// rather than a session; later turns go straight to /run.
+	sseUnsupported atomic.Bool
 }
 
+// errSSEUnsupported is returned by chatSSE when the server has no
+// streaming endpoint.
+var errSSEUnsupported = errors.New("run_sse not supported by ADK server")
+
+// errSessionNotFound is returned when a run fails because ADK no longer
+// knows the session, e.g. after an ADK restart dropped in-memory state.
+var errSessionNotFound = errors.New("ADK session not found")
+
 const (
 	MimeTypeSilentIgnore = "application/x-adk-silent-ignore"
+	// MimeTypeForm marks a reply part declaring fields the gateway should
+	// collect from the user before the next turn (base64 JSON).
+	MimeTypeForm = "application/x-adk-form"
+	// MimeTypePoll marks a reply part the gateway sends as a native
+	// WhatsApp poll (base64 JSON); votes come back as user messages.
+	MimeTypePoll = "application/x-adk-poll"
+	// MimeTypeButtons marks a reply part the gateway sends as up to three
+	// quick-reply buttons (base64 JSON); taps come back as user messages.
+	MimeTypeButtons = "application/x-adk-buttons"
+	// MimeTypeList marks a reply part the gateway sends as a WhatsApp list
+	// message (base64 JSON); selections come back as user messages.
+	MimeTypeList = "application/x-adk-list"
 )
 
 type RunRequest struct {
-	AppName    string   `json:"appName"`
-	UserID     string   `json:"userId"`
-	SessionID  string   `json:"sessionId"`
-	NewMessage *Message `json:"newMessage"`
-	Streaming  bool     `json:"streaming,omitempty"`
+	AppName    string         `json:"appName"`
+	UserID     string         `json:"userId"`
+	SessionID  string         `json:"sessionId"`
+	NewMessage *Message       `json:"newMessage"`
+	Streaming  bool           `json:"streaming,omitempty"`
+	StateDelta map[string]any `json:"stateDelta,omitempty"`
+	// GenerationConfig is forwarded to agents that support constrained
+	// output.
+	GenerationConfig *GenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// GenerationConfig requests replies in a predictable JSON shape.
+type GenerationConfig struct {
+	ResponseMimeType string          `json:"responseMimeType,omitempty"`
+	ResponseSchema   json.RawMessage `json:"responseSchema,omitempty"`
 }
 
 type Message struct {
@@ -49,6 +122,8 @@ type Part struct {
 type InlineData struct {
 	MimeType string `json:"mimeType"`
 	Data     string `json:"data"` // base64
+	// DisplayName is the file name shown to the agent, e.g. for documents.
+	DisplayName string `json:"displayName,omitempty"`
 }
 
 type SessionRequest struct {
@@ -67,47 +142,220 @@ type Content struct {
 }
 
 func NewClient(cfg *config.ADKConfig, jwtGen *auth.JWTGenerator) *Client {
-	return &Client{
-		endpoint:  strings.TrimSuffix(cfg.Endpoint, "/"),
-		appName:   cfg.AppName,
-		apiKey:    cfg.APIKey,
-		streaming: cfg.Streaming,
-		jwtGen:    jwtGen,
+	c := &Client{
+		endpoint:          strings.TrimSuffix(cfg.Endpoint, "/"),
+		appName:           cfg.AppName,
+		apiKey:            cfg.APIKey,
+		streaming:         cfg.Streaming,
+		jwtGen:            jwtGen,
+		userAgent:         config.DefaultUserAgent(),
+		tags:              newSessionTags(cfg.SessionTags),
+		snippetLen:        cfg.ErrorSnippetLength,
+		delivery:          cfg.DeliveryConfirmation,
+		rateRetries:       cfg.RateLimit.MaxRetries,
+		sleep:             sleepCtx,
+		recreateSessions:  !cfg.DisableSessionRecreate,
+		namespaceSessions: cfg.NamespaceSessions,
 		httpClient: &http.Client{
 			Timeout: 120 * time.Second,
 		},
 	}
+	// Load has validated max_wait; an unset value disables waiting.
+	if d, err := time.ParseDuration(cfg.RateLimit.MaxWait); err == nil {
+		c.rateMaxWait = d
+	}
+	if !cfg.DisableSessionCoalescing {
+		c.sessions = newFlightGroup()
+	}
+	if cfg.LastReply.Enabled {
+		c.lastReply = newLastReplies(cfg.LastReply.Prefix)
+	}
+	// Load has validated the schema as a JSON object.
+	if cfg.ResponseSchema != "" {
+		c.generation = &GenerationConfig{
+			ResponseMimeType: "application/json",
+			ResponseSchema:   json.RawMessage(cfg.ResponseSchema),
+		}
+	}
+	return c
+}
+
+// ForApp returns a client that talks to appName on the same endpoint,
+// sharing credentials and the HTTP client.
+func (c *Client) ForApp(appName string) *Client {
+	return &Client{
+		endpoint:          c.endpoint,
+		appName:           appName,
+		apiKey:            c.apiKey,
+		streaming:         c.streaming,
+		httpClient:        c.httpClient,
+		jwtGen:            c.jwtGen,
+		userAgent:         c.userAgent,
+		sessions:          c.sessionsForApp(),
+		tags:              c.tags,
+		snippetLen:        c.snippetLen,
+		seed:              c.seed,
+		sessionKey:        c.sessionKey,
+		initialState:      c.initialState,
+		delivery:          c.delivery,
+		rateRetries:       c.rateRetries,
+		rateMaxWait:       c.rateMaxWait,
+		sleep:             c.sleep,
+		recreateSessions:  c.recreateSessions,
+		namespaceSessions: c.namespaceSessions,
+		generation:        c.generation,
+		lastReply:         c.lastReplyForApp(),
+	}
+}
+
+func (c *Client) lastReplyForApp() *lastReplies {
+	if c.lastReply == nil {
+		return nil
+	}
+	return newLastReplies(c.lastReply.prefix)
+}
+
+func (c *Client) sessionsForApp() *flightGroup {
+	if c.sessions == nil {
+		return nil
+	}
+	return newFlightGroup()
 }
 
+// SetUserAgent overrides the User-Agent sent on every ADK request. Call it
+// before deriving clients with ForApp.
+func (c *Client) SetUserAgent(ua string) {
+	c.userAgent = ua
+}
+
+// SetTLSConfig applies outbound TLS restrictions (minimum version, cipher
+// suites) to the client's transport.
+func (c *Client) SetTLSConfig(tlsCfg *tls.Config) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	c.httpClient.Transport = transport
+}
+
+// SessionSeeder returns the initial state for a user's new session, e.g.
+// stored conversation summaries.
+type SessionSeeder func(ctx context.Context, userID string) (map[string]any, error)
+
+// SetSessionSeeder registers seed to supply state for newly created
+// sessions. It is only consulted when a create request actually creates the
+// session, and its state is sent with that session's first turn. Seeding
+// failures are logged and the turn proceeds without it.
+func (c *Client) SetSessionSeeder(seed SessionSeeder) {
+	c.seed = seed
+}
+
+// EnsureSession creates the user's session if needed. Concurrent calls for
+// the same user share a single create request unless coalescing is disabled.
 func (c *Client) EnsureSession(ctx context.Context, userID string) error {
-	sessionID := userID
-	url := fmt.Sprintf("%s/apps/%s/users/%s/sessions/%s", c.endpoint, c.appName, userID, sessionID)
+	_, err := c.ensureSession(ctx, userID, c.sessionID(userID), nil)
+	return err
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader([]byte("{}")))
+// sessionID maps a gateway session name to the ADK session ID: unchanged,
+// or "<app>:<name>" with adk.namespace_sessions.
+func (c *Client) sessionID(name string) string {
+	if c.namespaceSessions {
+		return c.appName + ":" + name
+	}
+	return name
+}
+
+// DeleteSession deletes the user's main session on the ADK server. A
+// session that does not exist is not an error.
+func (c *Client) DeleteSession(ctx context.Context, userID string) error {
+	return c.deleteSession(ctx, userID, c.sessionID(userID))
+}
+
+func (c *Client) deleteSession(ctx context.Context, userID, sessionID string) error {
+	url, err := c.sessionURL(userID, sessionID)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create session request: %w", err)
+		return fmt.Errorf("failed to create delete session request: %w", err)
+	}
+	if err := c.addHeaders(req, userID); err != nil {
+		return fmt.Errorf("failed to set auth header: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("session deletion failed (%d)", resp.StatusCode)
+	}
+	return fmt.Errorf("session deletion failed (%d): %s", resp.StatusCode, string(body))
+}
+
+// ensureSession reports whether this call created the session. Callers
+// coalesced onto another caller's request report false. A created session
+// starts with initial as its state.
+func (c *Client) ensureSession(ctx context.Context, userID, sessionID string, initial map[string]any) (bool, error) {
+	if c.sessions == nil {
+		return c.createSession(ctx, userID, sessionID, initial)
+	}
+	var created bool
+	err := c.sessions.do(ctx, sessionID, func() error {
+		var err error
+		created, err = c.createSession(ctx, userID, sessionID, initial)
+		return err
+	})
+	return created, err
+}
+
+func (c *Client) createSession(ctx context.Context, userID, sessionID string, initial map[string]any) (bool, error) {
+	url, err := c.sessionURL(userID, sessionID)
+	if err != nil {
+		return false, err
+	}
+	body, err := json.Marshal(SessionRequest{State: initial})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode session request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create session request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	if err := c.addAuthHeader(req, userID); err != nil {
-		return fmt.Errorf("failed to set auth header: %w", err)
+	if err := c.addHeaders(req, userID); err != nil {
+		return false, fmt.Errorf("failed to set auth header: %w", err)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to create session: %w", err)
+		return false, fmt.Errorf("failed to create session: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusConflict {
+	if resp.StatusCode == http.StatusOK {
+		return true, nil
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return false, rateLimitError(resp.Header, time.Now())
+	}
+	if resp.StatusCode != http.StatusConflict {
 		body, _ := io.ReadAll(resp.Body)
 		if strings.Contains(string(body), "already exists") {
-			return nil
+			return false, nil
 		}
-		return fmt.Errorf("session creation failed (%d): %s", resp.StatusCode, string(body))
+		return false, fmt.Errorf("session creation failed (%d): %s", resp.StatusCode, string(body))
 	}
 
-	return nil
+	return false, nil
 }
 
 func (c *Client) Chat(ctx context.Context, userID, message string) ([]Part, error) {
@@ -115,25 +363,119 @@ func (c *Client) Chat(ctx context.Context, userID, message string) ([]Part, erro
 }
 
 func (c *Client) ChatParts(ctx context.Context, userID string, parts []Part) ([]Part, error) {
-	if err := c.EnsureSession(ctx, userID); err != nil {
+	return c.ChatPartsWithState(ctx, userID, parts, nil)
+}
+
+// ChatPartsWithState is ChatParts with a session state delta applied for
+// this turn, e.g. fresh user profile attributes.
+func (c *Client) ChatPartsWithState(ctx context.Context, userID string, parts []Part, state map[string]any) ([]Part, error) {
+	return c.chatParts(ctx, userID, userID, nil, parts, state)
+}
+
+// chatParts runs a turn of userID in the session named session, creating
+// it with initial state and seeding it first if needed.
+func (c *Client) chatParts(ctx context.Context, userID, session string, initial map[string]any, parts []Part, state map[string]any) ([]Part, error) {
+	sessionID := c.sessionID(session)
+	created, err := c.ensureSession(ctx, userID, sessionID, initial)
+	if err != nil {
 		return nil, err
 	}
+	if created && c.seed != nil {
+		state = c.seedState(ctx, userID, state)
+	}
+	if c.lastReply == nil {
+		return c.run(ctx, userID, sessionID, parts, c.withTags(state))
+	}
+	reply, err := c.run(ctx, userID, sessionID, c.lastReply.withContext(sessionID, parts), c.withTags(state))
+	if err == nil {
+		c.lastReply.record(sessionID, reply)
+	}
+	return reply, err
+}
+
+// ChatInSession sends message on a separate session of userID, leaving the
+// user's main conversation untouched. Seeding and tags are not applied.
+func (c *Client) ChatInSession(ctx context.Context, userID, sessionID, message string) ([]Part, error) {
+	sessionID = c.sessionID(sessionID)
+	if _, err := c.ensureSession(ctx, userID, sessionID, nil); err != nil {
+		return nil, err
+	}
+	return c.run(ctx, userID, sessionID, []Part{{Text: message}}, nil)
+}
+
+// ChatOnce is ChatInSession on a throwaway session: sessionID should be
+// unique to this call, and the session is deleted once the reply is in,
+// so no history carries over to the next call. A failed deletion is only
+// logged.
+func (c *Client) ChatOnce(ctx context.Context, userID, sessionID, message string) ([]Part, error) {
+	reply, err := c.ChatInSession(ctx, userID, sessionID, message)
+	if derr := c.deleteSession(ctx, userID, c.sessionID(sessionID)); derr != nil {
+		slog.Warn("failed to delete throwaway session", "user_id", userID, "session_id", sessionID, "error", derr)
+	}
+	return reply, err
+}
+
+// seedState merges the seeder's state under state; keys in state win.
+func (c *Client) seedState(ctx context.Context, userID string, state map[string]any) map[string]any {
+	seed, err := c.seed(ctx, userID)
+	if err != nil {
+		slog.Warn("failed to seed session state", "user_id", userID, "error", err)
+		return state
+	}
+	if len(seed) == 0 {
+		return state
+	}
+	merged := make(map[string]any, len(seed)+len(state))
+	for k, v := range seed {
+		merged[k] = v
+	}
+	for k, v := range state {
+		merged[k] = v
+	}
+	return merged
+}
+
+func (c *Client) run(ctx context.Context, userID, sessionID string, parts []Part, state map[string]any) ([]Part, error) {
+	attempt := func() ([]Part, error) {
+		return c.withRateLimitRetry(ctx, func() ([]Part, error) {
+			return c.runOnce(ctx, userID, sessionID, parts, state)
+		})
+	}
+	respParts, err := attempt()
+	if !errors.Is(err, errSessionNotFound) || !c.recreateSessions {
+		return respParts, err
+	}
+	slog.Warn("ADK session not found, recreating and retrying", "user_id", userID, "session_id", sessionID)
+	if _, cerr := c.ensureSession(ctx, userID, sessionID, nil); cerr != nil {
+		return nil, fmt.Errorf("recreate session after %v: %w", err, cerr)
+	}
+	return attempt()
+}
 
-	if c.streaming {
-		return c.chatSSE(ctx, userID, parts)
+func (c *Client) runOnce(ctx context.Context, userID, sessionID string, parts []Part, state map[string]any) ([]Part, error) {
+	if c.streaming && !c.sseUnsupported.Load() {
+		respParts, err := c.chatSSE(ctx, userID, sessionID, parts, state)
+		if !errors.Is(err, errSSEUnsupported) {
+			return respParts, err
+		}
+		if c.sseUnsupported.CompareAndSwap(false, true) {
+			slog.Warn("ADK server does not support /run_sse, falling back to /run", "endpoint", c.endpoint)
+		}
 	}
-	return c.chatRun(ctx, userID, parts)
+	return c.chatRun(ctx, userID, sessionID, parts, state)
 }
 
-func (c *Client) chatRun(ctx context.Context, userID string, parts []Part) ([]Part, error) {
+func (c *Client) chatRun(ctx context.Context, userID, sessionID string, parts []Part, state map[string]any) ([]Part, error) {
 	runReq := RunRequest{
 		AppName:   c.appName,
 		UserID:    userID,
-		SessionID: userID,
+		SessionID: sessionID,
 		NewMessage: &Message{
 			Role:  "user",
 			Parts: parts,
 		},
+		StateDelta:       state,
+		GenerationConfig: c.generation,
 	}
 
 	body, err := json.Marshal(runReq)
@@ -141,14 +483,17 @@ func (c *Client) chatRun(ctx context.Context, userID string, parts []Part) ([]Pa
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/run", c.endpoint)
+	url, err := joinEndpoint(c.endpoint, "run")
+	if err != nil {
+		return nil, err
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	if err := c.addAuthHeader(req, userID); err != nil {
+	if err := c.addHeaders(req, userID); err != nil {
 		return nil, fmt.Errorf("failed to set auth header: %w", err)
 	}
 
@@ -158,29 +503,59 @@ func (c *Client) chatRun(ctx context.Context, userID string, parts []Part) ([]Pa
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, rateLimitError(resp.Header, time.Now())
+	}
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
+		if sessionMissing(resp.StatusCode, respBody) {
+			return nil, fmt.Errorf("%w: run failed (%d): %s", errSessionNotFound, resp.StatusCode, string(respBody))
+		}
 		return nil, fmt.Errorf("run failed (%d): %s", resp.StatusCode, string(respBody))
 	}
 
-	var events []Event
-	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	events, err := decodeEvents(respBody, resp.Header.Get("Content-Type"), c.snippetLen)
+	if err != nil {
+		return nil, err
 	}
 
 	return extractFinalParts(events), nil
 }
 
-func (c *Client) chatSSE(ctx context.Context, userID string, parts []Part) ([]Part, error) {
+// sseRouteMissing reports whether a /run_sse error means the endpoint
+// itself does not exist. ADK also answers 404 for "Session not found",
+// which must not switch the client to /run for good.
+func sseRouteMissing(status int, body []byte) bool {
+	switch status {
+	case http.StatusMethodNotAllowed:
+		return true
+	case http.StatusNotFound:
+		return !sessionMissing(status, body)
+	}
+	return false
+}
+
+// sessionMissing reports whether a run error is ADK's "Session not found".
+func sessionMissing(status int, body []byte) bool {
+	return status == http.StatusNotFound && bytes.Contains(bytes.ToLower(body), []byte("session"))
+}
+
+func (c *Client) chatSSE(ctx context.Context, userID, sessionID string, parts []Part, state map[string]any) ([]Part, error) {
 	runReq := RunRequest{
 		AppName:   c.appName,
 		UserID:    userID,
-		SessionID: userID,
+		SessionID: sessionID,
 		NewMessage: &Message{
 			Role:  "user",
 			Parts: parts,
 		},
-		Streaming: true,
+		Streaming:        true,
+		StateDelta:       state,
+		GenerationConfig: c.generation,
 	}
 
 	body, err := json.Marshal(runReq)
@@ -188,7 +563,10 @@ func (c *Client) chatSSE(ctx context.Context, userID string, parts []Part) ([]Pa
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/run_sse", c.endpoint)
+	url, err := joinEndpoint(c.endpoint, "run_sse")
+	if err != nil {
+		return nil, err
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -196,7 +574,7 @@ func (c *Client) chatSSE(ctx context.Context, userID string, parts []Part) ([]Pa
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "text/event-stream")
-	if err := c.addAuthHeader(req, userID); err != nil {
+	if err := c.addHeaders(req, userID); err != nil {
 		return nil, fmt.Errorf("failed to set auth header: %w", err)
 	}
 
@@ -206,8 +584,17 @@ func (c *Client) chatSSE(ctx context.Context, userID string, parts []Part) ([]Pa
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, rateLimitError(resp.Header, time.Now())
+	}
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
+		if sseRouteMissing(resp.StatusCode, respBody) {
+			return nil, fmt.Errorf("%w (%d)", errSSEUnsupported, resp.StatusCode)
+		}
+		if sessionMissing(resp.StatusCode, respBody) {
+			return nil, fmt.Errorf("%w: run_sse failed (%d): %s", errSessionNotFound, resp.StatusCode, string(respBody))
+		}
 		return nil, fmt.Errorf("run_sse failed (%d): %s", resp.StatusCode, string(respBody))
 	}
 
@@ -239,7 +626,10 @@ func (c *Client) chatSSE(ctx context.Context, userID string, parts []Part) ([]Pa
 	return extractFinalParts(events), nil
 }
 
-func (c *Client) addAuthHeader(req *http.Request, userID string) error {
+// addHeaders sets the User-Agent and, when credentials are configured, the
+// Authorization header.
+func (c *Client) addHeaders(req *http.Request, userID string) error {
+	req.Header.Set("User-Agent", c.userAgent)
 	if c.jwtGen != nil {
 		token, err := c.jwtGen.Token(userID)
 		if err != nil {
@@ -0,0 +1,545 @@
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestChatPartsWithStateForwardsStateDelta(t *testing.T) {
+	var got RunRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode run request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"content":{"role":"model","parts":[{"text":"hi Asha"}]}}]`))
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app"}, nil)
+	state := map[string]any{"user_name": "Asha", "user_tier": "gold"}
+
+	parts, err := c.ChatPartsWithState(t.Context(), "919876543210", []Part{{Text: "hello"}}, state)
+	if err != nil {
+		t.Fatalf("ChatPartsWithState() error: %v", err)
+	}
+	if len(parts) != 1 || parts[0].Text != "hi Asha" {
+		t.Errorf("unexpected reply parts: %+v", parts)
+	}
+	if got.StateDelta["user_name"] != "Asha" || got.StateDelta["user_tier"] != "gold" {
+		t.Errorf("stateDelta = %v, want profile fields", got.StateDelta)
+	}
+}
+
+func TestChatPartsOmitsEmptyStateDelta(t *testing.T) {
+	var raw map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Errorf("failed to decode run request: %v", err)
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app"}, nil)
+	if _, err := c.ChatParts(t.Context(), "919876543210", []Part{{Text: "hello"}}); err != nil {
+		t.Fatalf("ChatParts() error: %v", err)
+	}
+	if _, ok := raw["stateDelta"]; ok {
+		t.Errorf("stateDelta should be omitted when empty, got %v", raw["stateDelta"])
+	}
+}
+
+func TestRunRequestIncludesResponseSchema(t *testing.T) {
+	schema := `{"type":"object","properties":{"reply":{"type":"string"}},"required":["reply"]}`
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"configured", schema},
+		{"unset", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw map[string]json.RawMessage
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "/sessions/") {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+					t.Errorf("failed to decode run request: %v", err)
+				}
+				w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", ResponseSchema: tt.schema}, nil)
+			if _, err := c.ChatParts(t.Context(), "919876543210", []Part{{Text: "hello"}}); err != nil {
+				t.Fatalf("ChatParts() error: %v", err)
+			}
+
+			gen, ok := raw["generationConfig"]
+			if tt.schema == "" {
+				if ok {
+					t.Errorf("generationConfig should be omitted without a schema, got %s", gen)
+				}
+				return
+			}
+			var got GenerationConfig
+			if err := json.Unmarshal(gen, &got); err != nil {
+				t.Fatalf("decode generationConfig: %v", err)
+			}
+			if got.ResponseMimeType != "application/json" {
+				t.Errorf("responseMimeType = %q, want application/json", got.ResponseMimeType)
+			}
+			if string(got.ResponseSchema) != tt.schema {
+				t.Errorf("responseSchema = %s, want %s", got.ResponseSchema, tt.schema)
+			}
+		})
+	}
+}
+
+func TestSetTLSConfig(t *testing.T) {
+	c := NewClient(&config.ADKConfig{Endpoint: "https://adk.example.com"}, nil)
+	c.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})
+
+	transport, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", c.httpClient.Transport)
+	}
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("MinVersion = %x, want TLS 1.2", transport.TLSClientConfig.MinVersion)
+	}
+}
+
+func TestUserAgentOnAllRequests(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("User-Agent"); got != "whatsadk/test" {
+			t.Errorf("%s User-Agent = %q, want whatsadk/test", r.URL.Path, got)
+		}
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/run_sse" {
+			w.Write([]byte("data: {\"content\":{\"parts\":[{\"text\":\"pong\"}]}}\n\n"))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	for _, streaming := range []bool{false, true} {
+		c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", Streaming: streaming}, nil)
+		c.SetUserAgent("whatsadk/test")
+		if _, err := c.Chat(t.Context(), "919876543210", "ping"); err != nil {
+			t.Fatalf("Chat(streaming=%v) error: %v", streaming, err)
+		}
+	}
+	if len(paths) != 4 {
+		t.Errorf("requests = %v, want session and run for both modes", paths)
+	}
+}
+
+func TestChatSSEFallsBackToRun(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusMethodNotAllowed} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var sseCalls, runCalls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.Contains(r.URL.Path, "/sessions/"):
+					w.WriteHeader(http.StatusOK)
+				case r.URL.Path == "/run_sse":
+					sseCalls++
+					w.WriteHeader(status)
+				case r.URL.Path == "/run":
+					runCalls++
+					w.Write([]byte(`[{"content":{"role":"model","parts":[{"text":"pong"}]}}]`))
+				default:
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", Streaming: true}, nil)
+			for i := 0; i < 2; i++ {
+				parts, err := c.Chat(t.Context(), "919876543210", "ping")
+				if err != nil {
+					t.Fatalf("Chat() error: %v", err)
+				}
+				if len(parts) != 1 || parts[0].Text != "pong" {
+					t.Fatalf("unexpected reply parts: %+v", parts)
+				}
+			}
+			if sseCalls != 1 {
+				t.Errorf("expected /run_sse to be tried once, got %d", sseCalls)
+			}
+			if runCalls != 2 {
+				t.Errorf("expected 2 calls to /run, got %d", runCalls)
+			}
+		})
+	}
+}
+
+func TestChatSSEOtherErrorsDoNotFallBack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.URL.Path == "/run" {
+			t.Error("unexpected fallback to /run")
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", Streaming: true}, nil)
+	if _, err := c.Chat(t.Context(), "919876543210", "ping"); err == nil {
+		t.Fatal("expected error from failing /run_sse")
+	}
+}
+
+func TestChatSSESessionNotFoundDoesNotLatch(t *testing.T) {
+	var sseCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/sessions/"):
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/run_sse":
+			sseCalls++
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail":"Session not found"}`))
+		default:
+			t.Errorf("unexpected fallback to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", Streaming: true}, nil)
+	for i := 0; i < 2; i++ {
+		if _, err := c.Chat(t.Context(), "919876543210", "ping"); err == nil {
+			t.Fatal("expected session error from /run_sse")
+		}
+	}
+	// Each call retries once after recreating the session.
+	if sseCalls != 4 {
+		t.Errorf("expected /run_sse on every attempt, got %d", sseCalls)
+	}
+	if c.sseUnsupported.Load() {
+		t.Error("session 404 must not disable SSE")
+	}
+}
+
+func TestSeedOnlyWhenSessionCreated(t *testing.T) {
+	tests := []struct {
+		name        string
+		sessionCode int
+		wantSeeded  bool
+	}{
+		{name: "new session", sessionCode: http.StatusOK, wantSeeded: true},
+		{name: "existing session", sessionCode: http.StatusConflict, wantSeeded: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RunRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "/sessions/") {
+					w.WriteHeader(tt.sessionCode)
+					return
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("failed to decode run request: %v", err)
+				}
+				w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			seeds := 0
+			c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app"}, nil)
+			c.SetSessionSeeder(func(_ context.Context, userID string) (map[string]any, error) {
+				seeds++
+				return map[string]any{"conversation_summary": "summary for " + userID}, nil
+			})
+
+			state := map[string]any{"user_name": "Asha"}
+			if _, err := c.ChatPartsWithState(t.Context(), "919876543210", []Part{{Text: "hi"}}, state); err != nil {
+				t.Fatalf("ChatPartsWithState() error: %v", err)
+			}
+			if seeded := seeds > 0; seeded != tt.wantSeeded {
+				t.Fatalf("seeder called %d times, want seeded=%v", seeds, tt.wantSeeded)
+			}
+			_, hasSummary := got.StateDelta["conversation_summary"]
+			if hasSummary != tt.wantSeeded {
+				t.Errorf("stateDelta = %v, want summary present=%v", got.StateDelta, tt.wantSeeded)
+			}
+			if got.StateDelta["user_name"] != "Asha" {
+				t.Errorf("turn state lost: %v", got.StateDelta)
+			}
+		})
+	}
+}
+
+func TestSeedFailureStillRuns(t *testing.T) {
+	var got RunRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode run request: %v", err)
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app"}, nil)
+	c.SetSessionSeeder(func(context.Context, string) (map[string]any, error) {
+		return nil, errors.New("store down")
+	})
+
+	if _, err := c.Chat(t.Context(), "919876543210", "hi"); err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if len(got.StateDelta) != 0 {
+		t.Errorf("stateDelta = %v, want empty", got.StateDelta)
+	}
+}
+
+func TestChatInSessionUsesSeparateSession(t *testing.T) {
+	var sessionPaths []string
+	var got RunRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			sessionPaths = append(sessionPaths, r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode run request: %v", err)
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app"}, nil)
+	c.SetSessionSeeder(func(context.Context, string) (map[string]any, error) {
+		t.Error("seeder must not run for side sessions")
+		return nil, nil
+	})
+	if _, err := c.ChatInSession(t.Context(), "919876543210", "919876543210-summary", "summarize"); err != nil {
+		t.Fatalf("ChatInSession() error: %v", err)
+	}
+	if len(sessionPaths) != 1 || !strings.HasSuffix(sessionPaths[0], "/users/919876543210/sessions/919876543210-summary") {
+		t.Errorf("session paths = %v", sessionPaths)
+	}
+	if got.SessionID != "919876543210-summary" || got.UserID != "919876543210" {
+		t.Errorf("run request session = %q user = %q", got.SessionID, got.UserID)
+	}
+}
+
+func TestChatOnceDeletesSession(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/run" {
+			w.Write([]byte(`[{"content":{"role":"model","parts":[{"text":"done"}]}}]`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app"}, nil)
+	parts, err := c.ChatOnce(t.Context(), "919876543210", "919876543210-summary-MSG1", "summarize")
+	if err != nil {
+		t.Fatalf("ChatOnce() error: %v", err)
+	}
+	if len(parts) != 1 || parts[0].Text != "done" {
+		t.Errorf("parts = %+v", parts)
+	}
+	session := "/apps/app/users/919876543210/sessions/919876543210-summary-MSG1"
+	want := []string{"POST " + session, "POST /run", "DELETE " + session}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRunRecreatesMissingSession(t *testing.T) {
+	for _, streaming := range []bool{false, true} {
+		t.Run(fmt.Sprintf("streaming=%v", streaming), func(t *testing.T) {
+			var sessionCreates, runs int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.Contains(r.URL.Path, "/sessions/"):
+					sessionCreates++
+					w.WriteHeader(http.StatusOK)
+				case r.URL.Path == "/run" || r.URL.Path == "/run_sse":
+					runs++
+					if runs == 1 {
+						w.WriteHeader(http.StatusNotFound)
+						w.Write([]byte(`{"detail":"Session not found"}`))
+						return
+					}
+					if streaming {
+						w.Write([]byte("data: {\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"back\"}]}}\n\n"))
+						return
+					}
+					w.Write([]byte(`[{"content":{"role":"model","parts":[{"text":"back"}]}}]`))
+				}
+			}))
+			defer server.Close()
+
+			c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", Streaming: streaming}, nil)
+			parts, err := c.Chat(t.Context(), "919876543210", "ping")
+			if err != nil {
+				t.Fatalf("Chat() error: %v", err)
+			}
+			if len(parts) != 1 || parts[0].Text != "back" {
+				t.Errorf("unexpected reply parts: %+v", parts)
+			}
+			if sessionCreates != 2 {
+				t.Errorf("session creates = %d, want initial + recreate", sessionCreates)
+			}
+			if runs != 2 {
+				t.Errorf("runs = %d, want one retry", runs)
+			}
+		})
+	}
+}
+
+func TestRunSessionRecreateRetriesOnce(t *testing.T) {
+	var runs int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		runs++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail":"Session not found"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app"}, nil)
+	_, err := c.Chat(t.Context(), "919876543210", "ping")
+	if !errors.Is(err, errSessionNotFound) {
+		t.Fatalf("Chat() error = %v, want errSessionNotFound", err)
+	}
+	if runs != 2 {
+		t.Errorf("runs = %d, want exactly one retry", runs)
+	}
+}
+
+func TestRunSessionRecreateDisabled(t *testing.T) {
+	var runs int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		runs++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail":"Session not found"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", DisableSessionRecreate: true}, nil)
+	if _, err := c.Chat(t.Context(), "919876543210", "ping"); err == nil {
+		t.Fatal("expected session error")
+	}
+	if runs != 1 {
+		t.Errorf("runs = %d, want no retry when disabled", runs)
+	}
+}
+
+func TestNamespacedSessionIDAcrossLifecycle(t *testing.T) {
+	const user = "919876543210"
+	var paths, runSessions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/run" {
+			var req RunRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("failed to decode run request: %v", err)
+			}
+			runSessions = append(runSessions, req.SessionID)
+			w.Write([]byte(`[]`))
+			return
+		}
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{
+		Endpoint:             server.URL,
+		AppName:              "shop",
+		NamespaceSessions:    true,
+		DeliveryConfirmation: config.DeliveryConfirmationConfig{Mode: "state", StateKey: "last_delivery"},
+	}, nil)
+	ctx := t.Context()
+
+	if err := c.EnsureSession(ctx, user); err != nil {
+		t.Fatalf("EnsureSession() error: %v", err)
+	}
+	if _, err := c.Chat(ctx, user, "hi"); err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if _, err := c.ChatInSession(ctx, user, user+"-summary", "summarize"); err != nil {
+		t.Fatalf("ChatInSession() error: %v", err)
+	}
+	if err := c.ConfirmDelivery(ctx, Conversation{UserID: user}, Delivery{MessageID: "m1"}); err != nil {
+		t.Fatalf("ConfirmDelivery() error: %v", err)
+	}
+	if err := c.DeleteSession(ctx, user); err != nil {
+		t.Fatalf("DeleteSession() error: %v", err)
+	}
+
+	base := "/apps/shop/users/" + user + "/sessions/"
+	wantPaths := []string{
+		"POST " + base + "shop:" + user,
+		"POST " + base + "shop:" + user,
+		"POST " + base + "shop:" + user + "-summary",
+		"PATCH " + base + "shop:" + user,
+		"DELETE " + base + "shop:" + user,
+	}
+	if strings.Join(paths, "\n") != strings.Join(wantPaths, "\n") {
+		t.Errorf("session requests:\n%s\nwant:\n%s", strings.Join(paths, "\n"), strings.Join(wantPaths, "\n"))
+	}
+	wantRuns := []string{"shop:" + user, "shop:" + user + "-summary"}
+	if strings.Join(runSessions, ",") != strings.Join(wantRuns, ",") {
+		t.Errorf("run session IDs = %v, want %v", runSessions, wantRuns)
+	}
+}
+
+func TestSessionIDWithoutNamespace(t *testing.T) {
+	c := NewClient(&config.ADKConfig{AppName: "shop"}, nil)
+	if got := c.sessionID("919876543210"); got != "919876543210" {
+		t.Errorf("sessionID() = %q, want bare user ID", got)
+	}
+	if got := c.ForApp("biz").sessionID("919876543210"); got != "919876543210" {
+		t.Errorf("ForApp sessionID() = %q, want bare user ID", got)
+	}
+	ns := NewClient(&config.ADKConfig{AppName: "shop", NamespaceSessions: true}, nil)
+	if got := ns.ForApp("biz").sessionID("919876543210"); got != "biz:919876543210" {
+		t.Errorf("ForApp sessionID() = %q, want biz:919876543210", got)
+	}
+}
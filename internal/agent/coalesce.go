@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup coalesces concurrent calls sharing a key so that only one runs
+// at a time; callers arriving while it is in flight wait for its result.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	err  error
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: make(map[string]*flightCall)}
+}
+
+// do runs fn for key unless a call for key is already in flight, in which
+// case it waits for that call and returns its error. A waiter whose ctx ends
+// first returns ctx.Err() without affecting the in-flight call.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() error) error {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.err = fn()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+	return call.err
+}
@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestEnsureSessionCoalescesConcurrentCalls(t *testing.T) {
+	const callers = 5
+
+	tests := []struct {
+		name        string
+		disable     bool
+		wantCreates int64
+	}{
+		{name: "coalesced", wantCreates: 1},
+		{name: "disabled", disable: true, wantCreates: callers},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var creates atomic.Int64
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				creates.Add(1)
+				<-release
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			c := NewClient(&config.ADKConfig{
+				Endpoint:                 server.URL,
+				AppName:                  "app",
+				DisableSessionCoalescing: tt.disable,
+			}, nil)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, callers)
+			for range callers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- c.EnsureSession(t.Context(), "919876543210")
+				}()
+			}
+
+			// Hold the first request open until every caller has had time
+			// to arrive, then let the server answer.
+			deadline := time.Now().Add(time.Second)
+			for creates.Load() < tt.wantCreates && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				if err != nil {
+					t.Errorf("EnsureSession() error: %v", err)
+				}
+			}
+			if got := creates.Load(); got != tt.wantCreates {
+				t.Errorf("session create requests = %d, want %d", got, tt.wantCreates)
+			}
+		})
+	}
+}
+
+func TestEnsureSessionSequentialCallsEachCreate(t *testing.T) {
+	var creates atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		creates.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app"}, nil)
+	for range 2 {
+		if err := c.EnsureSession(t.Context(), "919876543210"); err != nil {
+			t.Fatalf("EnsureSession() error: %v", err)
+		}
+	}
+	if got := creates.Load(); got != 2 {
+		t.Errorf("session create requests = %d, want 2 (results are not cached)", got)
+	}
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Delivery describes an agent reply that WhatsApp accepted for delivery.
+type Delivery struct {
+	MessageID string    `json:"messageId"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// deliveryEvent is the body POSTed in "event" mode.
+type deliveryEvent struct {
+	Event     string `json:"event"`
+	AppName   string `json:"appName"`
+	UserID    string `json:"userId"`
+	SessionID string `json:"sessionId"`
+	Delivery
+}
+
+type sessionUpdateRequest struct {
+	StateDelta map[string]any `json:"stateDelta"`
+}
+
+// ConfirmDelivery tells ADK that a reply in conv reached the user, either
+// as an event POSTed to the configured path or as a state update on the
+// session the turn ran in, depending on adk.delivery_confirmation.mode.
+func (c *Client) ConfirmDelivery(ctx context.Context, conv Conversation, d Delivery) error {
+	userID, session := c.SessionFor(conv)
+	var (
+		method, url string
+		payload     any
+		err         error
+	)
+	if c.delivery.Mode == "state" {
+		method = http.MethodPatch
+		url, err = c.sessionURL(userID, c.sessionID(session))
+		payload = sessionUpdateRequest{StateDelta: map[string]any{c.delivery.StateKey: d}}
+	} else {
+		method = http.MethodPost
+		url, err = joinEndpoint(c.endpoint, c.delivery.Path)
+		payload = deliveryEvent{Event: "delivered", AppName: c.appName, UserID: userID, SessionID: c.sessionID(session), Delivery: d}
+	}
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal delivery confirmation: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create delivery confirmation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.addHeaders(req, userID); err != nil {
+		return fmt.Errorf("failed to set auth header: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send delivery confirmation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("delivery confirmation failed (%d)", resp.StatusCode)
+		}
+		return fmt.Errorf("delivery confirmation failed (%d): %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
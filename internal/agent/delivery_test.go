@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestConfirmDelivery(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name       string
+		cfg        config.DeliveryConfirmationConfig
+		wantMethod string
+		wantPath   string
+		check      func(t *testing.T, body map[string]any)
+	}{
+		{
+			name:       "event",
+			cfg:        config.DeliveryConfirmationConfig{Mode: "event", Path: "/delivery"},
+			wantMethod: http.MethodPost,
+			wantPath:   "/delivery",
+			check: func(t *testing.T, body map[string]any) {
+				if body["event"] != "delivered" || body["userId"] != "919876543210" || body["messageId"] != "MSG1" || body["appName"] != "app" {
+					t.Errorf("event body = %v", body)
+				}
+			},
+		},
+		{
+			name:       "state",
+			cfg:        config.DeliveryConfirmationConfig{Mode: "state", StateKey: "last_delivery"},
+			wantMethod: http.MethodPatch,
+			wantPath:   "/apps/app/users/919876543210/sessions/919876543210",
+			check: func(t *testing.T, body map[string]any) {
+				delta, _ := body["stateDelta"].(map[string]any)
+				last, _ := delta["last_delivery"].(map[string]any)
+				if last["messageId"] != "MSG1" || last["timestamp"] != "2026-01-02T03:04:05Z" {
+					t.Errorf("stateDelta = %v", body["stateDelta"])
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, path string
+			var body map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, path = r.Method, r.URL.Path
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode body: %v", err)
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", DeliveryConfirmation: tt.cfg}, nil)
+			if err := c.ConfirmDelivery(t.Context(), Conversation{UserID: "919876543210"}, Delivery{MessageID: "MSG1", Timestamp: ts}); err != nil {
+				t.Fatalf("ConfirmDelivery() error: %v", err)
+			}
+			if method != tt.wantMethod || path != tt.wantPath {
+				t.Errorf("request = %s %s, want %s %s", method, path, tt.wantMethod, tt.wantPath)
+			}
+			tt.check(t, body)
+		})
+	}
+}
+
+func TestConfirmDeliveryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", DeliveryConfirmation: config.DeliveryConfirmationConfig{Mode: "event", Path: "/delivery"}}, nil)
+	if err := c.ConfirmDelivery(t.Context(), Conversation{UserID: "u"}, Delivery{MessageID: "MSG1"}); err == nil {
+		t.Fatal("expected error for non-2xx response")
+	}
+}
+
+func TestConfirmDeliveryInGroupSession(t *testing.T) {
+	const group = "120363012345678901@g.us"
+	tests := []struct {
+		mode     string
+		wantPath string
+	}{
+		{"state", "/apps/app/users/" + group + "/sessions/" + group},
+		{"event", "/delivery"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			var path string
+			var body map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode body: %v", err)
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", DeliveryConfirmation: config.DeliveryConfirmationConfig{
+				Mode: tt.mode, Path: "/delivery", StateKey: "last_delivery",
+			}}, nil)
+			c.SetSessionKey(PerGroupSession)
+			conv := Conversation{UserID: "919876543210", GroupID: group}
+			if err := c.ConfirmDelivery(t.Context(), conv, Delivery{MessageID: "MSG1"}); err != nil {
+				t.Fatalf("ConfirmDelivery() error: %v", err)
+			}
+			if path != tt.wantPath {
+				t.Errorf("path = %s, want %s", path, tt.wantPath)
+			}
+			if tt.mode == "event" && (body["userId"] != group || body["sessionId"] != group) {
+				t.Errorf("event body = %v, want the group session", body)
+			}
+		})
+	}
+}
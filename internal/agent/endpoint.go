@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// joinEndpoint appends path elements to the ADK endpoint, keeping any base
+// path (e.g. https://host/adk) and query string. Elements are already
+// escaped and may contain "/"; empty elements and repeated slashes are
+// collapsed, so base paths with or without a trailing slash join the same.
+func joinEndpoint(endpoint string, elems ...string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil {
+		return "", fmt.Errorf("invalid ADK endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid ADK endpoint %q: want an absolute http(s) URL", endpoint)
+	}
+
+	segments := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	for _, e := range elems {
+		segments = append(segments, strings.Split(e, "/")...)
+	}
+	var b strings.Builder
+	for _, s := range segments {
+		if s == "" {
+			continue
+		}
+		b.WriteByte('/')
+		b.WriteString(s)
+	}
+
+	p, err := url.PathUnescape(b.String())
+	if err != nil {
+		return "", fmt.Errorf("invalid ADK path %q: %w", b.String(), err)
+	}
+	u.Path, u.RawPath = p, b.String()
+	return u.String(), nil
+}
+
+// sessionURL is the URL of a session resource on the ADK server. IDs are
+// escaped so a "/" in one cannot address a different resource.
+func (c *Client) sessionURL(userID, sessionID string) (string, error) {
+	return joinEndpoint(c.endpoint, "apps", url.PathEscape(c.appName), "users", url.PathEscape(userID), "sessions", url.PathEscape(sessionID))
+}
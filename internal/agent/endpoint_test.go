@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestJoinEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		elems    []string
+		want     string
+	}{
+		{"host only", "http://localhost:8000", []string{"run"}, "http://localhost:8000/run"},
+		{"host with trailing slash", "http://localhost:8000/", []string{"run"}, "http://localhost:8000/run"},
+		{"base path", "https://host/adk", []string{"run"}, "https://host/adk/run"},
+		{"base path with trailing slash", "https://host/adk/", []string{"run"}, "https://host/adk/run"},
+		{"nested base path", "https://host/v1/adk//", []string{"apps", "shop", "users", "u1", "sessions", "s1"}, "https://host/v1/adk/apps/shop/users/u1/sessions/s1"},
+		{"element with slashes", "https://host/adk", []string{"/hooks/delivered/"}, "https://host/adk/hooks/delivered"},
+		{"query kept", "https://host/adk?key=abc", []string{"run_sse"}, "https://host/adk/run_sse?key=abc"},
+		{"escaped element", "https://host/adk", []string{"users", "a%2Fb"}, "https://host/adk/users/a%2Fb"},
+		{"escaped base path", "https://host/my%20adk/", []string{"run"}, "https://host/my%20adk/run"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := joinEndpoint(tt.endpoint, tt.elems...)
+			if err != nil {
+				t.Fatalf("joinEndpoint() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("joinEndpoint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinEndpointInvalid(t *testing.T) {
+	for _, endpoint := range []string{"", "localhost:8000", "/adk", "http://host/%zz"} {
+		if got, err := joinEndpoint(endpoint, "run"); err == nil {
+			t.Errorf("joinEndpoint(%q) = %q, want error", endpoint, got)
+		}
+	}
+}
+
+func TestClientKeepsEndpointBasePath(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.EscapedPath())
+		if strings.HasSuffix(r.URL.Path, "/run") {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	for _, endpoint := range []string{server.URL + "/adk", server.URL + "/adk/"} {
+		paths = nil
+		c := NewClient(&config.ADKConfig{Endpoint: endpoint, AppName: "shop"}, nil)
+		if _, err := c.Chat(t.Context(), "a/b", "hi"); err != nil {
+			t.Fatalf("Chat() error: %v", err)
+		}
+		want := []string{
+			"POST /adk/apps/shop/users/a%2Fb/sessions/a%2Fb",
+			"POST /adk/run",
+		}
+		if strings.Join(paths, "\n") != strings.Join(want, "\n") {
+			t.Errorf("endpoint %q requests:\n%s\nwant:\n%s", endpoint, strings.Join(paths, "\n"), strings.Join(want, "\n"))
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+)
+
+// lastReplies remembers the latest agent reply in each session so the next
+// turn can carry it as context for agents that keep no history of their own.
+type lastReplies struct {
+	prefix string
+
+	mu      sync.Mutex
+	replies map[string]string
+}
+
+func newLastReplies(prefix string) *lastReplies {
+	return &lastReplies{prefix: prefix, replies: make(map[string]string)}
+}
+
+// withContext returns parts preceded by a text part quoting sessionID's
+// previous reply, or parts unchanged on the first turn.
+func (l *lastReplies) withContext(sessionID string, parts []Part) []Part {
+	l.mu.Lock()
+	reply, ok := l.replies[sessionID]
+	l.mu.Unlock()
+	if !ok {
+		return parts
+	}
+	text := reply
+	if l.prefix != "" {
+		text = l.prefix + "\n" + reply
+	}
+	return append([]Part{{Text: text}}, parts...)
+}
+
+// record remembers the text of reply for sessionID. Replies without text,
+// such as media only, leave the previous one in place.
+func (l *lastReplies) record(sessionID string, reply []Part) {
+	var texts []string
+	for _, p := range reply {
+		if t := strings.TrimSpace(p.Text); t != "" {
+			texts = append(texts, t)
+		}
+	}
+	if len(texts) == 0 {
+		return
+	}
+	l.mu.Lock()
+	l.replies[sessionID] = strings.Join(texts, "\n")
+	l.mu.Unlock()
+}
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestLastReplyIncludedInNextTurn(t *testing.T) {
+	var runs []RunRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var req RunRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode run request: %v", err)
+		}
+		runs = append(runs, req)
+		fmt.Fprintf(w, `[{"content":{"role":"model","parts":[{"text":"reply %d"}]}}]`, len(runs))
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{
+		Endpoint:  server.URL,
+		AppName:   "app",
+		LastReply: config.LastReplyConfig{Enabled: true, Prefix: "Previously:"},
+	}, nil)
+	ctx := t.Context()
+	for _, msg := range []string{"first", "second"} {
+		if _, err := c.Chat(ctx, "919876543210", msg); err != nil {
+			t.Fatalf("Chat(%q) error: %v", msg, err)
+		}
+	}
+	if _, err := c.Chat(ctx, "919800000000", "other user"); err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+
+	texts := func(req RunRequest) []string {
+		var out []string
+		for _, p := range req.NewMessage.Parts {
+			out = append(out, p.Text)
+		}
+		return out
+	}
+	want := [][]string{
+		{"first"},
+		{"Previously:\nreply 1", "second"},
+		{"other user"},
+	}
+	if len(runs) != len(want) {
+		t.Fatalf("got %d run requests, want %d", len(runs), len(want))
+	}
+	for i, w := range want {
+		if got := texts(runs[i]); strings.Join(got, "|") != strings.Join(w, "|") {
+			t.Errorf("run %d parts = %q, want %q", i+1, got, w)
+		}
+	}
+}
+
+func TestLastReplyDisabled(t *testing.T) {
+	var parts [][]Part
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var req RunRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode run request: %v", err)
+		}
+		parts = append(parts, req.NewMessage.Parts)
+		w.Write([]byte(`[{"content":{"role":"model","parts":[{"text":"ok"}]}}]`))
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app"}, nil)
+	for range 2 {
+		if _, err := c.Chat(t.Context(), "919876543210", "hi"); err != nil {
+			t.Fatalf("Chat() error: %v", err)
+		}
+	}
+	if len(parts) != 2 || len(parts[1]) != 1 {
+		t.Errorf("second turn parts = %+v, want only the message", parts)
+	}
+}
+
+func TestLastReplyKeepsTextAcrossMediaReplies(t *testing.T) {
+	l := newLastReplies("")
+	l.record("s1", []Part{{Text: "see below"}, {Text: " details "}})
+	l.record("s1", []Part{{InlineData: &InlineData{MimeType: "image/png", Data: "AA=="}}})
+
+	got := l.withContext("s1", []Part{{Text: "next"}})
+	if len(got) != 2 || got[0].Text != "see below\ndetails" || got[1].Text != "next" {
+		t.Errorf("withContext() = %+v", got)
+	}
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRetryAfter is waited before retrying a 429 that carries no usable
+// Retry-After header.
+const defaultRetryAfter = time.Second
+
+// RateLimitedError is returned when ADK answers 429 Too Many Requests and
+// the turn could not be retried within adk.rate_limit.max_wait.
+type RateLimitedError struct {
+	// RetryAfter is the delay requested by the server, 0 if it gave none.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("ADK rate limited the request (retry after %s)", e.RetryAfter)
+	}
+	return "ADK rate limited the request"
+}
+
+// rateLimitError builds a RateLimitedError from a 429 response's
+// Retry-After header.
+func rateLimitError(header http.Header, now time.Time) *RateLimitedError {
+	return &RateLimitedError{RetryAfter: parseRetryAfter(header.Get("Retry-After"), now)}
+}
+
+// parseRetryAfter parses a Retry-After value in either delta-seconds or
+// HTTP-date form. Missing or invalid values and dates in the past yield 0.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0
+	}
+	if d := t.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// sleepCtx waits for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// withRateLimitRetry calls attempt, and while it fails with a
+// RateLimitedError waits the requested delay and tries again, up to
+// c.rateRetries times and c.rateMaxWait in total. When the next wait would
+// exceed the budget the RateLimitedError is returned straight away.
+func (c *Client) withRateLimitRetry(ctx context.Context, attempt func() ([]Part, error)) ([]Part, error) {
+	var waited time.Duration
+	for retry := 0; ; retry++ {
+		parts, err := attempt()
+		var rl *RateLimitedError
+		if !errors.As(err, &rl) || retry >= c.rateRetries {
+			return parts, err
+		}
+		wait := rl.RetryAfter
+		if wait <= 0 {
+			wait = defaultRetryAfter
+		}
+		if waited+wait > c.rateMaxWait {
+			return nil, err
+		}
+		slog.Info("ADK rate limited, retrying", "retry_after", wait, "attempt", retry+1)
+		if err := c.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		waited += wait
+	}
+}
@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		in   string
+		want time.Duration
+	}{
+		{"delta seconds", "120", 2 * time.Minute},
+		{"delta seconds with space", " 7 ", 7 * time.Second},
+		{"http date", "Fri, 02 Jan 2026 03:04:35 GMT", 30 * time.Second},
+		{"rfc850 date", "Friday, 02-Jan-26 03:05:05 GMT", time.Minute},
+		{"date in the past", "Fri, 02 Jan 2026 03:00:00 GMT", 0},
+		{"negative", "-5", 0},
+		{"empty", "", 0},
+		{"garbage", "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.in, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// rateLimitServer answers the first limited /run calls with 429 and
+// retryAfter, then succeeds.
+func rateLimitServer(t *testing.T, limited int, retryAfter string) (*httptest.Server, *int) {
+	t.Helper()
+	runs := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		runs++
+		if runs <= limited {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"content":{"role":"model","parts":[{"text":"ok"}]}}]`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &runs
+}
+
+func TestRateLimitRetryHonorsRetryAfter(t *testing.T) {
+	server, runs := rateLimitServer(t, 2, "3")
+	c := NewClient(&config.ADKConfig{
+		Endpoint:  server.URL,
+		AppName:   "app",
+		RateLimit: config.ADKRateLimitConfig{MaxRetries: 2, MaxWait: "10s"},
+	}, nil)
+	var waits []time.Duration
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	parts, err := c.Chat(t.Context(), "919876543210", "hello")
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if len(parts) != 1 || parts[0].Text != "ok" {
+		t.Errorf("parts = %+v", parts)
+	}
+	if *runs != 3 {
+		t.Errorf("runs = %d, want 3", *runs)
+	}
+	if len(waits) != 2 || waits[0] != 3*time.Second || waits[1] != 3*time.Second {
+		t.Errorf("waits = %v, want [3s 3s]", waits)
+	}
+}
+
+func TestRateLimitWaitBeyondBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		maxRetries int
+		wantRuns   int
+	}{
+		{"wait exceeds max_wait", "60", 2, 1},
+		{"retries exhausted", "1", 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, runs := rateLimitServer(t, 5, tt.retryAfter)
+			c := NewClient(&config.ADKConfig{
+				Endpoint:  server.URL,
+				AppName:   "app",
+				RateLimit: config.ADKRateLimitConfig{MaxRetries: tt.maxRetries, MaxWait: "10s"},
+			}, nil)
+			c.sleep = func(context.Context, time.Duration) error { return nil }
+
+			_, err := c.Chat(t.Context(), "919876543210", "hello")
+			var rl *RateLimitedError
+			if !errors.As(err, &rl) {
+				t.Fatalf("Chat() error = %v, want *RateLimitedError", err)
+			}
+			if *runs != tt.wantRuns {
+				t.Errorf("runs = %d, want %d", *runs, tt.wantRuns)
+			}
+		})
+	}
+}
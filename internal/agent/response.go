@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultSnippetLength is how much of an unexpected body is quoted in
+// InvalidResponseError when adk.error_snippet_length is unset.
+const defaultSnippetLength = 256
+
+// InvalidResponseError reports an ADK response that is not the expected
+// JSON, such as a proxy error page served with status 200.
+type InvalidResponseError struct {
+	ContentType string
+	// Snippet is the start of the body, whitespace-collapsed and truncated.
+	Snippet string
+	// Err is the underlying decode error.
+	Err error
+}
+
+func (e *InvalidResponseError) Error() string {
+	return fmt.Sprintf("ADK returned a non-JSON response (content-type %q), check adk.endpoint: %v; body: %q", e.ContentType, e.Err, e.Snippet)
+}
+
+func (e *InvalidResponseError) Unwrap() error {
+	return e.Err
+}
+
+// decodeEvents decodes a /run response body, returning an
+// *InvalidResponseError when it is not a JSON event list. The content type
+// is only reported, not enforced, since some servers mislabel valid JSON.
+func decodeEvents(body []byte, contentType string, snippetLen int) ([]Event, error) {
+	var events []Event
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, &InvalidResponseError{ContentType: contentType, Snippet: bodySnippet(body, snippetLen), Err: err}
+	}
+	return events, nil
+}
+
+// bodySnippet collapses whitespace in body and truncates it to at most n
+// bytes without splitting a UTF-8 sequence.
+func bodySnippet(body []byte, n int) string {
+	if n <= 0 {
+		n = defaultSnippetLength
+	}
+	s := strings.Join(strings.Fields(string(body)), " ")
+	if len(s) <= n {
+		return s
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
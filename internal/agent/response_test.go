@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestChatRunNonJSONResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		snippetLen  int
+		wantSnippet string
+	}{
+		{
+			name:        "html error page with 200",
+			contentType: "text/html; charset=utf-8",
+			body:        "<html>\n  <body>502 Bad Gateway</body>\n</html>",
+			wantSnippet: "<html> <body>502 Bad Gateway</body> </html>",
+		},
+		{
+			name:        "json content type with html body",
+			contentType: "application/json",
+			body:        "<html>oops</html>",
+			wantSnippet: "<html>oops</html>",
+		},
+		{
+			name:        "snippet truncated",
+			contentType: "text/plain",
+			body:        strings.Repeat("x", 50),
+			snippetLen:  10,
+			wantSnippet: strings.Repeat("x", 10) + "…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "/sessions/") {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", ErrorSnippetLength: tt.snippetLen}, nil)
+			_, err := c.Chat(t.Context(), "919876543210", "hello")
+
+			var invalid *InvalidResponseError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("Chat() error = %v, want *InvalidResponseError", err)
+			}
+			if invalid.ContentType != tt.contentType {
+				t.Errorf("ContentType = %q, want %q", invalid.ContentType, tt.contentType)
+			}
+			if invalid.Snippet != tt.wantSnippet {
+				t.Errorf("Snippet = %q, want %q", invalid.Snippet, tt.wantSnippet)
+			}
+			if invalid.Err == nil {
+				t.Error("Err should carry the decode error")
+			}
+		})
+	}
+}
+
+func TestChatRunAcceptsMislabelledJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/sessions/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(`[{"content":{"role":"model","parts":[{"text":"ok"}]}}]`))
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app"}, nil)
+	parts, err := c.Chat(t.Context(), "919876543210", "hello")
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if len(parts) != 1 || parts[0].Text != "ok" {
+		t.Errorf("unexpected parts: %+v", parts)
+	}
+}
+
+func TestBodySnippetKeepsRunesIntact(t *testing.T) {
+	got := bodySnippet([]byte("héllo"), 2)
+	if got != "h…" {
+		t.Errorf("bodySnippet = %q, want %q", got, "h…")
+	}
+}
@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Conversation identifies where a turn was sent from.
+type Conversation struct {
+	// UserID is the sender.
+	UserID string
+	// GroupID is the group chat's JID; empty for direct chats.
+	GroupID string
+}
+
+// SessionKey maps a conversation to the ADK user and session name its
+// turns run in.
+type SessionKey func(conv Conversation) (userID, session string)
+
+// PerUserSession keys every conversation by its sender, so a user's direct
+// and group messages share the user's session. It is the default.
+func PerUserSession(conv Conversation) (string, string) {
+	return conv.UserID, conv.UserID
+}
+
+// PerGroupSession keys a group conversation by the group JID, so all members
+// share one session. Direct chats stay keyed by the sender.
+func PerGroupSession(conv Conversation) (string, string) {
+	if conv.GroupID != "" {
+		return conv.GroupID, conv.GroupID
+	}
+	return conv.UserID, conv.UserID
+}
+
+// SetSessionKey selects how conversations map to ADK sessions. Call it
+// before deriving clients with ForApp.
+func (c *Client) SetSessionKey(key SessionKey) {
+	c.sessionKey = key
+}
+
+// SessionFor returns the ADK user and session name conv's turns run in.
+func (c *Client) SessionFor(conv Conversation) (userID, session string) {
+	key := c.sessionKey
+	if key == nil {
+		key = PerUserSession
+	}
+	return key(conv)
+}
+
+// SessionStater returns the state a conversation's session is created
+// with, e.g. a group's subject and members.
+type SessionStater func(ctx context.Context, conv Conversation) (map[string]any, error)
+
+// SetSessionStater registers state for sessions created by
+// ChatConversation, sent as SessionRequest.State. Sessions are ensured on
+// every turn, so state is asked for on every turn and should be cheap.
+// Failures are logged and the session is created without it.
+func (c *Client) SetSessionStater(state SessionStater) {
+	c.initialState = state
+}
+
+// ChatConversation is ChatPartsWithState for a turn in conv, run in the
+// session the client's SessionKey picks for it.
+func (c *Client) ChatConversation(ctx context.Context, conv Conversation, parts []Part, state map[string]any) ([]Part, error) {
+	userID, session := c.SessionFor(conv)
+	var initial map[string]any
+	if c.initialState != nil {
+		var err error
+		if initial, err = c.initialState(ctx, conv); err != nil {
+			slog.Warn("failed to build initial session state", "user_id", userID, "session", session, "error", err)
+		}
+	}
+	return c.chatParts(ctx, userID, session, initial, parts, state)
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestChatConversationSessionKeys(t *testing.T) {
+	const group = "120363012345678901@g.us"
+	var runs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/run" {
+			var req RunRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("failed to decode run request: %v", err)
+			}
+			runs = append(runs, req.UserID+"/"+req.SessionID)
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	turns := []Conversation{
+		{UserID: "919811111111", GroupID: group},
+		{UserID: "919822222222", GroupID: group},
+		{UserID: "919811111111"},
+	}
+	tests := []struct {
+		name string
+		key  SessionKey
+		want []string
+	}{
+		{"default per user", nil, []string{
+			"919811111111/919811111111",
+			"919822222222/919822222222",
+			"919811111111/919811111111",
+		}},
+		{"per group", PerGroupSession, []string{
+			group + "/" + group,
+			group + "/" + group,
+			"919811111111/919811111111",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs = nil
+			c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "shop"}, nil)
+			if tt.key != nil {
+				c.SetSessionKey(tt.key)
+			}
+			for _, conv := range turns {
+				if _, err := c.ForApp("shop").ChatConversation(t.Context(), conv, []Part{{Text: "hi"}}, nil); err != nil {
+					t.Fatalf("ChatConversation() error: %v", err)
+				}
+			}
+			if len(runs) != len(tt.want) {
+				t.Fatalf("runs = %v, want %v", runs, tt.want)
+			}
+			for i := range runs {
+				if runs[i] != tt.want[i] {
+					t.Errorf("run %d = %q, want %q", i, runs[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestChatConversationInitialState(t *testing.T) {
+	var created []SessionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/run" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		var req SessionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode session request: %v", err)
+		}
+		created = append(created, req)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "shop"}, nil)
+	c.SetSessionKey(PerGroupSession)
+	c.SetSessionStater(func(_ context.Context, conv Conversation) (map[string]any, error) {
+		if conv.GroupID == "" {
+			return nil, errors.New("not a group")
+		}
+		return map[string]any{"group": map[string]any{"subject": "Book club"}}, nil
+	})
+
+	ctx := t.Context()
+	if _, err := c.ChatConversation(ctx, Conversation{UserID: "919811111111", GroupID: "120363012345678901@g.us"}, []Part{{Text: "hi"}}, nil); err != nil {
+		t.Fatalf("ChatConversation() error: %v", err)
+	}
+	if _, err := c.ChatConversation(ctx, Conversation{UserID: "919811111111"}, []Part{{Text: "hi"}}, nil); err != nil {
+		t.Fatalf("ChatConversation() with failing stater error: %v", err)
+	}
+	if len(created) != 2 {
+		t.Fatalf("session requests = %d, want 2", len(created))
+	}
+	group, ok := created[0].State["group"].(map[string]any)
+	if !ok || group["subject"] != "Book club" {
+		t.Errorf("group session state = %v", created[0].State)
+	}
+	if created[1].State != nil {
+		t.Errorf("direct session state = %v, want none", created[1].State)
+	}
+}
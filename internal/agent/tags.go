@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"os"
+	"sync"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// Session tag keys set by the gateway itself.
+const (
+	TagChannel  = "channel"
+	TagInstance = "gateway_instance"
+	TagBotJID   = "bot_jid"
+)
+
+// sessionTags is attribution metadata merged into every run request's state
+// delta so the backend can tell which channel and gateway a turn came from.
+type sessionTags struct {
+	mu     sync.RWMutex
+	values map[string]any
+}
+
+func newSessionTags(cfg config.SessionTagsConfig) *sessionTags {
+	if !cfg.Enabled {
+		return nil
+	}
+	values := make(map[string]any, len(cfg.Static)+2)
+	for k, v := range cfg.Static {
+		values[k] = v
+	}
+	values[TagChannel] = "whatsapp"
+	instance := cfg.InstanceID
+	if instance == "" {
+		if host, err := os.Hostname(); err == nil {
+			instance = host
+		}
+	}
+	if instance != "" {
+		values[TagInstance] = instance
+	}
+	return &sessionTags{values: values}
+}
+
+// SetSessionTag sets a dynamic tag such as the bot JID once it is known. It
+// is a no-op when session tags are disabled.
+func (c *Client) SetSessionTag(key string, value any) {
+	if c.tags == nil {
+		return
+	}
+	c.tags.mu.Lock()
+	defer c.tags.mu.Unlock()
+	c.tags.values[key] = value
+}
+
+// withTags returns state with the session tags added. Keys already present
+// in state take precedence.
+func (c *Client) withTags(state map[string]any) map[string]any {
+	if c.tags == nil {
+		return state
+	}
+	c.tags.mu.RLock()
+	defer c.tags.mu.RUnlock()
+	merged := make(map[string]any, len(c.tags.values)+len(state))
+	for k, v := range c.tags.values {
+		merged[k] = v
+	}
+	for k, v := range state {
+		merged[k] = v
+	}
+	return merged
+}
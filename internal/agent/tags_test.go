@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestSessionTagsInRunRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		tags  config.SessionTagsConfig
+		state map[string]any
+		want  map[string]any
+	}{
+		{
+			name: "static and dynamic tags",
+			tags: config.SessionTagsConfig{
+				Enabled:    true,
+				InstanceID: "gw-1",
+				Static:     map[string]string{"region": "ap-south-1"},
+			},
+			want: map[string]any{
+				TagChannel:  "whatsapp",
+				TagInstance: "gw-1",
+				TagBotJID:   "919000000000@s.whatsapp.net",
+				"region":    "ap-south-1",
+			},
+		},
+		{
+			name:  "turn state wins over tags",
+			tags:  config.SessionTagsConfig{Enabled: true, InstanceID: "gw-1"},
+			state: map[string]any{TagChannel: "override", "user_name": "Asha"},
+			want: map[string]any{
+				TagChannel:  "override",
+				TagInstance: "gw-1",
+				TagBotJID:   "919000000000@s.whatsapp.net",
+				"user_name": "Asha",
+			},
+		},
+		{
+			name: "disabled",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RunRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "/sessions/") {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("failed to decode run request: %v", err)
+				}
+				w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			c := NewClient(&config.ADKConfig{Endpoint: server.URL, AppName: "app", SessionTags: tt.tags}, nil)
+			c.SetSessionTag(TagBotJID, "919000000000@s.whatsapp.net")
+
+			if _, err := c.ChatPartsWithState(t.Context(), "919876543210", []Part{{Text: "hi"}}, tt.state); err != nil {
+				t.Fatalf("ChatPartsWithState() error: %v", err)
+			}
+			if len(got.StateDelta) != len(tt.want) {
+				t.Fatalf("stateDelta = %v, want %v", got.StateDelta, tt.want)
+			}
+			for k, v := range tt.want {
+				if got.StateDelta[k] != v {
+					t.Errorf("stateDelta[%q] = %v, want %v", k, got.StateDelta[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionTagsSharedWithForApp(t *testing.T) {
+	c := NewClient(&config.ADKConfig{AppName: "app", SessionTags: config.SessionTagsConfig{Enabled: true, InstanceID: "gw-1"}}, nil)
+	biz := c.ForApp("business")
+	c.SetSessionTag(TagBotJID, "919000000000@s.whatsapp.net")
+
+	if got := biz.withTags(nil)[TagBotJID]; got != "919000000000@s.whatsapp.net" {
+		t.Errorf("ForApp client bot_jid = %v, want shared tag", got)
+	}
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Recognised AUTH command formats. A command selects its format with a
+// leading version marker; commands without one use AuthFormatV1.
+const (
+	// AuthFormatV1 is "AUTH <public_key> <nonce>" (also "AUTH v1 ...").
+	AuthFormatV1 = "v1"
+	// AuthFormatV2 is "AUTH v2 <nonce> <public_key>".
+	AuthFormatV2 = "v2"
+)
+
+const (
+	pubKeyPattern = `([A-Za-z0-9_-]{43}=?)`
+	noncePattern  = `([A-Za-z0-9_-]{16,})`
+)
+
+var (
+	authVersionRe = regexp.MustCompile(`^AUTH\s+(v\d+)\s+(.*)$`)
+
+	// authFormats maps each format to its argument grammar and the
+	// submatch indexes of the public key and nonce.
+	authFormats = map[string]struct {
+		re            *regexp.Regexp
+		pubKey, nonce int
+	}{
+		AuthFormatV1: {regexp.MustCompile(`^` + pubKeyPattern + `\s+` + noncePattern + `$`), 1, 2},
+		AuthFormatV2: {regexp.MustCompile(`^` + noncePattern + `\s+` + pubKeyPattern + `$`), 2, 1},
+	}
+)
+
+var (
+	errMalformedAuth = errors.New("malformed AUTH command")
+	// errUnsupportedAuthVersion wraps the version marker of a command whose
+	// format is unknown or not enabled.
+	errUnsupportedAuthVersion = errors.New("unsupported AUTH command version")
+)
+
+// authCommand is a parsed AUTH command.
+type authCommand struct {
+	format string
+	pubKey string
+	nonce  string
+}
+
+// parseAuthCommand parses text in any of the enabled formats. The format is
+// chosen by the version marker, so each command is matched against one
+// grammar only.
+func parseAuthCommand(text string, enabled map[string]bool) (authCommand, error) {
+	text = strings.TrimSpace(text)
+	format, args := AuthFormatV1, ""
+	if m := authVersionRe.FindStringSubmatch(text); m != nil {
+		format, args = m[1], m[2]
+	} else if rest, ok := strings.CutPrefix(text, "AUTH"); ok {
+		args = strings.TrimSpace(rest)
+	} else {
+		return authCommand{}, errMalformedAuth
+	}
+
+	grammar, known := authFormats[format]
+	if !known || !enabled[format] {
+		return authCommand{}, fmt.Errorf("%w %q", errUnsupportedAuthVersion, format)
+	}
+	m := grammar.re.FindStringSubmatch(args)
+	if m == nil {
+		return authCommand{}, errMalformedAuth
+	}
+	return authCommand{format: format, pubKey: m[grammar.pubKey], nonce: m[grammar.nonce]}, nil
+}
+
+// authFormatSet validates format names; an empty list enables AuthFormatV1
+// only.
+func authFormatSet(formats []string) (map[string]bool, error) {
+	if len(formats) == 0 {
+		return map[string]bool{AuthFormatV1: true}, nil
+	}
+	set := make(map[string]bool, len(formats))
+	for _, f := range formats {
+		if _, ok := authFormats[f]; !ok {
+			return nil, fmt.Errorf("unknown AUTH command format %q", f)
+		}
+		set[f] = true
+	}
+	return set, nil
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseAuthCommand(t *testing.T) {
+	const key = "MCowBQYDK2VwAyEAabcdefghijklmnopqrstuvwxyz1"
+	const nonce = "abcdefghijklmnop"
+	both := map[string]bool{AuthFormatV1: true, AuthFormatV2: true}
+
+	tests := []struct {
+		name    string
+		text    string
+		enabled map[string]bool
+		want    authCommand
+		wantErr error
+	}{
+		{"v1 default", "AUTH " + key + " " + nonce, both, authCommand{AuthFormatV1, key, nonce}, nil},
+		{"v1 explicit marker", "AUTH v1 " + key + " " + nonce, both, authCommand{AuthFormatV1, key, nonce}, nil},
+		{"v2 nonce first", "AUTH v2 " + nonce + " " + key, both, authCommand{AuthFormatV2, key, nonce}, nil},
+		{"v2 surrounding space", "  AUTH  v2  " + nonce + "  " + key + "  ", both, authCommand{AuthFormatV2, key, nonce}, nil},
+		{"v2 with v1 order", "AUTH v2 " + key + " " + nonce, both, authCommand{}, errMalformedAuth},
+		{"v2 not enabled", "AUTH v2 " + nonce + " " + key, map[string]bool{AuthFormatV1: true}, authCommand{}, errUnsupportedAuthVersion},
+		{"unknown version", "AUTH v9 " + key + " " + nonce, both, authCommand{}, errUnsupportedAuthVersion},
+		{"missing nonce", "AUTH " + key, both, authCommand{}, errMalformedAuth},
+		{"not auth", "HELLO " + key + " " + nonce, both, authCommand{}, errMalformedAuth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAuthCommand(tt.text, tt.enabled)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("parseAuthCommand() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAuthCommand() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseAuthCommand() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetCommandFormats(t *testing.T) {
+	h := newTestOAuthHandler(t)
+	if err := h.SetCommandFormats([]string{"v3"}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if err := h.SetCommandFormats([]string{AuthFormatV1, AuthFormatV2}); err != nil {
+		t.Fatalf("SetCommandFormats() error: %v", err)
+	}
+
+	nonce := "abcdefghijklmnop"
+	reply, err := h.Handle(context.Background(), "919876543210", "AUTH v2 "+nonce+" "+validPubKey(t))
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if !strings.Contains(reply, "/auth#token=") || !strings.Contains(reply, "&nonce="+nonce) {
+		t.Errorf("v2 command not accepted: %s", reply)
+	}
+
+	reply, err = h.Handle(context.Background(), "919876543210", "AUTH v7 "+nonce+" "+validPubKey(t))
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if !strings.Contains(reply, "Unsupported AUTH command version") {
+		t.Errorf("unknown version not rejected: %s", reply)
+	}
+}
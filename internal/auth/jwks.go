@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+)
+
+// JWKSPath is where the gateway publishes its signing keys.
+const JWKSPath = "/.well-known/jwks.json"
+
+// JWK is the public half of an RSA signing key in JSON Web Key form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyID derives a stable key ID from pub using its RFC 7638 thumbprint.
+func KeyID(pub *rsa.PublicKey) string {
+	n, e := encodeRSA(pub)
+	// Members in lexicographic order, no whitespace, as RFC 7638 requires.
+	canonical := fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, e, n)
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func encodeRSA(pub *rsa.PublicKey) (n, e string) {
+	n = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+	e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	return n, e
+}
+
+func rsaJWK(pub *rsa.PublicKey, kid string) JWK {
+	n, e := encodeRSA(pub)
+	return JWK{Kty: "RSA", Use: "sig", Alg: "RS256", Kid: kid, N: n, E: e}
+}
+
+// JWKSHandler serves the key set as JSON.
+func JWKSHandler(keys JWKS) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := json.NewEncoder(w).Encode(keys); err != nil {
+			http.Error(w, "encode key set", http.StatusInternalServerError)
+		}
+	})
+}
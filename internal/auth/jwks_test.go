@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// publicKeyFromJWK rebuilds the RSA key an app would derive from the JWKS.
+func publicKeyFromJWK(t *testing.T, k JWK) *rsa.PublicKey {
+	t.Helper()
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		t.Fatalf("decode n: %v", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		t.Fatalf("decode e: %v", err)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+}
+
+func TestCallbackTokenKidMatchesJWKS(t *testing.T) {
+	keyPath, _ := generateTestKey(t)
+	gen, err := NewJWTGenerator(keyPath, "whatsadk-gateway", "", 2*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	JWKSHandler(gen.JWKS()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, JWKSPath, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("JWKS status = %d", rr.Code)
+	}
+	var set JWKS
+	if err := json.Unmarshal(rr.Body.Bytes(), &set); err != nil {
+		t.Fatalf("decode JWKS: %v", err)
+	}
+
+	tokenStr, err := gen.TokenWithAudience("910987654321", "test-app")
+	if err != nil {
+		t.Fatalf("failed to sign callback token: %v", err)
+	}
+
+	// Verify the way an app would: select the JWKS entry by kid.
+	_, err = jwt.ParseWithClaims(tokenStr, &Claims{}, func(tok *jwt.Token) (interface{}, error) {
+		kid, _ := tok.Header["kid"].(string)
+		for _, k := range set.Keys {
+			if k.Kid == kid {
+				return publicKeyFromJWK(t, k), nil
+			}
+		}
+		t.Fatalf("kid %q not found in JWKS", kid)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("callback token did not verify against JWKS: %v", err)
+	}
+}
+
+func TestJWKSPublishedKeys(t *testing.T) {
+	keyPath, _ := generateTestKey(t)
+	gen, err := NewJWTGenerator(keyPath, "iss", "", time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+	gen.SetKeyID("current")
+
+	_, retired := generateTestKey(t)
+	der, err := x509.MarshalPKIXPublicKey(retired)
+	if err != nil {
+		t.Fatalf("marshal retired key: %v", err)
+	}
+	retiredPath := filepath.Join(t.TempDir(), "retired.pem")
+	if err := os.WriteFile(retiredPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644); err != nil {
+		t.Fatalf("write retired key: %v", err)
+	}
+	if err := gen.AddPublishedKeys([]string{retiredPath}); err != nil {
+		t.Fatalf("AddPublishedKeys: %v", err)
+	}
+
+	keys := gen.JWKS().Keys
+	if len(keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(keys))
+	}
+	if keys[0].Kid != "current" {
+		t.Errorf("current kid = %q, want %q", keys[0].Kid, "current")
+	}
+	if keys[1].Kid != KeyID(retired) {
+		t.Errorf("retired kid = %q, want thumbprint %q", keys[1].Kid, KeyID(retired))
+	}
+
+	if err := gen.AddPublishedKeys([]string{filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("expected error for missing published key")
+	}
+}
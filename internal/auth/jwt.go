@@ -13,9 +13,13 @@ import (
 
 type JWTGenerator struct {
 	key      *rsa.PrivateKey
+	kid      string
 	issuer   string
 	audience string
 	ttl      time.Duration
+	// published holds retired keys still listed in the JWKS so tokens signed
+	// before a rotation keep verifying until they expire.
+	published []JWK
 }
 
 func NewJWTGenerator(keyPath, issuer, audience string, ttl time.Duration) (*JWTGenerator, error) {
@@ -31,6 +35,7 @@ func NewJWTGenerator(keyPath, issuer, audience string, ttl time.Duration) (*JWTG
 
 	return &JWTGenerator{
 		key:      key,
+		kid:      KeyID(&key.PublicKey),
 		issuer:   issuer,
 		audience: audience,
 		ttl:      ttl,
@@ -53,8 +58,7 @@ func (g *JWTGenerator) Token(userID string) (string, error) {
 		claims.Audience = jwt.ClaimStrings{g.audience}
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	return token.SignedString(g.key)
+	return g.sign(claims)
 }
 
 func (g *JWTGenerator) TokenWithAudience(userID, audience string) (string, error) {
@@ -69,10 +73,45 @@ func (g *JWTGenerator) TokenWithAudience(userID, audience string) (string, error
 			ExpiresAt: jwt.NewNumericDate(now.Add(g.ttl)),
 		},
 	}
+	return g.sign(claims)
+}
+
+// sign issues an RS256 token carrying the signing key's kid header.
+func (g *JWTGenerator) sign(claims Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = g.kid
 	return token.SignedString(g.key)
 }
 
+// KeyID returns the kid set on issued tokens.
+func (g *JWTGenerator) KeyID() string {
+	return g.kid
+}
+
+// SetKeyID overrides the kid derived from the key's thumbprint.
+func (g *JWTGenerator) SetKeyID(kid string) {
+	g.kid = kid
+}
+
+// AddPublishedKeys lists retired public keys in the JWKS alongside the
+// current signing key. Their kid is the RFC 7638 thumbprint.
+func (g *JWTGenerator) AddPublishedKeys(paths []string) error {
+	for _, path := range paths {
+		pub, err := loadPublicKey(path)
+		if err != nil {
+			return fmt.Errorf("published key %s: %w", path, err)
+		}
+		g.published = append(g.published, rsaJWK(pub, KeyID(pub)))
+	}
+	return nil
+}
+
+// JWKS returns the current signing key followed by any published keys.
+func (g *JWTGenerator) JWKS() JWKS {
+	keys := []JWK{rsaJWK(&g.key.PublicKey, g.kid)}
+	return JWKS{Keys: append(keys, g.published...)}
+}
+
 func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
 	block, _ := pem.Decode(data)
 	if block == nil {
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrBindingNotFound is returned when no binding was recorded for a nonce.
+	ErrBindingNotFound = errors.New("nonce binding not found")
+	// ErrBindingExpired is returned when a binding is older than its TTL.
+	ErrBindingExpired = errors.New("nonce binding expired")
+	// ErrNonceInUse is returned by Bind when the nonce is already bound and
+	// the binding has not expired.
+	ErrNonceInUse = errors.New("nonce already bound")
+)
+
+// NonceBinding records which user public key an OAuth token was issued for,
+// so a later signature over the nonce can be checked server-side.
+type NonceBinding struct {
+	Nonce    string    `json:"nonce"`
+	Phone    string    `json:"phone"`
+	PubKey   string    `json:"pubkey"`
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+// BindingStore persists nonce bindings. store.Store implements it.
+type BindingStore interface {
+	PutNonceBinding(ctx context.Context, b NonceBinding) error
+	GetNonceBinding(ctx context.Context, nonce string) (*NonceBinding, error)
+	DeleteNonceBinding(ctx context.Context, nonce string) error
+	// PruneNonceBindings deletes bindings issued before cutoff and returns
+	// how many were removed.
+	PruneNonceBindings(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// NonceBindings records and looks up nonce bindings, enforcing a TTL.
+type NonceBindings struct {
+	store BindingStore
+	ttl   time.Duration
+	now   func() time.Time
+
+	// mu serializes Bind's check-then-write within this process.
+	mu sync.Mutex
+}
+
+// NewNonceBindings creates a binding registry backed by store. Bindings
+// older than ttl are treated as expired and removed on lookup.
+func NewNonceBindings(store BindingStore, ttl time.Duration) *NonceBindings {
+	return &NonceBindings{store: store, ttl: ttl, now: time.Now}
+}
+
+// Bind records that a token for nonce was issued to phone with pubKey. The
+// first binding for a nonce wins: while it is unexpired, binding the same
+// nonce again fails with ErrNonceInUse, so a nonce seen by someone else
+// cannot be rebound to their phone or key.
+func (n *NonceBindings) Bind(ctx context.Context, nonce, phone, pubKey string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	existing, err := n.store.GetNonceBinding(ctx, nonce)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce binding: %w", err)
+	}
+	if existing != nil && n.now().Sub(existing.IssuedAt) <= n.ttl {
+		return ErrNonceInUse
+	}
+
+	b := NonceBinding{
+		Nonce:    nonce,
+		Phone:    phone,
+		PubKey:   pubKey,
+		IssuedAt: n.now().UTC(),
+	}
+	if err := n.store.PutNonceBinding(ctx, b); err != nil {
+		return fmt.Errorf("failed to store nonce binding: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the binding for nonce, or ErrBindingNotFound /
+// ErrBindingExpired.
+func (n *NonceBindings) Lookup(ctx context.Context, nonce string) (*NonceBinding, error) {
+	b, err := n.store.GetNonceBinding(ctx, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce binding: %w", err)
+	}
+	if b == nil {
+		return nil, ErrBindingNotFound
+	}
+	if n.now().Sub(b.IssuedAt) > n.ttl {
+		if err := n.store.DeleteNonceBinding(ctx, nonce); err != nil {
+			return nil, fmt.Errorf("failed to delete expired nonce binding: %w", err)
+		}
+		return nil, ErrBindingExpired
+	}
+	return b, nil
+}
+
+// Prune deletes bindings older than the TTL. Lookup only removes the
+// bindings it touches, so Prune keeps the store from growing unbounded.
+func (n *NonceBindings) Prune(ctx context.Context) (int, error) {
+	removed, err := n.store.PruneNonceBindings(ctx, n.now().Add(-n.ttl))
+	if err != nil {
+		return removed, fmt.Errorf("failed to prune nonce bindings: %w", err)
+	}
+	return removed, nil
+}
+
+// RunPruner calls Prune every interval until ctx is done.
+func (n *NonceBindings) RunPruner(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := n.Prune(ctx)
+			if err != nil {
+				slog.Warn("nonce binding prune failed", "error", err)
+				continue
+			}
+			if removed > 0 {
+				slog.Info("pruned expired nonce bindings", "count", removed)
+			}
+		}
+	}
+}
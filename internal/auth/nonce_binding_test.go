@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type memBindingStore struct {
+	bindings map[string]NonceBinding
+}
+
+func newMemBindingStore() *memBindingStore {
+	return &memBindingStore{bindings: make(map[string]NonceBinding)}
+}
+
+func (m *memBindingStore) PutNonceBinding(_ context.Context, b NonceBinding) error {
+	m.bindings[b.Nonce] = b
+	return nil
+}
+
+func (m *memBindingStore) GetNonceBinding(_ context.Context, nonce string) (*NonceBinding, error) {
+	b, ok := m.bindings[nonce]
+	if !ok {
+		return nil, nil
+	}
+	return &b, nil
+}
+
+func (m *memBindingStore) DeleteNonceBinding(_ context.Context, nonce string) error {
+	delete(m.bindings, nonce)
+	return nil
+}
+
+func (m *memBindingStore) PruneNonceBindings(_ context.Context, cutoff time.Time) (int, error) {
+	removed := 0
+	for nonce, b := range m.bindings {
+		if b.IssuedAt.Before(cutoff) {
+			delete(m.bindings, nonce)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func TestNonceBindings_BindAndLookup(t *testing.T) {
+	ctx := context.Background()
+	nb := NewNonceBindings(newMemBindingStore(), time.Hour)
+
+	if err := nb.Bind(ctx, "abcdefghijklmnop", "919876543210", "pubkey"); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	b, err := nb.Lookup(ctx, "abcdefghijklmnop")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if b.Phone != "919876543210" || b.PubKey != "pubkey" {
+		t.Errorf("unexpected binding: %+v", b)
+	}
+	if b.IssuedAt.IsZero() {
+		t.Error("expected IssuedAt to be set")
+	}
+}
+
+func TestNonceBindings_NotFound(t *testing.T) {
+	nb := NewNonceBindings(newMemBindingStore(), time.Hour)
+
+	if _, err := nb.Lookup(context.Background(), "missing"); !errors.Is(err, ErrBindingNotFound) {
+		t.Errorf("expected ErrBindingNotFound, got %v", err)
+	}
+}
+
+func TestNonceBindings_Expired(t *testing.T) {
+	ctx := context.Background()
+	store := newMemBindingStore()
+	nb := NewNonceBindings(store, time.Hour)
+	clock := time.Now()
+	nb.now = func() time.Time { return clock }
+
+	if err := nb.Bind(ctx, "abcdefghijklmnop", "919876543210", "pubkey"); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	clock = clock.Add(2 * time.Hour)
+	if _, err := nb.Lookup(ctx, "abcdefghijklmnop"); !errors.Is(err, ErrBindingExpired) {
+		t.Errorf("expected ErrBindingExpired, got %v", err)
+	}
+	if _, ok := store.bindings["abcdefghijklmnop"]; ok {
+		t.Error("expected expired binding to be removed")
+	}
+}
+
+func TestOAuthHandler_Handle_RecordsBinding(t *testing.T) {
+	h := newTestOAuthHandler(t)
+	store := newMemBindingStore()
+	nb := NewNonceBindings(store, time.Hour)
+	h.SetNonceBindings(nb)
+
+	pubkey := validPubKey(t)
+	nonce := "abcdefghijklmnop"
+	if _, err := h.Handle(context.Background(), "919876543210", "AUTH "+pubkey+" "+nonce); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	b, err := nb.Lookup(context.Background(), nonce)
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if b.Phone != "919876543210" || b.PubKey != pubkey {
+		t.Errorf("unexpected binding: %+v", b)
+	}
+}
+
+func TestNonceBindings_RebindRejected(t *testing.T) {
+	ctx := context.Background()
+	nb := NewNonceBindings(newMemBindingStore(), time.Hour)
+	clock := time.Now()
+	nb.now = func() time.Time { return clock }
+
+	if err := nb.Bind(ctx, "abcdefghijklmnop", "919876543210", "victim-key"); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	if err := nb.Bind(ctx, "abcdefghijklmnop", "911111111111", "attacker-key"); !errors.Is(err, ErrNonceInUse) {
+		t.Fatalf("second Bind error = %v, want ErrNonceInUse", err)
+	}
+	b, err := nb.Lookup(ctx, "abcdefghijklmnop")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if b.Phone != "919876543210" || b.PubKey != "victim-key" {
+		t.Errorf("binding was overwritten: %+v", b)
+	}
+
+	// Once the first binding expires the nonce may be bound again.
+	clock = clock.Add(2 * time.Hour)
+	if err := nb.Bind(ctx, "abcdefghijklmnop", "911111111111", "new-key"); err != nil {
+		t.Errorf("Bind after expiry: %v", err)
+	}
+}
+
+func TestOAuthHandler_Handle_RejectsForeignNonce(t *testing.T) {
+	h := newTestOAuthHandler(t)
+	h.SetNonceBindings(NewNonceBindings(newMemBindingStore(), time.Hour))
+
+	nonce := "abcdefghijklmnop"
+	if _, err := h.Handle(context.Background(), "919876543210", "AUTH "+validPubKey(t)+" "+nonce); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if _, err := h.Handle(context.Background(), "911111111111", "AUTH "+validPubKey(t)+" "+nonce); !errors.Is(err, ErrNonceInUse) {
+		t.Errorf("Handle with reused nonce error = %v, want ErrNonceInUse", err)
+	}
+}
+
+func TestNonceBindings_Prune(t *testing.T) {
+	ctx := context.Background()
+	store := newMemBindingStore()
+	nb := NewNonceBindings(store, time.Hour)
+	clock := time.Now()
+	nb.now = func() time.Time { return clock }
+
+	if err := nb.Bind(ctx, "old-nonce-aaaaaaaa", "919876543210", "k1"); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+	clock = clock.Add(90 * time.Minute)
+	if err := nb.Bind(ctx, "new-nonce-bbbbbbbb", "919876543210", "k2"); err != nil {
+		t.Fatalf("Bind: %v", err)
+	}
+
+	removed, err := nb.Prune(ctx)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	if _, ok := store.bindings["new-nonce-bbbbbbbb"]; !ok {
+		t.Error("unexpired binding was pruned")
+	}
+}
@@ -1,21 +1,26 @@
 package auth
 
 import (
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"regexp"
+	"log/slog"
 	"strings"
 	"sync"
 	"time"
 )
 
-var authCommandRe = regexp.MustCompile(`^AUTH\s+([A-Za-z0-9_-]{43}=?)\s+([A-Za-z0-9_-]{16,})$`)
-
 // OAuthHandler processes AUTH commands received via WhatsApp messages.
 type OAuthHandler struct {
 	tokenGen  *OAuthTokenGenerator
 	spaURL    string
 	rateLimit int
+	bindings  *NonceBindings
+	// logReplies logs each issued reply with its token masked.
+	logReplies bool
+	// formats holds the enabled AUTH command formats.
+	formats map[string]bool
 
 	mu      sync.Mutex
 	history map[string][]time.Time // phone → timestamps of AUTH requests
@@ -27,25 +32,52 @@ func NewOAuthHandler(tokenGen *OAuthTokenGenerator, spaURL string, rateLimit int
 		tokenGen:  tokenGen,
 		spaURL:    strings.TrimRight(spaURL, "/"),
 		rateLimit: rateLimit,
+		formats:   map[string]bool{AuthFormatV1: true},
 		history:   make(map[string][]time.Time),
 	}
 }
 
+// SetNonceBindings enables recording of (nonce, phone, pubkey) for every
+// issued token so the binding can be verified later.
+func (h *OAuthHandler) SetNonceBindings(b *NonceBindings) {
+	h.bindings = b
+}
+
+// SetCommandFormats selects which AUTH command formats are accepted
+// (AuthFormatV1, AuthFormatV2). An empty list keeps the default, v1 only.
+func (h *OAuthHandler) SetCommandFormats(formats []string) error {
+	set, err := authFormatSet(formats)
+	if err != nil {
+		return err
+	}
+	h.formats = set
+	return nil
+}
+
+// SetLogReplies enables logging of every issued AUTH reply. The deep-link
+// token is always masked with RedactURLTokens; only the nonce and a token
+// fingerprint appear in the log.
+func (h *OAuthHandler) SetLogReplies(enabled bool) {
+	h.logReplies = enabled
+}
+
 // IsAuthCommand returns true if the text starts with "AUTH " (case-insensitive).
 func IsAuthCommand(text string) bool {
 	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(text)), "AUTH ")
 }
 
 // Handle parses an AUTH command and returns a WhatsApp reply with a deep link.
-func (h *OAuthHandler) Handle(senderPhone, messageBody string) (string, error) {
-	messageBody = strings.TrimSpace(messageBody)
-	matches := authCommandRe.FindStringSubmatch(messageBody)
-	if matches == nil {
+func (h *OAuthHandler) Handle(ctx context.Context, senderPhone, messageBody string) (string, error) {
+	cmd, err := parseAuthCommand(messageBody, h.formats)
+	if errors.Is(err, errUnsupportedAuthVersion) {
+		return "❌ Unsupported AUTH command version. Please update the app and try again.", nil
+	}
+	if err != nil {
 		return "❌ Invalid AUTH command format.\nExpected: AUTH <public_key> <nonce>", nil
 	}
 
-	userPubKey := matches[1]
-	nonce := matches[2]
+	userPubKey := cmd.pubKey
+	nonce := cmd.nonce
 
 	// Validate the public key is a valid 32-byte base64url-encoded key
 	decoded, err := base64.RawURLEncoding.DecodeString(userPubKey)
@@ -67,8 +99,17 @@ func (h *OAuthHandler) Handle(senderPhone, messageBody string) (string, error) {
 		return "", fmt.Errorf("failed to generate OAuth token: %w", err)
 	}
 
+	if h.bindings != nil {
+		if err := h.bindings.Bind(ctx, nonce, senderPhone, userPubKey); err != nil {
+			return "", err
+		}
+	}
+
 	deepLink := fmt.Sprintf("%s/auth#token=%s&nonce=%s", h.spaURL, tokenStr, nonce)
 	reply := fmt.Sprintf("Click here to complete login:\n%s", deepLink)
+	if h.logReplies {
+		slog.Info("issued AUTH reply", "phone", senderPhone, "reply", RedactURLTokens(reply))
+	}
 	return reply, nil
 }
 
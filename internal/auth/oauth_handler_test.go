@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/base64"
@@ -58,7 +59,7 @@ func TestOAuthHandler_Handle_Valid(t *testing.T) {
 	pubkey := validPubKey(t)
 	nonce := "abcdefghijklmnop"
 
-	reply, err := h.Handle("919876543210", "AUTH "+pubkey+" "+nonce)
+	reply, err := h.Handle(context.Background(), "919876543210", "AUTH "+pubkey+" "+nonce)
 	if err != nil {
 		t.Fatalf("Handle: %v", err)
 	}
@@ -80,7 +81,7 @@ func TestOAuthHandler_Handle_InvalidPubKey(t *testing.T) {
 	shortKey := base64.RawURLEncoding.EncodeToString(make([]byte, 16))
 	nonce := "abcdefghijklmnop"
 
-	reply, err := h.Handle("919876543210", "AUTH "+shortKey+" "+nonce)
+	reply, err := h.Handle(context.Background(), "919876543210", "AUTH "+shortKey+" "+nonce)
 	if err != nil {
 		t.Fatalf("Handle: %v", err)
 	}
@@ -102,7 +103,7 @@ func TestOAuthHandler_Handle_MalformedCommand(t *testing.T) {
 
 	for _, text := range tests {
 		t.Run(text, func(t *testing.T) {
-			reply, err := h.Handle("919876543210", text)
+			reply, err := h.Handle(context.Background(), "919876543210", text)
 			if err != nil {
 				t.Fatalf("Handle: %v", err)
 			}
@@ -120,7 +121,7 @@ func TestOAuthHandler_Handle_RateLimit(t *testing.T) {
 	phone := "919876543210"
 
 	for i := 0; i < 5; i++ {
-		reply, err := h.Handle(phone, "AUTH "+pubkey+" "+nonce)
+		reply, err := h.Handle(context.Background(), phone, "AUTH "+pubkey+" "+nonce)
 		if err != nil {
 			t.Fatalf("Handle #%d: %v", i+1, err)
 		}
@@ -130,7 +131,7 @@ func TestOAuthHandler_Handle_RateLimit(t *testing.T) {
 	}
 
 	// 6th request should be rate-limited
-	reply, err := h.Handle(phone, "AUTH "+pubkey+" "+nonce)
+	reply, err := h.Handle(context.Background(), phone, "AUTH "+pubkey+" "+nonce)
 	if err != nil {
 		t.Fatalf("Handle #6: %v", err)
 	}
@@ -158,7 +159,7 @@ func TestOAuthHandler_Handle_Integration(t *testing.T) {
 	nonce := "test_nonce_1234567"
 	phone := "919876543210"
 
-	reply, err := h.Handle(phone, "AUTH "+pubkey+" "+nonce)
+	reply, err := h.Handle(context.Background(), phone, "AUTH "+pubkey+" "+nonce)
 	if err != nil {
 		t.Fatalf("Handle: %v", err)
 	}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+)
+
+// tokenParamRe matches a token=<value> parameter in a URL query or fragment.
+var tokenParamRe = regexp.MustCompile(`\b(token=)([^&#\s]+)`)
+
+// TokenFingerprint returns a short, stable identifier for a token that can be
+// logged in its place: the first 12 hex digits of its SHA-256.
+func TokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// RedactURLTokens replaces every token=<value> parameter in s with the
+// token's fingerprint, leaving the rest of the URL (including the nonce)
+// intact. Use it on any text that may carry a deep link before logging it.
+func RedactURLTokens(s string) string {
+	return tokenParamRe.ReplaceAllStringFunc(s, func(m string) string {
+		sub := tokenParamRe.FindStringSubmatch(m)
+		return sub[1] + "[redacted:" + TokenFingerprint(sub[2]) + "]"
+	})
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestAuthenticator(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	body := `{"phone":"919876543210","text":"hi"}`
+
+	signed := func(ts time.Time, key []byte, b string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/admin/send", strings.NewReader(body))
+		r.Header.Set(SignatureTimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+		r.Header.Set(SignatureHeader, SignRequest(key, http.MethodPost, "/admin/send", ts, []byte(b)))
+		return r
+	}
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"valid signature", signed(now, secret, body), http.StatusOK},
+		{"valid signature within skew", signed(now.Add(-4*time.Minute), secret, body), http.StatusOK},
+		{"expired timestamp", signed(now.Add(-10*time.Minute), secret, body), http.StatusUnauthorized},
+		{"future timestamp", signed(now.Add(10*time.Minute), secret, body), http.StatusUnauthorized},
+		{"wrong secret", signed(now, []byte("other"), body), http.StatusUnauthorized},
+		{"tampered body", signed(now, secret, `{"phone":"910000000000"}`), http.StatusUnauthorized},
+		{"bearer token", func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/admin/send", strings.NewReader(body))
+			r.Header.Set("Authorization", "Bearer tok")
+			return r
+		}(), http.StatusOK},
+		{"wrong bearer token", func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/admin/send", strings.NewReader(body))
+			r.Header.Set("Authorization", "Bearer nope")
+			return r
+		}(), http.StatusUnauthorized},
+		{"no credentials", httptest.NewRequest(http.MethodPost, "/admin/send", strings.NewReader(body)), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewRequestAuthenticator("tok", secret, 5*time.Minute)
+			a.now = func() time.Time { return now }
+			var gotBody string
+			h := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("read body: %v", err)
+				}
+				gotBody = string(b)
+			}))
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, tt.req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && gotBody != body {
+				t.Errorf("handler saw body %q, want original body", gotBody)
+			}
+		})
+	}
+}
+
+func TestRequestAuthenticatorSigningDisabled(t *testing.T) {
+	now := time.Now()
+	a := NewRequestAuthenticator("tok", nil, 5*time.Minute)
+	r := httptest.NewRequest(http.MethodPost, "/admin/send", strings.NewReader("{}"))
+	r.Header.Set(SignatureTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	r.Header.Set(SignatureHeader, SignRequest(nil, http.MethodPost, "/admin/send", now, []byte("{}")))
+
+	rec := httptest.NewRecorder()
+	a.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("handler called for signed request with signing disabled")
+	})).ServeHTTP(rec, r)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestRequestAuthenticatorRejectsReplayOnOtherEndpoint(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	// A captured signature for GET /admin/status, which has no body.
+	sig := SignRequest(secret, http.MethodGet, "/admin/status", now, nil)
+
+	tests := []struct {
+		name, method, target string
+		want                 int
+	}{
+		{"original request", http.MethodGet, "/admin/status", http.StatusOK},
+		{"other method and path", http.MethodPost, "/admin/relogin", http.StatusUnauthorized},
+		{"other method, same path", http.MethodDelete, "/admin/status", http.StatusUnauthorized},
+		{"other path", http.MethodDelete, "/admin/blacklist/919876543210", http.StatusUnauthorized},
+		{"added query", http.MethodGet, "/admin/status?x=1", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewRequestAuthenticator("", secret, 5*time.Minute)
+			a.now = func() time.Time { return now }
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			r.Header.Set(SignatureTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+			r.Header.Set(SignatureHeader, sig)
+
+			rec := httptest.NewRecorder()
+			a.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(rec, r)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/rsa"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -41,13 +42,19 @@ func IsVerificationToken(raw string) *VerificationClaims {
 }
 
 func VerifyVerificationToken(raw string, appKey *rsa.PublicKey) (*VerificationClaims, error) {
+	return VerifyVerificationTokenWithLeeway(raw, appKey, 0)
+}
+
+// VerifyVerificationTokenWithLeeway is VerifyVerificationToken accepting a
+// token up to leeway past its expiry.
+func VerifyVerificationTokenWithLeeway(raw string, appKey *rsa.PublicKey, leeway time.Duration) (*VerificationClaims, error) {
 	claims := &VerificationClaims{}
 	token, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
 		return appKey, nil
-	})
+	}, jwt.WithLeeway(leeway))
 	if err != nil {
 		return nil, fmt.Errorf("token verification failed: %w", err)
 	}
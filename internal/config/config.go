@@ -1,13 +1,17 @@
 package config
 
 import (
+	"crypto/tls"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -20,7 +24,146 @@ type Config struct {
 	Verification VerificationConfig `yaml:"verification"`
 	Cron         CronConfig         `yaml:"cron"`
 	SurrealDB    SurrealDBConfig    `yaml:"surrealdb"`
+	Store        StoreConfig        `yaml:"store"`
+	Blacklist    BlacklistConfig    `yaml:"blacklist"`
+	TLS          OutboundTLSConfig  `yaml:"tls"`
 	Logging      LoggingConfig      `yaml:"logging"`
+	Gateway      GatewayConfig      `yaml:"gateway"`
+}
+
+// GatewayConfig holds process-level settings for the WhatsApp gateway.
+type GatewayConfig struct {
+	// HeartbeatInterval enables a periodic liveness log line (e.g. "1m")
+	// while connected. Empty disables the heartbeat.
+	HeartbeatInterval string `yaml:"heartbeat_interval"`
+	// HeartbeatFile, when set, is rewritten with the timestamp of each beat
+	// so external watchdogs can check its age.
+	HeartbeatFile string `yaml:"heartbeat_file"`
+	// UserAgent is sent on outbound requests to ADK and verification
+	// callbacks (default "whatsadk/<version>").
+	UserAgent string `yaml:"user_agent"`
+	// Environment (e.g. "staging") and InstanceID are attached to every
+	// log record so logs from several gateways can be told apart. Empty
+	// values are omitted.
+	Environment string `yaml:"environment"`
+	InstanceID  string `yaml:"instance_id"`
+	// AdminAddr, when set, serves the admin HTTP API on this address (e.g.
+	// "127.0.0.1:8082"), behind the auth.admin credentials.
+	AdminAddr string `yaml:"admin_addr"`
+}
+
+// StoreConfig controls how store-dependent checks behave when the database
+// is unavailable.
+type StoreConfig struct {
+	// FailurePolicy is "open" (default) to let the request through in
+	// degraded mode when a check cannot be completed, or "closed" to reject
+	// it.
+	FailurePolicy string `yaml:"failure_policy"`
+	// MigrateAttempts is how many times schema migration is tried at
+	// startup before giving up (default 5).
+	MigrateAttempts int `yaml:"migrate_attempts"`
+	// MigrateBackoff is the wait before the first retry, doubling after
+	// each failure (default "1s").
+	MigrateBackoff string `yaml:"migrate_backoff"`
+	// ReadDSN optionally points blacklist reads at a Postgres read replica;
+	// writes and schema migration always use the primary DSN.
+	ReadDSN string `yaml:"read_dsn"`
+	// ReplicaLag is how long after a blacklist change reads that could see
+	// it keep going to the primary (default "5s").
+	ReplicaLag string `yaml:"replica_lag"`
+	// SchemaUpgrade is "auto" (default) to migrate an older database
+	// schema forward at startup, or "refuse" to fail startup instead. A
+	// database newer than the binary always fails startup.
+	SchemaUpgrade string `yaml:"schema_upgrade"`
+}
+
+// OutboundTLSConfig restricts the TLS settings used by outbound HTTPS clients
+// (ADK and verification callbacks).
+type OutboundTLSConfig struct {
+	// MinVersion is the minimum TLS version: "1.2" (default) or "1.3".
+	MinVersion string `yaml:"min_version"`
+	// CipherSuites optionally restricts TLS 1.2 cipher suites, using Go's
+	// names (e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"). Insecure suites
+	// are rejected. TLS 1.3 suites are not configurable.
+	CipherSuites []string `yaml:"cipher_suites"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig builds a tls.Config from the settings, rejecting unknown
+// versions and cipher suites.
+func (c OutboundTLSConfig) TLSConfig() (*tls.Config, error) {
+	version := c.MinVersion
+	if version == "" {
+		version = "1.2"
+	}
+	minVersion, ok := tlsVersions[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TLS min_version %q (want \"1.2\" or \"1.3\")", c.MinVersion)
+	}
+	tlsCfg := &tls.Config{MinVersion: minVersion}
+
+	if len(c.CipherSuites) == 0 {
+		return tlsCfg, nil
+	}
+	if minVersion == tls.VersionTLS13 {
+		return nil, fmt.Errorf("cipher_suites cannot be set when min_version is 1.3")
+	}
+	ids := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		ids[suite.Name] = suite.ID
+	}
+	for _, name := range c.CipherSuites {
+		id, ok := ids[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		tlsCfg.CipherSuites = append(tlsCfg.CipherSuites, id)
+	}
+	return tlsCfg, nil
+}
+
+// BlacklistConfig configures best-effort notifications sent when a number
+// is added to the global blacklist, so downstream apps can revoke sessions.
+type BlacklistConfig struct {
+	// NotifyURLs receive a JSON POST with the phone hash and reason. Empty disables notifications.
+	NotifyURLs []string `yaml:"notify_urls"`
+	// NotifyTimeout bounds each notification request.
+	NotifyTimeout string `yaml:"notify_timeout"`
+	// Appeals lets blacklisted users contest their block.
+	Appeals AppealsConfig `yaml:"appeals"`
+}
+
+// AppealsConfig controls the appeal command for blacklisted users. An
+// appeal is stored for admin review and acknowledged; it never unblocks
+// the number by itself.
+type AppealsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Command starts an appeal, followed by the user's reason (default
+	// "/appeal").
+	Command string `yaml:"command"`
+	// Interval is how long a number must wait between appeals (default
+	// "24h").
+	Interval string `yaml:"interval"`
+	// AckMessage confirms a recorded appeal.
+	AckMessage string `yaml:"ack_message"`
+	// RateLimitedMessage answers an appeal sent within Interval of the
+	// previous one.
+	RateLimitedMessage string `yaml:"rate_limited_message"`
+}
+
+const (
+	StoreFailClosed = "closed"
+	StoreFailOpen   = "open"
+)
+
+// FailOpen reports whether store-dependent checks should allow requests
+// through when the store returns an error.
+func (c StoreConfig) FailOpen() bool {
+	return c.FailurePolicy == StoreFailOpen
 }
 
 type LoggingConfig struct {
@@ -41,6 +184,18 @@ type WABAConfig struct {
 	AccessToken       string `yaml:"access_token"`
 	PhoneNumberID     string `yaml:"phone_number_id"`
 	BusinessAccountID string `yaml:"business_account_id"`
+	// Templates declares the pre-approved message templates that may be
+	// sent proactively, keyed by template name.
+	Templates map[string]WABATemplateConfig `yaml:"templates"`
+}
+
+// WABATemplateConfig describes one approved template so sends can be
+// checked before they reach Meta.
+type WABATemplateConfig struct {
+	// Language is the template's language code (default "en_US").
+	Language string `yaml:"language"`
+	// BodyParameters is the number of {{n}} placeholders in the body.
+	BodyParameters int `yaml:"body_parameters"`
 }
 
 type CronConfig struct {
@@ -68,14 +223,66 @@ type VerificationConfig struct {
 	DatabaseURL string `yaml:"database_url"`
 	// DevOpsNumbers lists phone numbers that can bypass sender-token mismatch checks.
 	DevOpsNumbers []string `yaml:"devops_numbers"`
+	// DevOpsExpiryGrace lets devops numbers use tokens that expired up to
+	// this long ago (e.g. "10m"). Empty (default) keeps strict expiry.
+	DevOpsExpiryGrace string `yaml:"devops_expiry_grace"`
 	// Apps maps application names to their respective cryptographic public key configurations.
 	Apps map[string]AppVerifyConfig `yaml:"apps"`
+	// AllowHTTPCallbacks permits plain-http callback URLs. By default only
+	// https callbacks are posted.
+	AllowHTTPCallbacks bool `yaml:"allow_http_callbacks"`
+	// SingleActive limits each phone to one pending verification at a time.
+	SingleActive SingleActiveConfig `yaml:"single_active"`
 	// Messages configures custom templates for user-facing status responses sent on WhatsApp.
 	Messages VerificationMessages `yaml:"messages"`
 }
 
 type AppVerifyConfig struct {
 	PublicKeyPath string `yaml:"public_key_path"`
+	// CallbackTLS configures a dedicated HTTP client for this app's callbacks.
+	// When empty, the app shares the gateway's default callback client.
+	CallbackTLS CallbackTLSConfig `yaml:"callback_tls,omitempty"`
+	// SuccessStatuses lists the callback status codes treated as success.
+	// When empty, any 2xx status succeeds.
+	SuccessStatuses []int `yaml:"success_statuses,omitempty"`
+	// FollowRedirects lets the callback client follow 3xx responses. Disabled
+	// by default so a callback cannot redirect the gateway to internal hosts.
+	FollowRedirects bool `yaml:"follow_redirects,omitempty"`
+	// SingleActiveExempt lets this app verify while another app's
+	// verification is pending, without blocking others in turn.
+	SingleActiveExempt bool `yaml:"single_active_exempt,omitempty"`
+	// LogLevel "debug" logs every verification step for this app at INFO,
+	// so one integration can be traced without debug logging for all.
+	LogLevel string `yaml:"log_level,omitempty"`
+	// CallbackDomain is the domain this app's callbacks must go to. A
+	// validly signed token whose callback_url host is neither the domain
+	// nor one of its subdomains is rejected. Empty accepts any host.
+	CallbackDomain string `yaml:"callback_domain,omitempty"`
+}
+
+// SingleActiveConfig configures single-active-verification enforcement.
+type SingleActiveConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TTL releases a pending verification that never completed (default "10m").
+	TTL string `yaml:"ttl"`
+}
+
+// CallbackTLSConfig holds per-app transport settings for verification callbacks.
+type CallbackTLSConfig struct {
+	// CAFile is a PEM bundle used instead of the system roots to verify the app's server.
+	CAFile string `yaml:"ca_file"`
+	// CertFile and KeyFile enable mutual TLS with a client certificate.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ServerName overrides the TLS server name used for certificate verification.
+	ServerName string `yaml:"server_name"`
+	// ProxyURL routes this app's callbacks through an HTTP proxy.
+	ProxyURL string `yaml:"proxy_url"`
+}
+
+// IsZero reports whether no per-app transport settings are configured.
+func (c CallbackTLSConfig) IsZero() bool {
+	return c == CallbackTLSConfig{}
 }
 
 type VerificationMessages struct {
@@ -84,11 +291,32 @@ type VerificationMessages struct {
 	PhoneMismatch string `yaml:"phone_mismatch"`
 	Blacklisted   string `yaml:"blacklisted"`
 	Error         string `yaml:"error"`
+	// Pending is sent when another app's verification is still pending.
+	Pending string `yaml:"pending"`
+	// CallbackMismatch is sent when a token's callback host is not the
+	// app's callback_domain.
+	CallbackMismatch string `yaml:"callback_mismatch"`
 }
 
 type AuthConfig struct {
 	JWT   JWTConfig   `yaml:"jwt"`
 	OAuth OAuthConfig `yaml:"oauth"`
+	// Admin authenticates requests to the gateway's admin HTTP endpoints.
+	Admin AdminAuthConfig `yaml:"admin"`
+}
+
+// AdminAuthConfig accepts a static bearer token and/or HMAC-signed requests.
+type AdminAuthConfig struct {
+	// BearerToken is accepted as "Authorization: Bearer <token>". Prefer
+	// the ADMIN_TOKEN environment variable.
+	BearerToken string `yaml:"bearer_token"`
+	// HMACSecret enables signed requests: X-Whatsadk-Signature carries
+	// "sha256=" + hex HMAC-SHA256 of "<X-Whatsadk-Timestamp>.<body>".
+	// Prefer the ADMIN_HMAC_SECRET environment variable.
+	HMACSecret string `yaml:"hmac_secret"`
+	// MaxSkew is how far a signed request's timestamp may be from the
+	// gateway clock before it is rejected as a replay (default "5m").
+	MaxSkew string `yaml:"max_skew"`
 }
 
 type OAuthConfig struct {
@@ -99,6 +327,17 @@ type OAuthConfig struct {
 	Audience  string `yaml:"audience"`
 	TTL       string `yaml:"ttl"`
 	RateLimit int    `yaml:"rate_limit"`
+	// PersistBindings stores (nonce, phone, pubkey, issued_at) for every
+	// issued token so a later signature over the nonce can be verified.
+	PersistBindings bool `yaml:"persist_bindings"`
+	// BindingTTL is how long stored bindings remain valid (defaults to TTL).
+	BindingTTL string `yaml:"binding_ttl"`
+	// LogReplies logs every AUTH reply for debugging. The deep-link token is
+	// masked to a fingerprint; only the nonce is logged in full.
+	LogReplies bool `yaml:"log_replies"`
+	// CommandFormats lists the accepted AUTH command formats: "v1"
+	// (AUTH <key> <nonce>, the default) and "v2" (AUTH v2 <nonce> <key>).
+	CommandFormats []string `yaml:"command_formats"`
 }
 
 type JWTConfig struct {
@@ -106,20 +345,853 @@ type JWTConfig struct {
 	Issuer         string `yaml:"issuer,omitempty"`
 	Audience       string `yaml:"audience,omitempty"`
 	TTL            string `yaml:"ttl,omitempty"`
+	// KeyID overrides the kid header on issued tokens. Defaults to the
+	// signing key's RFC 7638 thumbprint.
+	KeyID string `yaml:"key_id,omitempty"`
+	// PublishedKeyPaths lists retired RSA public keys (PEM) still served in
+	// the JWKS during a key rotation.
+	PublishedKeyPaths []string `yaml:"published_key_paths,omitempty"`
+	// JWKSAddr, when set, serves the JWKS at /.well-known/jwks.json on this
+	// address (e.g. ":8081"). The WABA gateway serves it on its webhook port.
+	JWKSAddr string `yaml:"jwks_addr,omitempty"`
 }
 
 type WhatsAppConfig struct {
 	StoreDSN         string   `yaml:"store_dsn"`
 	LogLevel         string   `yaml:"log_level"`
 	WhitelistedUsers []string `yaml:"whitelisted_users"`
+	// AllowAllUsers bypasses the whitelist and country checks for open
+	// deployments. Blacklisted numbers are still blocked.
+	AllowAllUsers bool `yaml:"allow_all_users"`
+	// MentionNames lists bot names (e.g. "@bot", "Shopper") stripped from the
+	// start of direct messages before they are forwarded to the agent.
+	MentionNames []string `yaml:"mention_names"`
+	// PreserveWhitespace disables trimming of leading/trailing whitespace
+	// from inbound text before command detection and agent forwarding.
+	PreserveWhitespace bool `yaml:"preserve_whitespace"`
+	// InboundPipeline is the ordered list of transforms applied to inbound
+	// text before verification, AUTH and agent routing.
+	InboundPipeline InboundPipelineConfig `yaml:"inbound_pipeline"`
+	// AuthPolicy decides whether users outside the allowlist may still use the
+	// verification and AUTH flows: "any" (default) or "allowed".
+	AuthPolicy string `yaml:"auth_policy"`
+	// AuthRejectedMessage is sent when AuthPolicy is "allowed" and a
+	// non-allowed user attempts verification or AUTH.
+	AuthRejectedMessage string `yaml:"auth_rejected_message"`
+	// AuthPrecedence picks the handler for an AUTH command that also
+	// carries a verification token: "verification" (default) or "auth".
+	// Only one of them ever runs.
+	AuthPrecedence string `yaml:"auth_precedence"`
+	// MaxConnectionAge (e.g. "6h") proactively reconnects to WhatsApp once the
+	// connection is this old, after in-flight messages drain. Empty disables it.
+	MaxConnectionAge string `yaml:"max_connection_age"`
+	// MediaFallbackText is sent in place of an agent's media reply that
+	// WhatsApp rejected (not on connection errors) and that had no caption
+	// to fall back to. "{type}" is replaced with image, audio, video or
+	// document. Empty disables it.
+	MediaFallbackText string `yaml:"media_fallback_text"`
+	// MediaErrorReply is sent when a user's image, audio, video or document
+	// could not be downloaded or converted for the agent and the message
+	// had no text to forward instead. "{type}" is replaced as for
+	// MediaFallbackText. Empty disables it.
+	MediaErrorReply string `yaml:"media_error_reply"`
+	// Transcription sends voice notes to a speech-to-text backend and
+	// forwards the transcript to the agent instead of the audio.
+	Transcription TranscriptionConfig `yaml:"transcription"`
+	// VoiceReplies answers voice notes with synthesized voice notes.
+	VoiceReplies VoiceRepliesConfig `yaml:"voice_replies"`
+	// Video decides how users' videos are forwarded to the agent.
+	Video VideoConfig `yaml:"video"`
+	// Stickers decides whether stickers are ignored, answered with a
+	// canned reply or forwarded to the agent.
+	Stickers StickersConfig `yaml:"stickers"`
+	// Reactions records users' emoji reactions and can forward reactions
+	// to the agent's replies as feedback.
+	Reactions ReactionsConfig `yaml:"reactions"`
+	// Edits decides whether edited messages reach the agent.
+	Edits EditsConfig `yaml:"edits"`
+	// ViewOnce can refuse view-once photos and videos for compliance.
+	ViewOnce ViewOnceConfig `yaml:"view_once"`
+	// AckReactions reacts to users' messages with an emoji per event, as
+	// a lighter signal than a text reply.
+	AckReactions AckReactionsConfig `yaml:"ack_reactions"`
+	// QuoteReplies sends the first reply to each message as a quote of it,
+	// so answers stay threaded in busy chats.
+	QuoteReplies bool `yaml:"quote_replies"`
+	// ReadReceipts is when incoming messages are marked as read:
+	// ReadReceiptsNever (default), ReadReceiptsImmediate or
+	// ReadReceiptsAfterReply.
+	ReadReceipts string `yaml:"read_receipts"`
+	// LinkPreviews decides whether links in outgoing text get a preview.
+	LinkPreviews LinkPreviewsConfig `yaml:"link_previews"`
+	// TypingIndicator shows "typing…" in the chat while the agent works.
+	TypingIndicator TypingIndicatorConfig `yaml:"typing_indicator"`
+	// ImageLinks sends the images behind markdown image links in agent
+	// replies as WhatsApp images instead of raw URLs.
+	ImageLinks ImageLinksConfig `yaml:"image_links"`
+	// UndecryptableReply is sent when a message from a user cannot be
+	// decrypted, asking them to resend. Empty disables the reply.
+	UndecryptableReply string `yaml:"undecryptable_reply"`
+	// UndecryptableReplyInterval limits UndecryptableReply to once per user
+	// per interval (default "1h").
+	UndecryptableReplyInterval string `yaml:"undecryptable_reply_interval"`
+	// OutboundPipeline is the ordered list of transforms applied to agent
+	// text replies before they are sent.
+	OutboundPipeline OutboundPipelineConfig `yaml:"outbound_pipeline"`
+	// ReplyPaging sends long agent replies one page at a time.
+	ReplyPaging ReplyPagingConfig `yaml:"reply_paging"`
+	// Flood throttles users repeating the same text as distinct messages.
+	Flood FloodConfig `yaml:"flood"`
+	// RevokeMode controls handling of "delete for everyone" messages: "log"
+	// (default) or "audit" to also record them in filesys.
+	RevokeMode string `yaml:"revoke_mode"`
+	// BusinessAccounts decides how messages from WhatsApp Business senders
+	// (those with a verified business name) are routed.
+	BusinessAccounts BusinessAccountsConfig `yaml:"business_accounts"`
+	// Newsletters decides what happens to WhatsApp Channel (newsletter)
+	// posts: "ignore" (default) drops them, "store" records them under
+	// newsletters/<channel>/<id> and "agent" sends them to the agent in
+	// the channel's own session. The agent's reply is never sent.
+	Newsletters string `yaml:"newsletters"`
+	// CommunityAnnouncements decides what happens to posts in the
+	// announcement group of a community, once that group is allowed in
+	// Groups. It takes the same modes as Newsletters; stored posts go to
+	// announcements/<group>/<id>. Only community admins can post there, so
+	// the agent is never asked to reply.
+	CommunityAnnouncements string `yaml:"community_announcements"`
+	// Groups lets the agent take part in selected group chats, which are
+	// otherwise ignored.
+	Groups GroupsConfig `yaml:"groups"`
+	// AdminCommands lets verification.devops_numbers manage the gateway
+	// with slash commands sent over WhatsApp.
+	AdminCommands AdminCommandsConfig `yaml:"admin_commands"`
+	// Broadcasts paces operator broadcasts started through MCP.
+	Broadcasts BroadcastsConfig `yaml:"broadcasts"`
+	// SendQueue bounds outbound text messages queued behind a slow
+	// WhatsApp connection.
+	SendQueue SendQueueConfig `yaml:"send_queue"`
+	// InteractiveTokens also looks for verification tokens in the selected
+	// id or text of button, list and template replies.
+	InteractiveTokens bool `yaml:"interactive_tokens"`
+	// SelfAuth runs verification and AUTH for messages sent from the bot's
+	// own account (self-testing, linked devices). Replies go to the bot's
+	// own chat and such messages never reach the agent.
+	SelfAuth bool `yaml:"self_auth"`
+	// ErrorCooldown pauses processing for a user after an agent error reply.
+	ErrorCooldown ErrorCooldownConfig `yaml:"error_cooldown"`
+	// LinkFilter blocks inbound messages linking to blocklisted domains.
+	LinkFilter LinkFilterConfig `yaml:"link_filter"`
+	// Forwarded decides what happens to messages the user forwarded from
+	// another chat.
+	Forwarded ForwardedConfig `yaml:"forwarded"`
+	// ReplyBudget caps the length of the agent's text replies, separately
+	// from the chunking needed to fit WhatsApp's per-message limit.
+	ReplyBudget ReplyBudgetConfig `yaml:"reply_budget"`
+	// AgentRateLimit caps agent calls per user.
+	AgentRateLimit AgentRateLimitConfig `yaml:"agent_rate_limit"`
+	// OnboardingNudge is sent once to users admitted only by the country
+	// code rule rather than the whitelist.
+	OnboardingNudge OnboardingNudgeConfig `yaml:"onboarding_nudge"`
+	// Forms lets the agent collect structured input over several turns.
+	Forms FormsConfig `yaml:"forms"`
+	// Usage counts messages and agent calls per user and day in the
+	// gateway store.
+	Usage UsageConfig `yaml:"usage"`
+	// PersistState names the in-memory state saved to the gateway store on
+	// graceful shutdown and restored on startup: PersistAgentRateLimit,
+	// PersistFlood and/or PersistErrorCooldown.
+	PersistState []string `yaml:"persist_state"`
+}
+
+// UsageConfig batches per-user usage counts in memory and writes them to
+// the store periodically, when BatchSize events are buffered, and on
+// shutdown.
+type UsageConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// BatchSize is how many events trigger an early flush (default 100).
+	BatchSize int `yaml:"batch_size"`
+	// FlushInterval is how often buffered counts are written (default "30s").
+	FlushInterval string `yaml:"flush_interval"`
+}
+
+// FormsConfig configures gateway-side collection of agent-declared forms.
+// Form state is kept in the gateway store.
+type FormsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TTL abandons a form left unanswered this long (default "30m").
+	TTL string `yaml:"ttl"`
+	// CancelCommand abandons the open form (case-insensitive, default
+	// "cancel").
+	CancelCommand string `yaml:"cancel_command"`
+	// CancelledMessage confirms a cancelled form.
+	CancelledMessage string `yaml:"cancelled_message"`
+}
+
+// OnboardingNudgeConfig configures the one-time nudge for country-code-only
+// users. It needs the gateway store to remember who was nudged.
+type OnboardingNudgeConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Message string `yaml:"message"`
+}
+
+// ErrorCooldownConfig configures the per-user cooldown after agent errors.
+type ErrorCooldownConfig struct {
+	// Window is how long a user's messages are not sent to the agent after
+	// an error reply (e.g. "30s"). Empty disables the cooldown.
+	Window string `yaml:"window"`
+	// Message is sent once per cooldown to a user who keeps writing.
+	Message string `yaml:"message"`
+}
+
+// ForwardedConfig is the policy for forwarded messages, which WhatsApp
+// flags on the message itself.
+type ForwardedConfig struct {
+	// Policy is ForwardedProcess (default), ForwardedPrompt or ForwardedIgnore.
+	Policy string `yaml:"policy"`
+	// Message is the reply sent instead of calling the agent under
+	// ForwardedPrompt.
+	Message string `yaml:"message"`
+}
+
+// ViewOnceConfig handles view-once media, which is otherwise processed and
+// stored like any other attachment.
+type ViewOnceConfig struct {
+	// Refuse drops view-once messages before anything is downloaded,
+	// stored or sent to the agent.
+	Refuse bool `yaml:"refuse"`
+	// Message is sent in reply to a refused message. Empty sends nothing.
+	Message string `yaml:"message"`
+}
+
+// AckReactionsConfig sets the emoji the gateway reacts to a user's message
+// with for each event. Empty disables the reaction for that event. A later
+// reaction replaces an earlier one, as WhatsApp keeps one per sender.
+type AckReactionsConfig struct {
+	// Received is shown while the agent works on the message.
+	Received string `yaml:"received"`
+	// Replied replaces Received once the agent's reply is sent. When empty,
+	// Received is withdrawn instead.
+	Replied string `yaml:"replied"`
+	// Failed replaces Received when the agent call fails.
+	Failed string `yaml:"failed"`
+	// Verified marks a verification token that was accepted.
+	Verified string `yaml:"verified"`
+	// VerificationFailed marks a verification token that was rejected.
+	VerificationFailed string `yaml:"verification_failed"`
+}
+
+// LinkPreviewsConfig controls link previews in outgoing text. WhatsApp
+// only shows previews the sender attaches, so without LinkPreviewGenerate
+// links are sent bare.
+type LinkPreviewsConfig struct {
+	// Mode is LinkPreviewNone (default) or LinkPreviewGenerate.
+	Mode string `yaml:"mode"`
+	// AllowedHosts, when set, are the only hosts previews are built for.
+	AllowedHosts []string `yaml:"allowed_hosts"`
+	// Timeout bounds each page and image download (default "5s").
+	Timeout string `yaml:"timeout"`
+}
+
+// GroupsConfig opts group chats in. Each allowed group shares one agent
+// session; per-user checks such as the blacklist and rate limits still
+// apply to each sender.
+type GroupsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Allowed lists the group JIDs the agent answers in, e.g.
+	// "120363012345678901@g.us". More groups can be allowed at runtime in
+	// the gateway store.
+	Allowed []string `yaml:"allowed"`
+	// MentionOnly handles only messages that @-mention the bot or reply
+	// to one of its messages.
+	MentionOnly bool `yaml:"mention_only"`
+}
+
+// AdminCommandsConfig enables in-chat admin commands ("/block", "/unblock",
+// "/blacklist list", "/stats") for devops numbers. They need the gateway
+// store.
+type AdminCommandsConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// BroadcastsConfig paces templated messages sent to many recipients.
+type BroadcastsConfig struct {
+	// Interval is the minimum time between two broadcast messages
+	// (default "2s").
+	Interval string `yaml:"interval"`
+}
+
+// TypingIndicatorConfig controls the "typing…" chat presence sent while
+// waiting for the agent.
+type TypingIndicatorConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Refresh is how often the indicator is re-sent during long agent
+	// calls, since WhatsApp drops it after about 25 seconds (default "10s").
+	Refresh string `yaml:"refresh"`
+}
+
+// ImageLinksConfig controls how markdown image links (![alt](https://...))
+// in agent replies are fetched and sent as images, with alt as the caption.
+// Links that cannot be fetched stay in the text.
+type ImageLinksConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// AllowedHosts, when set, are the only hosts images are fetched from.
+	AllowedHosts []string `yaml:"allowed_hosts"`
+	// MaxBytes caps the size of each image (default 5MB).
+	MaxBytes int64 `yaml:"max_bytes"`
+	// Timeout bounds each download (default "10s").
+	Timeout string `yaml:"timeout"`
+}
+
+// EditsConfig handles messages a user edits after sending. Only the new
+// text or caption is used; edited media is not processed again.
+type EditsConfig struct {
+	// Mode is EditModeIgnore (default), EditModeRerun or EditModeAppend.
+	Mode string `yaml:"mode"`
+	// Prefix introduces the corrected text under EditModeAppend (default
+	// "The user corrected their previous message to:").
+	Prefix string `yaml:"prefix"`
+}
+
+// ReactionsConfig handles emoji reactions, which are never treated as
+// messages.
+type ReactionsConfig struct {
+	// Record stores each user's latest reaction per message at
+	// reactions/<phone>/<message_id>.
+	Record bool `yaml:"record"`
+	// Forward sends reactions to the gateway's replies to the agent.
+	Forward bool `yaml:"forward"`
+	// ForwardEmojis limits forwarding to these emojis. Empty forwards all.
+	ForwardEmojis []string `yaml:"forward_emojis"`
+	// ForwardText is the message the agent receives; "{emoji}" is replaced
+	// by the reaction (default "The user reacted {emoji} to your reply.").
+	ForwardText string `yaml:"forward_text"`
+}
+
+// StickersConfig decides what happens to stickers users send. They are
+// always stored.
+type StickersConfig struct {
+	// Mode is StickerModeIgnore (default), StickerModeReply or
+	// StickerModeForward.
+	Mode string `yaml:"mode"`
+	// Reply is sent under StickerModeReply.
+	Reply string `yaml:"reply"`
+}
+
+// VideoConfig decides how a user's video reaches the agent. Its caption is
+// always forwarded as text.
+type VideoConfig struct {
+	// Mode is VideoModeFrames (default), VideoModeThumbnail, VideoModeFull
+	// or VideoModeNote.
+	Mode string `yaml:"mode"`
+	// Note tells the agent a video was attached under VideoModeThumbnail
+	// and VideoModeNote (default "[The user sent a video.]").
+	Note string `yaml:"note"`
+}
+
+// TranscriptionConfig selects the speech-to-text backend for voice notes.
+type TranscriptionConfig struct {
+	// Provider is TranscriptionWhisper or TranscriptionGoogle. Empty
+	// disables transcription and forwards the audio itself.
+	Provider string `yaml:"provider"`
+	// Endpoint overrides the provider's API URL, e.g. for a self-hosted
+	// Whisper server.
+	Endpoint string `yaml:"endpoint"`
+	APIKey   string `yaml:"api_key"`
+	// Model is the Whisper model (default "whisper-1").
+	Model string `yaml:"model"`
+	// Language is a BCP-47 hint such as "hi-IN". Whisper detects the
+	// language when empty; Google defaults to "en-US".
+	Language string `yaml:"language"`
+	// Timeout bounds each transcription request (default "30s").
+	Timeout string `yaml:"timeout"`
+	// Prefix introduces the transcript sent to the agent (default
+	// "Voice note transcript:").
+	Prefix string `yaml:"prefix"`
+	// KeepAudio forwards the audio alongside the transcript.
+	KeepAudio bool `yaml:"keep_audio"`
 }
 
+// VoiceRepliesConfig selects the text-to-speech backend used to answer
+// voice notes with voice notes.
+type VoiceRepliesConfig struct {
+	// Provider is TTSOpenAI or TTSGoogle. Empty disables voice replies.
+	Provider string `yaml:"provider"`
+	// Endpoint overrides the provider's API URL.
+	Endpoint string `yaml:"endpoint"`
+	APIKey   string `yaml:"api_key"`
+	// Model is the OpenAI speech model (default "tts-1").
+	Model string `yaml:"model"`
+	// Voice is the provider's voice name (OpenAI default "alloy"; Google
+	// picks one for Language when empty).
+	Voice string `yaml:"voice"`
+	// Language is the BCP-47 language for Google (default "en-US").
+	Language string `yaml:"language"`
+	// Timeout bounds each synthesis request (default "30s").
+	Timeout string `yaml:"timeout"`
+	// MaxChars is the longest reply spoken; longer replies are sent as
+	// text only (default 1000).
+	MaxChars int `yaml:"max_chars"`
+	// KeepText also sends the reply as text after the voice note.
+	KeepText bool `yaml:"keep_text"`
+}
+
+// ReplyBudgetConfig decides what happens to an agent reply longer than
+// MaxChars characters.
+type ReplyBudgetConfig struct {
+	// MaxChars is the budget in characters. Zero disables it.
+	MaxChars int `yaml:"max_chars"`
+	// Policy is ReplyBudgetTruncate (default), ReplyBudgetSummarize or
+	// ReplyBudgetDocument.
+	Policy string `yaml:"policy"`
+	// Notice is appended to truncated replies.
+	Notice string `yaml:"notice"`
+	// SummarizePrompt asks the agent to shorten the reply, which follows
+	// it; "{max}" is replaced by MaxChars.
+	SummarizePrompt string `yaml:"summarize_prompt"`
+	// DocumentCaption captions the text file sent under ReplyBudgetDocument.
+	DocumentCaption string `yaml:"document_caption"`
+}
+
+// LinkFilterConfig blocks messages containing links to blocklisted
+// domains (and their subdomains) or text matching blocklisted patterns.
+// Blocked messages are stored but never handled further.
+type LinkFilterConfig struct {
+	BlockedDomains []string `yaml:"blocked_domains"`
+	// Patterns are regular expressions matched against the message text.
+	Patterns []string `yaml:"patterns"`
+	// Message is sent in reply to a blocked message. Empty sends nothing.
+	Message string `yaml:"message"`
+	// AutoBlacklist blacklists senders of repeated blocked messages.
+	AutoBlacklist AutoBlacklistConfig `yaml:"auto_blacklist"`
+}
+
+// AutoBlacklistConfig blacklists a sender after Threshold blocked messages
+// within Window.
+type AutoBlacklistConfig struct {
+	// Threshold of 0 (default) disables auto-blacklisting.
+	Threshold int `yaml:"threshold"`
+	// Window is the sliding window strikes are counted over (default "24h").
+	Window string `yaml:"window"`
+	// Reason is recorded on the blacklist entry (default "spam links").
+	Reason string `yaml:"reason"`
+}
+
+// Enabled reports whether any domain or pattern is configured.
+func (c LinkFilterConfig) Enabled() bool {
+	return len(c.BlockedDomains) > 0 || len(c.Patterns) > 0
+}
+
+// AgentRateLimitConfig limits how often one user's messages are sent to
+// the agent, independent of outbound send pacing.
+type AgentRateLimitConfig struct {
+	// MaxCalls is the most agent calls per user within Window. 0 disables
+	// the limit.
+	MaxCalls int `yaml:"max_calls"`
+	// Window is the sliding window calls are counted over (default "1m").
+	Window string `yaml:"window"`
+	// Message is sent once when a user goes over the limit.
+	Message string `yaml:"message"`
+	// ExemptWhitelisted and ExemptDevOps lift the limit for
+	// whatsapp.whitelisted_users and verification.devops_numbers.
+	ExemptWhitelisted bool `yaml:"exempt_whitelisted"`
+	ExemptDevOps      bool `yaml:"exempt_devops"`
+}
+
+// BusinessAccountsConfig routes messages from business senders.
+type BusinessAccountsConfig struct {
+	// Mode is "default" (same agent as personal accounts), "ignore" (store
+	// the message but do not call the agent) or "agent" (use AppName).
+	Mode string `yaml:"mode"`
+	// AppName is the ADK app used for business senders when Mode is "agent".
+	AppName string `yaml:"app_name"`
+}
+
+const (
+	RevokeModeLog   = "log"
+	RevokeModeAudit = "audit"
+)
+
+const (
+	NewsletterModeIgnore = "ignore"
+	NewsletterModeStore  = "store"
+	NewsletterModeAgent  = "agent"
+)
+
+const (
+	BusinessModeDefault = "default"
+	BusinessModeIgnore  = "ignore"
+	BusinessModeAgent   = "agent"
+)
+
+// InboundPipelineConfig configures the inbound transform pipeline.
+type InboundPipelineConfig struct {
+	// Steps are applied in order. Built-ins: "trim", "strip_mention",
+	// "strip_command_prefix", "max_length". Defaults to trim (unless
+	// PreserveWhitespace is set) followed by strip_mention.
+	Steps []string `yaml:"steps"`
+	// CommandPrefixes are removed by "strip_command_prefix" (e.g. "/ask").
+	CommandPrefixes []string `yaml:"command_prefixes"`
+	// MaxLength is the rune limit enforced by "max_length".
+	MaxLength int `yaml:"max_length"`
+}
+
+// validateInbound rejects settings that the inbound pipeline would
+// silently ignore: mention names without a strip_mention step, and
+// preserve_whitespace alongside an explicit trim step.
+func (c WhatsAppConfig) validateInbound() error {
+	has := func(step string) bool {
+		for _, s := range c.InboundPipeline.Steps {
+			if s == step {
+				return true
+			}
+		}
+		return false
+	}
+	if len(c.MentionNames) > 0 && !has("strip_mention") {
+		return fmt.Errorf("mention_names is set but inbound_pipeline.steps has no \"strip_mention\" step")
+	}
+	if c.PreserveWhitespace && has("trim") {
+		return fmt.Errorf("preserve_whitespace is set but inbound_pipeline.steps includes \"trim\"")
+	}
+	return nil
+}
+
+// OutboundPipelineConfig configures the outbound transform pipeline.
+type OutboundPipelineConfig struct {
+	// Steps are applied in order. Built-ins: "strip_markup",
+	// "strip_boilerplate", "markdown", "branding", "sanitize", "split",
+	// "chunk". Defaults to sanitize, branding, split, chunk; chunk should
+	// stay last so branding counts toward chunk size.
+	Steps []string `yaml:"steps"`
+	// Prefix and Suffix are added by "branding" to the first and last message.
+	Prefix string `yaml:"prefix"`
+	Suffix string `yaml:"suffix"`
+	// Delimiter splits a reply into separate messages in "split".
+	Delimiter string `yaml:"delimiter"`
+	// ChunkSize is the maximum message length in runes for "chunk" (0 disables).
+	ChunkSize int `yaml:"chunk_size"`
+	// StripMarkup selects what the "strip_markup" step removes.
+	StripMarkup StripMarkupConfig `yaml:"strip_markup"`
+	// StripBoilerplate selects what the "strip_boilerplate" step removes.
+	StripBoilerplate StripBoilerplateConfig `yaml:"strip_boilerplate"`
+}
+
+// StripBoilerplateConfig lists fixed disclaimers an agent repeats at the
+// start or end of every reply.
+type StripBoilerplateConfig struct {
+	// Texts are matched exactly (ignoring surrounding whitespace).
+	Texts []string `yaml:"texts"`
+	// Patterns are regular expressions matched at the start or end.
+	Patterns []string `yaml:"patterns"`
+}
+
+// StripMarkupConfig lists agent framework markup removed from replies.
+type StripMarkupConfig struct {
+	// Presets are built-in pattern sets: "bracket_tools", "xml_tools",
+	// "citations".
+	Presets []string `yaml:"presets"`
+	// Patterns are extra regular expressions; every match is removed.
+	Patterns []string `yaml:"patterns"`
+}
+
+// ReplyPagingConfig truncates long agent replies to MaxLength characters
+// and serves the remainder when the user sends Command.
+type ReplyPagingConfig struct {
+	// MaxLength is the page size in characters. 0 disables paging.
+	MaxLength int `yaml:"max_length"`
+	// Command is the (case-insensitive) message that requests the next page.
+	Command string `yaml:"command"`
+	// Prompt is appended to every truncated page.
+	Prompt string `yaml:"prompt"`
+	// TTL is how long the remainder is kept for the user.
+	TTL string `yaml:"ttl"`
+}
+
+// FloodConfig suppresses replies once a user sends the same normalized
+// text more than MaxRepeats times within Window.
+type FloodConfig struct {
+	// MaxRepeats is how many identical messages are answered per window.
+	// 0 disables flood protection.
+	MaxRepeats int `yaml:"max_repeats"`
+	// Window is the period repeats are counted over (default "1m").
+	Window string `yaml:"window"`
+	// Message, when set, is sent once when a flood starts.
+	Message string `yaml:"message"`
+}
+
+// SendQueueConfig configures the outbound send queue.
+type SendQueueConfig struct {
+	// MaxDepth is the most messages waiting to be sent. 0 disables the
+	// queue and messages are sent inline.
+	MaxDepth int `yaml:"max_depth"`
+	// Overflow is what happens when the queue is full: "block" (default)
+	// waits for room, "drop_oldest" or "drop_newest" discard a message.
+	Overflow string `yaml:"overflow"`
+	// WarnDepth logs a warning when the queue reaches it (default 80% of
+	// MaxDepth).
+	WarnDepth int `yaml:"warn_depth"`
+	// Persist keeps queued messages in the gateway store and sends them
+	// after a restart.
+	Persist bool `yaml:"persist"`
+	// ResumeMaxAge drops persisted messages queued longer ago than this
+	// instead of sending them after a restart (default "10m").
+	ResumeMaxAge string `yaml:"resume_max_age"`
+}
+
+const (
+	// AuthPolicyAny lets every non-blacklisted user verify and authenticate,
+	// regardless of the whitelist and country checks.
+	AuthPolicyAny = "any"
+	// AuthPolicyAllowed applies the whitelist and country checks before the
+	// verification and AUTH flows.
+	AuthPolicyAllowed = "allowed"
+)
+
+const (
+	// AuthPrecedenceVerification verifies the embedded token and skips AUTH.
+	AuthPrecedenceVerification = "verification"
+	// AuthPrecedenceAuth runs the AUTH command and ignores the token.
+	AuthPrecedenceAuth = "auth"
+)
+
+const (
+	// ForwardedProcess sends forwarded messages to the agent as usual.
+	ForwardedProcess = "process"
+	// ForwardedPrompt replies with forwarded.message instead of calling
+	// the agent.
+	ForwardedPrompt = "prompt"
+	// ForwardedIgnore keeps forwarded messages away from the agent without
+	// replying.
+	ForwardedIgnore = "ignore"
+)
+
+const (
+	// LinkPreviewNone sends links without a preview.
+	LinkPreviewNone = "none"
+	// LinkPreviewGenerate fetches the first link in a message and attaches
+	// its title, description and thumbnail.
+	LinkPreviewGenerate = "generate"
+)
+
+const (
+	// ReadReceiptsNever leaves incoming messages unread.
+	ReadReceiptsNever = "never"
+	// ReadReceiptsImmediate marks a message read as soon as it is accepted
+	// for handling.
+	ReadReceiptsImmediate = "immediate"
+	// ReadReceiptsAfterReply marks a message read once it has been handled
+	// and any reply sent.
+	ReadReceiptsAfterReply = "after_reply"
+)
+
+const (
+	// EditModeIgnore logs edits without calling the agent.
+	EditModeIgnore = "ignore"
+	// EditModeRerun sends the corrected text to the agent as a new turn.
+	EditModeRerun = "rerun"
+	// EditModeAppend sends the corrected text after edits.prefix, so the
+	// agent knows it replaces an earlier message.
+	EditModeAppend = "append"
+)
+
+const (
+	// StickerModeIgnore stores stickers without replying.
+	StickerModeIgnore = "ignore"
+	// StickerModeReply answers stickers with stickers.reply.
+	StickerModeReply = "reply"
+	// StickerModeForward sends the sticker to the agent as an image.
+	StickerModeForward = "forward"
+)
+
+const (
+	// VideoModeFrames samples up to one frame per second as JPEG images.
+	VideoModeFrames = "frames"
+	// VideoModeThumbnail sends the note and WhatsApp's preview thumbnail.
+	VideoModeThumbnail = "thumbnail"
+	// VideoModeFull sends the video file itself.
+	VideoModeFull = "full"
+	// VideoModeNote sends only the note, without any media.
+	VideoModeNote = "note"
+)
+
+// Text-to-speech backends for whatsapp.voice_replies.
+const (
+	// TTSOpenAI uses an OpenAI-compatible speech API.
+	TTSOpenAI = "openai"
+	// TTSGoogle uses Google Cloud Text-to-Speech.
+	TTSGoogle = "google"
+)
+
+// Speech-to-text backends for whatsapp.transcription.
+const (
+	// TranscriptionWhisper uses an OpenAI-compatible transcription API.
+	TranscriptionWhisper = "whisper"
+	// TranscriptionGoogle uses Google Cloud Speech-to-Text.
+	TranscriptionGoogle = "google"
+)
+
+// Names of in-memory state that whatsapp.persist_state can keep across
+// restarts.
+const (
+	PersistAgentRateLimit = "agent_rate_limit"
+	PersistFlood          = "flood"
+	PersistErrorCooldown  = "error_cooldown"
+)
+
+const (
+	// ReplyBudgetTruncate cuts over-budget replies and appends a notice.
+	ReplyBudgetTruncate = "truncate"
+	// ReplyBudgetSummarize asks the agent to shorten over-budget replies,
+	// truncating whatever is still too long.
+	ReplyBudgetSummarize = "summarize"
+	// ReplyBudgetDocument sends over-budget replies as a text document.
+	ReplyBudgetDocument = "document"
+)
+
 type ADKConfig struct {
 	Enabled   bool   `yaml:"enabled"`
 	Endpoint  string `yaml:"endpoint"`
 	AppName   string `yaml:"app_name"`
 	Streaming bool   `yaml:"streaming"`
 	APIKey    string `yaml:"api_key"`
+	// ProfileState maps stored user profile attributes to ADK session state
+	// keys, forwarded as a state delta with every message.
+	ProfileState map[string]string `yaml:"profile_state"`
+	// DisableSessionCoalescing lets concurrent first messages from one user
+	// each send their own session-create request.
+	DisableSessionCoalescing bool `yaml:"disable_session_coalescing"`
+	// DisableSessionRecreate turns off recreating a session ADK reports as
+	// not found (e.g. after an ADK restart) and retrying the run once.
+	DisableSessionRecreate bool `yaml:"disable_session_recreate"`
+	// NamespaceSessions uses "<app_name>:<user>" as the ADK session ID
+	// instead of the bare user ID, so apps sharing an ADK server and store
+	// never collide on session IDs.
+	NamespaceSessions bool `yaml:"namespace_sessions"`
+	// ResponseSchema is a JSON schema object sent as the run request's
+	// generationConfig.responseSchema, so agents that support constrained
+	// output reply in a predictable JSON shape. Empty sends none.
+	ResponseSchema string `yaml:"response_schema"`
+	// MessageMetadata adds details of each inbound message to the run
+	// request's state delta.
+	MessageMetadata MessageMetadataConfig `yaml:"message_metadata"`
+	// GroupMetadata creates group sessions with the group's subject and
+	// participants in their state.
+	GroupMetadata GroupMetadataConfig `yaml:"group_metadata"`
+	// SessionTags adds channel attribution to every run request's state delta.
+	SessionTags SessionTagsConfig `yaml:"session_tags"`
+	// ErrorSnippetLength caps how much of a non-JSON ADK response body is
+	// included in the error (default 256 bytes).
+	ErrorSnippetLength int `yaml:"error_snippet_length"`
+	// Summary keeps rolling per-user conversation summaries and seeds them
+	// into new sessions.
+	Summary SummaryConfig `yaml:"summary"`
+	// DeliveryConfirmation reports agent replies back to ADK once WhatsApp
+	// has accepted them.
+	DeliveryConfirmation DeliveryConfirmationConfig `yaml:"delivery_confirmation"`
+	// RateLimit controls how 429 Too Many Requests answers are handled.
+	RateLimit ADKRateLimitConfig `yaml:"rate_limit"`
+	// LastReply sends the agent's previous reply along with each new
+	// message, a lighter alternative to replaying history.
+	LastReply LastReplyConfig `yaml:"last_reply"`
+	// QuotedContext sends the text of the message a user replied to along
+	// with the reply.
+	QuotedContext QuotedContextConfig `yaml:"quoted_context"`
+}
+
+// QuotedContextConfig prepends the quoted message, when a user replies to
+// one, as an extra text part of the run request.
+type QuotedContextConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Prefix introduces the quoted text (default "The user is replying to:").
+	Prefix string `yaml:"prefix"`
+	// MaxChars cuts longer quotes (default 500).
+	MaxChars int `yaml:"max_chars"`
+}
+
+// LastReplyConfig prepends the agent's previous reply to the user (kept in
+// memory per user) as an extra text part of the next run request.
+type LastReplyConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Prefix introduces the quoted reply (default "Your previous reply:").
+	Prefix string `yaml:"prefix"`
+}
+
+// MessageMetadataConfig selects per-message metadata sent to the agent as
+// a map under StateKey in the run request's state delta.
+type MessageMetadataConfig struct {
+	// Fields lists what to include: "timestamp", "message_id",
+	// "push_name", "is_reply", "quoted_id" and "sender_phone". Empty
+	// disables metadata.
+	Fields []string `yaml:"fields"`
+	// StateKey is the state delta key (default "message_metadata").
+	StateKey string `yaml:"state_key"`
+	// HashPhone sends sender_phone as a hex SHA-256 digest instead of the
+	// number itself.
+	HashPhone bool `yaml:"hash_phone"`
+}
+
+// GroupMetadataConfig puts a group's subject, description and participants
+// under StateKey in the state its ADK session is created with.
+type GroupMetadataConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// StateKey is the session state key (default "group").
+	StateKey string `yaml:"state_key"`
+}
+
+// Message metadata fields.
+const (
+	MetadataTimestamp   = "timestamp"
+	MetadataMessageID   = "message_id"
+	MetadataPushName    = "push_name"
+	MetadataIsReply     = "is_reply"
+	MetadataQuotedID    = "quoted_id"
+	MetadataSenderPhone = "sender_phone"
+)
+
+// ADKRateLimitConfig bounds how long a message waits on ADK's Retry-After.
+type ADKRateLimitConfig struct {
+	// MaxRetries is how often a rate-limited turn is retried (default 2).
+	MaxRetries int `yaml:"max_retries"`
+	// MaxWait caps the total Retry-After wait for one message (default
+	// "30s"); a longer requested wait gets BusyMessage immediately.
+	MaxWait string `yaml:"max_wait"`
+	// BusyMessage is sent when ADK stays rate limited.
+	BusyMessage string `yaml:"busy_message"`
+}
+
+// DeliveryConfirmationConfig controls delivery confirmations. In "event"
+// mode (default) a JSON event is POSTed to Path on the ADK endpoint; in
+// "state" mode the user's session state gets StateKey set to the delivery.
+type DeliveryConfirmationConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Mode     string `yaml:"mode"`
+	Path     string `yaml:"path"`
+	StateKey string `yaml:"state_key"`
+	// Timeout bounds each confirmation request (default 5s).
+	Timeout string `yaml:"timeout"`
+}
+
+// SummaryConfig controls rolling conversation summaries. Every EveryTurns
+// agent turns the agent is asked to summarize the conversation with Prompt;
+// the last Keep summaries are stored under summaries/<phone> and seeded into
+// session state as StateKey when a session is created.
+type SummaryConfig struct {
+	// EveryTurns is the refresh interval in agent turns. 0 disables summaries.
+	EveryTurns int    `yaml:"every_turns"`
+	Keep       int    `yaml:"keep"`
+	Prompt     string `yaml:"prompt"`
+	StateKey   string `yaml:"state_key"`
+}
+
+// SessionTagsConfig controls the attribution metadata sent to ADK. When
+// enabled, channel, gateway_instance and (once logged in) bot_jid are set
+// alongside the static entries.
+type SessionTagsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// InstanceID identifies this gateway process; defaults to the hostname.
+	InstanceID string `yaml:"instance_id"`
+	// Static holds extra fixed tags, e.g. region or deployment name.
+	Static map[string]string `yaml:"static"`
 }
 
 type SurrealDBConfig struct {
@@ -181,9 +1253,231 @@ func Load() (*Config, error) {
 
 	cfg.applyDefaults()
 	cfg.applyEnvOverrides()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
+// validate rejects settings that would otherwise be silently ignored or,
+// for security policies, fail open on a typo.
+func (c *Config) validate() error {
+	if _, err := c.TLS.TLSConfig(); err != nil {
+		return fmt.Errorf("invalid tls config: %w", err)
+	}
+	switch c.WhatsApp.AuthPolicy {
+	case AuthPolicyAny, AuthPolicyAllowed:
+	default:
+		return fmt.Errorf("invalid whatsapp auth_policy %q (want %q or %q)", c.WhatsApp.AuthPolicy, AuthPolicyAny, AuthPolicyAllowed)
+	}
+	switch c.WhatsApp.AuthPrecedence {
+	case AuthPrecedenceVerification, AuthPrecedenceAuth:
+	default:
+		return fmt.Errorf("invalid whatsapp auth_precedence %q (want %q or %q)", c.WhatsApp.AuthPrecedence, AuthPrecedenceVerification, AuthPrecedenceAuth)
+	}
+	switch c.WhatsApp.Forwarded.Policy {
+	case ForwardedProcess, ForwardedPrompt, ForwardedIgnore:
+	default:
+		return fmt.Errorf("invalid whatsapp forwarded policy %q (want %q, %q or %q)", c.WhatsApp.Forwarded.Policy, ForwardedProcess, ForwardedPrompt, ForwardedIgnore)
+	}
+	if c.WhatsApp.ReplyBudget.MaxChars < 0 {
+		return fmt.Errorf("whatsapp reply_budget max_chars must not be negative")
+	}
+	if c.WhatsApp.AdminCommands.Enabled && len(c.Verification.DevOpsNumbers) == 0 {
+		return fmt.Errorf("whatsapp admin_commands enabled without any verification devops_numbers")
+	}
+	switch c.WhatsApp.LinkPreviews.Mode {
+	case LinkPreviewNone, LinkPreviewGenerate:
+	default:
+		return fmt.Errorf("invalid whatsapp link_previews mode %q (want %q or %q)", c.WhatsApp.LinkPreviews.Mode, LinkPreviewNone, LinkPreviewGenerate)
+	}
+	if d, err := time.ParseDuration(c.WhatsApp.LinkPreviews.Timeout); err != nil || d <= 0 {
+		return fmt.Errorf("invalid whatsapp link_previews timeout %q", c.WhatsApp.LinkPreviews.Timeout)
+	}
+	if h := c.Gateway.HeartbeatInterval; h != "" {
+		if d, err := time.ParseDuration(h); err != nil || d <= 0 {
+			return fmt.Errorf("invalid gateway heartbeat_interval %q", h)
+		}
+	}
+	if a := c.WhatsApp.MaxConnectionAge; a != "" {
+		if d, err := time.ParseDuration(a); err != nil || d <= 0 {
+			return fmt.Errorf("invalid whatsapp max_connection_age %q", a)
+		}
+	}
+	switch c.WhatsApp.ReadReceipts {
+	case ReadReceiptsNever, ReadReceiptsImmediate, ReadReceiptsAfterReply:
+	default:
+		return fmt.Errorf("invalid whatsapp read_receipts %q (want %q, %q or %q)", c.WhatsApp.ReadReceipts, ReadReceiptsNever, ReadReceiptsImmediate, ReadReceiptsAfterReply)
+	}
+	switch c.WhatsApp.Edits.Mode {
+	case EditModeIgnore, EditModeRerun, EditModeAppend:
+	default:
+		return fmt.Errorf("invalid whatsapp edits mode %q (want %q, %q or %q)", c.WhatsApp.Edits.Mode, EditModeIgnore, EditModeRerun, EditModeAppend)
+	}
+	switch c.WhatsApp.Stickers.Mode {
+	case StickerModeIgnore, StickerModeReply, StickerModeForward:
+	default:
+		return fmt.Errorf("invalid whatsapp stickers mode %q (want %q, %q or %q)", c.WhatsApp.Stickers.Mode, StickerModeIgnore, StickerModeReply, StickerModeForward)
+	}
+	switch c.WhatsApp.Video.Mode {
+	case VideoModeFrames, VideoModeThumbnail, VideoModeFull, VideoModeNote:
+	default:
+		return fmt.Errorf("invalid whatsapp video mode %q (want %q, %q, %q or %q)", c.WhatsApp.Video.Mode, VideoModeFrames, VideoModeThumbnail, VideoModeFull, VideoModeNote)
+	}
+	switch c.WhatsApp.Transcription.Provider {
+	case "", TranscriptionWhisper, TranscriptionGoogle:
+	default:
+		return fmt.Errorf("invalid whatsapp transcription provider %q (want %q or %q)", c.WhatsApp.Transcription.Provider, TranscriptionWhisper, TranscriptionGoogle)
+	}
+	switch c.WhatsApp.VoiceReplies.Provider {
+	case "", TTSOpenAI, TTSGoogle:
+	default:
+		return fmt.Errorf("invalid whatsapp voice_replies provider %q (want %q or %q)", c.WhatsApp.VoiceReplies.Provider, TTSOpenAI, TTSGoogle)
+	}
+	if v := c.WhatsApp.VoiceReplies; v.Provider != "" {
+		if d, err := time.ParseDuration(v.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid whatsapp voice_replies timeout %q", v.Timeout)
+		}
+	}
+	if t := c.WhatsApp.Transcription; t.Provider != "" {
+		if d, err := time.ParseDuration(t.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid whatsapp transcription timeout %q", t.Timeout)
+		}
+	}
+	if d, err := time.ParseDuration(c.WhatsApp.Broadcasts.Interval); err != nil || d <= 0 {
+		return fmt.Errorf("invalid whatsapp broadcasts interval %q", c.WhatsApp.Broadcasts.Interval)
+	}
+	if t := c.WhatsApp.TypingIndicator; t.Enabled {
+		if d, err := time.ParseDuration(t.Refresh); err != nil || d <= 0 {
+			return fmt.Errorf("invalid whatsapp typing_indicator refresh %q", t.Refresh)
+		}
+	}
+	if l := c.WhatsApp.ImageLinks; l.Enabled {
+		if d, err := time.ParseDuration(l.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid whatsapp image_links timeout %q", l.Timeout)
+		}
+		if l.MaxBytes <= 0 {
+			return fmt.Errorf("invalid whatsapp image_links max_bytes %d", l.MaxBytes)
+		}
+	}
+	for _, name := range c.WhatsApp.PersistState {
+		switch name {
+		case PersistAgentRateLimit, PersistFlood, PersistErrorCooldown:
+		default:
+			return fmt.Errorf("invalid whatsapp persist_state %q (want %q, %q or %q)", name, PersistAgentRateLimit, PersistFlood, PersistErrorCooldown)
+		}
+	}
+	switch c.WhatsApp.ReplyBudget.Policy {
+	case ReplyBudgetTruncate, ReplyBudgetSummarize, ReplyBudgetDocument:
+	default:
+		return fmt.Errorf("invalid whatsapp reply_budget policy %q (want %q, %q or %q)", c.WhatsApp.ReplyBudget.Policy, ReplyBudgetTruncate, ReplyBudgetSummarize, ReplyBudgetDocument)
+	}
+	switch c.WhatsApp.Newsletters {
+	case NewsletterModeIgnore, NewsletterModeStore, NewsletterModeAgent:
+	default:
+		return fmt.Errorf("invalid whatsapp newsletters %q (want %q, %q or %q)", c.WhatsApp.Newsletters, NewsletterModeIgnore, NewsletterModeStore, NewsletterModeAgent)
+	}
+	switch c.WhatsApp.CommunityAnnouncements {
+	case NewsletterModeIgnore, NewsletterModeStore, NewsletterModeAgent:
+	default:
+		return fmt.Errorf("invalid whatsapp community_announcements %q (want %q, %q or %q)", c.WhatsApp.CommunityAnnouncements, NewsletterModeIgnore, NewsletterModeStore, NewsletterModeAgent)
+	}
+	switch c.Store.FailurePolicy {
+	case StoreFailClosed, StoreFailOpen:
+	default:
+		return fmt.Errorf("invalid store failure_policy %q (want %q or %q)", c.Store.FailurePolicy, StoreFailClosed, StoreFailOpen)
+	}
+	switch c.Store.SchemaUpgrade {
+	case "auto", "refuse":
+	default:
+		return fmt.Errorf("invalid store schema_upgrade %q (want auto or refuse)", c.Store.SchemaUpgrade)
+	}
+	switch c.WhatsApp.SendQueue.Overflow {
+	case "block", "drop_oldest", "drop_newest":
+	default:
+		return fmt.Errorf("invalid whatsapp send_queue overflow %q (want block, drop_oldest or drop_newest)", c.WhatsApp.SendQueue.Overflow)
+	}
+	if u := c.WhatsApp.Usage; u.Enabled {
+		if u.BatchSize < 0 {
+			return fmt.Errorf("invalid whatsapp usage batch_size %d", u.BatchSize)
+		}
+		if d, err := time.ParseDuration(u.FlushInterval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid whatsapp usage flush_interval %q", u.FlushInterval)
+		}
+	}
+	for name, t := range c.WABA.Templates {
+		if t.BodyParameters < 0 {
+			return fmt.Errorf("invalid waba template %q: body_parameters must not be negative", name)
+		}
+	}
+	if q := c.WhatsApp.SendQueue; q.Persist {
+		if d, err := time.ParseDuration(q.ResumeMaxAge); err != nil || d <= 0 {
+			return fmt.Errorf("invalid whatsapp send_queue resume_max_age %q", q.ResumeMaxAge)
+		}
+	}
+	switch c.ADK.DeliveryConfirmation.Mode {
+	case "event", "state":
+	default:
+		return fmt.Errorf("invalid adk delivery_confirmation mode %q (want event or state)", c.ADK.DeliveryConfirmation.Mode)
+	}
+	for name, app := range c.Verification.Apps {
+		switch strings.ToLower(app.LogLevel) {
+		case "", "debug":
+		default:
+			return fmt.Errorf("invalid verification app %q log_level %q (want \"debug\" or empty)", name, app.LogLevel)
+		}
+	}
+	for _, f := range c.ADK.MessageMetadata.Fields {
+		switch f {
+		case MetadataTimestamp, MetadataMessageID, MetadataPushName, MetadataIsReply, MetadataQuotedID, MetadataSenderPhone:
+		default:
+			return fmt.Errorf("invalid adk message_metadata field %q", f)
+		}
+	}
+	for _, p := range c.WhatsApp.LinkFilter.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid whatsapp link_filter pattern %q: %w", p, err)
+		}
+	}
+	if ab := c.WhatsApp.LinkFilter.AutoBlacklist; ab.Threshold > 0 {
+		if d, err := time.ParseDuration(ab.Window); err != nil || d <= 0 {
+			return fmt.Errorf("invalid whatsapp link_filter auto_blacklist window %q", ab.Window)
+		}
+	}
+	if rl := c.WhatsApp.AgentRateLimit; rl.MaxCalls > 0 {
+		if d, err := time.ParseDuration(rl.Window); err != nil || d <= 0 {
+			return fmt.Errorf("invalid whatsapp agent_rate_limit window %q", rl.Window)
+		}
+	}
+	if d, err := time.ParseDuration(c.Blacklist.Appeals.Interval); err != nil || d < 0 {
+		return fmt.Errorf("invalid blacklist appeals interval %q", c.Blacklist.Appeals.Interval)
+	}
+	if s := c.ADK.ResponseSchema; s != "" {
+		var schema map[string]any
+		if err := json.Unmarshal([]byte(s), &schema); err != nil || schema == nil {
+			return fmt.Errorf("invalid adk response_schema: must be a JSON object")
+		}
+	}
+	if g := c.Verification.DevOpsExpiryGrace; g != "" {
+		if d, err := time.ParseDuration(g); err != nil || d < 0 {
+			return fmt.Errorf("invalid verification devops_expiry_grace %q", g)
+		}
+	}
+	if _, err := time.ParseDuration(c.Auth.Admin.MaxSkew); err != nil {
+		return fmt.Errorf("invalid auth admin max_skew %q: %w", c.Auth.Admin.MaxSkew, err)
+	}
+	if admin := c.Auth.Admin; c.Gateway.AdminAddr != "" && admin.BearerToken == "" && admin.HMACSecret == "" {
+		return fmt.Errorf("gateway admin_addr is set without an auth admin bearer_token or hmac_secret")
+	}
+	if _, err := time.ParseDuration(c.ADK.RateLimit.MaxWait); err != nil {
+		return fmt.Errorf("invalid adk rate_limit max_wait %q: %w", c.ADK.RateLimit.MaxWait, err)
+	}
+	if err := c.WhatsApp.validateInbound(); err != nil {
+		return fmt.Errorf("invalid whatsapp config: %w", err)
+	}
+	return nil
+}
+
 func findConfigPath() string {
 	var configArg string
 	flag.StringVar(&configArg, "config", "", "path to config file")
@@ -237,6 +1531,271 @@ func (c *Config) applyDefaults() {
 	if c.WhatsApp.LogLevel == "" {
 		c.WhatsApp.LogLevel = "INFO"
 	}
+	if c.WhatsApp.AuthPolicy == "" {
+		c.WhatsApp.AuthPolicy = AuthPolicyAny
+	}
+	if c.WhatsApp.AuthPrecedence == "" {
+		c.WhatsApp.AuthPrecedence = AuthPrecedenceVerification
+	}
+	if c.WhatsApp.LinkPreviews.Mode == "" {
+		c.WhatsApp.LinkPreviews.Mode = LinkPreviewNone
+	}
+	if c.WhatsApp.LinkPreviews.Timeout == "" {
+		c.WhatsApp.LinkPreviews.Timeout = "5s"
+	}
+	if c.WhatsApp.ReadReceipts == "" {
+		c.WhatsApp.ReadReceipts = ReadReceiptsNever
+	}
+	if c.WhatsApp.Edits.Mode == "" {
+		c.WhatsApp.Edits.Mode = EditModeIgnore
+	}
+	if c.WhatsApp.Edits.Prefix == "" {
+		c.WhatsApp.Edits.Prefix = "The user corrected their previous message to:"
+	}
+	if c.WhatsApp.Reactions.ForwardText == "" {
+		c.WhatsApp.Reactions.ForwardText = "The user reacted {emoji} to your reply."
+	}
+	if c.WhatsApp.TypingIndicator.Refresh == "" {
+		c.WhatsApp.TypingIndicator.Refresh = "10s"
+	}
+	if c.WhatsApp.Broadcasts.Interval == "" {
+		c.WhatsApp.Broadcasts.Interval = "2s"
+	}
+	if c.WhatsApp.ImageLinks.MaxBytes == 0 {
+		c.WhatsApp.ImageLinks.MaxBytes = 5 << 20
+	}
+	if c.WhatsApp.ImageLinks.Timeout == "" {
+		c.WhatsApp.ImageLinks.Timeout = "10s"
+	}
+	if c.WhatsApp.Stickers.Mode == "" {
+		c.WhatsApp.Stickers.Mode = StickerModeIgnore
+	}
+	if c.WhatsApp.Stickers.Reply == "" {
+		c.WhatsApp.Stickers.Reply = "Nice sticker! How can I help you?"
+	}
+	if c.WhatsApp.Video.Mode == "" {
+		c.WhatsApp.Video.Mode = VideoModeFrames
+	}
+	if c.WhatsApp.Video.Note == "" {
+		c.WhatsApp.Video.Note = "[The user sent a video.]"
+	}
+	if t := &c.WhatsApp.Transcription; t.Provider != "" {
+		if t.Model == "" {
+			t.Model = "whisper-1"
+		}
+		if t.Language == "" && t.Provider == TranscriptionGoogle {
+			t.Language = "en-US"
+		}
+		if t.Timeout == "" {
+			t.Timeout = "30s"
+		}
+		if t.Prefix == "" {
+			t.Prefix = "Voice note transcript:"
+		}
+	}
+	if v := &c.WhatsApp.VoiceReplies; v.Provider != "" {
+		if v.Model == "" {
+			v.Model = "tts-1"
+		}
+		if v.Voice == "" && v.Provider == TTSOpenAI {
+			v.Voice = "alloy"
+		}
+		if v.Language == "" && v.Provider == TTSGoogle {
+			v.Language = "en-US"
+		}
+		if v.Timeout == "" {
+			v.Timeout = "30s"
+		}
+		if v.MaxChars == 0 {
+			v.MaxChars = 1000
+		}
+	}
+	if c.WhatsApp.ReplyBudget.Policy == "" {
+		c.WhatsApp.ReplyBudget.Policy = ReplyBudgetTruncate
+	}
+	if c.WhatsApp.ReplyBudget.Notice == "" {
+		c.WhatsApp.ReplyBudget.Notice = "(Reply shortened.)"
+	}
+	if c.WhatsApp.ReplyBudget.SummarizePrompt == "" {
+		c.WhatsApp.ReplyBudget.SummarizePrompt = "Rewrite the following reply in at most {max} characters, keeping the essential information. Answer with the rewritten reply only."
+	}
+	if c.WhatsApp.ReplyBudget.DocumentCaption == "" {
+		c.WhatsApp.ReplyBudget.DocumentCaption = "My reply was long, so here it is as a document."
+	}
+	if c.WhatsApp.Forwarded.Policy == "" {
+		c.WhatsApp.Forwarded.Policy = ForwardedProcess
+	}
+	if c.WhatsApp.Forwarded.Message == "" {
+		c.WhatsApp.Forwarded.Message = "It looks like you forwarded this message. Did you mean to send it to me? If so, please type your question directly."
+	}
+	if c.WhatsApp.AuthRejectedMessage == "" {
+		c.WhatsApp.AuthRejectedMessage = "Sorry, verification and login are not available for this number."
+	}
+	if c.Store.FailurePolicy == "" {
+		c.Store.FailurePolicy = StoreFailOpen
+	}
+	if c.Blacklist.NotifyTimeout == "" {
+		c.Blacklist.NotifyTimeout = "5s"
+	}
+	if c.Blacklist.Appeals.Command == "" {
+		c.Blacklist.Appeals.Command = "/appeal"
+	}
+	if c.Blacklist.Appeals.Interval == "" {
+		c.Blacklist.Appeals.Interval = "24h"
+	}
+	if c.Blacklist.Appeals.AckMessage == "" {
+		c.Blacklist.Appeals.AckMessage = "Your appeal has been recorded and will be reviewed."
+	}
+	if c.Blacklist.Appeals.RateLimitedMessage == "" {
+		c.Blacklist.Appeals.RateLimitedMessage = "You have already appealed recently. Please wait for it to be reviewed."
+	}
+	if c.WhatsApp.UndecryptableReplyInterval == "" {
+		c.WhatsApp.UndecryptableReplyInterval = "1h"
+	}
+	if c.ADK.Summary.Keep == 0 {
+		c.ADK.Summary.Keep = 3
+	}
+	if c.ADK.Summary.Prompt == "" {
+		c.ADK.Summary.Prompt = "Summarize our conversation so far in a few sentences for future reference."
+	}
+	if c.ADK.Summary.StateKey == "" {
+		c.ADK.Summary.StateKey = "conversation_summary"
+	}
+	if c.ADK.DeliveryConfirmation.Mode == "" {
+		c.ADK.DeliveryConfirmation.Mode = "event"
+	}
+	if c.ADK.DeliveryConfirmation.Path == "" {
+		c.ADK.DeliveryConfirmation.Path = "/delivery"
+	}
+	if c.ADK.DeliveryConfirmation.StateKey == "" {
+		c.ADK.DeliveryConfirmation.StateKey = "last_delivery"
+	}
+	if c.ADK.DeliveryConfirmation.Timeout == "" {
+		c.ADK.DeliveryConfirmation.Timeout = "5s"
+	}
+	if c.ADK.RateLimit.MaxRetries == 0 {
+		c.ADK.RateLimit.MaxRetries = 2
+	}
+	if c.ADK.RateLimit.MaxWait == "" {
+		c.ADK.RateLimit.MaxWait = "30s"
+	}
+	if c.ADK.RateLimit.BusyMessage == "" {
+		c.ADK.RateLimit.BusyMessage = "I'm a bit busy right now. Please try again shortly."
+	}
+	if c.WhatsApp.Forms.TTL == "" {
+		c.WhatsApp.Forms.TTL = "30m"
+	}
+	if c.WhatsApp.Forms.CancelCommand == "" {
+		c.WhatsApp.Forms.CancelCommand = "cancel"
+	}
+	if c.WhatsApp.Forms.CancelledMessage == "" {
+		c.WhatsApp.Forms.CancelledMessage = "Okay, I've cancelled that form."
+	}
+	if c.WhatsApp.OnboardingNudge.Message == "" {
+		c.WhatsApp.OnboardingNudge.Message = "Welcome! Register with us to get the full experience."
+	}
+	if c.WhatsApp.LinkFilter.AutoBlacklist.Window == "" {
+		c.WhatsApp.LinkFilter.AutoBlacklist.Window = "24h"
+	}
+	if c.WhatsApp.LinkFilter.AutoBlacklist.Reason == "" {
+		c.WhatsApp.LinkFilter.AutoBlacklist.Reason = "spam links"
+	}
+	if c.WhatsApp.AgentRateLimit.Window == "" {
+		c.WhatsApp.AgentRateLimit.Window = "1m"
+	}
+	if c.WhatsApp.AgentRateLimit.Message == "" {
+		c.WhatsApp.AgentRateLimit.Message = "You're sending messages faster than I can answer. Please slow down a little."
+	}
+	if c.WhatsApp.ErrorCooldown.Message == "" {
+		c.WhatsApp.ErrorCooldown.Message = "I'm still having trouble right now. Please try again in a little while."
+	}
+	if c.WhatsApp.Flood.Window == "" {
+		c.WhatsApp.Flood.Window = "1m"
+	}
+	if c.Gateway.UserAgent == "" {
+		c.Gateway.UserAgent = DefaultUserAgent()
+	}
+	if c.TLS.MinVersion == "" {
+		c.TLS.MinVersion = "1.2"
+	}
+	if c.WhatsApp.ReplyPaging.Command == "" {
+		c.WhatsApp.ReplyPaging.Command = "more"
+	}
+	if c.WhatsApp.ReplyPaging.Prompt == "" {
+		c.WhatsApp.ReplyPaging.Prompt = fmt.Sprintf("Reply %q to continue.", c.WhatsApp.ReplyPaging.Command)
+	}
+	if c.WhatsApp.ReplyPaging.TTL == "" {
+		c.WhatsApp.ReplyPaging.TTL = "1h"
+	}
+	if c.WhatsApp.BusinessAccounts.Mode == "" {
+		c.WhatsApp.BusinessAccounts.Mode = BusinessModeDefault
+	}
+	if len(c.WhatsApp.InboundPipeline.Steps) == 0 {
+		if !c.WhatsApp.PreserveWhitespace {
+			c.WhatsApp.InboundPipeline.Steps = append(c.WhatsApp.InboundPipeline.Steps, "trim")
+		}
+		c.WhatsApp.InboundPipeline.Steps = append(c.WhatsApp.InboundPipeline.Steps, "strip_mention")
+	}
+	if len(c.WhatsApp.OutboundPipeline.Steps) == 0 {
+		c.WhatsApp.OutboundPipeline.Steps = []string{"sanitize", "branding", "split", "chunk"}
+	}
+	if c.Store.MigrateAttempts == 0 {
+		c.Store.MigrateAttempts = 5
+	}
+	if c.Store.MigrateBackoff == "" {
+		c.Store.MigrateBackoff = "1s"
+	}
+	if c.ADK.MessageMetadata.StateKey == "" {
+		c.ADK.MessageMetadata.StateKey = "message_metadata"
+	}
+	if c.ADK.GroupMetadata.StateKey == "" {
+		c.ADK.GroupMetadata.StateKey = "group"
+	}
+	if c.ADK.LastReply.Prefix == "" {
+		c.ADK.LastReply.Prefix = "Your previous reply:"
+	}
+	if c.ADK.QuotedContext.Prefix == "" {
+		c.ADK.QuotedContext.Prefix = "The user is replying to:"
+	}
+	if c.ADK.QuotedContext.MaxChars <= 0 {
+		c.ADK.QuotedContext.MaxChars = 500
+	}
+	if c.Store.SchemaUpgrade == "" {
+		c.Store.SchemaUpgrade = "auto"
+	}
+	if c.Store.ReplicaLag == "" {
+		c.Store.ReplicaLag = "5s"
+	}
+	if c.WhatsApp.SendQueue.Overflow == "" {
+		c.WhatsApp.SendQueue.Overflow = "block"
+	}
+	if c.WhatsApp.SendQueue.WarnDepth == 0 {
+		c.WhatsApp.SendQueue.WarnDepth = c.WhatsApp.SendQueue.MaxDepth * 8 / 10
+	}
+	if c.WhatsApp.Usage.BatchSize == 0 {
+		c.WhatsApp.Usage.BatchSize = 100
+	}
+	if c.WhatsApp.Usage.FlushInterval == "" {
+		c.WhatsApp.Usage.FlushInterval = "30s"
+	}
+	if c.WhatsApp.SendQueue.ResumeMaxAge == "" {
+		c.WhatsApp.SendQueue.ResumeMaxAge = "10m"
+	}
+	if c.WhatsApp.Newsletters == "" {
+		c.WhatsApp.Newsletters = NewsletterModeIgnore
+	}
+	if c.WhatsApp.CommunityAnnouncements == "" {
+		c.WhatsApp.CommunityAnnouncements = NewsletterModeIgnore
+	}
+	if c.WhatsApp.RevokeMode == "" {
+		c.WhatsApp.RevokeMode = RevokeModeLog
+	}
+	for name, t := range c.WABA.Templates {
+		if t.Language == "" {
+			t.Language = "en_US"
+			c.WABA.Templates[name] = t
+		}
+	}
 	if c.WABA.Port == 0 {
 		c.WABA.Port = 8081
 	}
@@ -271,12 +1830,27 @@ func (c *Config) applyDefaults() {
 	if c.Verification.CallbackTimeout == "" {
 		c.Verification.CallbackTimeout = "10s"
 	}
+	if c.Verification.SingleActive.TTL == "" {
+		c.Verification.SingleActive.TTL = "10m"
+	}
+	if c.Verification.Messages.Pending == "" {
+		c.Verification.Messages.Pending = "⏳ Another verification for this number is still in progress. Please finish it or try again in a few minutes."
+	}
+	if c.Verification.Messages.CallbackMismatch == "" {
+		c.Verification.Messages.CallbackMismatch = "❌ Verification failed. This link was not issued for this app. Please request a new one from the app."
+	}
 	if c.Auth.OAuth.Issuer == "" {
 		c.Auth.OAuth.Issuer = "whatsadk-gateway"
 	}
 	if c.Auth.OAuth.TTL == "" {
 		c.Auth.OAuth.TTL = "24h"
 	}
+	if c.Auth.OAuth.BindingTTL == "" {
+		c.Auth.OAuth.BindingTTL = c.Auth.OAuth.TTL
+	}
+	if c.Auth.Admin.MaxSkew == "" {
+		c.Auth.Admin.MaxSkew = "5m"
+	}
 	if c.Auth.OAuth.RateLimit == 0 {
 		c.Auth.OAuth.RateLimit = 5
 	}
@@ -324,6 +1898,12 @@ func (c *Config) applyEnvOverrides() {
 	if apiKey := os.Getenv("ADK_API_KEY"); apiKey != "" {
 		c.ADK.APIKey = apiKey
 	}
+	if v := os.Getenv("TRANSCRIPTION_API_KEY"); v != "" {
+		c.WhatsApp.Transcription.APIKey = v
+	}
+	if v := os.Getenv("TTS_API_KEY"); v != "" {
+		c.WhatsApp.VoiceReplies.APIKey = v
+	}
 	if v := os.Getenv("WABA_ENABLED"); v != "" {
 		c.WABA.Enabled = v == "true"
 	}
@@ -362,6 +1942,21 @@ func (c *Config) applyEnvOverrides() {
 	if v := os.Getenv("WHATSAPP_STORE_DSN"); v != "" {
 		c.WhatsApp.StoreDSN = v
 	}
+	if v := os.Getenv("GATEWAY_ENVIRONMENT"); v != "" {
+		c.Gateway.Environment = v
+	}
+	if v := os.Getenv("GATEWAY_INSTANCE_ID"); v != "" {
+		c.Gateway.InstanceID = v
+	}
+	if v := os.Getenv("STORE_READ_DSN"); v != "" {
+		c.Store.ReadDSN = v
+	}
+	if v := os.Getenv("ADMIN_TOKEN"); v != "" {
+		c.Auth.Admin.BearerToken = v
+	}
+	if v := os.Getenv("ADMIN_HMAC_SECRET"); v != "" {
+		c.Auth.Admin.HMACSecret = v
+	}
 	if v := os.Getenv("OAUTH_ENABLED"); v == "true" {
 		c.Auth.OAuth.Enabled = true
 	}
@@ -0,0 +1,386 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestOutboundTLSConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        OutboundTLSConfig
+		wantMin    uint16
+		wantSuites int
+		wantErr    bool
+	}{
+		{"default", OutboundTLSConfig{}, tls.VersionTLS12, 0, false},
+		{"tls 1.3", OutboundTLSConfig{MinVersion: "1.3"}, tls.VersionTLS13, 0, false},
+		{"restricted suites", OutboundTLSConfig{MinVersion: "1.2", CipherSuites: []string{
+			"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+			"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+		}}, tls.VersionTLS12, 2, false},
+		{"old version", OutboundTLSConfig{MinVersion: "1.0"}, 0, 0, true},
+		{"garbage version", OutboundTLSConfig{MinVersion: "tls12"}, 0, 0, true},
+		{"unknown suite", OutboundTLSConfig{CipherSuites: []string{"TLS_FAKE"}}, 0, 0, true},
+		{"insecure suite", OutboundTLSConfig{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}, 0, 0, true},
+		{"suites with 1.3", OutboundTLSConfig{MinVersion: "1.3", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.TLSConfig()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.MinVersion != tt.wantMin {
+				t.Errorf("MinVersion = %x, want %x", got.MinVersion, tt.wantMin)
+			}
+			if len(got.CipherSuites) != tt.wantSuites {
+				t.Errorf("got %d cipher suites, want %d", len(got.CipherSuites), tt.wantSuites)
+			}
+		})
+	}
+}
+
+func TestValidateInbound(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     WhatsAppConfig
+		wantErr bool
+	}{
+		{"defaults", WhatsAppConfig{InboundPipeline: InboundPipelineConfig{Steps: []string{"trim", "strip_mention"}}}, false},
+		{"mention names with strip step", WhatsAppConfig{
+			MentionNames:    []string{"@bot"},
+			InboundPipeline: InboundPipelineConfig{Steps: []string{"strip_mention"}},
+		}, false},
+		{"mention names without strip step", WhatsAppConfig{
+			MentionNames:    []string{"@bot"},
+			InboundPipeline: InboundPipelineConfig{Steps: []string{"trim"}},
+		}, true},
+		{"preserve whitespace without trim", WhatsAppConfig{
+			PreserveWhitespace: true,
+			InboundPipeline:    InboundPipelineConfig{Steps: []string{"strip_mention"}},
+		}, false},
+		{"preserve whitespace with trim", WhatsAppConfig{
+			PreserveWhitespace: true,
+			InboundPipeline:    InboundPipelineConfig{Steps: []string{"trim", "strip_mention"}},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validateInbound()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateInbound() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePolicies(t *testing.T) {
+	tests := []struct {
+		name          string
+		authPolicy    string
+		failurePolicy string
+		wantErr       bool
+	}{
+		{"defaults", AuthPolicyAny, StoreFailOpen, false},
+		{"allowed and closed", AuthPolicyAllowed, StoreFailClosed, false},
+		{"auth policy typo", "alowed", StoreFailClosed, true},
+		{"auth policy case", "Allowed", StoreFailClosed, true},
+		{"failure policy typo", AuthPolicyAny, "close", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.WhatsApp.AuthPolicy = tt.authPolicy
+			cfg.Store.FailurePolicy = tt.failurePolicy
+			err := cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if !defaultConfig().Store.FailOpen() {
+		t.Error("store failure_policy should default to open, as messages were always let through")
+	}
+}
+
+// fields formats v space-separated, for comparing several settings at once.
+func fields(v ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+}
+
+func TestApplyDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+		got  func(*Config) string
+		want string
+	}{
+		{"send_queue", &Config{WhatsApp: WhatsAppConfig{SendQueue: SendQueueConfig{MaxDepth: 100}}},
+			func(c *Config) string { return fields(c.WhatsApp.SendQueue.Overflow, c.WhatsApp.SendQueue.WarnDepth) },
+			"block 80"},
+		{"send_queue persist", &Config{WhatsApp: WhatsAppConfig{SendQueue: SendQueueConfig{MaxDepth: 100, Persist: true}}},
+			func(c *Config) string { return c.WhatsApp.SendQueue.ResumeMaxAge },
+			"10m"},
+		{"delivery_confirmation", &Config{ADK: ADKConfig{DeliveryConfirmation: DeliveryConfirmationConfig{Enabled: true}}},
+			func(c *Config) string {
+				dc := c.ADK.DeliveryConfirmation
+				return fields(dc.Mode, dc.Path, dc.StateKey, dc.Timeout)
+			},
+			"event /delivery last_delivery 5s"},
+		{"adk rate_limit", &Config{},
+			func(c *Config) string {
+				rl := c.ADK.RateLimit
+				return fields(rl.MaxRetries, rl.MaxWait, rl.BusyMessage != "")
+			},
+			"2 30s true"},
+		{"auth admin max_skew", &Config{},
+			func(c *Config) string { return c.Auth.Admin.MaxSkew },
+			"5m"},
+		{"store schema_upgrade", &Config{},
+			func(c *Config) string { return c.Store.SchemaUpgrade },
+			"auto"},
+		{"message_metadata", &Config{ADK: ADKConfig{MessageMetadata: MessageMetadataConfig{Fields: []string{MetadataTimestamp, MetadataSenderPhone}}}},
+			func(c *Config) string { return c.ADK.MessageMetadata.StateKey },
+			"message_metadata"},
+		{"blacklist appeals", &Config{},
+			func(c *Config) string {
+				a := c.Blacklist.Appeals
+				return fields(a.Command, a.Interval, a.AckMessage != "", a.RateLimitedMessage != "")
+			},
+			"/appeal 24h true true"},
+		{"agent_rate_limit", &Config{WhatsApp: WhatsAppConfig{AgentRateLimit: AgentRateLimitConfig{MaxCalls: 5}}},
+			func(c *Config) string {
+				rl := c.WhatsApp.AgentRateLimit
+				return fields(rl.Window, rl.Message != "")
+			},
+			"1m true"},
+		{"link_filter auto_blacklist", &Config{WhatsApp: WhatsAppConfig{LinkFilter: LinkFilterConfig{
+			BlockedDomains: []string{"bit.ly"},
+			AutoBlacklist:  AutoBlacklistConfig{Threshold: 3},
+		}}},
+			func(c *Config) string {
+				ab := c.WhatsApp.LinkFilter.AutoBlacklist
+				return ab.Window + "/" + ab.Reason
+			},
+			"24h/spam links"},
+		{"forwarded", &Config{},
+			func(c *Config) string { return fields(c.WhatsApp.Forwarded.Policy, c.WhatsApp.Forwarded.Message != "") },
+			ForwardedProcess + " true"},
+		{"reply_budget", &Config{WhatsApp: WhatsAppConfig{ReplyBudget: ReplyBudgetConfig{MaxChars: 1000}}},
+			func(c *Config) string {
+				b := c.WhatsApp.ReplyBudget
+				return fields(b.Policy, b.Notice != "", b.SummarizePrompt != "", b.DocumentCaption != "")
+			},
+			ReplyBudgetTruncate + " true true true"},
+		{"transcription", &Config{WhatsApp: WhatsAppConfig{Transcription: TranscriptionConfig{Provider: TranscriptionGoogle}}},
+			func(c *Config) string {
+				tc := c.WhatsApp.Transcription
+				return fields(tc.Language, tc.Timeout, tc.Prefix != "")
+			},
+			"en-US 30s true"},
+		{"video", &Config{},
+			func(c *Config) string { return fields(c.WhatsApp.Video.Mode, c.WhatsApp.Video.Note != "") },
+			VideoModeFrames + " true"},
+		{"stickers", &Config{},
+			func(c *Config) string { return fields(c.WhatsApp.Stickers.Mode, c.WhatsApp.Stickers.Reply != "") },
+			StickerModeIgnore + " true"},
+		{"quoted_context", &Config{ADK: ADKConfig{QuotedContext: QuotedContextConfig{Enabled: true}}},
+			func(c *Config) string {
+				return fmt.Sprintf("%s/%d", c.ADK.QuotedContext.Prefix, c.ADK.QuotedContext.MaxChars)
+			},
+			"The user is replying to:/500"},
+		{"edits", &Config{},
+			func(c *Config) string { return fields(c.WhatsApp.Edits.Mode, c.WhatsApp.Edits.Prefix != "") },
+			EditModeIgnore + " true"},
+		{"community_announcements", &Config{},
+			func(c *Config) string { return c.WhatsApp.CommunityAnnouncements },
+			NewsletterModeIgnore},
+		{"broadcasts interval", &Config{},
+			func(c *Config) string { return c.WhatsApp.Broadcasts.Interval },
+			"2s"},
+		{"voice_replies", &Config{WhatsApp: WhatsAppConfig{VoiceReplies: VoiceRepliesConfig{Provider: TTSOpenAI}}},
+			func(c *Config) string {
+				v := c.WhatsApp.VoiceReplies
+				return fields(v.Model, v.Voice, v.Timeout, v.MaxChars)
+			},
+			"tts-1 alloy 30s 1000"},
+		{"link_previews", &Config{},
+			func(c *Config) string { return fields(c.WhatsApp.LinkPreviews.Mode, c.WhatsApp.LinkPreviews.Timeout) },
+			LinkPreviewNone + " 5s"},
+		{"read_receipts", &Config{},
+			func(c *Config) string { return c.WhatsApp.ReadReceipts },
+			ReadReceiptsNever},
+		{"typing_indicator", &Config{WhatsApp: WhatsAppConfig{TypingIndicator: TypingIndicatorConfig{Enabled: true}}},
+			func(c *Config) string { return c.WhatsApp.TypingIndicator.Refresh },
+			"10s"},
+		{"image_links", &Config{WhatsApp: WhatsAppConfig{ImageLinks: ImageLinksConfig{Enabled: true}}},
+			func(c *Config) string { return fields(c.WhatsApp.ImageLinks.MaxBytes, c.WhatsApp.ImageLinks.Timeout) },
+			"5242880 10s"},
+		{"last_reply", &Config{ADK: ADKConfig{LastReply: LastReplyConfig{Enabled: true}}},
+			func(c *Config) string { return fields(c.ADK.LastReply.Prefix != "") },
+			"true"},
+		{"usage", &Config{WhatsApp: WhatsAppConfig{Usage: UsageConfig{Enabled: true}}},
+			func(c *Config) string { return fields(c.WhatsApp.Usage.BatchSize, c.WhatsApp.Usage.FlushInterval) },
+			"100 30s"},
+		{"waba template", &Config{WABA: WABAConfig{Templates: map[string]WABATemplateConfig{"order_update": {BodyParameters: 2}}}},
+			func(c *Config) string { return c.WABA.Templates["order_update"].Language },
+			"en_US"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.applyDefaults()
+			if got := tt.got(tt.cfg); got != tt.want {
+				t.Errorf("defaults = %q, want %q", got, tt.want)
+			}
+			if err := tt.cfg.validate(); err != nil {
+				t.Errorf("validate() rejected the defaults: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateAcceptsValues(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(*Config)
+	}{
+		{"read_receipts immediate", func(c *Config) { c.WhatsApp.ReadReceipts = ReadReceiptsImmediate }},
+		{"read_receipts after_reply", func(c *Config) { c.WhatsApp.ReadReceipts = ReadReceiptsAfterReply }},
+		{"community announcements to agent", func(c *Config) {
+			c.WhatsApp.CommunityAnnouncements = NewsletterModeAgent
+			c.WhatsApp.Newsletters = NewsletterModeAgent
+		}},
+		// Groups may all be allowed at runtime through the store.
+		{"groups without allowed list", func(c *Config) { c.WhatsApp.Groups.Enabled = true }},
+		{"groups with allowed list", func(c *Config) {
+			c.WhatsApp.Groups = GroupsConfig{Enabled: true, Allowed: []string{"120363012345678901@g.us"}}
+		}},
+		{"admin commands with devops numbers", func(c *Config) {
+			c.WhatsApp.AdminCommands.Enabled = true
+			c.Verification.DevOpsNumbers = []string{"910000000000"}
+		}},
+		{"admin_addr with credentials", func(c *Config) {
+			c.Gateway.AdminAddr = "127.0.0.1:8082"
+			c.Auth.Admin.HMACSecret = "s3cret"
+		}},
+		{"response_schema object", func(c *Config) {
+			c.ADK.ResponseSchema = `{"type":"object","properties":{"reply":{"type":"string"}}}`
+		}},
+		{"link_previews generate", func(c *Config) { c.WhatsApp.LinkPreviews.Mode = LinkPreviewGenerate }},
+		{"persist_state", func(c *Config) {
+			c.WhatsApp.PersistState = []string{PersistAgentRateLimit, PersistFlood, PersistErrorCooldown}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.set(cfg)
+			if err := cfg.validate(); err != nil {
+				t.Errorf("validate() error: %v", err)
+			}
+		})
+	}
+}
+
+// TestValidateInvalidValues applies defaults after set, as loading a config
+// file does, and expects an error naming the setting.
+func TestValidateInvalidValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     func(*Config)
+		setting string
+	}{
+		{"max_connection_age garbage", func(c *Config) { c.WhatsApp.MaxConnectionAge = "6 hours" }, "max_connection_age"},
+		{"max_connection_age zero", func(c *Config) { c.WhatsApp.MaxConnectionAge = "0s" }, "max_connection_age"},
+		{"max_connection_age negative", func(c *Config) { c.WhatsApp.MaxConnectionAge = "-1h" }, "max_connection_age"},
+		{"heartbeat_interval garbage", func(c *Config) { c.Gateway.HeartbeatInterval = "every minute" }, "heartbeat_interval"},
+		{"heartbeat_interval zero", func(c *Config) { c.Gateway.HeartbeatInterval = "0s" }, "heartbeat_interval"},
+		{"send_queue overflow", func(c *Config) {
+			c.WhatsApp.SendQueue = SendQueueConfig{MaxDepth: 100, Overflow: "drop-oldest"}
+		}, "overflow"},
+		{"send_queue resume_max_age negative", func(c *Config) {
+			c.WhatsApp.SendQueue = SendQueueConfig{MaxDepth: 100, Persist: true, ResumeMaxAge: "-1m"}
+		}, "resume_max_age"},
+		{"delivery_confirmation mode", func(c *Config) {
+			c.ADK.DeliveryConfirmation = DeliveryConfirmationConfig{Enabled: true, Mode: "webhook"}
+		}, "mode"},
+		{"adk rate_limit max_wait", func(c *Config) { c.ADK.RateLimit.MaxWait = "half a minute" }, "max_wait"},
+		{"auth admin max_skew", func(c *Config) { c.Auth.Admin.MaxSkew = "five minutes" }, "max_skew"},
+		{"admin_addr without credentials", func(c *Config) { c.Gateway.AdminAddr = "127.0.0.1:8082" }, "admin_addr"},
+		{"response_schema malformed", func(c *Config) { c.ADK.ResponseSchema = `{"type":"object"` }, "response_schema"},
+		{"response_schema not an object", func(c *Config) { c.ADK.ResponseSchema = `["reply"]` }, "response_schema"},
+		{"response_schema null", func(c *Config) { c.ADK.ResponseSchema = "null" }, "response_schema"},
+		{"store schema_upgrade", func(c *Config) { c.Store.SchemaUpgrade = "never" }, "schema_upgrade"},
+		{"message_metadata field", func(c *Config) {
+			c.ADK.MessageMetadata.Fields = []string{MetadataTimestamp, "phone_number"}
+		}, "phone_number"},
+		{"blacklist appeals interval", func(c *Config) { c.Blacklist.Appeals.Interval = "a day" }, "interval"},
+		{"agent_rate_limit window", func(c *Config) {
+			c.WhatsApp.AgentRateLimit = AgentRateLimitConfig{MaxCalls: 5, Window: "0s"}
+		}, "window"},
+		{"link_filter pattern", func(c *Config) { c.WhatsApp.LinkFilter.Patterns = []string{"("} }, "pattern"},
+		{"link_filter auto_blacklist window", func(c *Config) {
+			c.WhatsApp.LinkFilter = LinkFilterConfig{
+				BlockedDomains: []string{"bit.ly"},
+				AutoBlacklist:  AutoBlacklistConfig{Threshold: 3, Window: "soon"},
+			}
+		}, "window"},
+		{"forwarded policy", func(c *Config) { c.WhatsApp.Forwarded.Policy = "drop" }, "policy"},
+		{"reply_budget policy", func(c *Config) {
+			c.WhatsApp.ReplyBudget = ReplyBudgetConfig{MaxChars: 1000, Policy: "drop"}
+		}, "policy"},
+		{"reply_budget max_chars negative", func(c *Config) {
+			c.WhatsApp.ReplyBudget = ReplyBudgetConfig{MaxChars: -1, Policy: ReplyBudgetDocument}
+		}, "max_chars"},
+		{"persist_state name", func(c *Config) { c.WhatsApp.PersistState = []string{PersistFlood, "sessions"} }, "sessions"},
+		{"transcription timeout", func(c *Config) {
+			c.WhatsApp.Transcription = TranscriptionConfig{Provider: TranscriptionGoogle, Timeout: "soon"}
+		}, "timeout"},
+		{"transcription provider", func(c *Config) { c.WhatsApp.Transcription.Provider = "azure" }, "azure"},
+		{"video mode", func(c *Config) { c.WhatsApp.Video.Mode = "gif" }, "gif"},
+		{"stickers mode", func(c *Config) { c.WhatsApp.Stickers.Mode = "react" }, "react"},
+		{"edits mode", func(c *Config) { c.WhatsApp.Edits.Mode = "replace" }, "replace"},
+		{"community_announcements mode", func(c *Config) { c.WhatsApp.CommunityAnnouncements = "reply" }, "community_announcements"},
+		{"broadcasts interval zero", func(c *Config) { c.WhatsApp.Broadcasts.Interval = "0s" }, "interval"},
+		{"admin commands without devops numbers", func(c *Config) { c.WhatsApp.AdminCommands.Enabled = true }, "devops"},
+		{"voice_replies provider", func(c *Config) { c.WhatsApp.VoiceReplies.Provider = "polly" }, "polly"},
+		{"link_previews mode", func(c *Config) { c.WhatsApp.LinkPreviews.Mode = "rich" }, "rich"},
+		{"read_receipts mode", func(c *Config) { c.WhatsApp.ReadReceipts = "always" }, "read_receipts"},
+		{"typing_indicator refresh zero", func(c *Config) {
+			c.WhatsApp.TypingIndicator = TypingIndicatorConfig{Enabled: true, Refresh: "0s"}
+		}, "refresh"},
+		{"image_links timeout", func(c *Config) {
+			c.WhatsApp.ImageLinks = ImageLinksConfig{Enabled: true, Timeout: "soon"}
+		}, "timeout"},
+		{"usage flush_interval zero", func(c *Config) {
+			c.WhatsApp.Usage = UsageConfig{Enabled: true, FlushInterval: "0s"}
+		}, "flush_interval"},
+		{"waba template body_parameters", func(c *Config) {
+			c.WABA.Templates = map[string]WABATemplateConfig{"order_update": {BodyParameters: -1}}
+		}, "body_parameters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			tt.set(cfg)
+			cfg.applyDefaults()
+			err := cfg.validate()
+			if err == nil {
+				t.Fatal("validate() accepted an invalid value")
+			}
+			if !strings.Contains(err.Error(), tt.setting) {
+				t.Errorf("validate() error = %v, want it to name %s", err, tt.setting)
+			}
+		})
+	}
+}
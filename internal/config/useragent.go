@@ -0,0 +1,24 @@
+package config
+
+import "runtime/debug"
+
+// Version is the gateway version reported in the default User-Agent. It
+// can be set at link time (-ldflags "-X .../config.Version=v1.2.3");
+// otherwise the main module version from the build info is used.
+var Version = ""
+
+// DefaultUserAgent returns "whatsadk/<version>", with "dev" for source
+// builds that carry no module version.
+func DefaultUserAgent() string {
+	return "whatsadk/" + buildVersion()
+}
+
+func buildVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
@@ -88,6 +88,13 @@ func (m *Manager) runJob(job config.CronJobConfig) {
 	}
 
 	client := agent.NewClient(&agentCfg, m.jwtGen)
+	tlsCfg, err := m.cfg.TLS.TLSConfig()
+	if err != nil {
+		log.Printf("[Cron] Job %s skipped: invalid TLS config: %v", job.Name, err)
+		return
+	}
+	client.SetTLSConfig(tlsCfg)
+	client.SetUserAgent(m.cfg.Gateway.UserAgent)
 
 	// 4. Run Agent
 	parts, err := client.Chat(ctx, job.UserID, fullMessage)
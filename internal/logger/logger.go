@@ -103,12 +103,28 @@ func Init(cfg *config.Config) (*slog.Logger, error) {
 		handlers = append(handlers, slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
 	}
 
-	Log = slog.New(NewMultiHandler(handlers...))
+	Log = slog.New(withInstanceAttrs(NewMultiHandler(handlers...), cfg.Gateway))
 	slog.SetDefault(Log)
 
 	return Log, nil
 }
 
+// withInstanceAttrs tags every record from h with the configured
+// environment and instance ID.
+func withInstanceAttrs(h slog.Handler, gw config.GatewayConfig) slog.Handler {
+	var attrs []slog.Attr
+	if gw.Environment != "" {
+		attrs = append(attrs, slog.String("env", gw.Environment))
+	}
+	if gw.InstanceID != "" {
+		attrs = append(attrs, slog.String("instance_id", gw.InstanceID))
+	}
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.WithAttrs(attrs)
+}
+
 // WhatsMeowLogger wraps slog.Logger to implement go.mau.fi/whatsmeow/util/log.Logger
 type WhatsMeowLogger struct {
 	logger *slog.Logger
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestInitTagsRecordsWithInstance(t *testing.T) {
+	tests := []struct {
+		name    string
+		gateway config.GatewayConfig
+		want    map[string]string
+	}{
+		{"both set", config.GatewayConfig{Environment: "staging", InstanceID: "gw-2"}, map[string]string{"env": "staging", "instance_id": "gw-2"}},
+		{"environment only", config.GatewayConfig{Environment: "prod"}, map[string]string{"env": "prod"}},
+		{"unset", config.GatewayConfig{}, map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := &config.Config{Gateway: tt.gateway}
+			cfg.Logging.FileEnabled = true
+			cfg.Logging.Dir = dir
+			cfg.Logging.FileName = "test.log"
+			cfg.Logging.MaxSizeMB = 1
+			cfg.Logging.MaxBackups = 1
+
+			log, err := Init(cfg)
+			if err != nil {
+				t.Fatalf("Init() error: %v", err)
+			}
+			log.Info("app record")
+			NewWhatsMeowLogger(log, "Client").Infof("whatsmeow record")
+
+			f, err := os.Open(filepath.Join(dir, "test.log"))
+			if err != nil {
+				t.Fatalf("open log: %v", err)
+			}
+			defer f.Close()
+			records := 0
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				var rec map[string]any
+				if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+					t.Fatalf("decode record %q: %v", scanner.Text(), err)
+				}
+				records++
+				for _, key := range []string{"env", "instance_id"} {
+					want, ok := tt.want[key]
+					got, present := rec[key]
+					if present != ok || (ok && got != want) {
+						t.Errorf("%s: %s = %v (present %v), want %q (present %v)", rec["msg"], key, got, present, want, ok)
+					}
+				}
+			}
+			if records != 2 {
+				t.Errorf("got %d records, want 2", records)
+			}
+		})
+	}
+}
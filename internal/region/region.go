@@ -0,0 +1,100 @@
+// Package region maps phone numbers to ISO 3166-1 alpha-2 country codes using
+// a built-in table of international calling code prefixes.
+package region
+
+import "strings"
+
+const (
+	minE164Digits = 8
+	maxE164Digits = 15
+	maxPrefixLen  = 4
+)
+
+// prefixes maps calling code prefixes to countries. Shared codes are
+// resolved by listing the longer, more specific prefix (e.g. Kazakhstan
+// within +7, Canadian area codes within the NANP +1); the shorter entry
+// is the fallback.
+var prefixes = map[string]string{
+	// North American Numbering Plan
+	"1":    "US",
+	"1204": "CA", "1226": "CA", "1236": "CA", "1249": "CA", "1250": "CA",
+	"1289": "CA", "1306": "CA", "1343": "CA", "1365": "CA", "1403": "CA",
+	"1416": "CA", "1418": "CA", "1431": "CA", "1437": "CA", "1438": "CA",
+	"1450": "CA", "1506": "CA", "1514": "CA", "1519": "CA", "1548": "CA",
+	"1579": "CA", "1581": "CA", "1587": "CA", "1604": "CA", "1613": "CA",
+	"1639": "CA", "1647": "CA", "1672": "CA", "1705": "CA", "1709": "CA",
+	"1778": "CA", "1780": "CA", "1782": "CA", "1807": "CA", "1819": "CA",
+	"1825": "CA", "1867": "CA", "1873": "CA", "1902": "CA", "1905": "CA",
+	"1242": "BS", "1246": "BB", "1441": "BM", "1787": "PR", "1939": "PR",
+	"1868": "TT", "1876": "JM",
+
+	// Russia and Kazakhstan share +7
+	"7": "RU", "76": "KZ", "77": "KZ",
+
+	"20": "EG", "27": "ZA", "30": "GR", "31": "NL", "32": "BE", "33": "FR",
+	"34": "ES", "36": "HU", "39": "IT", "40": "RO", "41": "CH", "43": "AT",
+	"44": "GB", "45": "DK", "46": "SE", "47": "NO", "48": "PL", "49": "DE",
+	"51": "PE", "52": "MX", "53": "CU", "54": "AR", "55": "BR", "56": "CL",
+	"57": "CO", "58": "VE", "60": "MY", "61": "AU", "62": "ID", "63": "PH",
+	"64": "NZ", "65": "SG", "66": "TH", "81": "JP", "82": "KR", "84": "VN",
+	"86": "CN", "90": "TR", "91": "IN", "92": "PK", "93": "AF", "94": "LK",
+	"95": "MM", "98": "IR",
+
+	"211": "SS", "212": "MA", "213": "DZ", "216": "TN", "218": "LY",
+	"220": "GM", "221": "SN", "225": "CI", "233": "GH", "234": "NG",
+	"237": "CM", "243": "CD", "244": "AO", "249": "SD", "250": "RW",
+	"251": "ET", "254": "KE", "255": "TZ", "256": "UG", "260": "ZM",
+	"263": "ZW", "351": "PT", "352": "LU", "353": "IE", "354": "IS",
+	"356": "MT", "357": "CY", "358": "FI", "359": "BG", "370": "LT",
+	"371": "LV", "372": "EE", "380": "UA", "381": "RS", "385": "HR",
+	"386": "SI", "420": "CZ", "421": "SK", "852": "HK", "853": "MO",
+	"855": "KH", "856": "LA", "880": "BD", "886": "TW", "960": "MV",
+	"961": "LB", "962": "JO", "963": "SY", "964": "IQ", "965": "KW",
+	"966": "SA", "967": "YE", "968": "OM", "970": "PS", "971": "AE",
+	"972": "IL", "973": "BH", "974": "QA", "975": "BT", "976": "MN",
+	"977": "NP", "992": "TJ", "993": "TM", "994": "AZ", "995": "GE",
+	"996": "KG", "998": "UZ",
+}
+
+// Lookup returns the country for phone, given in international format with
+// or without a leading "+" (e.g. "919876543210"). Common separators are
+// ignored. ok is false if the number is not a plausible E.164 number or its
+// calling code is not in the built-in table.
+func Lookup(phone string) (country string, ok bool) {
+	digits, valid := e164Digits(phone)
+	if !valid {
+		return "", false
+	}
+
+	for n := min(maxPrefixLen, len(digits)); n > 0; n-- {
+		if c, found := prefixes[digits[:n]]; found {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// Is reports whether phone belongs to country (ISO 3166-1 alpha-2).
+func Is(phone, country string) bool {
+	c, ok := Lookup(phone)
+	return ok && strings.EqualFold(c, country)
+}
+
+func e164Digits(phone string) (string, bool) {
+	phone = strings.TrimPrefix(strings.TrimSpace(phone), "+")
+	var b strings.Builder
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+	digits := b.String()
+	if len(digits) < minE164Digits || len(digits) > maxE164Digits || digits[0] == '0' {
+		return "", false
+	}
+	return digits, true
+}
@@ -0,0 +1,76 @@
+package region
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		phone   string
+		country string
+		ok      bool
+	}{
+		{"919876543210", "IN", true},
+		{"+91 98765 43210", "IN", true},
+		{"447911123456", "GB", true},
+		{"4915123456789", "DE", true},
+		{"33612345678", "FR", true},
+		{"8613800138000", "CN", true},
+		{"971501234567", "AE", true},
+		{"966501234567", "SA", true},
+		{"254712345678", "KE", true},
+		{"2348031234567", "NG", true},
+		{"61412345678", "AU", true},
+		{"5511912345678", "BR", true},
+		{"6591234567", "SG", true},
+		{"9779812345678", "NP", true},
+		{"8801712345678", "BD", true},
+
+		// Shared calling codes resolve to the most specific prefix.
+		{"12025550123", "US", true},
+		{"+1 (416) 555-0123", "CA", true},
+		{"16045550123", "CA", true},
+		{"18765550123", "JM", true},
+		{"17875550123", "PR", true},
+		{"74951234567", "RU", true},
+		{"77011234567", "KZ", true},
+		{"76112345678", "KZ", true},
+		{"352621123456", "LU", true},
+		{"35312345678", "IE", true},
+
+		// Invalid or unknown.
+		{"", "", false},
+		{"12345", "", false},
+		{"0987654321", "", false},
+		{"9198765432101234", "", false},
+		{"91abc76543210", "", false},
+		{"13061129773287@lid", "", false},
+		{"80012345678", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			country, ok := Lookup(tt.phone)
+			if country != tt.country || ok != tt.ok {
+				t.Errorf("Lookup(%q) = (%q, %v), want (%q, %v)", tt.phone, country, ok, tt.country, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIs(t *testing.T) {
+	tests := []struct {
+		phone   string
+		country string
+		want    bool
+	}{
+		{"919876543210", "IN", true},
+		{"919876543210", "in", true},
+		{"12025550123", "IN", false},
+		{"invalid", "IN", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phone+"/"+tt.country, func(t *testing.T) {
+			if got := Is(tt.phone, tt.country); got != tt.want {
+				t.Errorf("Is(%q, %q) = %v, want %v", tt.phone, tt.country, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Appeal statuses.
+const (
+	AppealPending  = "pending"
+	AppealRejected = "rejected"
+)
+
+// Appeal is a blacklisted user's request to be unblocked, kept for admin
+// review. Only the latest appeal per phone is stored.
+type Appeal struct {
+	Phone     string    `json:"phone"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const appealPrefix = "appeals/"
+
+func appealPath(phone string) string {
+	return appealPrefix + phone
+}
+
+// PutAppeal stores a's appeal, replacing any earlier one for the phone.
+func (s *Store) PutAppeal(ctx context.Context, a Appeal) error {
+	content, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to encode appeal for %s: %w", a.Phone, err)
+	}
+	metadata := map[string]interface{}{
+		"status":    a.Status,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, appealPath(a.Phone), metadata, content, a.CreatedAt)
+}
+
+// GetAppeal returns the latest appeal from phone, or nil if none exists.
+func (s *Store) GetAppeal(ctx context.Context, phone string) (*Appeal, error) {
+	file, err := s.GetFile(ctx, appealPath(phone))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appeal for %s: %w", phone, err)
+	}
+	if file == nil {
+		return nil, nil
+	}
+
+	var a Appeal
+	if err := json.Unmarshal(file.Content, &a); err != nil {
+		return nil, fmt.Errorf("failed to decode appeal for %s: %w", phone, err)
+	}
+	return &a, nil
+}
+
+// ListAppeals returns stored appeals, filtered by status unless it is
+// empty.
+func (s *Store) ListAppeals(ctx context.Context, status string) ([]Appeal, error) {
+	var appeals []Appeal
+	err := s.EachFile(ctx, appealPrefix, func(file FileEntry) error {
+		var a Appeal
+		if err := json.Unmarshal(file.Content, &a); err != nil {
+			return fmt.Errorf("failed to decode appeal %s: %w", strings.TrimPrefix(file.Path, appealPrefix), err)
+		}
+		if status == "" || a.Status == status {
+			appeals = append(appeals, a)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appeals: %w", err)
+	}
+	return appeals, nil
+}
+
+// DeleteAppeal removes the appeal from phone.
+func (s *Store) DeleteAppeal(ctx context.Context, phone string) error {
+	return s.DeleteFile(ctx, appealPath(phone))
+}
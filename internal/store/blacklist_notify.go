@@ -0,0 +1,125 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BlacklistNotifier is told about numbers added to the blacklist. It must be
+// best-effort: failures are its own concern and never fail AddBlacklist.
+type BlacklistNotifier interface {
+	BlacklistAdded(ctx context.Context, phone, reason string)
+}
+
+// SetBlacklistNotifier registers a notifier fired after each successful
+// AddBlacklist.
+func (s *Store) SetBlacklistNotifier(n BlacklistNotifier) {
+	s.notifier = n
+}
+
+// BlacklistEvent is the JSON body POSTed to blacklist webhooks. The phone
+// number is sent only as a SHA-256 hex digest.
+type BlacklistEvent struct {
+	Event     string    `json:"event"`
+	PhoneHash string    `json:"phone_hash"`
+	Reason    string    `json:"reason,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// HashPhone returns the hex SHA-256 digest used to identify a number in
+// blacklist notifications.
+func HashPhone(phone string) string {
+	sum := sha256.Sum256([]byte(phone))
+	return hex.EncodeToString(sum[:])
+}
+
+// WebhookNotifier POSTs a BlacklistEvent to each configured URL. Posts run
+// in the background, concurrently, so a slow webhook never delays
+// AddBlacklist.
+type WebhookNotifier struct {
+	urls       []string
+	timeout    time.Duration
+	httpClient *http.Client
+	logger     *slog.Logger
+
+	wg sync.WaitGroup
+}
+
+func NewWebhookNotifier(urls []string, timeout time.Duration, logger *slog.Logger) *WebhookNotifier {
+	return &WebhookNotifier{
+		urls:       urls,
+		timeout:    timeout,
+		httpClient: &http.Client{Timeout: timeout},
+		logger:     logger,
+	}
+}
+
+// SetTLSConfig applies outbound TLS restrictions (minimum version, cipher
+// suites) to webhook requests.
+func (w *WebhookNotifier) SetTLSConfig(tlsCfg *tls.Config) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	w.httpClient.Transport = transport
+}
+
+// Wait blocks until in-flight notifications have finished, e.g. before
+// the process exits.
+func (w *WebhookNotifier) Wait() {
+	w.wg.Wait()
+}
+
+func (w *WebhookNotifier) BlacklistAdded(ctx context.Context, phone, reason string) {
+	body, err := json.Marshal(BlacklistEvent{
+		Event:     "blacklist.added",
+		PhoneHash: HashPhone(phone),
+		Reason:    reason,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		w.logger.Error("failed to encode blacklist notification", "error", err)
+		return
+	}
+
+	// Detached from the caller: AddBlacklist returns before delivery, and
+	// a finished request context must not cancel it.
+	ctx = context.WithoutCancel(ctx)
+	for _, url := range w.urls {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			postCtx, cancel := context.WithTimeout(ctx, w.timeout)
+			defer cancel()
+			if err := w.post(postCtx, url, body); err != nil {
+				w.logger.Warn("blacklist notification failed", "url", url, "error", err)
+			}
+		}()
+	}
+}
+
+func (w *WebhookNotifier) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeBlacklistBackend implements only the blacklist write path; other
+// storeBackend methods panic if called.
+type fakeBlacklistBackend struct {
+	storeBackend
+	err error
+}
+
+func (f *fakeBlacklistBackend) AddBlacklist(_ context.Context, _, _ string) error {
+	return f.err
+}
+
+func TestAddBlacklistNotifiesWebhooks(t *testing.T) {
+	events := make(chan BlacklistEvent, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev BlacklistEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("failed to decode notification: %v", err)
+		}
+		events <- ev
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := &Store{backend: &fakeBlacklistBackend{}}
+	notifier := NewWebhookNotifier([]string{server.URL, server.URL}, time.Second, logger)
+	s.SetBlacklistNotifier(notifier)
+
+	if err := s.AddBlacklist(context.Background(), "910987654321", "spam"); err != nil {
+		t.Fatalf("AddBlacklist() error: %v", err)
+	}
+	notifier.Wait()
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(events))
+	}
+	ev := <-events
+	if ev.Event != "blacklist.added" {
+		t.Errorf("event = %q, want blacklist.added", ev.Event)
+	}
+	if ev.PhoneHash != HashPhone("910987654321") {
+		t.Errorf("phone_hash = %q, want hash of phone", ev.PhoneHash)
+	}
+	if ev.Reason != "spam" {
+		t.Errorf("reason = %q, want spam", ev.Reason)
+	}
+}
+
+func TestAddBlacklistSkipsNotificationOnError(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := &Store{backend: &fakeBlacklistBackend{err: errors.New("db down")}}
+	notifier := NewWebhookNotifier([]string{server.URL}, time.Second, logger)
+	s.SetBlacklistNotifier(notifier)
+
+	if err := s.AddBlacklist(context.Background(), "910987654321", "spam"); err == nil {
+		t.Fatal("expected AddBlacklist error")
+	}
+	notifier.Wait()
+	if called {
+		t.Error("expected no notification when the blacklist write fails")
+	}
+}
+
+func TestWebhookFailureDoesNotFailAddBlacklist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := &Store{backend: &fakeBlacklistBackend{}}
+	s.SetBlacklistNotifier(NewWebhookNotifier([]string{server.URL}, time.Second, logger))
+
+	if err := s.AddBlacklist(context.Background(), "910987654321", "spam"); err != nil {
+		t.Fatalf("AddBlacklist() should be best-effort, got error: %v", err)
+	}
+}
+
+func TestSlowWebhookDoesNotBlockAddBlacklist(t *testing.T) {
+	release := make(chan struct{})
+	delivered := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		delivered <- r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := &Store{backend: &fakeBlacklistBackend{}}
+	notifier := NewWebhookNotifier([]string{server.URL}, 5*time.Second, logger)
+	s.SetBlacklistNotifier(notifier)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := s.AddBlacklist(ctx, "910987654321", "spam"); err != nil {
+		t.Fatalf("AddBlacklist() error: %v", err)
+	}
+	// The caller's context ending must not abort the notification.
+	cancel()
+	close(release)
+	notifier.Wait()
+
+	select {
+	case got := <-delivered:
+		if got != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", got)
+		}
+	default:
+		t.Fatal("expected notification to be delivered after AddBlacklist returned")
+	}
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Broadcast delivery statuses.
+const (
+	BroadcastPending = "pending"
+	BroadcastSent    = "sent"
+	BroadcastFailed  = "failed"
+)
+
+// Broadcast is a templated message sent by operators to many recipients.
+// Template is Go text/template syntax rendered with each recipient's Vars.
+type Broadcast struct {
+	ID        string    `json:"id"`
+	Template  string    `json:"template"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BroadcastDelivery tracks one recipient of a broadcast.
+type BroadcastDelivery struct {
+	BroadcastID string            `json:"broadcast_id"`
+	JID         string            `json:"jid"`
+	Vars        map[string]string `json:"vars,omitempty"`
+	Status      string            `json:"status"`
+	// MessageID is the WhatsApp ID of the sent message.
+	MessageID string    `json:"message_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func broadcastPath(id string) string {
+	return "broadcasts/" + id + "/message"
+}
+
+func broadcastRecipientsPrefix(id string) string {
+	return "broadcasts/" + id + "/recipients/"
+}
+
+// CreateBroadcast stores b and a pending delivery for each recipient.
+func (s *Store) CreateBroadcast(ctx context.Context, b Broadcast, recipients []BroadcastDelivery) error {
+	content, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to encode broadcast %s: %w", b.ID, err)
+	}
+	metadata := map[string]interface{}{
+		"recipients": len(recipients),
+		"mime_type":  "application/json",
+	}
+	if err := s.PutFile(ctx, broadcastPath(b.ID), metadata, content, b.CreatedAt); err != nil {
+		return fmt.Errorf("failed to store broadcast %s: %w", b.ID, err)
+	}
+	for _, d := range recipients {
+		d.BroadcastID = b.ID
+		d.Status = BroadcastPending
+		d.UpdatedAt = b.CreatedAt
+		if err := s.PutBroadcastDelivery(ctx, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBroadcast returns the broadcast id, or nil if none exists.
+func (s *Store) GetBroadcast(ctx context.Context, id string) (*Broadcast, error) {
+	file, err := s.GetFile(ctx, broadcastPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast %s: %w", id, err)
+	}
+	if file == nil {
+		return nil, nil
+	}
+	var b Broadcast
+	if err := json.Unmarshal(file.Content, &b); err != nil {
+		return nil, fmt.Errorf("failed to decode broadcast %s: %w", id, err)
+	}
+	return &b, nil
+}
+
+// PutBroadcastDelivery records d's status, replacing the recipient's
+// earlier one.
+func (s *Store) PutBroadcastDelivery(ctx context.Context, d BroadcastDelivery) error {
+	content, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("failed to encode delivery of broadcast %s to %s: %w", d.BroadcastID, d.JID, err)
+	}
+	metadata := map[string]interface{}{
+		"status":    d.Status,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, broadcastRecipientsPrefix(d.BroadcastID)+d.JID, metadata, content, d.UpdatedAt)
+}
+
+// ListBroadcastDeliveries returns every recipient of broadcast id, in JID
+// order.
+func (s *Store) ListBroadcastDeliveries(ctx context.Context, id string) ([]BroadcastDelivery, error) {
+	var deliveries []BroadcastDelivery
+	err := s.EachFile(ctx, broadcastRecipientsPrefix(id), func(file FileEntry) error {
+		var d BroadcastDelivery
+		if err := json.Unmarshal(file.Content, &d); err != nil {
+			return fmt.Errorf("failed to decode delivery %s: %w", file.Path, err)
+		}
+		deliveries = append(deliveries, d)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries of broadcast %s: %w", id, err)
+	}
+	return deliveries, nil
+}
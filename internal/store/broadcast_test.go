@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func (f *fakeFilesBackend) EachFile(_ context.Context, prefix string, fn func(FileEntry) error) error {
+	var paths []string
+	for path := range f.files {
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := fn(*f.files[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestBroadcastDeliveries(t *testing.T) {
+	ctx := context.Background()
+	s := &Store{backend: &fakeFilesBackend{files: make(map[string]*FileEntry)}}
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	b := Broadcast{ID: "b1", Template: "Hi {{.name}}", CreatedAt: created}
+	recipients := []BroadcastDelivery{
+		{JID: "919822222222", Vars: map[string]string{"name": "Ravi"}},
+		{JID: "919811111111", Vars: map[string]string{"name": "Asha"}},
+	}
+	if err := s.CreateBroadcast(ctx, b, recipients); err != nil {
+		t.Fatalf("CreateBroadcast() error: %v", err)
+	}
+
+	got, err := s.GetBroadcast(ctx, "b1")
+	if err != nil || got == nil || got.Template != b.Template {
+		t.Fatalf("GetBroadcast() = %+v, %v", got, err)
+	}
+	if missing, err := s.GetBroadcast(ctx, "b2"); missing != nil || err != nil {
+		t.Errorf("GetBroadcast(unknown) = %+v, %v; want nil", missing, err)
+	}
+
+	if err := s.PutBroadcastDelivery(ctx, BroadcastDelivery{BroadcastID: "b1", JID: "919811111111", Status: BroadcastSent, MessageID: "m1", UpdatedAt: created.Add(time.Second)}); err != nil {
+		t.Fatalf("PutBroadcastDelivery() error: %v", err)
+	}
+
+	deliveries, err := s.ListBroadcastDeliveries(ctx, "b1")
+	if err != nil {
+		t.Fatalf("ListBroadcastDeliveries() error: %v", err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("deliveries = %+v, want 2", deliveries)
+	}
+	if d := deliveries[0]; d.JID != "919811111111" || d.Status != BroadcastSent || d.MessageID != "m1" {
+		t.Errorf("first delivery = %+v", d)
+	}
+	if d := deliveries[1]; d.Status != BroadcastPending || d.BroadcastID != "b1" || d.Vars["name"] != "Ravi" {
+		t.Errorf("second delivery = %+v", d)
+	}
+}
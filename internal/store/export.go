@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/verification"
+)
+
+// Export formats understood by ExportTable.
+const (
+	ExportJSON = "json"
+	ExportCSV  = "csv"
+)
+
+// Tables understood by ExportTable.
+const (
+	ExportTableBlacklist     = "blacklist"
+	ExportTableVerifications = "verifications"
+)
+
+// ExportTables lists the exportable tables.
+var ExportTables = []string{ExportTableBlacklist, ExportTableVerifications}
+
+// exportWriter writes one table as a JSON array or CSV with a header row,
+// a record at a time.
+type exportWriter struct {
+	format string
+	w      io.Writer
+	csv    *csv.Writer
+	rows   int
+}
+
+func newExportWriter(w io.Writer, format string, header []string) (*exportWriter, error) {
+	ew := &exportWriter{format: format, w: w}
+	switch format {
+	case ExportJSON:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return nil, err
+		}
+	case ExportCSV:
+		ew.csv = csv.NewWriter(w)
+		if err := ew.csv.Write(header); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown export format %q (want %s or %s)", format, ExportJSON, ExportCSV)
+	}
+	return ew, nil
+}
+
+// write emits one record: v as a JSON object, or fields as a CSV row.
+func (ew *exportWriter) write(v any, fields []string) error {
+	ew.rows++
+	if ew.csv != nil {
+		return ew.csv.Write(fields)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode record: %w", err)
+	}
+	sep := ",\n"
+	if ew.rows == 1 {
+		sep = "\n"
+	}
+	if _, err := io.WriteString(ew.w, sep); err != nil {
+		return err
+	}
+	_, err = ew.w.Write(data)
+	return err
+}
+
+func (ew *exportWriter) close() error {
+	if ew.csv != nil {
+		ew.csv.Flush()
+		return ew.csv.Error()
+	}
+	end := "\n]\n"
+	if ew.rows == 0 {
+		end = "]\n"
+	}
+	_, err := io.WriteString(ew.w, end)
+	return err
+}
+
+// ExportTable streams table to w in format. Records are read and written
+// one at a time, so large tables are never held in memory.
+func (s *Store) ExportTable(ctx context.Context, w io.Writer, table, format string) error {
+	switch table {
+	case ExportTableBlacklist:
+		return s.ExportBlacklist(ctx, w, format)
+	case ExportTableVerifications:
+		return s.ExportVerifications(ctx, w, format)
+	default:
+		return fmt.Errorf("unknown export table %q (want one of %s)", table, strings.Join(ExportTables, ", "))
+	}
+}
+
+// ExportBlacklist writes every blacklisted number, oldest first.
+func (s *Store) ExportBlacklist(ctx context.Context, w io.Writer, format string) error {
+	ew, err := newExportWriter(w, format, []string{"phone", "reason", "created_at"})
+	if err != nil {
+		return err
+	}
+	err = s.backend.EachBlacklist(ctx, func(n BlacklistedNumber) error {
+		return ew.write(n, []string{n.Phone, n.Reason, n.CreatedAt.UTC().Format(time.RFC3339)})
+	})
+	if err != nil {
+		return fmt.Errorf("export blacklist: %w", err)
+	}
+	return ew.close()
+}
+
+// ExportVerifications writes the pending verifications recorded under
+// verifications/pending/.
+func (s *Store) ExportVerifications(ctx context.Context, w io.Writer, format string) error {
+	ew, err := newExportWriter(w, format, []string{"phone", "app_name", "challenge_id", "expires_at"})
+	if err != nil {
+		return err
+	}
+	err = s.backend.EachFile(ctx, pendingVerificationPath(""), func(f FileEntry) error {
+		var p verification.PendingVerification
+		if err := json.Unmarshal(f.Content, &p); err != nil {
+			return fmt.Errorf("decode %s: %w", f.Path, err)
+		}
+		return ew.write(p, []string{p.Phone, p.AppName, p.ChallengeID, p.ExpiresAt.UTC().Format(time.RFC3339)})
+	})
+	if err != nil {
+		return fmt.Errorf("export verifications: %w", err)
+	}
+	return ew.close()
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/verification"
+)
+
+type fakeExportBackend struct {
+	storeBackend
+	blacklist []BlacklistedNumber
+	files     []FileEntry
+}
+
+func (f *fakeExportBackend) EachBlacklist(_ context.Context, fn func(BlacklistedNumber) error) error {
+	for _, n := range f.blacklist {
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeExportBackend) EachFile(_ context.Context, prefix string, fn func(FileEntry) error) error {
+	for _, e := range f.files {
+		if !strings.HasPrefix(e.Path, prefix) {
+			continue
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExportBlacklist(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := &Store{backend: &fakeExportBackend{blacklist: []BlacklistedNumber{
+		{Phone: "919876543210", Reason: "spam", CreatedAt: created},
+		{Phone: "910000000000", Reason: `says "hi", a lot`, CreatedAt: created.Add(time.Hour)},
+	}}}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: ExportCSV,
+			want: "phone,reason,created_at\n" +
+				"919876543210,spam,2026-01-02T03:04:05Z\n" +
+				"910000000000,\"says \"\"hi\"\", a lot\",2026-01-02T04:04:05Z\n",
+		},
+		{
+			format: ExportJSON,
+			want: "[\n" +
+				`{"phone":"919876543210","reason":"spam","created_at":"2026-01-02T03:04:05Z"},` + "\n" +
+				`{"phone":"910000000000","reason":"says \"hi\", a lot","created_at":"2026-01-02T04:04:05Z"}` + "\n]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := s.ExportTable(context.Background(), &buf, ExportTableBlacklist, tt.format); err != nil {
+				t.Fatalf("ExportTable() error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExportEmptyTable(t *testing.T) {
+	s := &Store{backend: &fakeExportBackend{}}
+	tests := []struct {
+		table, format, want string
+	}{
+		{ExportTableBlacklist, ExportCSV, "phone,reason,created_at\n"},
+		{ExportTableBlacklist, ExportJSON, "[]\n"},
+		{ExportTableVerifications, ExportCSV, "phone,app_name,challenge_id,expires_at\n"},
+		{ExportTableVerifications, ExportJSON, "[]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table+"/"+tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := s.ExportTable(context.Background(), &buf, tt.table, tt.format); err != nil {
+				t.Fatalf("ExportTable() error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+			if tt.format == ExportJSON {
+				var v []any
+				if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+					t.Errorf("output is not valid JSON: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestExportVerifications(t *testing.T) {
+	p := verification.PendingVerification{
+		Phone:       "919876543210",
+		AppName:     "billing",
+		ChallengeID: "abc-123",
+		ExpiresAt:   time.Date(2026, 1, 2, 3, 14, 5, 0, time.UTC),
+	}
+	content, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Store{backend: &fakeExportBackend{files: []FileEntry{
+		{Path: "summaries/919876543210", Content: []byte("not a verification")},
+		{Path: "verifications/pending/919876543210", Content: content},
+	}}}
+
+	var buf bytes.Buffer
+	if err := s.ExportVerifications(context.Background(), &buf, ExportCSV); err != nil {
+		t.Fatalf("ExportVerifications() error: %v", err)
+	}
+	want := "phone,app_name,challenge_id,expires_at\n919876543210,billing,abc-123,2026-01-02T03:14:05Z\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestExportRejectsUnknownTableAndFormat(t *testing.T) {
+	s := &Store{backend: &fakeExportBackend{}}
+	var buf bytes.Buffer
+	if err := s.ExportTable(context.Background(), &buf, "contacts", ExportCSV); err == nil {
+		t.Error("expected error for unknown table")
+	}
+	if err := s.ExportTable(context.Background(), &buf, ExportTableBlacklist, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FormField is one value an agent-declared form collects.
+type FormField struct {
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
+}
+
+// FormState is a user's in-progress form: the fields still to ask for and
+// the answers collected so far.
+type FormState struct {
+	ID        string            `json:"id"`
+	Fields    []FormField       `json:"fields"`
+	Values    map[string]string `json:"values"`
+	Next      int               `json:"next"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+func formPath(phone string) string {
+	return "forms/" + phone
+}
+
+// PutForm stores the form in progress for phone, replacing any earlier one.
+func (s *Store) PutForm(ctx context.Context, phone string, f FormState) error {
+	content, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to encode form for %s: %w", phone, err)
+	}
+	metadata := map[string]interface{}{
+		"form_id":   f.ID,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, formPath(phone), metadata, content, time.Now().UTC())
+}
+
+// GetForm returns the form in progress for phone, or nil if none exists.
+// Expiry is left to the caller.
+func (s *Store) GetForm(ctx context.Context, phone string) (*FormState, error) {
+	file, err := s.GetFile(ctx, formPath(phone))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get form for %s: %w", phone, err)
+	}
+	if file == nil {
+		return nil, nil
+	}
+
+	var f FormState
+	if err := json.Unmarshal(file.Content, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode form for %s: %w", phone, err)
+	}
+	return &f, nil
+}
+
+// DeleteForm removes the form in progress for phone.
+func (s *Store) DeleteForm(ctx context.Context, phone string) error {
+	return s.DeleteFile(ctx, formPath(phone))
+}
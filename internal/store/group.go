@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// AllowedGroup is a group chat the agent was enabled for at runtime.
+type AllowedGroup struct {
+	JID       string    `json:"jid"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsGroupAllowed reports whether jid was added with AddAllowedGroup.
+func (s *Store) IsGroupAllowed(ctx context.Context, jid string) (bool, error) {
+	return s.backend.IsGroupAllowed(ctx, jid)
+}
+
+// AddAllowedGroup lets the agent take part in the group jid, in addition
+// to those listed in whatsapp.groups.allowed. Adding a group twice is not
+// an error.
+func (s *Store) AddAllowedGroup(ctx context.Context, jid string) error {
+	return s.backend.AddAllowedGroup(ctx, jid)
+}
+
+// RemoveAllowedGroup undoes AddAllowedGroup. Groups listed in the config
+// stay allowed.
+func (s *Store) RemoveAllowedGroup(ctx context.Context, jid string) error {
+	return s.backend.RemoveAllowedGroup(ctx, jid)
+}
+
+// ListAllowedGroups returns the groups added at runtime, newest first.
+func (s *Store) ListAllowedGroups(ctx context.Context) ([]AllowedGroup, error) {
+	return s.backend.ListAllowedGroups(ctx)
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// migrationLockKey is the Postgres advisory lock taken around schema
+// migration, so replicas starting together apply DDL one at a time.
+const migrationLockKey int64 = 0x77686174736164 // "whatsad"
+
+// SchemaVersion is the Postgres schema version this binary expects. Bump
+// it together with any migration step that changes existing tables.
+const SchemaVersion = 1
+
+// Schema upgrade policies for a database older than SchemaVersion.
+const (
+	// SchemaUpgradeAuto migrates the database forward at Open.
+	SchemaUpgradeAuto = "auto"
+	// SchemaUpgradeRefuse fails Open instead, for deployments that run
+	// migrations separately.
+	SchemaUpgradeRefuse = "refuse"
+)
+
+// ErrSchemaVersion is returned by Open when the database schema version is
+// incompatible with this binary. It is never retried.
+var ErrSchemaVersion = errors.New("incompatible store schema version")
+
+// checkSchemaVersion reports whether a database at version current (0 when
+// it predates versioning or is empty) may be used by a binary expecting
+// want. A newer database is always refused, since this binary cannot know
+// what changed; an older one only under SchemaUpgradeRefuse.
+func checkSchemaVersion(current, want int, policy string) error {
+	switch {
+	case current > want:
+		return fmt.Errorf("%w: database is at version %d but this binary supports up to %d; upgrade the gateway", ErrSchemaVersion, current, want)
+	case current > 0 && current < want && policy == SchemaUpgradeRefuse:
+		return fmt.Errorf("%w: database is at version %d, this binary needs %d; migrate it or set store.schema_upgrade: %s", ErrSchemaVersion, current, want, SchemaUpgradeAuto)
+	}
+	return nil
+}
+
+// Options tunes Open.
+type Options struct {
+	// MigrateAttempts is how many times a failing migration is tried
+	// (minimum 1).
+	MigrateAttempts int
+	// MigrateBackoff is the wait before the second attempt; it doubles for
+	// each later attempt.
+	MigrateBackoff time.Duration
+	// ReadDSN optionally names a Postgres read replica used for blacklist
+	// reads. Writes always go to the primary.
+	ReadDSN string
+	// ReplicaLag is how long after a blacklist write reads affected by it
+	// stay on the primary.
+	ReplicaLag time.Duration
+	// SchemaUpgrade is SchemaUpgradeAuto (default when empty) or
+	// SchemaUpgradeRefuse.
+	SchemaUpgrade string
+}
+
+// DefaultOptions returns the options used by Open.
+func DefaultOptions() Options {
+	return Options{MigrateAttempts: 5, MigrateBackoff: time.Second, ReplicaLag: 5 * time.Second, SchemaUpgrade: SchemaUpgradeAuto}
+}
+
+// retryMigrate runs migrate until it succeeds, attempts are exhausted or
+// ctx ends, doubling the wait between attempts. Schema version mismatches
+// fail immediately.
+func retryMigrate(ctx context.Context, attempts int, backoff time.Duration, migrate func(context.Context) error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			slog.Warn("store migration failed, retrying", "attempt", i, "of", attempts, "backoff", backoff, "error", err)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		if err = migrate(ctx); err == nil {
+			return nil
+		}
+		if errors.Is(err, ErrSchemaVersion) {
+			return err
+		}
+	}
+	return fmt.Errorf("after %d attempts: %w", attempts, err)
+}
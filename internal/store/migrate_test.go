@@ -0,0 +1,172 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryMigrateTransientFailure(t *testing.T) {
+	calls := 0
+	err := retryMigrate(context.Background(), 5, time.Millisecond, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("could not obtain lock")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryMigrate() error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetryMigrateGivesUp(t *testing.T) {
+	calls := 0
+	boom := errors.New("syntax error")
+	err := retryMigrate(context.Background(), 2, time.Millisecond, func(context.Context) error {
+		calls++
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("error = %v, want wrapped last failure", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestRetryMigrateStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := retryMigrate(ctx, 5, time.Hour, func(context.Context) error {
+		calls++
+		cancel()
+		return errors.New("timeout")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestConcurrentMigrate(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	// Simulates replicas starting together; each uses its own connection.
+	s := &sqlStore{db: db}
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.migrate(context.Background(), SchemaUpgradeAuto)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent migrate failed: %v", err)
+		}
+	}
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		policy  string
+		wantErr bool
+	}{
+		{"matching version", 3, SchemaUpgradeAuto, false},
+		{"matching version strict", 3, SchemaUpgradeRefuse, false},
+		{"unversioned database", 0, SchemaUpgradeRefuse, false},
+		{"older database migrated", 2, SchemaUpgradeAuto, false},
+		{"older database refused", 2, SchemaUpgradeRefuse, true},
+		{"newer database", 4, SchemaUpgradeAuto, true},
+		{"newer database strict", 4, SchemaUpgradeRefuse, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSchemaVersion(tt.current, 3, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkSchemaVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrSchemaVersion) {
+				t.Errorf("error = %v, want ErrSchemaVersion", err)
+			}
+		})
+	}
+}
+
+func TestRetryMigrateSchemaVersionNotRetried(t *testing.T) {
+	calls := 0
+	err := retryMigrate(context.Background(), 5, time.Millisecond, func(context.Context) error {
+		calls++
+		return checkSchemaVersion(SchemaVersion+1, SchemaVersion, SchemaUpgradeAuto)
+	})
+	if !errors.Is(err, ErrSchemaVersion) {
+		t.Errorf("error = %v, want ErrSchemaVersion", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestMigrateSchemaVersion(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	s := &sqlStore{db: db}
+
+	if err := s.migrate(ctx, SchemaUpgradeRefuse); err != nil {
+		t.Fatalf("migrate() error: %v", err)
+	}
+	var version int
+	if err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM store_schema`).Scan(&version); err != nil {
+		t.Fatalf("read version: %v", err)
+	}
+	if version != SchemaVersion {
+		t.Errorf("stamped version = %d, want %d", version, SchemaVersion)
+	}
+	if err := s.migrate(ctx, SchemaUpgradeRefuse); err != nil {
+		t.Fatalf("migrate() at matching version error: %v", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `UPDATE store_schema SET version = $1`, SchemaVersion+1); err != nil {
+		t.Fatalf("bump version: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.ExecContext(ctx, `UPDATE store_schema SET version = $1`, SchemaVersion); err != nil {
+			t.Errorf("restore version: %v", err)
+		}
+	})
+	if err := s.migrate(ctx, SchemaUpgradeAuto); !errors.Is(err, ErrSchemaVersion) {
+		t.Errorf("migrate() against newer schema error = %v, want ErrSchemaVersion", err)
+	}
+}
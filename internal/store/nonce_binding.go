@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/auth"
+)
+
+const nonceBindingPrefix = "oauth/nonces/"
+
+func nonceBindingPath(nonce string) string {
+	return nonceBindingPrefix + nonce
+}
+
+// PutNonceBinding stores an OAuth nonce binding in the filesys table.
+func (s *Store) PutNonceBinding(ctx context.Context, b auth.NonceBinding) error {
+	content, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to encode nonce binding: %w", err)
+	}
+	metadata := map[string]interface{}{
+		"phone":     b.Phone,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, nonceBindingPath(b.Nonce), metadata, content, time.Now().UTC())
+}
+
+// GetNonceBinding returns the binding for nonce, or nil if none exists.
+func (s *Store) GetNonceBinding(ctx context.Context, nonce string) (*auth.NonceBinding, error) {
+	file, err := s.GetFile(ctx, nonceBindingPath(nonce))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce binding: %w", err)
+	}
+	if file == nil {
+		return nil, nil
+	}
+
+	var b auth.NonceBinding
+	if err := json.Unmarshal(file.Content, &b); err != nil {
+		return nil, fmt.Errorf("failed to decode nonce binding: %w", err)
+	}
+	return &b, nil
+}
+
+// DeleteNonceBinding removes the binding for nonce.
+func (s *Store) DeleteNonceBinding(ctx context.Context, nonce string) error {
+	return s.DeleteFile(ctx, nonceBindingPath(nonce))
+}
+
+// PruneNonceBindings deletes bindings written before cutoff. Stale paths
+// are collected first so the deletes don't disturb the listing.
+func (s *Store) PruneNonceBindings(ctx context.Context, cutoff time.Time) (int, error) {
+	var stale []string
+	err := s.EachFile(ctx, nonceBindingPrefix, func(f FileEntry) error {
+		if f.Timestamp.Before(cutoff) {
+			stale = append(stale, f.Path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list nonce bindings: %w", err)
+	}
+	removed := 0
+	for _, path := range stale {
+		if err := s.DeleteFile(ctx, path); err != nil {
+			return removed, fmt.Errorf("failed to delete nonce binding %s: %w", path, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/auth"
+)
+
+func TestPruneNonceBindings(t *testing.T) {
+	ctx := context.Background()
+	backend := &fakeFilesBackend{files: make(map[string]*FileEntry)}
+	s := &Store{backend: backend}
+
+	if err := s.PutNonceBinding(ctx, auth.NonceBinding{Nonce: "fresh", Phone: "919876543210"}); err != nil {
+		t.Fatalf("PutNonceBinding: %v", err)
+	}
+	backend.files[nonceBindingPath("stale")] = &FileEntry{Path: nonceBindingPath("stale"), Timestamp: time.Now().Add(-2 * time.Hour)}
+	backend.files["profiles/919876543210"] = &FileEntry{Path: "profiles/919876543210", Timestamp: time.Now().Add(-2 * time.Hour)}
+
+	removed, err := s.PruneNonceBindings(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("PruneNonceBindings: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	if _, ok := backend.files[nonceBindingPath("fresh")]; !ok {
+		t.Error("fresh binding was pruned")
+	}
+	if _, ok := backend.files["profiles/919876543210"]; !ok {
+		t.Error("non-binding file was pruned")
+	}
+}
+
+func TestPruneNonceBindingsBeyondOneBatch(t *testing.T) {
+	ctx := context.Background()
+	backend := &fakeFilesBackend{files: make(map[string]*FileEntry)}
+	s := &Store{backend: backend}
+
+	const n = 2500
+	old := time.Now().Add(-2 * time.Hour)
+	for i := 0; i < n; i++ {
+		path := nonceBindingPath(fmt.Sprintf("nonce-%04d", i))
+		backend.files[path] = &FileEntry{Path: path, Timestamp: old}
+	}
+
+	removed, err := s.PruneNonceBindings(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("PruneNonceBindings: %v", err)
+	}
+	if removed != n || len(backend.files) != 0 {
+		t.Errorf("removed = %d, left %d, want all %d removed", removed, len(backend.files), n)
+	}
+}
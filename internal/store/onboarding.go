@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func onboardingPath(phone string) string {
+	return fmt.Sprintf("onboarding/%s", phone)
+}
+
+// OnboardingNudged reports whether the onboarding nudge was already sent
+// to phone.
+func (s *Store) OnboardingNudged(ctx context.Context, phone string) (bool, error) {
+	file, err := s.GetFile(ctx, onboardingPath(phone))
+	if err != nil {
+		return false, fmt.Errorf("failed to check onboarding for %s: %w", phone, err)
+	}
+	return file != nil, nil
+}
+
+// MarkOnboardingNudged records that the onboarding nudge was sent to phone.
+func (s *Store) MarkOnboardingNudged(ctx context.Context, phone string) error {
+	metadata := map[string]interface{}{
+		"phone": phone,
+	}
+	if err := s.PutFile(ctx, onboardingPath(phone), metadata, nil, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record onboarding for %s: %w", phone, err)
+	}
+	return nil
+}
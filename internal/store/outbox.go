@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OutboxMessage is an outbound text waiting in the send queue, persisted so
+// it can be resent after a restart.
+type OutboxMessage struct {
+	ID          string `json:"id"`
+	Chat        string `json:"chat"`
+	Phone       string `json:"phone"`
+	UniqueID    string `json:"unique_id"`
+	Text        string `json:"text"`
+	ContextType string `json:"context_type"`
+	MsgRef      string `json:"msg_ref"`
+	// GroupID is the group JID of the conversation an agent reply answers,
+	// so its delivery is confirmed in that session; empty for direct chats.
+	GroupID string `json:"group_id,omitempty"`
+	// BusinessAgent marks a reply produced by the business-account agent
+	// app, whose client confirms its delivery.
+	BusinessAgent bool      `json:"business_agent,omitempty"`
+	QueuedAt      time.Time `json:"queued_at"`
+	// Attempted is set just before the send, so a message whose send may
+	// have reached WhatsApp is never sent twice.
+	Attempted bool `json:"attempted"`
+}
+
+const outboxPrefix = "outbox/"
+
+func outboxPath(id string) string {
+	return outboxPrefix + id
+}
+
+// PutOutbox stores m, replacing any earlier copy with the same ID.
+func (s *Store) PutOutbox(ctx context.Context, m OutboxMessage) error {
+	content, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox message %s: %w", m.ID, err)
+	}
+	metadata := map[string]interface{}{
+		"phone":     m.Phone,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, outboxPath(m.ID), metadata, content, m.QueuedAt)
+}
+
+// ListOutbox returns the persisted outbox, oldest first.
+func (s *Store) ListOutbox(ctx context.Context) ([]OutboxMessage, error) {
+	var msgs []OutboxMessage
+	err := s.EachFile(ctx, outboxPrefix, func(file FileEntry) error {
+		var m OutboxMessage
+		if err := json.Unmarshal(file.Content, &m); err != nil {
+			return fmt.Errorf("failed to decode outbox message %s: %w", strings.TrimPrefix(file.Path, outboxPrefix), err)
+		}
+		msgs = append(msgs, m)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox: %w", err)
+	}
+	sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].QueuedAt.Before(msgs[j].QueuedAt) })
+	return msgs, nil
+}
+
+// DeleteOutbox removes the message with id from the outbox.
+func (s *Store) DeleteOutbox(ctx context.Context, id string) error {
+	return s.DeleteFile(ctx, outboxPath(id))
+}
+
+// TakeOutbox removes the message with id from the outbox and reports
+// whether it was still there. Gateways sharing the store use it to claim a
+// message before sending it, so only one of them does.
+func (s *Store) TakeOutbox(ctx context.Context, id string) (bool, error) {
+	return s.TakeFile(ctx, outboxPath(id))
+}
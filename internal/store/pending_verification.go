@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/verification"
+)
+
+func pendingVerificationPath(phone string) string {
+	return "verifications/pending/" + phone
+}
+
+// PutPendingVerification stores the pending verification for a phone in
+// the filesys table, replacing any earlier one.
+func (s *Store) PutPendingVerification(ctx context.Context, p verification.PendingVerification) error {
+	content, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode pending verification: %w", err)
+	}
+	metadata := map[string]interface{}{
+		"app_name":  p.AppName,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, pendingVerificationPath(p.Phone), metadata, content, time.Now().UTC())
+}
+
+// GetPendingVerification returns the pending verification for phone, or
+// nil if none exists. Expiry is left to the caller.
+func (s *Store) GetPendingVerification(ctx context.Context, phone string) (*verification.PendingVerification, error) {
+	file, err := s.GetFile(ctx, pendingVerificationPath(phone))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending verification: %w", err)
+	}
+	if file == nil {
+		return nil, nil
+	}
+
+	var p verification.PendingVerification
+	if err := json.Unmarshal(file.Content, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode pending verification: %w", err)
+	}
+	return &p, nil
+}
+
+// DeletePendingVerification removes the pending verification for phone.
+func (s *Store) DeletePendingVerification(ctx context.Context, phone string) error {
+	return s.DeleteFile(ctx, pendingVerificationPath(phone))
+}
+
+// ListPendingVerifications returns every recorded pending verification, in
+// phone order, expired ones included.
+func (s *Store) ListPendingVerifications(ctx context.Context) ([]verification.PendingVerification, error) {
+	var pending []verification.PendingVerification
+	err := s.EachFile(ctx, pendingVerificationPath(""), func(file FileEntry) error {
+		var p verification.PendingVerification
+		if err := json.Unmarshal(file.Content, &p); err != nil {
+			return fmt.Errorf("failed to decode pending verification %s: %w", file.Path, err)
+		}
+		pending = append(pending, p)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending verifications: %w", err)
+	}
+	return pending, nil
+}
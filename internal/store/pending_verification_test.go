@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/verification"
+)
+
+func TestPendingVerificationRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := &Store{backend: &fakeFilesBackend{files: make(map[string]*FileEntry)}}
+
+	if got, err := s.GetPendingVerification(ctx, "919876543210"); err != nil || got != nil {
+		t.Fatalf("GetPendingVerification on empty store = %v, %v, want nil", got, err)
+	}
+
+	want := verification.PendingVerification{
+		Phone:       "919876543210",
+		AppName:     "app-a",
+		ChallengeID: "c1",
+		ExpiresAt:   time.Unix(1_700_000_000, 0).UTC(),
+	}
+	if err := s.PutPendingVerification(ctx, want); err != nil {
+		t.Fatalf("PutPendingVerification: %v", err)
+	}
+	got, err := s.GetPendingVerification(ctx, "919876543210")
+	if err != nil {
+		t.Fatalf("GetPendingVerification: %v", err)
+	}
+	if got == nil || *got != want {
+		t.Errorf("GetPendingVerification = %+v, want %+v", got, want)
+	}
+
+	list, err := s.ListPendingVerifications(ctx)
+	if err != nil || len(list) != 1 || list[0] != want {
+		t.Errorf("ListPendingVerifications = %+v, %v, want [%+v]", list, err, want)
+	}
+
+	if err := s.DeletePendingVerification(ctx, "919876543210"); err != nil {
+		t.Fatalf("DeletePendingVerification: %v", err)
+	}
+	if got, err := s.GetPendingVerification(ctx, "919876543210"); err != nil || got != nil {
+		t.Errorf("after delete = %v, %v, want nil", got, err)
+	}
+}
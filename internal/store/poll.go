@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Poll is a poll the gateway sent for an agent, kept so votes, which only
+// carry hashes of the chosen options, can be mapped back to option names.
+type Poll struct {
+	ID        string    `json:"id"`
+	Phone     string    `json:"phone"`
+	Name      string    `json:"name"`
+	Options   []string  `json:"options"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func pollPath(id string) string {
+	return "polls/" + id
+}
+
+// PutPoll stores p under its WhatsApp message ID.
+func (s *Store) PutPoll(ctx context.Context, p Poll) error {
+	content, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode poll %s: %w", p.ID, err)
+	}
+	metadata := map[string]interface{}{
+		"phone":     p.Phone,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, pollPath(p.ID), metadata, content, p.CreatedAt)
+}
+
+// GetPoll returns the poll sent as message id, or nil if none was stored.
+func (s *Store) GetPoll(ctx context.Context, id string) (*Poll, error) {
+	file, err := s.GetFile(ctx, pollPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll %s: %w", id, err)
+	}
+	if file == nil {
+		return nil, nil
+	}
+
+	var p Poll
+	if err := json.Unmarshal(file.Content, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode poll %s: %w", id, err)
+	}
+	return &p, nil
+}
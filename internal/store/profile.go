@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// UserProfile is a free-form set of attributes stored for a phone number
+// (e.g. name, tier, last ticket id).
+type UserProfile map[string]any
+
+func profilePath(phone string) string {
+	return fmt.Sprintf("profiles/%s", phone)
+}
+
+// UserProfile returns the stored profile for phone, or nil if none exists.
+func (s *Store) UserProfile(ctx context.Context, phone string) (UserProfile, error) {
+	file, err := s.GetFile(ctx, profilePath(phone))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile for %s: %w", phone, err)
+	}
+	if file == nil || len(file.Content) == 0 {
+		return nil, nil
+	}
+
+	var profile UserProfile
+	if err := json.Unmarshal(file.Content, &profile); err != nil {
+		return nil, fmt.Errorf("failed to decode profile for %s: %w", phone, err)
+	}
+	return profile, nil
+}
+
+// PutUserProfile stores profile for phone, replacing any existing one.
+func (s *Store) PutUserProfile(ctx context.Context, phone string, profile UserProfile) error {
+	content, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile for %s: %w", phone, err)
+	}
+	metadata := map[string]interface{}{
+		"phone":     phone,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, profilePath(phone), metadata, content, time.Now().UTC())
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Reaction is a user's latest emoji reaction to a message. An empty Emoji
+// means the reaction was removed.
+type Reaction struct {
+	Phone     string `json:"phone"`
+	MessageID string `json:"message_id"`
+	Emoji     string `json:"emoji"`
+	// OnReply is set when the reacted-to message was sent by the gateway.
+	OnReply   bool      `json:"on_reply"`
+	ReactedAt time.Time `json:"reacted_at"`
+}
+
+func reactionPath(phone, messageID string) string {
+	return "reactions/" + phone + "/" + messageID
+}
+
+// PutReaction stores r, replacing the user's earlier reaction to the same
+// message.
+func (s *Store) PutReaction(ctx context.Context, r Reaction) error {
+	content, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode reaction from %s: %w", r.Phone, err)
+	}
+	metadata := map[string]interface{}{
+		"emoji":     r.Emoji,
+		"on_reply":  r.OnReply,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, reactionPath(r.Phone, r.MessageID), metadata, content, r.ReactedAt)
+}
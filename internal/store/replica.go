@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// readYourWrites remembers recent blacklist writes so reads that could
+// observe them are sent to the primary until the replica has caught up.
+type readYourWrites struct {
+	lag time.Duration
+	now func() time.Time
+
+	mu     sync.Mutex
+	phones map[string]time.Time
+	last   time.Time
+}
+
+func newReadYourWrites(lag time.Duration) *readYourWrites {
+	return &readYourWrites{lag: lag, now: time.Now, phones: make(map[string]time.Time)}
+}
+
+// wrote records a write to phone.
+func (r *readYourWrites) wrote(phone string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	r.last = now
+	r.phones[phone] = now
+	for p, at := range r.phones {
+		if now.Sub(at) >= r.lag {
+			delete(r.phones, p)
+		}
+	}
+}
+
+// phoneFresh reports whether phone was written within the lag window.
+func (r *readYourWrites) phoneFresh(phone string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	at, ok := r.phones[phone]
+	return ok && r.now().Sub(at) < r.lag
+}
+
+// anyFresh reports whether any phone was written within the lag window.
+func (r *readYourWrites) anyFresh() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.last.IsZero() && r.now().Sub(r.last) < r.lag
+}
+
+// readBlacklistBackend returns the backend a blacklist lookup for phone
+// should use: the replica, unless phone was changed too recently for the
+// replica to reflect it.
+func (s *Store) readBlacklistBackend(phone string) storeBackend {
+	if s.replica == nil || s.writes.phoneFresh(phone) {
+		return s.backend
+	}
+	return s.replica
+}
+
+// listBlacklistBackend is readBlacklistBackend for whole-table reads, which
+// stay on the primary after any recent write.
+func (s *Store) listBlacklistBackend() storeBackend {
+	if s.replica == nil || s.writes.anyFresh() {
+		return s.backend
+	}
+	return s.replica
+}
+
+// PrimaryBlacklist answers blacklist lookups from the primary only.
+type PrimaryBlacklist struct {
+	s *Store
+}
+
+// PrimaryBlacklist returns a blacklist checker that bypasses the read
+// replica, for checks that must not act on replication lag (e.g. before
+// issuing a verification callback).
+func (s *Store) PrimaryBlacklist() PrimaryBlacklist {
+	return PrimaryBlacklist{s: s}
+}
+
+func (p PrimaryBlacklist) IsBlacklisted(ctx context.Context, phone string) (bool, error) {
+	return p.s.backend.IsBlacklisted(ctx, phone)
+}
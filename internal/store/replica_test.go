@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeRoutingBackend records which blacklist calls reach it.
+type fakeRoutingBackend struct {
+	storeBackend
+	name  string
+	calls []string
+}
+
+func (f *fakeRoutingBackend) IsBlacklisted(_ context.Context, _ string) (bool, error) {
+	f.calls = append(f.calls, "is")
+	return f.name == "primary", nil
+}
+
+func (f *fakeRoutingBackend) ListBlacklist(_ context.Context) ([]BlacklistedNumber, error) {
+	f.calls = append(f.calls, "list")
+	return nil, nil
+}
+
+func (f *fakeRoutingBackend) AddBlacklist(_ context.Context, _, _ string) error {
+	f.calls = append(f.calls, "add")
+	return nil
+}
+
+func (f *fakeRoutingBackend) RemoveBlacklist(_ context.Context, _ string) error {
+	f.calls = append(f.calls, "remove")
+	return nil
+}
+
+func newRoutingStore(now *time.Time) (*Store, *fakeRoutingBackend, *fakeRoutingBackend) {
+	primary := &fakeRoutingBackend{name: "primary"}
+	replica := &fakeRoutingBackend{name: "replica"}
+	writes := newReadYourWrites(5 * time.Second)
+	writes.now = func() time.Time { return *now }
+	return &Store{backend: primary, replica: replica, writes: writes}, primary, replica
+}
+
+func TestReplicaServesReads(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s, primary, replica := newRoutingStore(&now)
+	ctx := context.Background()
+
+	blocked, err := s.IsBlacklisted(ctx, "911")
+	if err != nil {
+		t.Fatalf("IsBlacklisted() error: %v", err)
+	}
+	if blocked {
+		t.Error("IsBlacklisted() answered by primary, want replica")
+	}
+	if _, err := s.ListBlacklist(ctx); err != nil {
+		t.Fatalf("ListBlacklist() error: %v", err)
+	}
+	if len(primary.calls) != 0 {
+		t.Errorf("primary calls = %v, want none", primary.calls)
+	}
+	if len(replica.calls) != 2 {
+		t.Errorf("replica calls = %v, want is+list", replica.calls)
+	}
+}
+
+func TestWritesGoToPrimaryAndPinReads(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s, primary, replica := newRoutingStore(&now)
+	ctx := context.Background()
+
+	if err := s.AddBlacklist(ctx, "911", "spam"); err != nil {
+		t.Fatalf("AddBlacklist() error: %v", err)
+	}
+	if err := s.RemoveBlacklist(ctx, "922"); err != nil {
+		t.Fatalf("RemoveBlacklist() error: %v", err)
+	}
+	if len(replica.calls) != 0 {
+		t.Fatalf("replica calls = %v, want no writes", replica.calls)
+	}
+
+	// Just-written numbers and listings are read from the primary.
+	for _, phone := range []string{"911", "922"} {
+		blocked, err := s.IsBlacklisted(ctx, phone)
+		if err != nil {
+			t.Fatalf("IsBlacklisted(%s) error: %v", phone, err)
+		}
+		if !blocked {
+			t.Errorf("IsBlacklisted(%s) answered by replica within lag window", phone)
+		}
+	}
+	if _, err := s.ListBlacklist(ctx); err != nil {
+		t.Fatalf("ListBlacklist() error: %v", err)
+	}
+	// Other numbers still use the replica.
+	if _, err := s.IsBlacklisted(ctx, "933"); err != nil {
+		t.Fatalf("IsBlacklisted() error: %v", err)
+	}
+	want := []string{"add", "remove", "is", "is", "list"}
+	if len(primary.calls) != len(want) {
+		t.Fatalf("primary calls = %v, want %v", primary.calls, want)
+	}
+	if len(replica.calls) != 1 {
+		t.Errorf("replica calls = %v, want one lookup", replica.calls)
+	}
+
+	// Once the lag window passes, reads return to the replica.
+	now = now.Add(5 * time.Second)
+	if _, err := s.IsBlacklisted(ctx, "911"); err != nil {
+		t.Fatalf("IsBlacklisted() error: %v", err)
+	}
+	if _, err := s.ListBlacklist(ctx); err != nil {
+		t.Fatalf("ListBlacklist() error: %v", err)
+	}
+	if len(replica.calls) != 3 {
+		t.Errorf("replica calls = %v, want reads after lag window", replica.calls)
+	}
+}
+
+func TestPrimaryBlacklistBypassesReplica(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s, primary, replica := newRoutingStore(&now)
+
+	blocked, err := s.PrimaryBlacklist().IsBlacklisted(context.Background(), "911")
+	if err != nil {
+		t.Fatalf("IsBlacklisted() error: %v", err)
+	}
+	if !blocked || len(primary.calls) != 1 || len(replica.calls) != 0 {
+		t.Errorf("primary=%v replica=%v, want primary only", primary.calls, replica.calls)
+	}
+}
+
+func TestNoReplicaUsesPrimary(t *testing.T) {
+	primary := &fakeRoutingBackend{name: "primary"}
+	s := &Store{backend: primary}
+	ctx := context.Background()
+
+	if err := s.AddBlacklist(ctx, "911", "spam"); err != nil {
+		t.Fatalf("AddBlacklist() error: %v", err)
+	}
+	if _, err := s.IsBlacklisted(ctx, "911"); err != nil {
+		t.Fatalf("IsBlacklisted() error: %v", err)
+	}
+	if _, err := s.ListBlacklist(ctx); err != nil {
+		t.Fatalf("ListBlacklist() error: %v", err)
+	}
+	if len(primary.calls) != 3 {
+		t.Errorf("primary calls = %v, want all three", primary.calls)
+	}
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func stateSnapshotPath(name string) string {
+	return "state/" + name
+}
+
+// PutStateSnapshot stores the encoded in-memory state called name, saved
+// by the gateway on shutdown.
+func (s *Store) PutStateSnapshot(ctx context.Context, name string, content []byte) error {
+	metadata := map[string]interface{}{
+		"state":     name,
+		"mime_type": "application/json",
+	}
+	if err := s.PutFile(ctx, stateSnapshotPath(name), metadata, content, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to save %s state: %w", name, err)
+	}
+	return nil
+}
+
+// GetStateSnapshot returns the state saved under name, or nil if none was.
+func (s *Store) GetStateSnapshot(ctx context.Context, name string) ([]byte, error) {
+	file, err := s.GetFile(ctx, stateSnapshotPath(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s state: %w", name, err)
+	}
+	if file == nil {
+		return nil, nil
+	}
+	return file.Content, nil
+}
+
+// DeleteStateSnapshot removes the state saved under name.
+func (s *Store) DeleteStateSnapshot(ctx context.Context, name string) error {
+	return s.DeleteFile(ctx, stateSnapshotPath(name))
+}
@@ -22,13 +22,30 @@ type storeBackend interface {
 	AddBlacklist(ctx context.Context, phone, reason string) error
 	RemoveBlacklist(ctx context.Context, phone string) error
 	ListBlacklist(ctx context.Context) ([]BlacklistedNumber, error)
+	// EachBlacklist calls fn for every blacklist entry, oldest first,
+	// without loading the whole table. It stops at the first error.
+	EachBlacklist(ctx context.Context, fn func(BlacklistedNumber) error) error
+	IsGroupAllowed(ctx context.Context, jid string) (bool, error)
+	AddAllowedGroup(ctx context.Context, jid string) error
+	RemoveAllowedGroup(ctx context.Context, jid string) error
+	ListAllowedGroups(ctx context.Context) ([]AllowedGroup, error)
+	IsWhitelisted(ctx context.Context, phone string) (bool, error)
+	AddWhitelist(ctx context.Context, phone string) error
+	RemoveWhitelist(ctx context.Context, phone string) error
+	ListWhitelist(ctx context.Context) ([]WhitelistedUser, error)
 	ListContacts(ctx context.Context, query string) ([]Contact, error)
 	GetFilesysLogs(ctx context.Context, phone string, limit int) ([]FileEntry, error)
 	GetLatestGlobalMessages(ctx context.Context, limit int) ([]FileEntry, error)
 	QueryFilesys(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error)
 	GetFile(ctx context.Context, path string) (*FileEntry, error)
 	DeleteFile(ctx context.Context, path string) error
+	// TakeFile deletes the entry at path and reports whether it existed, so
+	// that of several callers racing for the same entry exactly one wins.
+	TakeFile(ctx context.Context, path string) (bool, error)
 	ListFiles(ctx context.Context, prefix string, limit int) ([]FileEntry, error)
+	// EachFile calls fn for every filesys entry under prefix, in path order,
+	// without loading them all. It stops at the first error.
+	EachFile(ctx context.Context, prefix string, fn func(FileEntry) error) error
 	GetAllContacts(ctx context.Context) ([]Contact, error)
 	PutContact(ctx context.Context, contact Contact) error
 	GetAllCommands(ctx context.Context) ([]Command, error)
@@ -38,7 +55,12 @@ type storeBackend interface {
 }
 
 type Store struct {
-	backend storeBackend
+	backend  storeBackend
+	notifier BlacklistNotifier
+	// replica, when set, serves blacklist reads; writes tracks recent
+	// changes so those reads fall back to the primary until it catches up.
+	replica storeBackend
+	writes  *readYourWrites
 }
 
 type sqlStore struct {
@@ -54,7 +76,15 @@ func IsSurrealDB(dsn string) bool {
 }
 
 func Open(dsn string) (*Store, error) {
+	return OpenWith(dsn, DefaultOptions())
+}
+
+// OpenWith is Open with explicit options.
+func OpenWith(dsn string, opts Options) (*Store, error) {
 	if IsSurrealDB(dsn) {
+		if opts.ReadDSN != "" {
+			return nil, fmt.Errorf("open store: read replicas require a Postgres primary")
+		}
 		backend, err := openSurrealDB(dsn)
 		if err != nil {
 			return nil, err
@@ -73,16 +103,50 @@ func Open(dsn string) (*Store, error) {
 	}
 
 	s := &sqlStore{db: db}
-	if err := s.migrate(); err != nil {
+	migrate := func(ctx context.Context) error { return s.migrate(ctx, opts.SchemaUpgrade) }
+	if err := retryMigrate(context.Background(), opts.MigrateAttempts, opts.MigrateBackoff, migrate); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("migrate store db: %w", err)
 	}
 
-	return &Store{backend: s}, nil
+	st := &Store{backend: s}
+	if opts.ReadDSN != "" {
+		replica, err := openReplica(opts.ReadDSN)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		st.replica = replica
+		st.writes = newReadYourWrites(opts.ReplicaLag)
+	}
+	return st, nil
+}
+
+// openReplica connects to a read-only Postgres replica. The schema is
+// owned by the primary, so no migration is run.
+func openReplica(dsn string) (*sqlStore, error) {
+	if IsSurrealDB(dsn) {
+		return nil, fmt.Errorf("open read replica: only Postgres replicas are supported")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open read replica: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping read replica: %w", err)
+	}
+	return &sqlStore{db: db}, nil
 }
 
 func (s *Store) Close() error {
-	return s.backend.Close()
+	err := s.backend.Close()
+	if s.replica != nil {
+		if rerr := s.replica.Close(); err == nil {
+			err = rerr
+		}
+	}
+	return err
 }
 
 func (s *sqlStore) Close() error {
@@ -110,19 +174,38 @@ func (s *Store) PutFile(ctx context.Context, path string, metadata interface{},
 }
 
 func (s *Store) IsBlacklisted(ctx context.Context, phone string) (bool, error) {
-	return s.backend.IsBlacklisted(ctx, phone)
+	return s.readBlacklistBackend(phone).IsBlacklisted(ctx, phone)
 }
 
 func (s *Store) AddBlacklist(ctx context.Context, phone, reason string) error {
-	return s.backend.AddBlacklist(ctx, phone, reason)
+	if err := s.backend.AddBlacklist(ctx, phone, reason); err != nil {
+		return err
+	}
+	if s.writes != nil {
+		s.writes.wrote(phone)
+	}
+	if s.notifier != nil {
+		s.notifier.BlacklistAdded(ctx, phone, reason)
+	}
+	return nil
 }
 
 func (s *Store) RemoveBlacklist(ctx context.Context, phone string) error {
-	return s.backend.RemoveBlacklist(ctx, phone)
+	if err := s.backend.RemoveBlacklist(ctx, phone); err != nil {
+		return err
+	}
+	if s.writes != nil {
+		s.writes.wrote(phone)
+	}
+	return nil
 }
 
 func (s *Store) ListBlacklist(ctx context.Context) ([]BlacklistedNumber, error) {
-	return s.backend.ListBlacklist(ctx)
+	return s.listBlacklistBackend().ListBlacklist(ctx)
+}
+
+func (s *Store) EachBlacklist(ctx context.Context, fn func(BlacklistedNumber) error) error {
+	return s.backend.EachBlacklist(ctx, fn)
 }
 
 func (s *Store) ListContacts(ctx context.Context, query string) ([]Contact, error) {
@@ -160,10 +243,18 @@ func (s *Store) DeleteFile(ctx context.Context, path string) error {
 	return s.backend.DeleteFile(ctx, path)
 }
 
+func (s *Store) TakeFile(ctx context.Context, path string) (bool, error) {
+	return s.backend.TakeFile(ctx, path)
+}
+
 func (s *Store) ListFiles(ctx context.Context, prefix string, limit int) ([]FileEntry, error) {
 	return s.backend.ListFiles(ctx, prefix, limit)
 }
 
+func (s *Store) EachFile(ctx context.Context, prefix string, fn func(FileEntry) error) error {
+	return s.backend.EachFile(ctx, prefix, fn)
+}
+
 func (s *Store) GetAllContacts(ctx context.Context) ([]Contact, error) {
 	return s.backend.GetAllContacts(ctx)
 }
@@ -188,19 +279,50 @@ func (s *Store) ResetSequence(ctx context.Context) error {
 	return s.backend.ResetSequence(ctx)
 }
 
-func (s *sqlStore) migrate() error {
-	_, err := s.db.Exec(`
+// migrate creates the schema. The DDL is idempotent and runs in one
+// transaction under an advisory lock, so concurrent replicas neither race
+// on CREATE statements nor see a half-applied schema. The version recorded
+// in store_schema is checked first (see checkSchemaVersion) and stamped
+// with SchemaVersion afterwards.
+func (s *sqlStore) migrate(ctx context.Context, policy string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("lock migration: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS store_schema (version INT NOT NULL)`); err != nil {
+		return fmt.Errorf("create schema version table: %w", err)
+	}
+	var current int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM store_schema`).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if err := checkSchemaVersion(current, SchemaVersion, policy); err != nil {
+		return err
+	}
+
+	statements := []string{`
 		CREATE TABLE IF NOT EXISTS blacklisted_numbers (
 			phone TEXT PRIMARY KEY,
 			reason TEXT NOT NULL DEFAULT '',
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		)
-	`)
-	if err != nil {
-		return err
-	}
-
-	_, err = s.db.Exec(`
+	`, `
+		CREATE TABLE IF NOT EXISTS allowed_groups (
+			jid TEXT PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`, `
+		CREATE TABLE IF NOT EXISTS whitelisted_users (
+			phone TEXT PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`, `
 		CREATE TABLE IF NOT EXISTS whatsmeow_contacts (
 			our_jid TEXT NOT NULL,
 			their_jid TEXT NOT NULL,
@@ -210,12 +332,7 @@ func (s *sqlStore) migrate() error {
 			business_name TEXT,
 			PRIMARY KEY (our_jid, their_jid)
 		)
-	`)
-	if err != nil {
-		return err
-	}
-
-	_, err = s.db.Exec(`
+	`, `
 		CREATE TABLE IF NOT EXISTS whatsmeow_commands (
 			id SERIAL PRIMARY KEY,
 			command TEXT NOT NULL,
@@ -225,21 +342,30 @@ func (s *sqlStore) migrate() error {
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		)
-	`)
-	if err != nil {
-		return err
-	}
-
-	_, err = s.db.Exec(`
+	`, `
 		CREATE TABLE IF NOT EXISTS filesys (
 			path TEXT PRIMARY KEY,
 			metadata JSONB,
 			content BYTEA,
 			tmstamp TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
-		);
-		CREATE INDEX IF NOT EXISTS idx_filesys_metadata ON filesys USING GIN (metadata);
-	`)
-	return err
+		)
+	`, `
+		CREATE INDEX IF NOT EXISTS idx_filesys_metadata ON filesys USING GIN (metadata)
+	`}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if current != SchemaVersion {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM store_schema`); err != nil {
+			return fmt.Errorf("stamp schema version: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO store_schema (version) VALUES ($1)`, SchemaVersion); err != nil {
+			return fmt.Errorf("stamp schema version: %w", err)
+		}
+	}
+	return tx.Commit()
 }
 
 type Command struct {
@@ -431,6 +557,125 @@ func (s *sqlStore) ListBlacklist(ctx context.Context) ([]BlacklistedNumber, erro
 	return numbers, rows.Err()
 }
 
+func (s *sqlStore) EachBlacklist(ctx context.Context, fn func(BlacklistedNumber) error) error {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT phone, reason, created_at FROM blacklisted_numbers ORDER BY created_at, phone",
+	)
+	if err != nil {
+		return fmt.Errorf("list blacklist: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var n BlacklistedNumber
+		if err := rows.Scan(&n.Phone, &n.Reason, &n.CreatedAt); err != nil {
+			return fmt.Errorf("scan blacklist row: %w", err)
+		}
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *sqlStore) IsGroupAllowed(ctx context.Context, jid string) (bool, error) {
+	var exists int
+	err := s.db.QueryRowContext(ctx,
+		"SELECT 1 FROM allowed_groups WHERE jid = $1", jid,
+	).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("check allowed group: %w", err)
+	}
+	return true, nil
+}
+
+func (s *sqlStore) AddAllowedGroup(ctx context.Context, jid string) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO allowed_groups (jid, created_at) VALUES ($1, $2) ON CONFLICT (jid) DO NOTHING",
+		jid, time.Now().UTC(),
+	)
+	return err
+}
+
+func (s *sqlStore) RemoveAllowedGroup(ctx context.Context, jid string) error {
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM allowed_groups WHERE jid = $1", jid,
+	)
+	return err
+}
+
+func (s *sqlStore) ListAllowedGroups(ctx context.Context) ([]AllowedGroup, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT jid, created_at FROM allowed_groups ORDER BY created_at DESC",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list allowed groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []AllowedGroup
+	for rows.Next() {
+		var g AllowedGroup
+		if err := rows.Scan(&g.JID, &g.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan allowed group row: %w", err)
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+func (s *sqlStore) IsWhitelisted(ctx context.Context, phone string) (bool, error) {
+	var exists int
+	err := s.db.QueryRowContext(ctx,
+		"SELECT 1 FROM whitelisted_users WHERE phone = $1", phone,
+	).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("check whitelist: %w", err)
+	}
+	return true, nil
+}
+
+func (s *sqlStore) AddWhitelist(ctx context.Context, phone string) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO whitelisted_users (phone, created_at) VALUES ($1, $2) ON CONFLICT (phone) DO NOTHING",
+		phone, time.Now().UTC(),
+	)
+	return err
+}
+
+func (s *sqlStore) RemoveWhitelist(ctx context.Context, phone string) error {
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM whitelisted_users WHERE phone = $1", phone,
+	)
+	return err
+}
+
+func (s *sqlStore) ListWhitelist(ctx context.Context) ([]WhitelistedUser, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT phone, created_at FROM whitelisted_users ORDER BY created_at DESC",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list whitelist: %w", err)
+	}
+	defer rows.Close()
+
+	var users []WhitelistedUser
+	for rows.Next() {
+		var u WhitelistedUser
+		if err := rows.Scan(&u.Phone, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan whitelist row: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
 type Contact struct {
 	OurJID       string `json:"our_jid"`
 	TheirJID     string `json:"their_jid"`
@@ -614,6 +859,18 @@ func (s *sqlStore) DeleteFile(ctx context.Context, path string) error {
 	return nil
 }
 
+func (s *sqlStore) TakeFile(ctx context.Context, path string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM filesys WHERE path = $1", path)
+	if err != nil {
+		return false, fmt.Errorf("take file: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("take file: %w", err)
+	}
+	return n > 0, nil
+}
+
 func (s *sqlStore) ListFiles(ctx context.Context, prefix string, limit int) ([]FileEntry, error) {
 	if limit <= 0 {
 		limit = 50
@@ -645,6 +902,28 @@ func (s *sqlStore) ListFiles(ctx context.Context, prefix string, limit int) ([]F
 	return entries, rows.Err()
 }
 
+func (s *sqlStore) EachFile(ctx context.Context, prefix string, fn func(FileEntry) error) error {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT path, metadata, content, tmstamp FROM filesys WHERE path LIKE $1 ORDER BY path",
+		prefix+"%",
+	)
+	if err != nil {
+		return fmt.Errorf("list files: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e FileEntry
+		if err := rows.Scan(&e.Path, &e.Metadata, &e.Content, &e.Timestamp); err != nil {
+			return fmt.Errorf("scan filesys row: %w", err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (s *sqlStore) GetAllContacts(ctx context.Context) ([]Contact, error) {
 	rows, err := s.db.QueryContext(ctx,
 		"SELECT our_jid, their_jid, full_name, short_name, push_name, business_name FROM whatsmeow_contacts ORDER BY full_name ASC",
@@ -20,12 +20,14 @@ func openTestStore(t *testing.T) *Store {
 	ctx := context.Background()
 	if IsSurrealDB(dsn) {
 		_, _ = s.QueryFilesys(ctx, "DELETE FROM blacklisted_numbers")
+		_, _ = s.QueryFilesys(ctx, "DELETE FROM allowed_groups")
+		_, _ = s.QueryFilesys(ctx, "DELETE FROM whitelisted_users")
 		_, _ = s.QueryFilesys(ctx, "DELETE FROM whatsmeow_contacts")
 		_, _ = s.QueryFilesys(ctx, "DELETE FROM whatsmeow_commands")
 		_, _ = s.QueryFilesys(ctx, "DELETE FROM filesys")
 		_, _ = s.QueryFilesys(ctx, "DELETE FROM counter")
 	} else {
-		_, _ = s.QueryFilesys(ctx, "TRUNCATE TABLE blacklisted_numbers, whatsmeow_contacts, whatsmeow_commands, filesys CASCADE")
+		_, _ = s.QueryFilesys(ctx, "TRUNCATE TABLE blacklisted_numbers, allowed_groups, whitelisted_users, whatsmeow_contacts, whatsmeow_commands, filesys CASCADE")
 	}
 	t.Cleanup(func() { s.Close() })
 	return s
@@ -56,6 +58,70 @@ func TestIsBlacklisted(t *testing.T) {
 	}
 }
 
+func TestAllowedGroups(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	const group = "120363012345678901@g.us"
+
+	if err := s.AddAllowedGroup(ctx, group); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	if err := s.AddAllowedGroup(ctx, group); err != nil {
+		t.Fatalf("duplicate add should not error: %v", err)
+	}
+	ok, err := s.IsGroupAllowed(ctx, group)
+	if err != nil || !ok {
+		t.Fatalf("IsGroupAllowed() = %v, %v; want true", ok, err)
+	}
+	list, err := s.ListAllowedGroups(ctx)
+	if err != nil {
+		t.Fatalf("failed to list: %v", err)
+	}
+	if len(list) != 1 || list[0].JID != group {
+		t.Fatalf("ListAllowedGroups() = %+v", list)
+	}
+
+	if err := s.RemoveAllowedGroup(ctx, group); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+	ok, err = s.IsGroupAllowed(ctx, group)
+	if err != nil || ok {
+		t.Fatalf("IsGroupAllowed() after remove = %v, %v; want false", ok, err)
+	}
+}
+
+func TestWhitelist(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	const phone = "919876543210"
+
+	if err := s.AddWhitelist(ctx, phone); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	if err := s.AddWhitelist(ctx, phone); err != nil {
+		t.Fatalf("duplicate add should not error: %v", err)
+	}
+	ok, err := s.IsWhitelisted(ctx, phone)
+	if err != nil || !ok {
+		t.Fatalf("IsWhitelisted() = %v, %v; want true", ok, err)
+	}
+	list, err := s.ListWhitelist(ctx)
+	if err != nil {
+		t.Fatalf("failed to list: %v", err)
+	}
+	if len(list) != 1 || list[0].Phone != phone {
+		t.Fatalf("ListWhitelist() = %+v", list)
+	}
+
+	if err := s.RemoveWhitelist(ctx, phone); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+	ok, err = s.IsWhitelisted(ctx, phone)
+	if err != nil || ok {
+		t.Fatalf("IsWhitelisted() after remove = %v, %v; want false", ok, err)
+	}
+}
+
 func TestRemoveBlacklist(t *testing.T) {
 	s := openTestStore(t)
 	ctx := context.Background()
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ConversationSummary is one rolling summary of a user's conversation.
+type ConversationSummary struct {
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func summaryPath(phone string) string {
+	return fmt.Sprintf("summaries/%s", phone)
+}
+
+// UserSummaries returns the stored summaries for phone, oldest first, or nil
+// if none exist.
+func (s *Store) UserSummaries(ctx context.Context, phone string) ([]ConversationSummary, error) {
+	file, err := s.GetFile(ctx, summaryPath(phone))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get summaries for %s: %w", phone, err)
+	}
+	if file == nil || len(file.Content) == 0 {
+		return nil, nil
+	}
+
+	var summaries []ConversationSummary
+	if err := json.Unmarshal(file.Content, &summaries); err != nil {
+		return nil, fmt.Errorf("failed to decode summaries for %s: %w", phone, err)
+	}
+	return summaries, nil
+}
+
+// AppendUserSummary adds text as the newest summary for phone, keeping only
+// the last keep entries (at least one).
+func (s *Store) AppendUserSummary(ctx context.Context, phone, text string, keep int) error {
+	summaries, err := s.UserSummaries(ctx, phone)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	summaries = append(summaries, ConversationSummary{Text: text, CreatedAt: now})
+	if keep < 1 {
+		keep = 1
+	}
+	if len(summaries) > keep {
+		summaries = summaries[len(summaries)-keep:]
+	}
+
+	content, err := json.Marshal(summaries)
+	if err != nil {
+		return fmt.Errorf("failed to encode summaries for %s: %w", phone, err)
+	}
+	metadata := map[string]interface{}{
+		"phone":     phone,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, summaryPath(phone), metadata, content, now)
+}
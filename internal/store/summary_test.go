@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeFilesBackend keeps filesys entries in memory; other storeBackend
+// methods panic if called.
+type fakeFilesBackend struct {
+	storeBackend
+	files map[string]*FileEntry
+}
+
+func (f *fakeFilesBackend) PutFile(_ context.Context, path string, _ interface{}, content []byte, ts time.Time) error {
+	f.files[path] = &FileEntry{Path: path, Content: content, Timestamp: ts}
+	return nil
+}
+
+func (f *fakeFilesBackend) GetFile(_ context.Context, path string) (*FileEntry, error) {
+	return f.files[path], nil
+}
+
+func (f *fakeFilesBackend) DeleteFile(_ context.Context, path string) error {
+	delete(f.files, path)
+	return nil
+}
+
+func (f *fakeFilesBackend) ListFiles(_ context.Context, prefix string, limit int) ([]FileEntry, error) {
+	var out []FileEntry
+	for path, file := range f.files {
+		if strings.HasPrefix(path, prefix) && len(out) < limit {
+			out = append(out, *file)
+		}
+	}
+	return out, nil
+}
+
+func TestUserSummaries(t *testing.T) {
+	ctx := context.Background()
+	s := &Store{backend: &fakeFilesBackend{files: make(map[string]*FileEntry)}}
+
+	got, err := s.UserSummaries(ctx, "919876543210")
+	if err != nil {
+		t.Fatalf("UserSummaries() error: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected no summaries, got %+v", got)
+	}
+
+	for _, text := range []string{"first", "second", "third"} {
+		if err := s.AppendUserSummary(ctx, "919876543210", text, 2); err != nil {
+			t.Fatalf("AppendUserSummary(%q) error: %v", text, err)
+		}
+	}
+
+	got, err = s.UserSummaries(ctx, "919876543210")
+	if err != nil {
+		t.Fatalf("UserSummaries() error: %v", err)
+	}
+	if len(got) != 2 || got[0].Text != "second" || got[1].Text != "third" {
+		t.Errorf("summaries = %+v, want [second third]", got)
+	}
+	if got[1].CreatedAt.IsZero() {
+		t.Error("CreatedAt should be set")
+	}
+
+	other, err := s.UserSummaries(ctx, "910000000000")
+	if err != nil {
+		t.Fatalf("UserSummaries() error: %v", err)
+	}
+	if other != nil {
+		t.Errorf("summaries leaked across users: %+v", other)
+	}
+}
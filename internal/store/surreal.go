@@ -363,6 +363,20 @@ func (s *surrealStore) DeleteFile(ctx context.Context, path string) error {
 	return nil
 }
 
+func (s *surrealStore) TakeFile(ctx context.Context, path string) (bool, error) {
+	hasher := md5.New()
+	hasher.Write([]byte(path))
+	idPart := hex.EncodeToString(hasher.Sum(nil))
+	recordID := fmt.Sprintf("filesys:%s", idPart)
+
+	res, err := surrealdb.Query[[]surrealFileEntry](ctx, s.db,
+		"DELETE FROM type::record($record_id) RETURN BEFORE", map[string]interface{}{"record_id": recordID})
+	if err != nil {
+		return false, fmt.Errorf("take file: %w", err)
+	}
+	return res != nil && len(*res) > 0 && len((*res)[0].Result) > 0, nil
+}
+
 func (s *surrealStore) ListFiles(ctx context.Context, prefix string, limit int) ([]FileEntry, error) {
 	var res *[]surrealdb.QueryResult[[]surrealFileEntry]
 	var err error
@@ -390,6 +404,29 @@ func (s *surrealStore) ListFiles(ctx context.Context, prefix string, limit int)
 	return entries, nil
 }
 
+func (s *surrealStore) EachFile(ctx context.Context, prefix string, fn func(FileEntry) error) error {
+	for start := 0; ; start += surrealPageSize {
+		res, err := surrealdb.Query[[]surrealFileEntry](ctx, s.db,
+			"SELECT * FROM filesys WHERE string::starts_with(path, $prefix) ORDER BY path LIMIT $limit START $start",
+			map[string]interface{}{"prefix": prefix, "limit": surrealPageSize, "start": start})
+		if err != nil {
+			return fmt.Errorf("list files: %w", err)
+		}
+		if res == nil || len(*res) == 0 || len((*res)[0].Result) == 0 {
+			return nil
+		}
+		page := (*res)[0].Result
+		for _, sfe := range page {
+			if err := fn(toFileEntry(sfe)); err != nil {
+				return err
+			}
+		}
+		if len(page) < surrealPageSize {
+			return nil
+		}
+	}
+}
+
 func (s *surrealStore) GetFilesysLogs(ctx context.Context, phone string, limit int) ([]FileEntry, error) {
 	if limit <= 0 {
 		limit = 10
@@ -531,6 +568,126 @@ func (s *surrealStore) ListBlacklist(ctx context.Context) ([]BlacklistedNumber,
 	return numbers, nil
 }
 
+// allowedGroupRecord is the record ID of jid, hashed since JIDs contain
+// characters SurrealDB record IDs do not allow unescaped.
+func allowedGroupRecord(jid string) string {
+	hasher := md5.New()
+	hasher.Write([]byte(jid))
+	return fmt.Sprintf("allowed_groups:%s", hex.EncodeToString(hasher.Sum(nil)))
+}
+
+func (s *surrealStore) IsGroupAllowed(ctx context.Context, jid string) (bool, error) {
+	res, err := surrealdb.Query[[]AllowedGroup](ctx, s.db,
+		"SELECT * FROM type::record($record_id)", map[string]interface{}{"record_id": allowedGroupRecord(jid)})
+	if err != nil {
+		return false, fmt.Errorf("check allowed group: %w", err)
+	}
+	return res != nil && len(*res) > 0 && len((*res)[0].Result) > 0, nil
+}
+
+func (s *surrealStore) AddAllowedGroup(ctx context.Context, jid string) error {
+	_, err := surrealdb.Query[interface{}](ctx, s.db,
+		"UPSERT type::record($record_id) SET jid = $jid, created_at = $created_at",
+		map[string]interface{}{
+			"record_id":  allowedGroupRecord(jid),
+			"jid":        jid,
+			"created_at": time.Now().UTC(),
+		},
+	)
+	return err
+}
+
+func (s *surrealStore) RemoveAllowedGroup(ctx context.Context, jid string) error {
+	_, err := surrealdb.Query[interface{}](ctx, s.db,
+		"DELETE FROM type::record($record_id)", map[string]interface{}{"record_id": allowedGroupRecord(jid)})
+	return err
+}
+
+func (s *surrealStore) ListAllowedGroups(ctx context.Context) ([]AllowedGroup, error) {
+	res, err := surrealdb.Query[[]AllowedGroup](ctx, s.db,
+		"SELECT * FROM allowed_groups ORDER BY created_at DESC", nil)
+	if err != nil {
+		return nil, fmt.Errorf("list allowed groups: %w", err)
+	}
+	if res == nil || len(*res) == 0 {
+		return nil, nil
+	}
+	return (*res)[0].Result, nil
+}
+
+// whitelistRecord is the record ID of phone.
+func whitelistRecord(phone string) string {
+	hasher := md5.New()
+	hasher.Write([]byte(phone))
+	return fmt.Sprintf("whitelisted_users:%s", hex.EncodeToString(hasher.Sum(nil)))
+}
+
+func (s *surrealStore) IsWhitelisted(ctx context.Context, phone string) (bool, error) {
+	res, err := surrealdb.Query[[]WhitelistedUser](ctx, s.db,
+		"SELECT * FROM type::record($record_id)", map[string]interface{}{"record_id": whitelistRecord(phone)})
+	if err != nil {
+		return false, fmt.Errorf("check whitelist: %w", err)
+	}
+	return res != nil && len(*res) > 0 && len((*res)[0].Result) > 0, nil
+}
+
+func (s *surrealStore) AddWhitelist(ctx context.Context, phone string) error {
+	_, err := surrealdb.Query[interface{}](ctx, s.db,
+		"UPSERT type::record($record_id) SET phone = $phone, created_at = $created_at",
+		map[string]interface{}{
+			"record_id":  whitelistRecord(phone),
+			"phone":      phone,
+			"created_at": time.Now().UTC(),
+		},
+	)
+	return err
+}
+
+func (s *surrealStore) RemoveWhitelist(ctx context.Context, phone string) error {
+	_, err := surrealdb.Query[interface{}](ctx, s.db,
+		"DELETE FROM type::record($record_id)", map[string]interface{}{"record_id": whitelistRecord(phone)})
+	return err
+}
+
+func (s *surrealStore) ListWhitelist(ctx context.Context) ([]WhitelistedUser, error) {
+	res, err := surrealdb.Query[[]WhitelistedUser](ctx, s.db,
+		"SELECT * FROM whitelisted_users ORDER BY created_at DESC", nil)
+	if err != nil {
+		return nil, fmt.Errorf("list whitelist: %w", err)
+	}
+	if res == nil || len(*res) == 0 {
+		return nil, nil
+	}
+	return (*res)[0].Result, nil
+}
+
+// surrealPageSize is how many records EachBlacklist and EachFile fetch per
+// query, since SurrealDB results are not streamed.
+const surrealPageSize = 500
+
+func (s *surrealStore) EachBlacklist(ctx context.Context, fn func(BlacklistedNumber) error) error {
+	for start := 0; ; start += surrealPageSize {
+		res, err := surrealdb.Query[[]surrealBlacklist](ctx, s.db,
+			"SELECT * FROM blacklisted_numbers ORDER BY created_at, phone LIMIT $limit START $start",
+			map[string]interface{}{"limit": surrealPageSize, "start": start})
+		if err != nil {
+			return fmt.Errorf("list blacklist: %w", err)
+		}
+		if res == nil || len(*res) == 0 || len((*res)[0].Result) == 0 {
+			return nil
+		}
+		page := (*res)[0].Result
+		for _, sb := range page {
+			if err := fn(BlacklistedNumber{Phone: sb.Phone, Reason: sb.Reason, CreatedAt: sb.CreatedAt}); err != nil {
+				return err
+			}
+		}
+		if len(page) < surrealPageSize {
+			return nil
+		}
+	}
+}
+
 type surrealContact struct {
 	OurJID       string `json:"our_jid"`
 	TheirJID     string `json:"their_jid"`
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// UsageCounts is one user's message and agent call counts for one UTC day.
+type UsageCounts struct {
+	Phone      string `json:"phone"`
+	Day        string `json:"day"` // YYYY-MM-DD
+	Inbound    int64  `json:"inbound"`
+	Outbound   int64  `json:"outbound"`
+	AgentCalls int64  `json:"agent_calls"`
+}
+
+func usagePath(day, phone string) string {
+	return "usage/" + day + "/" + phone
+}
+
+// AddUsage adds delta's counts to the stored counts for its phone and day.
+// The read-modify-write is not atomic, so each gateway instance should be
+// the only writer for its users.
+func (s *Store) AddUsage(ctx context.Context, delta UsageCounts) error {
+	current, err := s.GetUsage(ctx, delta.Phone, delta.Day)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		current = &UsageCounts{Phone: delta.Phone, Day: delta.Day}
+	}
+	current.Inbound += delta.Inbound
+	current.Outbound += delta.Outbound
+	current.AgentCalls += delta.AgentCalls
+
+	content, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage for %s: %w", delta.Phone, err)
+	}
+	metadata := map[string]interface{}{
+		"day":       delta.Day,
+		"mime_type": "application/json",
+	}
+	return s.PutFile(ctx, usagePath(delta.Day, delta.Phone), metadata, content, time.Now().UTC())
+}
+
+// GetUsage returns phone's counts for day, or nil if none were recorded.
+func (s *Store) GetUsage(ctx context.Context, phone, day string) (*UsageCounts, error) {
+	file, err := s.GetFile(ctx, usagePath(day, phone))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage for %s: %w", phone, err)
+	}
+	if file == nil {
+		return nil, nil
+	}
+
+	var u UsageCounts
+	if err := json.Unmarshal(file.Content, &u); err != nil {
+		return nil, fmt.Errorf("failed to decode usage for %s: %w", phone, err)
+	}
+	return &u, nil
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// WhitelistedUser is a phone number whitelisted at runtime.
+type WhitelistedUser struct {
+	Phone     string    `json:"phone"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsWhitelisted reports whether phone was added with AddWhitelist.
+func (s *Store) IsWhitelisted(ctx context.Context, phone string) (bool, error) {
+	return s.backend.IsWhitelisted(ctx, phone)
+}
+
+// AddWhitelist whitelists phone in addition to whatsapp.whitelisted_users.
+// Adding a number twice is not an error.
+func (s *Store) AddWhitelist(ctx context.Context, phone string) error {
+	return s.backend.AddWhitelist(ctx, phone)
+}
+
+// RemoveWhitelist undoes AddWhitelist. Numbers listed in the config stay
+// whitelisted.
+func (s *Store) RemoveWhitelist(ctx context.Context, phone string) error {
+	return s.backend.RemoveWhitelist(ctx, phone)
+}
+
+// ListWhitelist returns the numbers added at runtime, newest first.
+func (s *Store) ListWhitelist(ctx context.Context) ([]WhitelistedUser, error) {
+	return s.backend.ListWhitelist(ctx)
+}
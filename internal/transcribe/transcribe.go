@@ -0,0 +1,205 @@
+// Package transcribe turns voice notes into text using a speech-to-text
+// backend, so the agent receives a transcript rather than raw audio.
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// Default API endpoints.
+const (
+	DefaultWhisperEndpoint = "https://api.openai.com/v1/audio/transcriptions"
+	DefaultGoogleEndpoint  = "https://speech.googleapis.com/v1/speech:recognize"
+)
+
+// maxErrorBody bounds how much of a failed response is quoted in errors.
+const maxErrorBody = 512
+
+// Transcriber converts audio to text. Audio is 16kHz mono 16-bit WAV, as
+// produced by the gateway's media processor.
+type Transcriber interface {
+	Transcribe(ctx context.Context, wav []byte) (string, error)
+}
+
+// New returns the Transcriber for cfg, or nil if transcription is disabled.
+// tlsCfg carries the gateway-wide outbound TLS restrictions and may be nil.
+func New(cfg config.TranscriptionConfig, tlsCfg *tls.Config) (Transcriber, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transcription timeout: %w", err)
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	if tlsCfg != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsCfg.Clone()
+		httpClient.Transport = transport
+	}
+	switch cfg.Provider {
+	case config.TranscriptionWhisper:
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultWhisperEndpoint
+		}
+		return &Whisper{endpoint: endpoint, apiKey: cfg.APIKey, model: cfg.Model, language: cfg.Language, httpClient: httpClient}, nil
+	case config.TranscriptionGoogle:
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultGoogleEndpoint
+		}
+		return &Google{endpoint: endpoint, apiKey: cfg.APIKey, language: cfg.Language, httpClient: httpClient}, nil
+	}
+	return nil, fmt.Errorf("unknown transcription provider %q", cfg.Provider)
+}
+
+// Whisper calls an OpenAI-compatible /audio/transcriptions endpoint, which
+// self-hosted Whisper servers also implement.
+type Whisper struct {
+	endpoint   string
+	apiKey     string
+	model      string
+	language   string
+	httpClient *http.Client
+}
+
+func (w *Whisper) Transcribe(ctx context.Context, wav []byte) (string, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "voice.wav")
+	if err != nil {
+		return "", fmt.Errorf("create form file: %w", err)
+	}
+	if _, err := fw.Write(wav); err != nil {
+		return "", fmt.Errorf("write audio: %w", err)
+	}
+	if err := mw.WriteField("model", w.model); err != nil {
+		return "", fmt.Errorf("write model: %w", err)
+	}
+	if lang := whisperLanguage(w.language); lang != "" {
+		if err := mw.WriteField("language", lang); err != nil {
+			return "", fmt.Errorf("write language: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("close form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.endpoint, &body)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if w.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+w.apiKey)
+	}
+
+	var out struct {
+		Text string `json:"text"`
+	}
+	if err := do(w.httpClient, req, &out); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.Text), nil
+}
+
+// whisperLanguage reduces a BCP-47 tag such as "hi-IN" to the ISO-639-1
+// code Whisper expects.
+func whisperLanguage(tag string) string {
+	lang, _, _ := strings.Cut(tag, "-")
+	return strings.ToLower(lang)
+}
+
+// Google calls the Cloud Speech-to-Text v1 recognize API.
+type Google struct {
+	endpoint   string
+	apiKey     string
+	language   string
+	httpClient *http.Client
+}
+
+type googleRequest struct {
+	Config googleConfig `json:"config"`
+	Audio  googleAudio  `json:"audio"`
+}
+
+type googleConfig struct {
+	Encoding        string `json:"encoding"`
+	SampleRateHertz int    `json:"sampleRateHertz"`
+	LanguageCode    string `json:"languageCode"`
+}
+
+type googleAudio struct {
+	Content string `json:"content"`
+}
+
+type googleResponse struct {
+	Results []struct {
+		Alternatives []struct {
+			Transcript string `json:"transcript"`
+		} `json:"alternatives"`
+	} `json:"results"`
+}
+
+func (g *Google) Transcribe(ctx context.Context, wav []byte) (string, error) {
+	payload, err := json.Marshal(googleRequest{
+		Config: googleConfig{Encoding: "LINEAR16", SampleRateHertz: 16000, LanguageCode: g.language},
+		Audio:  googleAudio{Content: base64.StdEncoding.EncodeToString(wav)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("X-Goog-Api-Key", g.apiKey)
+	}
+
+	var out googleResponse
+	if err := do(g.httpClient, req, &out); err != nil {
+		return "", err
+	}
+	var parts []string
+	for _, r := range out.Results {
+		if len(r.Alternatives) > 0 {
+			parts = append(parts, strings.TrimSpace(r.Alternatives[0].Transcript))
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// do sends req and decodes a successful JSON response into out.
+func do(c *http.Client, req *http.Request, out any) error {
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("transcription request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		if err != nil {
+			return fmt.Errorf("transcription failed with status %d", resp.StatusCode)
+		}
+		return fmt.Errorf("transcription failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode transcription response: %w", err)
+	}
+	return nil
+}
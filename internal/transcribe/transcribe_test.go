@@ -0,0 +1,130 @@
+package transcribe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestNewDisabled(t *testing.T) {
+	tr, err := New(config.TranscriptionConfig{}, nil)
+	if err != nil || tr != nil {
+		t.Errorf("New() = %v, %v, want nil, nil", tr, err)
+	}
+}
+
+func TestWhisperTranscribe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		if r.FormValue("model") != "whisper-1" || r.FormValue("language") != "hi" {
+			t.Errorf("model = %q, language = %q", r.FormValue("model"), r.FormValue("language"))
+		}
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("form file: %v", err)
+		}
+		audio, err := io.ReadAll(f)
+		if err != nil || string(audio) != "RIFF" {
+			t.Errorf("audio = %q, %v", audio, err)
+		}
+		w.Write([]byte(`{"text":" mera order kahan hai? "}`))
+	}))
+	defer srv.Close()
+
+	tr, err := New(config.TranscriptionConfig{Provider: config.TranscriptionWhisper, Endpoint: srv.URL, APIKey: "sk-test", Model: "whisper-1", Language: "hi-IN", Timeout: "5s"}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	got, err := tr.Transcribe(context.Background(), []byte("RIFF"))
+	if err != nil || got != "mera order kahan hai?" {
+		t.Errorf("Transcribe() = %q, %v", got, err)
+	}
+}
+
+func TestGoogleTranscribe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Goog-Api-Key"); got != "key" {
+			t.Errorf("X-Goog-Api-Key = %q", got)
+		}
+		var req googleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Config.LanguageCode != "ta-IN" || req.Config.SampleRateHertz != 16000 || req.Config.Encoding != "LINEAR16" {
+			t.Errorf("config = %+v", req.Config)
+		}
+		if req.Audio.Content != base64.StdEncoding.EncodeToString([]byte("RIFF")) {
+			t.Errorf("audio = %q", req.Audio.Content)
+		}
+		w.Write([]byte(`{"results":[{"alternatives":[{"transcript":"vanakkam"}]},{"alternatives":[{"transcript":"order status"}]}]}`))
+	}))
+	defer srv.Close()
+
+	tr, err := New(config.TranscriptionConfig{Provider: config.TranscriptionGoogle, Endpoint: srv.URL, APIKey: "key", Language: "ta-IN", Timeout: "5s"}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	got, err := tr.Transcribe(context.Background(), []byte("RIFF"))
+	if err != nil || got != "vanakkam order status" {
+		t.Errorf("Transcribe() = %q, %v", got, err)
+	}
+}
+
+func TestTranscribeHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	tr, err := New(config.TranscriptionConfig{Provider: config.TranscriptionWhisper, Endpoint: srv.URL, Model: "whisper-1", Timeout: "5s"}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	_, err = tr.Transcribe(context.Background(), []byte("RIFF"))
+	if err == nil || !strings.Contains(err.Error(), "429") || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("Transcribe() error = %v", err)
+	}
+}
+
+func TestNewAppliesTLSConfig(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"text":"ok"}`))
+	}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	cfg := config.TranscriptionConfig{Provider: config.TranscriptionWhisper, Endpoint: srv.URL, Timeout: "5s"}
+
+	tr, err := New(cfg, &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if got, err := tr.Transcribe(context.Background(), []byte("RIFF")); err != nil || got != "ok" {
+		t.Errorf("Transcribe() over TLS 1.2 = %q, %v", got, err)
+	}
+
+	tr, err = New(cfg, &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS13})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := tr.Transcribe(context.Background(), []byte("RIFF")); err == nil {
+		t.Error("Transcribe() succeeded against a TLS 1.2 server with min_version 1.3")
+	}
+}
@@ -0,0 +1,197 @@
+// Package tts turns agent replies into speech using a text-to-speech
+// backend, so voice notes can be answered with voice notes.
+package tts
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// Default API endpoints.
+const (
+	DefaultOpenAIEndpoint = "https://api.openai.com/v1/audio/speech"
+	DefaultGoogleEndpoint = "https://texttospeech.googleapis.com/v1/text:synthesize"
+)
+
+// MimeType is the format every Synthesizer returns: Ogg Opus, which
+// WhatsApp plays as a voice note.
+const MimeType = "audio/ogg; codecs=opus"
+
+// maxErrorBody bounds how much of a failed response is quoted in errors.
+const maxErrorBody = 512
+
+// maxAudio bounds the size of synthesized audio.
+const maxAudio = 16 << 20
+
+// Synthesizer converts text to Ogg Opus audio.
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text string) ([]byte, error)
+}
+
+// New returns the Synthesizer for cfg, or nil if voice replies are
+// disabled. tlsCfg carries the gateway-wide outbound TLS restrictions and
+// may be nil.
+func New(cfg config.VoiceRepliesConfig, tlsCfg *tls.Config) (Synthesizer, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid voice_replies timeout: %w", err)
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	if tlsCfg != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsCfg.Clone()
+		httpClient.Transport = transport
+	}
+	switch cfg.Provider {
+	case config.TTSOpenAI:
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultOpenAIEndpoint
+		}
+		return &OpenAI{endpoint: endpoint, apiKey: cfg.APIKey, model: cfg.Model, voice: cfg.Voice, httpClient: httpClient}, nil
+	case config.TTSGoogle:
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultGoogleEndpoint
+		}
+		return &Google{endpoint: endpoint, apiKey: cfg.APIKey, language: cfg.Language, voice: cfg.Voice, httpClient: httpClient}, nil
+	}
+	return nil, fmt.Errorf("unknown voice_replies provider %q", cfg.Provider)
+}
+
+// OpenAI calls an OpenAI-compatible /audio/speech endpoint.
+type OpenAI struct {
+	endpoint   string
+	apiKey     string
+	model      string
+	voice      string
+	httpClient *http.Client
+}
+
+type openAIRequest struct {
+	Model          string `json:"model"`
+	Input          string `json:"input"`
+	Voice          string `json:"voice"`
+	ResponseFormat string `json:"response_format"`
+}
+
+func (o *OpenAI) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	payload, err := json.Marshal(openAIRequest{Model: o.model, Input: text, Voice: o.voice, ResponseFormat: "opus"})
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := send(o.httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	audio, err := io.ReadAll(io.LimitReader(resp.Body, maxAudio))
+	if err != nil {
+		return nil, fmt.Errorf("read speech: %w", err)
+	}
+	return audio, nil
+}
+
+// Google calls the Cloud Text-to-Speech v1 synthesize API.
+type Google struct {
+	endpoint   string
+	apiKey     string
+	language   string
+	voice      string
+	httpClient *http.Client
+}
+
+type googleRequest struct {
+	Input       googleInput       `json:"input"`
+	Voice       googleVoice       `json:"voice"`
+	AudioConfig googleAudioConfig `json:"audioConfig"`
+}
+
+type googleInput struct {
+	Text string `json:"text"`
+}
+
+type googleVoice struct {
+	LanguageCode string `json:"languageCode"`
+	Name         string `json:"name,omitempty"`
+}
+
+type googleAudioConfig struct {
+	AudioEncoding string `json:"audioEncoding"`
+}
+
+func (g *Google) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	payload, err := json.Marshal(googleRequest{
+		Input:       googleInput{Text: text},
+		Voice:       googleVoice{LanguageCode: g.language, Name: g.voice},
+		AudioConfig: googleAudioConfig{AudioEncoding: "OGG_OPUS"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("X-Goog-Api-Key", g.apiKey)
+	}
+
+	resp, err := send(g.httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		AudioContent string `json:"audioContent"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode speech response: %w", err)
+	}
+	audio, err := base64.StdEncoding.DecodeString(out.AudioContent)
+	if err != nil {
+		return nil, fmt.Errorf("decode speech audio: %w", err)
+	}
+	return audio, nil
+}
+
+// send performs req and returns the response if it succeeded. The caller
+// closes the body.
+func send(c *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("speech request failed: %w", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		return nil, fmt.Errorf("speech synthesis failed with status %d", resp.StatusCode)
+	}
+	return nil, fmt.Errorf("speech synthesis failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
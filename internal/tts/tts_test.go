@@ -0,0 +1,123 @@
+package tts
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestNewDisabled(t *testing.T) {
+	s, err := New(config.VoiceRepliesConfig{}, nil)
+	if err != nil || s != nil {
+		t.Errorf("New() = %v, %v, want nil, nil", s, err)
+	}
+}
+
+func TestOpenAISynthesize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req != (openAIRequest{Model: "tts-1", Input: "Your order ships today.", Voice: "alloy", ResponseFormat: "opus"}) {
+			t.Errorf("request = %+v", req)
+		}
+		if _, err := w.Write([]byte("OggS...")); err != nil {
+			t.Errorf("write: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := New(config.VoiceRepliesConfig{Provider: config.TTSOpenAI, Endpoint: srv.URL, APIKey: "sk-test", Model: "tts-1", Voice: "alloy", Timeout: "5s"}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	got, err := s.Synthesize(context.Background(), "Your order ships today.")
+	if err != nil || string(got) != "OggS..." {
+		t.Errorf("Synthesize() = %q, %v", got, err)
+	}
+}
+
+func TestGoogleSynthesize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Goog-Api-Key"); got != "key" {
+			t.Errorf("X-Goog-Api-Key = %q", got)
+		}
+		var req googleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Input.Text != "Namaste" || req.Voice.LanguageCode != "hi-IN" || req.Voice.Name != "hi-IN-Wavenet-A" || req.AudioConfig.AudioEncoding != "OGG_OPUS" {
+			t.Errorf("request = %+v", req)
+		}
+		if err := json.NewEncoder(w).Encode(map[string]string{"audioContent": base64.StdEncoding.EncodeToString([]byte("OggS"))}); err != nil {
+			t.Errorf("encode: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := New(config.VoiceRepliesConfig{Provider: config.TTSGoogle, Endpoint: srv.URL, APIKey: "key", Language: "hi-IN", Voice: "hi-IN-Wavenet-A", Timeout: "5s"}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	got, err := s.Synthesize(context.Background(), "Namaste")
+	if err != nil || string(got) != "OggS" {
+		t.Errorf("Synthesize() = %q, %v", got, err)
+	}
+}
+
+func TestSynthesizeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	s, err := New(config.VoiceRepliesConfig{Provider: config.TTSOpenAI, Endpoint: srv.URL, Timeout: "5s"}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := s.Synthesize(context.Background(), "hi"); err == nil {
+		t.Error("expected error for 429")
+	}
+}
+
+func TestNewAppliesTLSConfig(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := w.Write([]byte("OggS...")); err != nil {
+			t.Errorf("write: %v", err)
+		}
+	}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	cfg := config.VoiceRepliesConfig{Provider: config.TTSOpenAI, Endpoint: srv.URL, Timeout: "5s"}
+
+	s, err := New(cfg, &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if got, err := s.Synthesize(context.Background(), "hi"); err != nil || string(got) != "OggS..." {
+		t.Errorf("Synthesize() over TLS 1.2 = %q, %v", got, err)
+	}
+
+	s, err = New(cfg, &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS13})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := s.Synthesize(context.Background(), "hi"); err == nil {
+		t.Error("Synthesize() succeeded against a TLS 1.2 server with min_version 1.3")
+	}
+}
@@ -0,0 +1,57 @@
+package verification
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var (
+	errCallbackScheme   = errors.New("callback URL must use https")
+	errCallbackFragment = errors.New("callback URL must not contain a fragment")
+	errCallbackHost     = errors.New("callback URL has no host")
+)
+
+// sanitizeCallbackURL validates a callback URL taken from a verification
+// token. Embedded credentials are removed (stripped reports whether any
+// were present); non-https schemes, unless allowHTTP is set, and fragments
+// are rejected.
+func sanitizeCallbackURL(raw string, allowHTTP bool) (clean string, stripped bool, err error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid callback URL: %w", err)
+	}
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && allowHTTP:
+	default:
+		return "", false, errCallbackScheme
+	}
+	if u.Host == "" {
+		return "", false, errCallbackHost
+	}
+	if u.Fragment != "" || u.RawFragment != "" {
+		return "", false, errCallbackFragment
+	}
+	if u.User != nil {
+		u.User = nil
+		stripped = true
+	}
+	return u.String(), stripped, nil
+}
+
+// callbackHostAllowed reports whether callbackURL's host is domain or one
+// of its subdomains. Ports and letter case are ignored.
+func callbackHostAllowed(callbackURL, domain string) bool {
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	domain = strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "."), ".")
+	if host == "" || domain == "" {
+		return false
+	}
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,120 @@
+package verification
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// NewAppClients builds one HTTP client per app that declares custom callback
+// transport settings or opts into following redirects. Apps without settings
+// are omitted and fall back to the handler's shared client. Clients are
+// built once at startup and reused for every callback so connections are
+// pooled per app. base carries the gateway-wide TLS restrictions and may be
+// nil.
+func NewAppClients(cfg config.VerificationConfig, timeout time.Duration, base *tls.Config) (map[string]*http.Client, error) {
+	clients := make(map[string]*http.Client)
+	for appName, appCfg := range cfg.Apps {
+		if appCfg.CallbackTLS.IsZero() && !appCfg.FollowRedirects {
+			continue
+		}
+		client := NewCallbackClient(timeout, base)
+		if !appCfg.CallbackTLS.IsZero() {
+			var err error
+			client, err = newCallbackClient(appCfg.CallbackTLS, timeout, base)
+			if err != nil {
+				return nil, fmt.Errorf("callback client for app %q: %w", appName, err)
+			}
+		}
+		if appCfg.FollowRedirects {
+			client.CheckRedirect = checkCallbackRedirect(appCfg.CallbackDomain, cfg.AllowHTTPCallbacks)
+		}
+		clients[appName] = client
+	}
+	return clients, nil
+}
+
+// maxCallbackRedirects caps the redirects a follow_redirects client takes.
+const maxCallbackRedirects = 5
+
+// checkCallbackRedirect returns a CheckRedirect that applies the checks the
+// callback URL itself passed to every hop, so a redirect cannot send the
+// signed token over plain http or outside the app's callback_domain.
+func checkCallbackRedirect(domain string, allowHTTP bool) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxCallbackRedirects {
+			return fmt.Errorf("stopped after %d redirects", len(via))
+		}
+		target, _, err := sanitizeCallbackURL(req.URL.String(), allowHTTP)
+		if err != nil {
+			return fmt.Errorf("redirect refused: %w", err)
+		}
+		if domain != "" && !callbackHostAllowed(target, domain) {
+			return fmt.Errorf("redirect refused: host %q is outside callback_domain %q", req.URL.Hostname(), domain)
+		}
+		return nil
+	}
+}
+
+// NewCallbackClient returns the shared callback client, applying base TLS
+// restrictions when set. Redirects are returned to the caller rather than
+// followed.
+func NewCallbackClient(timeout time.Duration, base *tls.Config) *http.Client {
+	if base == nil {
+		return &http.Client{Timeout: timeout, CheckRedirect: noRedirect}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = base.Clone()
+	return &http.Client{Timeout: timeout, Transport: transport, CheckRedirect: noRedirect}
+}
+
+func noRedirect(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+func newCallbackClient(cfg config.CallbackTLSConfig, timeout time.Duration, base *tls.Config) (*http.Client, error) {
+	tlsCfg := &tls.Config{}
+	if base != nil {
+		tlsCfg = base.Clone()
+	}
+	tlsCfg.ServerName = cfg.ServerName
+
+	if cfg.CAFile != "" {
+		pemData, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport, CheckRedirect: noRedirect}, nil
+}
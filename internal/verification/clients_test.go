@@ -0,0 +1,180 @@
+package verification
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/auth"
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestNewAppClients(t *testing.T) {
+	apps := map[string]config.AppVerifyConfig{
+		"plain-app":  {PublicKeyPath: "unused.pem"},
+		"secure-app": {PublicKeyPath: "unused.pem", CallbackTLS: config.CallbackTLSConfig{ServerName: "example.com"}},
+	}
+	clients, err := NewAppClients(config.VerificationConfig{Apps: apps}, 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := clients["plain-app"]; ok {
+		t.Error("app without TLS settings should use the shared client")
+	}
+	if clients["secure-app"] == nil {
+		t.Fatal("expected dedicated client for secure-app")
+	}
+
+	h := &Handler{httpClient: http.DefaultClient}
+	h.SetAppClients(clients)
+	if h.clientFor("secure-app") != h.clientFor("secure-app") {
+		t.Error("expected the dedicated client to be reused across calls")
+	}
+	if h.clientFor("plain-app") != http.DefaultClient {
+		t.Error("expected plain-app to fall back to the shared client")
+	}
+}
+
+func TestNewAppClients_InvalidCAFile(t *testing.T) {
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+	apps := map[string]config.AppVerifyConfig{
+		"bad-app": {CallbackTLS: config.CallbackTLSConfig{CAFile: caPath}},
+	}
+	if _, err := NewAppClients(config.VerificationConfig{Apps: apps}, time.Second, nil); err == nil {
+		t.Fatal("expected error for CA file without certificates")
+	}
+}
+
+func TestCallbackClientsApplyBaseTLS(t *testing.T) {
+	base := &tls.Config{MinVersion: tls.VersionTLS13}
+
+	shared := NewCallbackClient(time.Second, base)
+	if got := shared.Transport.(*http.Transport).TLSClientConfig.MinVersion; got != tls.VersionTLS13 {
+		t.Errorf("shared client MinVersion = %x, want TLS 1.3", got)
+	}
+
+	apps := map[string]config.AppVerifyConfig{
+		"secure-app": {CallbackTLS: config.CallbackTLSConfig{ServerName: "example.com"}},
+	}
+	clients, err := NewAppClients(config.VerificationConfig{Apps: apps}, time.Second, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tlsCfg := clients["secure-app"].Transport.(*http.Transport).TLSClientConfig
+	if tlsCfg.MinVersion != tls.VersionTLS13 {
+		t.Errorf("app client MinVersion = %x, want TLS 1.3", tlsCfg.MinVersion)
+	}
+	if tlsCfg.ServerName != "example.com" {
+		t.Errorf("app client ServerName = %q, want example.com", tlsCfg.ServerName)
+	}
+	if base.ServerName != "" {
+		t.Error("base TLS config must not be modified")
+	}
+}
+
+func TestHandler_AppWithCustomTLSUsesDedicatedClient(t *testing.T) {
+	ts := setupTest(t)
+
+	callbackCh := make(chan *http.Request, 1)
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callbackCh <- r
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(tlsServer.Close)
+
+	// Trust the test server only through the app's CA file.
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0644); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	apps := map[string]config.AppVerifyConfig{
+		"test-app": {
+			PublicKeyPath: writeAppPubKey(t, ts.appKey),
+			CallbackTLS:   config.CallbackTLSConfig{CAFile: caPath},
+		},
+	}
+	keyRegistry, err := auth.NewKeyRegistry(apps)
+	if err != nil {
+		t.Fatalf("failed to create key registry: %v", err)
+	}
+	jwtGen, err := auth.NewJWTGenerator(ts.gwKeyPath, "whatsadk-gateway", "", 2*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create jwt generator: %v", err)
+	}
+	clients, err := NewAppClients(config.VerificationConfig{Apps: apps}, 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("failed to build app clients: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg := config.VerificationConfig{Messages: ts.handler.messages}
+	// The shared client does not trust the test CA, so success proves the dedicated client was used.
+	handler := NewHandler(keyRegistry, jwtGen, ts.blacklist, cfg, &http.Client{Timeout: 5 * time.Second}, logger)
+	handler.SetAppClients(clients)
+
+	tokenStr := signTestVerificationToken(t, ts.appKey,
+		"910987654321", "test-app",
+		tlsServer.URL+"/callback", "abc-123",
+		time.Now().Add(5*time.Minute),
+	)
+
+	result := handler.Handle(context.Background(), "910987654321", tokenStr)
+	if !strings.Contains(result, "Verification successful") {
+		t.Fatalf("expected success via dedicated TLS client, got: %s", result)
+	}
+	select {
+	case <-callbackCh:
+	default:
+		t.Fatal("expected callback on TLS server")
+	}
+}
+
+func TestNewAppClients_RedirectChecks(t *testing.T) {
+	apps := map[string]config.AppVerifyConfig{
+		"app": {FollowRedirects: true, CallbackDomain: "example.com"},
+	}
+	clients, err := NewAppClients(config.VerificationConfig{Apps: apps}, time.Second, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check := clients["app"].CheckRedirect
+
+	via := []*http.Request{httptest.NewRequest(http.MethodPost, "https://api.example.com/callback", nil)}
+	tests := []struct {
+		name    string
+		target  string
+		hops    int
+		wantErr bool
+	}{
+		{"same domain", "https://auth.example.com/callback", 1, false},
+		{"plain http", "http://api.example.com/callback", 1, true},
+		{"off domain", "https://evil.test/callback", 1, true},
+		{"internal host", "https://169.254.169.254/latest/meta-data", 1, true},
+		{"too many hops", "https://auth.example.com/callback", maxCallbackRedirects, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hops []*http.Request
+			for i := 0; i < tt.hops; i++ {
+				hops = append(hops, via...)
+			}
+			req := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			if err := check(req, hops); (err != nil) != tt.wantErr {
+				t.Errorf("CheckRedirect(%s) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+		})
+	}
+}
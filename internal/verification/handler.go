@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/innomon/whatsadk/internal/auth"
 	"github.com/innomon/whatsadk/internal/config"
@@ -21,7 +22,16 @@ type Handler struct {
 	jwtGen        *auth.JWTGenerator
 	blacklist     BlacklistChecker
 	devOpsNumbers map[string]struct{}
+	devOpsGrace   time.Duration
 	httpClient    *http.Client
+	appClients    map[string]*http.Client
+	successCodes  map[string][]int
+	domains       map[string]string
+	verbose       map[string]bool
+	allowHTTP     bool
+	failOpen      bool
+	pending       *PendingLocks
+	userAgent     string
 	messages      config.VerificationMessages
 	logger        *slog.Logger
 }
@@ -38,17 +48,84 @@ func NewHandler(
 	for _, n := range cfg.DevOpsNumbers {
 		devOps[normalizePhone(n)] = struct{}{}
 	}
+	successCodes := make(map[string][]int)
+	verbose := make(map[string]bool)
+	domains := make(map[string]string)
+	for appName, appCfg := range cfg.Apps {
+		if appCfg.CallbackDomain != "" {
+			domains[appName] = appCfg.CallbackDomain
+		}
+		if len(appCfg.SuccessStatuses) > 0 {
+			successCodes[appName] = appCfg.SuccessStatuses
+		}
+		if strings.EqualFold(appCfg.LogLevel, "debug") {
+			verbose[appName] = true
+		}
+	}
 	return &Handler{
 		keys:          keys,
 		jwtGen:        jwtGen,
 		blacklist:     blacklist,
 		devOpsNumbers: devOps,
 		httpClient:    httpClient,
+		successCodes:  successCodes,
+		domains:       domains,
+		verbose:       verbose,
+		allowHTTP:     cfg.AllowHTTPCallbacks,
+		userAgent:     config.DefaultUserAgent(),
 		messages:      cfg.Messages,
 		logger:        logger,
 	}
 }
 
+// SetAppClients registers dedicated callback clients keyed by app name, as
+// built by NewAppClients. Apps without an entry use the shared client.
+func (h *Handler) SetAppClients(clients map[string]*http.Client) {
+	h.appClients = clients
+}
+
+// SetFailOpen makes blacklist lookup failures non-fatal: verification
+// proceeds in degraded mode instead of returning the error message.
+func (h *Handler) SetFailOpen(failOpen bool) {
+	h.failOpen = failOpen
+}
+
+// SetUserAgent overrides the User-Agent sent with verification callbacks.
+func (h *Handler) SetUserAgent(ua string) {
+	h.userAgent = ua
+}
+
+// SetDevOpsExpiryGrace lets devops numbers use verification tokens that
+// expired up to grace ago, e.g. to test stale links. Other numbers always
+// get strict expiry.
+func (h *Handler) SetDevOpsExpiryGrace(grace time.Duration) {
+	h.devOpsGrace = grace
+}
+
+// SetPendingLocks enables single-active-verification enforcement: while
+// one app's verification callback for a phone is in flight, possibly on
+// another gateway instance, other apps' tokens are rejected.
+func (h *Handler) SetPendingLocks(locks *PendingLocks) {
+	h.pending = locks
+}
+
+// trace logs a verification step for appName: at INFO for apps with
+// log_level "debug", otherwise at DEBUG.
+func (h *Handler) trace(ctx context.Context, appName, msg string, args ...any) {
+	level := slog.LevelDebug
+	if h.verbose[appName] {
+		level = slog.LevelInfo
+	}
+	h.logger.Log(ctx, level, msg, append([]any{"app", appName}, args...)...)
+}
+
+func (h *Handler) clientFor(appName string) *http.Client {
+	if c, ok := h.appClients[appName]; ok {
+		return c
+	}
+	return h.httpClient
+}
+
 func (h *Handler) Handle(ctx context.Context, senderPhone, messageBody string) string {
 	claims := auth.IsVerificationToken(messageBody)
 	if claims == nil {
@@ -56,17 +133,23 @@ func (h *Handler) Handle(ctx context.Context, senderPhone, messageBody string) s
 	}
 
 	senderNormalized := normalizePhone(senderPhone)
+	// The claims are unverified here; the app name only selects verbosity.
+	h.trace(ctx, claims.AppName, "verification token received", "phone", senderNormalized, "challenge_id", claims.ChallengeID)
 
 	if h.blacklist != nil {
 		blocked, err := h.blacklist.IsBlacklisted(ctx, senderNormalized)
 		if err != nil {
-			h.logger.Error("blacklist check failed", "error", err, "phone", senderNormalized)
-			return h.messages.Error
+			if !h.failOpen {
+				h.logger.Error("blacklist check failed", "error", err, "phone", senderNormalized)
+				return h.messages.Error
+			}
+			h.logger.Warn("blacklist check failed, continuing in degraded mode", "error", err, "phone", senderNormalized)
 		}
 		if blocked {
 			h.logger.Warn("blacklisted number attempted verification", "phone", senderNormalized)
 			return h.messages.Blacklisted
 		}
+		h.trace(ctx, claims.AppName, "blacklist check passed", "phone", senderNormalized)
 	}
 
 	appKey, err := h.keys.GetAppPublicKey(claims.AppName)
@@ -75,11 +158,23 @@ func (h *Handler) Handle(ctx context.Context, senderPhone, messageBody string) s
 		return h.messages.Error
 	}
 
-	verified, err := auth.VerifyVerificationToken(messageBody, appKey)
+	var leeway time.Duration
+	if _, isDevOps := h.devOpsNumbers[senderNormalized]; isDevOps {
+		leeway = h.devOpsGrace
+	}
+	verified, err := auth.VerifyVerificationTokenWithLeeway(messageBody, appKey, leeway)
 	if err != nil {
 		h.logger.Warn("verification token invalid", "error", err, "app", claims.AppName)
 		return h.messages.Expired
 	}
+	if verified.ExpiresAt != nil && time.Now().After(verified.ExpiresAt.Time) {
+		h.logger.Info("devops override: expired token accepted within grace",
+			"sender", senderNormalized,
+			"app", verified.AppName,
+			"expired_at", verified.ExpiresAt.Time,
+		)
+	}
+	h.trace(ctx, claims.AppName, "verification token signature valid", "claim_mobile", normalizePhone(verified.Mobile))
 
 	mobileNormalized := normalizePhone(verified.Mobile)
 	if senderNormalized != mobileNormalized {
@@ -96,15 +191,60 @@ func (h *Handler) Handle(ctx context.Context, senderPhone, messageBody string) s
 		)
 	}
 
+	callbackURL, stripped, err := sanitizeCallbackURL(verified.CallbackURL, h.allowHTTP)
+	if err != nil {
+		h.logger.Warn("callback URL rejected", "error", err, "app", verified.AppName)
+		return h.messages.Error
+	}
+	if stripped {
+		h.logger.Warn("stripped credentials from callback URL", "app", verified.AppName, "url", callbackURL)
+	}
+	if domain, ok := h.domains[verified.AppName]; ok && !callbackHostAllowed(callbackURL, domain) {
+		h.logger.Warn("callback host does not match app domain",
+			"app", verified.AppName,
+			"url", callbackURL,
+			"callback_domain", domain,
+		)
+		return h.messages.CallbackMismatch
+	}
+	h.trace(ctx, verified.AppName, "callback URL accepted", "url", callbackURL)
+
+	if h.pending != nil {
+		holder, err := h.pending.acquire(ctx, senderNormalized, verified.AppName, verified.ChallengeID)
+		if err != nil {
+			if !h.failOpen {
+				h.logger.Error("pending verification check failed", "error", err, "phone", senderNormalized)
+				return h.messages.Error
+			}
+			h.logger.Warn("pending verification check failed, continuing in degraded mode", "error", err, "phone", senderNormalized)
+		}
+		if holder != "" {
+			h.logger.Warn("verification already pending for another app",
+				"phone", senderNormalized,
+				"app", verified.AppName,
+				"pending_app", holder,
+			)
+			return h.messages.Pending
+		}
+		if err == nil {
+			// The reservation covers this attempt only. It is dropped
+			// whether the callback succeeds or fails, so an app whose
+			// callback is down cannot lock the user out of other apps.
+			defer h.releasePending(ctx, senderNormalized, verified.AppName)
+		}
+		h.trace(ctx, verified.AppName, "pending verification lock acquired", "phone", senderNormalized)
+	}
+
 	callbackJWT, err := h.jwtGen.TokenWithAudience(senderNormalized, verified.AppName)
 	if err != nil {
 		h.logger.Error("failed to sign callback JWT", "error", err)
 		return h.messages.Error
 	}
 
-	if err := h.postCallback(ctx, verified.CallbackURL, callbackJWT); err != nil {
+	h.trace(ctx, verified.AppName, "posting verification callback", "url", callbackURL)
+	if err := h.postCallback(ctx, h.clientFor(verified.AppName), callbackURL, callbackJWT, h.successCodes[verified.AppName]); err != nil {
 		h.logger.Error("callback failed",
-			"url", verified.CallbackURL,
+			"url", callbackURL,
 			"error", err,
 		)
 		return h.messages.Error
@@ -118,27 +258,50 @@ func (h *Handler) Handle(ctx context.Context, senderPhone, messageBody string) s
 	return h.messages.Success
 }
 
-func (h *Handler) postCallback(ctx context.Context, callbackURL, jwtToken string) error {
+// releasePending drops app's reservation on phone. A failed release is left
+// to expire with the lock's TTL.
+func (h *Handler) releasePending(ctx context.Context, phone, app string) {
+	if err := h.pending.release(context.WithoutCancel(ctx), phone, app); err != nil {
+		h.logger.Warn("failed to release pending verification", "error", err, "phone", phone)
+	}
+}
+
+func (h *Handler) postCallback(ctx context.Context, client *http.Client, callbackURL, jwtToken string, successCodes []int) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, nil)
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+jwtToken)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", h.userAgent)
 
-	resp, err := h.httpClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("execute callback: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
+	if !callbackSucceeded(resp.StatusCode, successCodes) {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
 		return fmt.Errorf("callback returned %d: %s", resp.StatusCode, string(body))
 	}
 	return nil
 }
 
+// callbackSucceeded reports whether status is in successCodes, or is 2xx when
+// no codes are configured.
+func callbackSucceeded(status int, successCodes []int) bool {
+	if len(successCodes) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, code := range successCodes {
+		if status == code {
+			return true
+		}
+	}
+	return false
+}
+
 func normalizePhone(phone string) string {
 	return strings.Map(func(r rune) rune {
 		if r >= '0' && r <= '9' {
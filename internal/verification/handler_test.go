@@ -1,11 +1,13 @@
 package verification
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -23,9 +25,13 @@ import (
 
 type mockBlacklist struct {
 	blocked map[string]bool
+	err     error
 }
 
 func (m *mockBlacklist) IsBlacklisted(_ context.Context, phone string) (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
 	return m.blocked[phone], nil
 }
 
@@ -100,7 +106,9 @@ func setupTest(t *testing.T) *testSetup {
 	}))
 	t.Cleanup(server.Close)
 
+	// The httptest callback servers speak plain http.
 	cfg := config.VerificationConfig{
+		AllowHTTPCallbacks: true,
 		Messages: config.VerificationMessages{
 			Success:       "✅ Verification successful! You can now return to the app.",
 			Expired:       "❌ Verification failed. The link may have expired. Please request a new one from the app.",
@@ -195,6 +203,29 @@ func TestHandler_SuccessFlow(t *testing.T) {
 	}
 }
 
+func TestHandler_CallbackUserAgent(t *testing.T) {
+	ts := setupTest(t)
+
+	tokenStr := signTestVerificationToken(t, ts.appKey,
+		"910987654321", "test-app",
+		ts.serverURL+"/callback", "abc-123",
+		time.Now().Add(5*time.Minute),
+	)
+
+	ts.handler.Handle(context.Background(), "910987654321", tokenStr)
+	req := <-ts.callbackCh
+	if got := req.Header.Get("User-Agent"); !strings.HasPrefix(got, "whatsadk/") {
+		t.Errorf("default User-Agent = %q, want whatsadk/<version>", got)
+	}
+
+	ts.handler.SetUserAgent("acme-gateway/2")
+	ts.handler.Handle(context.Background(), "910987654321", tokenStr)
+	req = <-ts.callbackCh
+	if got := req.Header.Get("User-Agent"); got != "acme-gateway/2" {
+		t.Errorf("User-Agent = %q, want acme-gateway/2", got)
+	}
+}
+
 func TestHandler_PhoneMismatch(t *testing.T) {
 	ts := setupTest(t)
 
@@ -228,7 +259,8 @@ func TestHandler_CallbackFails(t *testing.T) {
 
 	// Use the fail server's client so the handler hits the fail server
 	cfg := config.VerificationConfig{
-		Messages: ts.handler.messages,
+		AllowHTTPCallbacks: true,
+		Messages:           ts.handler.messages,
 	}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
 
@@ -247,6 +279,73 @@ func TestHandler_CallbackFails(t *testing.T) {
 	}
 }
 
+func TestHandler_CallbackStatusPolicy(t *testing.T) {
+	ts := setupTest(t)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(target.Close)
+
+	tests := []struct {
+		name    string
+		status  int
+		app     config.AppVerifyConfig
+		success bool
+	}{
+		{name: "redirect not followed is failure", status: http.StatusFound, success: false},
+		{name: "redirect followed when enabled", status: http.StatusFound, app: config.AppVerifyConfig{FollowRedirects: true}, success: true},
+		{name: "202 outside custom codes", status: http.StatusAccepted, app: config.AppVerifyConfig{SuccessStatuses: []int{http.StatusOK}}, success: false},
+		{name: "custom 202 is success", status: http.StatusAccepted, app: config.AppVerifyConfig{SuccessStatuses: []int{http.StatusAccepted}}, success: true},
+		{name: "default accepts 2xx", status: http.StatusNoContent, success: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status == http.StatusFound {
+					http.Redirect(w, r, target.URL, http.StatusFound)
+					return
+				}
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(server.Close)
+
+			appCfg := tt.app
+			appCfg.PublicKeyPath = writeAppPubKey(t, ts.appKey)
+			apps := map[string]config.AppVerifyConfig{"test-app": appCfg}
+			keyRegistry, err := auth.NewKeyRegistry(apps)
+			if err != nil {
+				t.Fatalf("failed to create key registry: %v", err)
+			}
+			jwtGen, err := auth.NewJWTGenerator(ts.gwKeyPath, "whatsadk-gateway", "", 2*time.Minute)
+			if err != nil {
+				t.Fatalf("failed to create jwt generator: %v", err)
+			}
+			cfg := config.VerificationConfig{Apps: apps, AllowHTTPCallbacks: true, Messages: ts.handler.messages}
+			clients, err := NewAppClients(cfg, 5*time.Second, nil)
+			if err != nil {
+				t.Fatalf("failed to build app clients: %v", err)
+			}
+
+			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			handler := NewHandler(keyRegistry, jwtGen, ts.blacklist, cfg, NewCallbackClient(5*time.Second, nil), logger)
+			handler.SetAppClients(clients)
+
+			tokenStr := signTestVerificationToken(t, ts.appKey,
+				"910987654321", "test-app",
+				server.URL+"/callback", "abc-123",
+				time.Now().Add(5*time.Minute),
+			)
+
+			result := handler.Handle(context.Background(), "910987654321", tokenStr)
+			if got := strings.Contains(result, "Verification successful"); got != tt.success {
+				t.Errorf("success = %v, want %v (reply %q)", got, tt.success, result)
+			}
+		})
+	}
+}
+
 func TestHandler_BlacklistedNumber(t *testing.T) {
 	ts := setupTest(t)
 
@@ -272,13 +371,55 @@ func TestHandler_BlacklistedNumber(t *testing.T) {
 	}
 }
 
+func TestHandler_BlacklistStoreDown(t *testing.T) {
+	tests := []struct {
+		name     string
+		failOpen bool
+		want     string
+		callback bool
+	}{
+		{"fail closed", false, "Something went wrong", false},
+		{"fail open", true, "Verification successful", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTest(t)
+			ts.blacklist.err = errors.New("connection refused")
+			ts.handler.SetFailOpen(tt.failOpen)
+
+			tokenStr := signTestVerificationToken(t, ts.appKey,
+				"910987654321", "test-app",
+				ts.serverURL+"/callback?challenge_id=abc-123", "abc-123",
+				time.Now().Add(5*time.Minute),
+			)
+
+			result := ts.handler.Handle(context.Background(), "910987654321", tokenStr)
+			if !strings.Contains(result, tt.want) {
+				t.Errorf("expected %q in result, got: %s", tt.want, result)
+			}
+
+			select {
+			case <-ts.callbackCh:
+				if !tt.callback {
+					t.Fatal("expected no callback when failing closed")
+				}
+			default:
+				if tt.callback {
+					t.Fatal("expected callback when failing open")
+				}
+			}
+		})
+	}
+}
+
 func TestHandler_PhoneMismatch_DevOps(t *testing.T) {
 	ts := setupTest(t)
 
 	// Re-create handler with devops number configured
 	cfg := config.VerificationConfig{
-		DevOpsNumbers: []string{"919999999999"},
-		Messages:      ts.handler.messages,
+		DevOpsNumbers:      []string{"919999999999"},
+		AllowHTTPCallbacks: true,
+		Messages:           ts.handler.messages,
 	}
 	apps := map[string]config.AppVerifyConfig{
 		"test-app": {PublicKeyPath: writeAppPubKey(t, ts.appKey)},
@@ -313,6 +454,54 @@ func TestHandler_PhoneMismatch_DevOps(t *testing.T) {
 	}
 }
 
+func TestHandler_DevOpsExpiryGrace(t *testing.T) {
+	tests := []struct {
+		name    string
+		sender  string
+		grace   time.Duration
+		expiry  time.Duration
+		success bool
+	}{
+		{"devops within grace", "919999999999", 10 * time.Minute, -time.Minute, true},
+		{"devops past grace", "919999999999", 10 * time.Minute, -15 * time.Minute, false},
+		{"devops without grace", "919999999999", 0, -time.Minute, false},
+		{"regular number with grace", "910987654321", 10 * time.Minute, -time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTest(t)
+			cfg := config.VerificationConfig{
+				DevOpsNumbers:      []string{"919999999999"},
+				AllowHTTPCallbacks: true,
+				Messages:           ts.handler.messages,
+			}
+			apps := map[string]config.AppVerifyConfig{
+				"test-app": {PublicKeyPath: writeAppPubKey(t, ts.appKey)},
+			}
+			keyRegistry, _ := auth.NewKeyRegistry(apps)
+			jwtGen, _ := auth.NewJWTGenerator(ts.gwKeyPath, "whatsadk-gateway", "", 2*time.Minute)
+			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			handler := NewHandler(keyRegistry, jwtGen, ts.blacklist, cfg, ts.server.Client(), logger)
+			handler.SetDevOpsExpiryGrace(tt.grace)
+
+			tokenStr := signTestVerificationToken(t, ts.appKey,
+				tt.sender, "test-app",
+				ts.serverURL+"/callback?challenge_id=abc-123", "abc-123",
+				time.Now().Add(tt.expiry),
+			)
+
+			result := handler.Handle(context.Background(), tt.sender, tokenStr)
+			if got := strings.Contains(result, "Verification successful"); got != tt.success {
+				t.Errorf("success = %v, want %v (result: %s)", got, tt.success, result)
+			}
+			if !tt.success && !strings.Contains(result, "expired") {
+				t.Errorf("expected expired message, got: %s", result)
+			}
+		})
+	}
+}
+
 func TestHandler_ExpiredToken(t *testing.T) {
 	ts := setupTest(t)
 
@@ -345,3 +534,108 @@ func writeAppPubKey(t *testing.T, key *rsa.PrivateKey) string {
 	}
 	return path
 }
+
+func TestHandler_PerAppVerboseLogging(t *testing.T) {
+	ts := setupTest(t)
+	apps := map[string]config.AppVerifyConfig{
+		"test-app":  {PublicKeyPath: writeAppPubKey(t, ts.appKey), LogLevel: "debug"},
+		"quiet-app": {PublicKeyPath: writeAppPubKey(t, ts.appKey)},
+	}
+	keyRegistry, err := auth.NewKeyRegistry(apps)
+	if err != nil {
+		t.Fatalf("failed to create key registry: %v", err)
+	}
+	jwtGen, err := auth.NewJWTGenerator(ts.gwKeyPath, "whatsadk-gateway", "", 2*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create jwt generator: %v", err)
+	}
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	cfg := config.VerificationConfig{AllowHTTPCallbacks: true, Apps: apps, Messages: ts.handler.messages}
+	handler := NewHandler(keyRegistry, jwtGen, ts.blacklist, cfg, ts.server.Client(), logger)
+
+	for _, tt := range []struct {
+		app         string
+		wantVerbose bool
+	}{
+		{"test-app", true},
+		{"quiet-app", false},
+	} {
+		logs.Reset()
+		tokenStr := signTestVerificationToken(t, ts.appKey,
+			"910987654321", tt.app,
+			ts.serverURL+"/callback", "abc-123",
+			time.Now().Add(5*time.Minute),
+		)
+		if result := handler.Handle(context.Background(), "910987654321", tokenStr); !strings.Contains(result, "Verification successful") {
+			t.Fatalf("%s: expected success, got: %s", tt.app, result)
+		}
+		<-ts.callbackCh
+
+		out := logs.String()
+		for _, step := range []string{"verification token received", "blacklist check passed", "callback URL accepted", "posting verification callback"} {
+			if got := strings.Contains(out, step); got != tt.wantVerbose {
+				t.Errorf("%s: step %q logged = %v, want %v", tt.app, step, got, tt.wantVerbose)
+			}
+		}
+		if !strings.Contains(out, "verification successful") {
+			t.Errorf("%s: outcome not logged: %s", tt.app, out)
+		}
+	}
+}
+
+func TestHandler_CallbackDomain(t *testing.T) {
+	ts := setupTest(t)
+	const mismatch = "❌ Verification failed. This link was not issued for this app."
+
+	tests := []struct {
+		name   string
+		domain string
+		want   string
+	}{
+		{"matching host", "127.0.0.1", "Verification successful"},
+		{"mismatching host", "example.com", mismatch},
+		{"no domain configured", "", "Verification successful"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apps := map[string]config.AppVerifyConfig{
+				"test-app": {PublicKeyPath: writeAppPubKey(t, ts.appKey), CallbackDomain: tt.domain},
+			}
+			keyRegistry, err := auth.NewKeyRegistry(apps)
+			if err != nil {
+				t.Fatalf("failed to create key registry: %v", err)
+			}
+			jwtGen, err := auth.NewJWTGenerator(ts.gwKeyPath, "whatsadk-gateway", "", 2*time.Minute)
+			if err != nil {
+				t.Fatalf("failed to create jwt generator: %v", err)
+			}
+			messages := ts.handler.messages
+			messages.CallbackMismatch = mismatch
+			cfg := config.VerificationConfig{Apps: apps, AllowHTTPCallbacks: true, Messages: messages}
+			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			handler := NewHandler(keyRegistry, jwtGen, ts.blacklist, cfg, ts.server.Client(), logger)
+
+			tokenStr := signTestVerificationToken(t, ts.appKey,
+				"910987654321", "test-app",
+				ts.serverURL+"/callback", "abc-123",
+				time.Now().Add(5*time.Minute),
+			)
+			result := handler.Handle(context.Background(), "910987654321", tokenStr)
+			if !strings.Contains(result, tt.want) {
+				t.Fatalf("reply = %q, want %q", result, tt.want)
+			}
+
+			select {
+			case <-ts.callbackCh:
+				if result == mismatch {
+					t.Error("callback posted despite host mismatch")
+				}
+			default:
+				if result != mismatch {
+					t.Error("expected a callback")
+				}
+			}
+		})
+	}
+}
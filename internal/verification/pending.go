@@ -0,0 +1,101 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PendingVerification reserves a phone for one app's verification until it
+// completes or ExpiresAt passes.
+type PendingVerification struct {
+	Phone       string    `json:"phone"`
+	AppName     string    `json:"app_name"`
+	ChallengeID string    `json:"challenge_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// PendingStore persists pending verifications keyed by phone. store.Store
+// implements it.
+type PendingStore interface {
+	GetPendingVerification(ctx context.Context, phone string) (*PendingVerification, error)
+	PutPendingVerification(ctx context.Context, p PendingVerification) error
+	DeletePendingVerification(ctx context.Context, phone string) error
+}
+
+// PendingLocks enforces a single active verification per phone. While one
+// app's verification is pending, tokens from other apps are rejected; the
+// same app may retry. Exempt apps neither take nor respect the lock.
+type PendingLocks struct {
+	store  PendingStore
+	ttl    time.Duration
+	exempt map[string]bool
+	now    func() time.Time
+
+	// mu serializes acquire's check-then-write within this process.
+	mu sync.Mutex
+}
+
+// NewPendingLocks creates a lock registry backed by store. A reservation
+// that is not released expires after ttl.
+func NewPendingLocks(store PendingStore, ttl time.Duration, exemptApps []string) *PendingLocks {
+	exempt := make(map[string]bool, len(exemptApps))
+	for _, app := range exemptApps {
+		exempt[app] = true
+	}
+	return &PendingLocks{store: store, ttl: ttl, exempt: exempt, now: time.Now}
+}
+
+// acquire reserves phone for app. It returns the app holding the phone, or
+// "" when the reservation was granted.
+func (l *PendingLocks) acquire(ctx context.Context, phone, app, challengeID string) (string, error) {
+	if l.exempt[app] {
+		return "", nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	existing, err := l.store.GetPendingVerification(ctx, phone)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pending verification: %w", err)
+	}
+	if existing != nil && existing.AppName != app && now.Before(existing.ExpiresAt) {
+		return existing.AppName, nil
+	}
+
+	p := PendingVerification{
+		Phone:       phone,
+		AppName:     app,
+		ChallengeID: challengeID,
+		ExpiresAt:   now.Add(l.ttl).UTC(),
+	}
+	if err := l.store.PutPendingVerification(ctx, p); err != nil {
+		return "", fmt.Errorf("failed to store pending verification: %w", err)
+	}
+	return "", nil
+}
+
+// release drops app's reservation on phone once its verification is done.
+func (l *PendingLocks) release(ctx context.Context, phone, app string) error {
+	if l.exempt[app] {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	existing, err := l.store.GetPendingVerification(ctx, phone)
+	if err != nil {
+		return fmt.Errorf("failed to get pending verification: %w", err)
+	}
+	if existing == nil || existing.AppName != app {
+		return nil
+	}
+	if err := l.store.DeletePendingVerification(ctx, phone); err != nil {
+		return fmt.Errorf("failed to delete pending verification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package verification
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/auth"
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+type memPendingStore struct {
+	entries map[string]PendingVerification
+}
+
+func newMemPendingStore() *memPendingStore {
+	return &memPendingStore{entries: make(map[string]PendingVerification)}
+}
+
+func (m *memPendingStore) GetPendingVerification(_ context.Context, phone string) (*PendingVerification, error) {
+	p, ok := m.entries[phone]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+func (m *memPendingStore) PutPendingVerification(_ context.Context, p PendingVerification) error {
+	m.entries[p.Phone] = p
+	return nil
+}
+
+func (m *memPendingStore) DeletePendingVerification(_ context.Context, phone string) error {
+	delete(m.entries, phone)
+	return nil
+}
+
+func TestPendingLocks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	locks := NewPendingLocks(newMemPendingStore(), 10*time.Minute, []string{"exempt-app"})
+	locks.now = func() time.Time { return now }
+
+	acquire := func(app string) string {
+		t.Helper()
+		holder, err := locks.acquire(ctx, "910987654321", app, "c-"+app)
+		if err != nil {
+			t.Fatalf("acquire(%s) error: %v", app, err)
+		}
+		return holder
+	}
+
+	if holder := acquire("app-a"); holder != "" {
+		t.Fatalf("first verification blocked by %q", holder)
+	}
+	if holder := acquire("app-b"); holder != "app-a" {
+		t.Errorf("second app holder = %q, want app-a", holder)
+	}
+	if holder := acquire("app-a"); holder != "" {
+		t.Errorf("retry by the same app blocked by %q", holder)
+	}
+	if holder := acquire("exempt-app"); holder != "" {
+		t.Errorf("exempt app blocked by %q", holder)
+	}
+
+	// Expiry releases the lock without an explicit release.
+	now = now.Add(10 * time.Minute)
+	if holder := acquire("app-b"); holder != "" {
+		t.Errorf("after expiry, holder = %q, want none", holder)
+	}
+
+	// Release frees the phone for the next app.
+	if err := locks.release(ctx, "910987654321", "app-b"); err != nil {
+		t.Fatalf("release() error: %v", err)
+	}
+	if holder := acquire("app-a"); holder != "" {
+		t.Errorf("after release, holder = %q, want none", holder)
+	}
+}
+
+func TestPendingLocksReleaseKeepsOtherApp(t *testing.T) {
+	ctx := context.Background()
+	store := newMemPendingStore()
+	locks := NewPendingLocks(store, time.Minute, nil)
+
+	if _, err := locks.acquire(ctx, "910987654321", "app-a", "c1"); err != nil {
+		t.Fatalf("acquire() error: %v", err)
+	}
+	if err := locks.release(ctx, "910987654321", "app-b"); err != nil {
+		t.Fatalf("release() error: %v", err)
+	}
+	if _, ok := store.entries["910987654321"]; !ok {
+		t.Error("release by another app must not drop the reservation")
+	}
+}
+
+func TestHandler_SecondVerificationRejected(t *testing.T) {
+	ts := setupTest(t)
+	ts.handler.messages.Pending = "⏳ Another verification is in progress."
+	store := newMemPendingStore()
+	store.entries["910987654321"] = PendingVerification{
+		Phone:     "910987654321",
+		AppName:   "other-app",
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	ts.handler.SetPendingLocks(NewPendingLocks(store, time.Minute, nil))
+
+	tokenStr := signTestVerificationToken(t, ts.appKey,
+		"910987654321", "test-app",
+		ts.serverURL+"/callback", "abc-123",
+		time.Now().Add(5*time.Minute),
+	)
+
+	result := ts.handler.Handle(context.Background(), "910987654321", tokenStr)
+	if !strings.Contains(result, "in progress") {
+		t.Errorf("expected pending message, got: %s", result)
+	}
+	select {
+	case <-ts.callbackCh:
+		t.Error("callback must not be posted while another verification is pending")
+	default:
+	}
+
+	// Once the other app's reservation lapses, verification goes through
+	// and its own reservation is released.
+	delete(store.entries, "910987654321")
+	result = ts.handler.Handle(context.Background(), "910987654321", tokenStr)
+	if !strings.Contains(result, "Verification successful") {
+		t.Errorf("expected success message, got: %s", result)
+	}
+	if _, ok := store.entries["910987654321"]; ok {
+		t.Error("successful verification should release the reservation")
+	}
+}
+
+func TestHandler_SingleActiveAcrossApps(t *testing.T) {
+	ts := setupTest(t)
+
+	entered := make(chan struct{})
+	proceed := make(chan struct{})
+	var appAStatus atomic.Int64
+	appAStatus.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app-a" {
+			entered <- struct{}{}
+			<-proceed
+			w.WriteHeader(int(appAStatus.Load()))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	pubKey := writeAppPubKey(t, ts.appKey)
+	keyRegistry, err := auth.NewKeyRegistry(map[string]config.AppVerifyConfig{
+		"app-a": {PublicKeyPath: pubKey},
+		"app-b": {PublicKeyPath: pubKey},
+	})
+	if err != nil {
+		t.Fatalf("key registry: %v", err)
+	}
+	jwtGen, err := auth.NewJWTGenerator(ts.gwKeyPath, "whatsadk-gateway", "", 2*time.Minute)
+	if err != nil {
+		t.Fatalf("jwt generator: %v", err)
+	}
+	messages := ts.handler.messages
+	messages.Pending = "⏳ Another verification is in progress."
+	cfg := config.VerificationConfig{AllowHTTPCallbacks: true, Messages: messages}
+	handler := NewHandler(keyRegistry, jwtGen, ts.blacklist, cfg, server.Client(), slog.New(slog.DiscardHandler))
+	store := newMemPendingStore()
+	handler.SetPendingLocks(NewPendingLocks(store, time.Hour, nil))
+
+	const phone = "910987654321"
+	token := func(app string) string {
+		return signTestVerificationToken(t, ts.appKey, phone, app, server.URL+"/"+app, "c-"+app, time.Now().Add(5*time.Minute))
+	}
+	ctx := context.Background()
+
+	// While app-a's callback is in flight, app-b is turned away.
+	resultA := make(chan string, 1)
+	go func() { resultA <- handler.Handle(ctx, phone, token("app-a")) }()
+	<-entered
+	if got := handler.Handle(ctx, phone, token("app-b")); got != messages.Pending {
+		t.Errorf("app-b during app-a's verification = %q, want pending message", got)
+	}
+	proceed <- struct{}{}
+	if got := <-resultA; got != messages.Success {
+		t.Errorf("app-a = %q, want success", got)
+	}
+	if got := handler.Handle(ctx, phone, token("app-b")); got != messages.Success {
+		t.Errorf("app-b after app-a completed = %q, want success", got)
+	}
+
+	// A failed callback releases the reservation too, so app-a being down
+	// does not lock the user out of app-b.
+	appAStatus.Store(http.StatusInternalServerError)
+	go func() { resultA <- handler.Handle(ctx, phone, token("app-a")) }()
+	<-entered
+	proceed <- struct{}{}
+	if got := <-resultA; got != messages.Error {
+		t.Errorf("app-a with failing callback = %q, want error message", got)
+	}
+	if got := handler.Handle(ctx, phone, token("app-b")); got != messages.Success {
+		t.Errorf("app-b after app-a failed = %q, want success", got)
+	}
+	if len(store.entries) != 0 {
+		t.Errorf("reservations left behind: %+v", store.entries)
+	}
+}
@@ -3,6 +3,7 @@ package waba
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -28,12 +29,21 @@ func NewClient(cfg *config.WABAConfig) *Client {
 	}
 }
 
+// SetTLSConfig applies outbound TLS restrictions (minimum version, cipher
+// suites) to Graph API requests.
+func (c *Client) SetTLSConfig(tlsCfg *tls.Config) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	c.httpClient.Transport = transport
+}
+
 type MessageRequest struct {
-	MessagingProduct string       `json:"messaging_product"`
-	To               string       `json:"to"`
-	Type             string       `json:"type"`
-	Text             *TextObject  `json:"text,omitempty"`
-	Image            *ImageObject `json:"image,omitempty"`
+	MessagingProduct string          `json:"messaging_product"`
+	To               string          `json:"to"`
+	Type             string          `json:"type"`
+	Text             *TextObject     `json:"text,omitempty"`
+	Image            *ImageObject    `json:"image,omitempty"`
+	Template         *TemplateObject `json:"template,omitempty"`
 }
 
 type TextObject struct {
@@ -0,0 +1,22 @@
+package waba
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestSetTLSConfig(t *testing.T) {
+	c := NewClient(&config.WABAConfig{})
+	c.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS13})
+
+	transport, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", c.httpClient.Transport)
+	}
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("MinVersion = %x, want TLS 1.3", transport.TLSClientConfig.MinVersion)
+	}
+}
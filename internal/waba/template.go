@@ -0,0 +1,125 @@
+package waba
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+var (
+	// ErrUnknownTemplate is returned for a template not declared in
+	// waba.templates.
+	ErrUnknownTemplate = errors.New("unknown template")
+	// ErrTemplateParams is returned when the parameter count does not
+	// match the template definition.
+	ErrTemplateParams = errors.New("template parameter count mismatch")
+)
+
+type TemplateObject struct {
+	Name       string              `json:"name"`
+	Language   TemplateLanguage    `json:"language"`
+	Components []TemplateComponent `json:"components,omitempty"`
+}
+
+type TemplateLanguage struct {
+	Code string `json:"code"`
+}
+
+type TemplateComponent struct {
+	Type       string              `json:"type"`
+	Parameters []TemplateParameter `json:"parameters"`
+}
+
+type TemplateParameter struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// buildTemplateMessage returns the send request for template name with
+// params filling its body placeholders in order, after checking them
+// against the declared templates.
+func buildTemplateMessage(templates map[string]config.WABATemplateConfig, to, name string, params []string) (MessageRequest, error) {
+	def, ok := templates[name]
+	if !ok {
+		return MessageRequest{}, fmt.Errorf("%w %q", ErrUnknownTemplate, name)
+	}
+	if len(params) != def.BodyParameters {
+		return MessageRequest{}, fmt.Errorf("%w: %q takes %d, got %d", ErrTemplateParams, name, def.BodyParameters, len(params))
+	}
+
+	tmpl := &TemplateObject{Name: name, Language: TemplateLanguage{Code: def.Language}}
+	if len(params) > 0 {
+		body := TemplateComponent{Type: "body"}
+		for _, p := range params {
+			body.Parameters = append(body.Parameters, TemplateParameter{Type: "text", Text: p})
+		}
+		tmpl.Components = []TemplateComponent{body}
+	}
+	return MessageRequest{
+		MessagingProduct: "whatsapp",
+		To:               to,
+		Type:             "template",
+		Template:         tmpl,
+	}, nil
+}
+
+// SendTemplate sends a pre-approved template, the only kind of message a
+// business may start a conversation with.
+func (c *Client) SendTemplate(ctx context.Context, to, name string, params []string) error {
+	reqBody, err := buildTemplateMessage(c.cfg.Templates, to, name, params)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, reqBody)
+}
+
+// TemplateSender sends template messages.
+type TemplateSender interface {
+	SendTemplate(ctx context.Context, to, name string, params []string) error
+}
+
+// TemplateSendRequest is the body of a proactive template send.
+type TemplateSendRequest struct {
+	To         string   `json:"to"`
+	Template   string   `json:"template"`
+	Parameters []string `json:"parameters"`
+}
+
+// NewTemplateSendHandler returns the proactive send endpoint. Requests for
+// undeclared templates or with the wrong parameter count are rejected with
+// 400 before anything is sent. Callers must wrap it in authentication.
+func NewTemplateSendHandler(sender TemplateSender) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req TemplateSendRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if req.To == "" || req.Template == "" {
+			http.Error(w, "to and template are required", http.StatusBadRequest)
+			return
+		}
+
+		err := sender.SendTemplate(r.Context(), req.To, req.Template, req.Parameters)
+		switch {
+		case errors.Is(err, ErrUnknownTemplate), errors.Is(err, ErrTemplateParams):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			slog.Error("template send failed", "to", req.To, "template", req.Template, "error", err)
+			http.Error(w, "send failed", http.StatusBadGateway)
+			return
+		}
+		slog.Info("template sent", "to", req.To, "template", req.Template)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,108 @@
+package waba
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+var testTemplates = map[string]config.WABATemplateConfig{
+	"order_update": {Language: "en_US", BodyParameters: 2},
+	"hello_world":  {Language: "en_US"},
+}
+
+func TestBuildTemplateMessage(t *testing.T) {
+	msg, err := buildTemplateMessage(testTemplates, "919876543210", "order_update", []string{"Asha", "#1234"})
+	if err != nil {
+		t.Fatalf("buildTemplateMessage() error: %v", err)
+	}
+	got, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"messaging_product":"whatsapp","to":"919876543210","type":"template","template":{"name":"order_update","language":{"code":"en_US"},` +
+		`"components":[{"type":"body","parameters":[{"type":"text","text":"Asha"},{"type":"text","text":"#1234"}]}]}}`
+	if string(got) != want {
+		t.Errorf("message =\n%s\nwant\n%s", got, want)
+	}
+
+	msg, err = buildTemplateMessage(testTemplates, "919876543210", "hello_world", nil)
+	if err != nil {
+		t.Fatalf("buildTemplateMessage() error: %v", err)
+	}
+	if msg.Template.Components != nil {
+		t.Errorf("components = %+v, want none for a template without parameters", msg.Template.Components)
+	}
+}
+
+func TestBuildTemplateMessageRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		params   []string
+		want     error
+	}{
+		{"too few parameters", "order_update", []string{"Asha"}, ErrTemplateParams},
+		{"too many parameters", "hello_world", []string{"extra"}, ErrTemplateParams},
+		{"unknown template", "promo", nil, ErrUnknownTemplate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildTemplateMessage(testTemplates, "919876543210", tt.template, tt.params); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+type fakeTemplateSender struct {
+	sent []TemplateSendRequest
+	err  error
+}
+
+func (f *fakeTemplateSender) SendTemplate(_ context.Context, to, name string, params []string) error {
+	if _, err := buildTemplateMessage(testTemplates, to, name, params); err != nil {
+		return err
+	}
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, TemplateSendRequest{To: to, Template: name, Parameters: params})
+	return nil
+}
+
+func TestTemplateSendHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		sendErr error
+		want    int
+	}{
+		{"valid send", `{"to":"919876543210","template":"order_update","parameters":["Asha","#1234"]}`, nil, http.StatusNoContent},
+		{"parameter mismatch", `{"to":"919876543210","template":"order_update","parameters":["Asha"]}`, nil, http.StatusBadRequest},
+		{"unknown template", `{"to":"919876543210","template":"promo"}`, nil, http.StatusBadRequest},
+		{"missing recipient", `{"template":"hello_world"}`, nil, http.StatusBadRequest},
+		{"invalid JSON", `{`, nil, http.StatusBadRequest},
+		{"upstream failure", `{"to":"919876543210","template":"hello_world"}`, errors.New("WABA API error (500)"), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &fakeTemplateSender{err: tt.sendErr}
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/admin/send_template", strings.NewReader(tt.body))
+			NewTemplateSendHandler(sender).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if (tt.want == http.StatusNoContent) != (len(sender.sent) == 1) {
+				t.Errorf("sent = %+v", sender.sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package whatsapp
+
+import (
+	"context"
+
+	"go.mau.fi/whatsmeow/types/events"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// settleAck returns the reaction that ends a message's handling: emoji, or
+// an empty reaction withdrawing cfg.Received so it does not linger. send is
+// false when there is nothing to change.
+func settleAck(cfg config.AckReactionsConfig, emoji string) (reaction string, send bool) {
+	return emoji, emoji != "" || cfg.Received != ""
+}
+
+// verificationAck returns the reaction for a verification reply: Verified
+// for the configured success message, VerificationFailed otherwise.
+func verificationAck(cfg config.AckReactionsConfig, response, success string) string {
+	if response == success {
+		return cfg.Verified
+	}
+	return cfg.VerificationFailed
+}
+
+// ackReact reacts to msg with emoji; an empty emoji removes the gateway's
+// earlier reaction.
+func (c *Client) ackReact(ctx context.Context, msg *events.Message, emoji string) {
+	_, err := c.wac.SendMessage(ctx, msg.Info.Chat, c.wac.BuildReaction(msg.Info.Chat, msg.Info.Sender, msg.Info.ID, emoji))
+	if err != nil {
+		c.log.Warnf("Failed to react to message %s: %v", msg.Info.ID, err)
+	}
+}
+
+// settleAckReact ends msg's acknowledgement with emoji, see settleAck.
+func (c *Client) settleAckReact(ctx context.Context, msg *events.Message, emoji string) {
+	if reaction, send := settleAck(c.cfg.WhatsApp.AckReactions, emoji); send {
+		c.ackReact(ctx, msg, reaction)
+	}
+}
@@ -0,0 +1,38 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestSettleAck(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.AckReactionsConfig
+		emoji    string
+		want     string
+		wantSend bool
+	}{
+		{"disabled", config.AckReactionsConfig{}, "", "", false},
+		{"replace received", config.AckReactionsConfig{Received: "⏳", Replied: "✅"}, "✅", "✅", true},
+		{"withdraw received", config.AckReactionsConfig{Received: "⏳"}, "", "", true},
+		{"without received", config.AckReactionsConfig{Failed: "⚠️"}, "⚠️", "⚠️", true},
+	}
+	for _, tt := range tests {
+		got, send := settleAck(tt.cfg, tt.emoji)
+		if got != tt.want || send != tt.wantSend {
+			t.Errorf("%s: settleAck() = %q, %v, want %q, %v", tt.name, got, send, tt.want, tt.wantSend)
+		}
+	}
+}
+
+func TestVerificationAck(t *testing.T) {
+	cfg := config.AckReactionsConfig{Verified: "✅", VerificationFailed: "❌"}
+	if got := verificationAck(cfg, "Verified!", "Verified!"); got != "✅" {
+		t.Errorf("success = %q", got)
+	}
+	if got := verificationAck(cfg, "Token expired", "Verified!"); got != "❌" {
+		t.Errorf("failure = %q", got)
+	}
+}
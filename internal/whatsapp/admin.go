@@ -0,0 +1,184 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// adminStore is the store surface admin commands act on.
+type adminStore interface {
+	AddBlacklist(ctx context.Context, phone, reason string) error
+	RemoveBlacklist(ctx context.Context, phone string) error
+	ListBlacklist(ctx context.Context) ([]store.BlacklistedNumber, error)
+}
+
+// adminCommand runs one admin command with the words following its name
+// and returns the reply.
+type adminCommand func(ctx context.Context, args []string) (string, error)
+
+// adminDispatcher runs slash commands sent by devops numbers, e.g.
+// "/block 919876543210 spam", instead of passing them to the agent.
+type adminDispatcher struct {
+	admins   map[string]struct{}
+	commands map[string]adminCommand
+}
+
+// maxListedBlacklist bounds the numbers "/blacklist list" replies with.
+const maxListedBlacklist = 50
+
+// newAdminDispatcher registers the built-in commands for admins. block and
+// unblock also update WhatsApp's own blocklist and are given user JIDs, not
+// bare numbers; stats describes the gateway.
+func newAdminDispatcher(admins []string, s adminStore, block, unblock func(jid string) error, stats func(ctx context.Context) string) *adminDispatcher {
+	d := &adminDispatcher{admins: make(map[string]struct{}, len(admins))}
+	for _, a := range admins {
+		d.admins[a] = struct{}{}
+	}
+	d.commands = map[string]adminCommand{
+		"block": func(ctx context.Context, args []string) (string, error) {
+			if len(args) == 0 {
+				return "Usage: /block <phone> [reason]", nil
+			}
+			phone, err := adminPhone(args[0])
+			if err != nil {
+				return "", err
+			}
+			if err := s.AddBlacklist(ctx, phone, strings.Join(args[1:], " ")); err != nil {
+				return "", fmt.Errorf("blacklist %s: %w", phone, err)
+			}
+			if err := block(userJID(phone)); err != nil {
+				return fmt.Sprintf("Blacklisted %s, but the WhatsApp block failed: %v", phone, err), nil
+			}
+			return fmt.Sprintf("Blacklisted %s", phone), nil
+		},
+		"unblock": func(ctx context.Context, args []string) (string, error) {
+			if len(args) == 0 {
+				return "Usage: /unblock <phone>", nil
+			}
+			phone, err := adminPhone(args[0])
+			if err != nil {
+				return "", err
+			}
+			if err := s.RemoveBlacklist(ctx, phone); err != nil {
+				return "", fmt.Errorf("unblacklist %s: %w", phone, err)
+			}
+			if err := unblock(userJID(phone)); err != nil {
+				return fmt.Sprintf("Removed %s from the blacklist, but the WhatsApp unblock failed: %v", phone, err), nil
+			}
+			return fmt.Sprintf("Removed %s from the blacklist", phone), nil
+		},
+		"blacklist": func(ctx context.Context, args []string) (string, error) {
+			if len(args) != 1 || !strings.EqualFold(args[0], "list") {
+				return "Usage: /blacklist list", nil
+			}
+			numbers, err := s.ListBlacklist(ctx)
+			if err != nil {
+				return "", err
+			}
+			return formatBlacklist(numbers), nil
+		},
+		"stats": func(ctx context.Context, _ []string) (string, error) {
+			return stats(ctx), nil
+		},
+	}
+	return d
+}
+
+// dispatch runs text as an admin command if phone is an admin and text
+// names a known command. Other messages, including unknown commands, are
+// left for the agent.
+func (d *adminDispatcher) dispatch(ctx context.Context, phone, text string) (string, bool) {
+	if _, ok := d.admins[phone]; !ok {
+		return "", false
+	}
+	name, args, ok := parseAdminCommand(text)
+	if !ok {
+		return "", false
+	}
+	cmd, ok := d.commands[name]
+	if !ok {
+		return "", false
+	}
+	reply, err := cmd(ctx, args)
+	if err != nil {
+		return fmt.Sprintf("/%s failed: %v", name, err), true
+	}
+	return reply, true
+}
+
+// parseAdminCommand splits "/name arg..." into the lower-cased name and
+// its arguments.
+func parseAdminCommand(text string) (string, []string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || len(fields[0]) < 2 || fields[0][0] != '/' {
+		return "", nil, false
+	}
+	return strings.ToLower(fields[0][1:]), fields[1:], true
+}
+
+// adminPhone normalizes a phone argument to E.164 digits without "+".
+func adminPhone(arg string) (string, error) {
+	phone := strings.TrimPrefix(arg, "+")
+	if phone == "" {
+		return "", fmt.Errorf("invalid phone number %q", arg)
+	}
+	for _, r := range phone {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("invalid phone number %q", arg)
+		}
+	}
+	return phone, nil
+}
+
+// formatBlacklist lists numbers newest first, one per line.
+func formatBlacklist(numbers []store.BlacklistedNumber) string {
+	if len(numbers) == 0 {
+		return "The blacklist is empty."
+	}
+	sorted := append([]store.BlacklistedNumber(nil), numbers...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].CreatedAt.After(sorted[j].CreatedAt) })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d blacklisted number(s):", len(sorted))
+	for i, n := range sorted {
+		if i == maxListedBlacklist {
+			fmt.Fprintf(&b, "\n… and %d more", len(sorted)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n%s", n.Phone)
+		if n.Reason != "" {
+			fmt.Fprintf(&b, " (%s)", n.Reason)
+		}
+		fmt.Fprintf(&b, " since %s", n.CreatedAt.UTC().Format(time.DateOnly))
+	}
+	return b.String()
+}
+
+// adminStats describes the gateway for "/stats".
+func (c *Client) adminStats(ctx context.Context) string {
+	var b strings.Builder
+	b.WriteString(formatStats(c.Stats(), time.Now()))
+	fmt.Fprintf(&b, "\nMessages in flight: %d", c.inflight.Load())
+	if c.sendq != nil {
+		fmt.Fprintf(&b, "\nSend queue: %d queued, %d dropped", c.sendq.depth(), c.sendq.dropped.Load())
+	}
+	numbers, err := c.store.ListBlacklist(ctx)
+	if err != nil {
+		fmt.Fprintf(&b, "\nBlacklist: unavailable (%v)", err)
+	} else {
+		fmt.Fprintf(&b, "\nBlacklisted numbers: %d", len(numbers))
+	}
+	return b.String()
+}
+
+// userJID returns the WhatsApp user JID of a bare phone number.
+func userJID(phone string) string {
+	return types.NewJID(phone, types.DefaultUserServer).String()
+}
@@ -0,0 +1,120 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+type fakeAdminStore struct {
+	numbers map[string]store.BlacklistedNumber
+}
+
+func (f *fakeAdminStore) AddBlacklist(_ context.Context, phone, reason string) error {
+	f.numbers[phone] = store.BlacklistedNumber{Phone: phone, Reason: reason, CreatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}
+	return nil
+}
+
+func (f *fakeAdminStore) RemoveBlacklist(_ context.Context, phone string) error {
+	delete(f.numbers, phone)
+	return nil
+}
+
+func (f *fakeAdminStore) ListBlacklist(_ context.Context) ([]store.BlacklistedNumber, error) {
+	var out []store.BlacklistedNumber
+	for _, n := range f.numbers {
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func TestAdminDispatcher(t *testing.T) {
+	const admin = "910000000000"
+	s := &fakeAdminStore{numbers: map[string]store.BlacklistedNumber{}}
+	var blocked []string
+	// Parse like RemoteBlock does, so a bare number would fail here.
+	block := func(jid string) error {
+		parsed, err := blockJID(jid)
+		if err != nil {
+			return err
+		}
+		blocked = append(blocked, parsed.User)
+		return nil
+	}
+	unblock := func(string) error { return errors.New("not connected") }
+	stats := func(context.Context) string { return "all good" }
+	d := newAdminDispatcher([]string{admin}, s, block, unblock, stats)
+	ctx := context.Background()
+
+	if _, ok := d.dispatch(ctx, "919811111111", "/block 919876543210"); ok {
+		t.Fatal("non-admin command handled")
+	}
+	if _, ok := d.dispatch(ctx, admin, "/reset"); ok {
+		t.Error("unknown command handled")
+	}
+	if _, ok := d.dispatch(ctx, admin, "block 919876543210"); ok {
+		t.Error("text without slash handled")
+	}
+
+	reply, ok := d.dispatch(ctx, admin, "/Block +919876543210 sends spam")
+	if !ok || reply != "Blacklisted 919876543210" {
+		t.Fatalf("/block = %q, %v", reply, ok)
+	}
+	if n := s.numbers["919876543210"]; n.Reason != "sends spam" {
+		t.Errorf("stored reason = %q", n.Reason)
+	}
+	if len(blocked) != 1 || blocked[0] != "919876543210" {
+		t.Errorf("remote blocks = %v", blocked)
+	}
+
+	reply, _ = d.dispatch(ctx, admin, "/blacklist list")
+	if !strings.Contains(reply, "919876543210 (sends spam) since 2026-10-01") {
+		t.Errorf("/blacklist list = %q", reply)
+	}
+
+	reply, _ = d.dispatch(ctx, admin, "/unblock 919876543210")
+	if !strings.Contains(reply, "WhatsApp unblock failed: not connected") {
+		t.Errorf("/unblock = %q", reply)
+	}
+	if _, ok := s.numbers["919876543210"]; ok {
+		t.Error("number still blacklisted after /unblock")
+	}
+
+	if reply, _ := d.dispatch(ctx, admin, "/block 91-98"); !strings.HasPrefix(reply, "/block failed: invalid phone number") {
+		t.Errorf("/block with bad number = %q", reply)
+	}
+	if reply, _ := d.dispatch(ctx, admin, "/blacklist"); reply != "Usage: /blacklist list" {
+		t.Errorf("/blacklist = %q", reply)
+	}
+	if reply, _ := d.dispatch(ctx, admin, "/stats"); reply != "all good" {
+		t.Errorf("/stats = %q", reply)
+	}
+}
+
+func TestFormatBlacklistTruncates(t *testing.T) {
+	numbers := make([]store.BlacklistedNumber, maxListedBlacklist+2)
+	for i := range numbers {
+		numbers[i] = store.BlacklistedNumber{Phone: "91", CreatedAt: time.Unix(int64(i), 0)}
+	}
+	got := formatBlacklist(numbers)
+	if !strings.HasSuffix(got, "… and 2 more") {
+		t.Errorf("formatBlacklist() tail = %q", got[len(got)-30:])
+	}
+	if formatBlacklist(nil) != "The blacklist is empty." {
+		t.Error("empty blacklist not reported")
+	}
+}
+
+func TestUserJIDIsBlockable(t *testing.T) {
+	jid, err := blockJID(userJID("919876543210"))
+	if err != nil || jid.User != "919876543210" {
+		t.Errorf("blockJID(userJID()) = %v, %v", jid, err)
+	}
+	if _, err := blockJID("120363000000000000@g.us"); err == nil {
+		t.Error("blockJID accepted a group JID")
+	}
+}
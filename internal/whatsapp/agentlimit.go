@@ -0,0 +1,97 @@
+package whatsapp
+
+import (
+	"sync"
+	"time"
+)
+
+// maxAgentLimitEntries bounds the per-user call history before idle users
+// are pruned.
+const maxAgentLimitEntries = 4096
+
+type agentLimitVerdict int
+
+const (
+	agentLimitAllow agentLimitVerdict = iota
+	// agentLimitNotice is returned for the first call over the limit, when
+	// the "slow down" reply should be sent.
+	agentLimitNotice
+	agentLimitSuppress
+)
+
+// agentLimiter caps agent calls per user over a sliding window, to bound
+// the cost of a single chatty user. Calls over the limit are not counted.
+type agentLimiter struct {
+	max    int
+	window time.Duration
+	exempt map[string]bool
+	now    func() time.Time
+
+	mu    sync.Mutex
+	users map[string]*agentCalls
+}
+
+type agentCalls struct {
+	at       []time.Time
+	notified bool
+}
+
+func newAgentLimiter(max int, window time.Duration, exempt []string) *agentLimiter {
+	l := &agentLimiter{
+		max:    max,
+		window: window,
+		exempt: make(map[string]bool, len(exempt)),
+		now:    time.Now,
+		users:  make(map[string]*agentCalls),
+	}
+	for _, u := range exempt {
+		l.exempt[u] = true
+	}
+	return l
+}
+
+// allow records an agent call for user if it is within the limit.
+func (l *agentLimiter) allow(user string) agentLimitVerdict {
+	if l.exempt[user] {
+		return agentLimitAllow
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	cutoff := now.Add(-l.window)
+	calls, ok := l.users[user]
+	if !ok {
+		if len(l.users) >= maxAgentLimitEntries {
+			l.prune(cutoff)
+		}
+		calls = &agentCalls{}
+		l.users[user] = calls
+	}
+	valid := calls.at[:0]
+	for _, t := range calls.at {
+		if t.After(cutoff) {
+			valid = append(valid, t)
+		}
+	}
+	calls.at = valid
+
+	if len(calls.at) >= l.max {
+		if !calls.notified {
+			calls.notified = true
+			return agentLimitNotice
+		}
+		return agentLimitSuppress
+	}
+	calls.at = append(calls.at, now)
+	calls.notified = false
+	return agentLimitAllow
+}
+
+func (l *agentLimiter) prune(cutoff time.Time) {
+	for u, calls := range l.users {
+		if n := len(calls.at); n == 0 || !calls.at[n-1].After(cutoff) {
+			delete(l.users, u)
+		}
+	}
+}
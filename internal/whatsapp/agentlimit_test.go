@@ -0,0 +1,46 @@
+package whatsapp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgentLimiter(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	l := newAgentLimiter(3, time.Minute, []string{"910000000000"})
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if got := l.allow("919876543210"); got != agentLimitAllow {
+			t.Fatalf("call %d = %v, want allow", i+1, got)
+		}
+		now = now.Add(10 * time.Second)
+	}
+	if got := l.allow("919876543210"); got != agentLimitNotice {
+		t.Errorf("4th call = %v, want notice", got)
+	}
+	if got := l.allow("919876543210"); got != agentLimitSuppress {
+		t.Errorf("5th call = %v, want suppress", got)
+	}
+	if got := l.allow("919811111111"); got != agentLimitAllow {
+		t.Errorf("other user = %v, want allow", got)
+	}
+
+	// The first call slides out of the window, freeing one slot.
+	now = now.Add(31 * time.Second)
+	if got := l.allow("919876543210"); got != agentLimitAllow {
+		t.Errorf("call after window slid = %v, want allow", got)
+	}
+	if got := l.allow("919876543210"); got != agentLimitNotice {
+		t.Errorf("next call over the limit = %v, want a fresh notice", got)
+	}
+}
+
+func TestAgentLimiterExempt(t *testing.T) {
+	l := newAgentLimiter(1, time.Minute, []string{"910000000000"})
+	for i := 0; i < 5; i++ {
+		if got := l.allow("910000000000"); got != agentLimitAllow {
+			t.Fatalf("exempt call %d = %v, want allow", i+1, got)
+		}
+	}
+}
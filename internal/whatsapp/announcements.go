@@ -0,0 +1,127 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+// announcementGroups remembers which groups are the announcement group of
+// a community. Whether a group is one never changes, so answers are kept
+// for the life of the process; failed lookups are not.
+type announcementGroups struct {
+	fetch func(ctx context.Context, jid types.JID) (*types.GroupInfo, error)
+
+	mu    sync.Mutex
+	cache map[types.JID]bool
+}
+
+func newAnnouncementGroups(fetch func(context.Context, types.JID) (*types.GroupInfo, error)) *announcementGroups {
+	return &announcementGroups{fetch: fetch, cache: make(map[types.JID]bool)}
+}
+
+// is reports whether jid is a community's announcement group.
+func (a *announcementGroups) is(ctx context.Context, jid types.JID) (bool, error) {
+	a.mu.Lock()
+	known, ok := a.cache[jid]
+	a.mu.Unlock()
+	if ok {
+		return known, nil
+	}
+	info, err := a.fetch(ctx, jid)
+	if err != nil {
+		return false, fmt.Errorf("fetch info of group %s: %w", jid, err)
+	}
+	announce := info.IsDefaultSubGroup
+	a.mu.Lock()
+	a.cache[jid] = announce
+	a.mu.Unlock()
+	return announce, nil
+}
+
+// handleAnnouncement routes a post in a community's announcement group as
+// whatsapp.community_announcements says and reports whether msg was one.
+// If the group cannot be looked up, msg is handled like any group message.
+func (c *Client) handleAnnouncement(ctx context.Context, msg *events.Message) bool {
+	announce, err := c.announcements.is(ctx, msg.Info.Chat)
+	if err != nil {
+		c.log.Warnf("Failed to check for a community announcement group: %v", err)
+		return false
+	}
+	if !announce {
+		return false
+	}
+	switch postRoute(c.cfg.WhatsApp.CommunityAnnouncements) {
+	case newsletterStore:
+		c.storeAnnouncementPost(ctx, msg)
+	case newsletterAgent:
+		sender := msg.Info.Sender
+		if sender.Server == types.HiddenUserServer {
+			sender = c.resolveLID(ctx, sender)
+		}
+		text := extractText(msg)
+		if text != "" {
+			text = groupTurnText(msg.Info.PushName, sender.User, text)
+		}
+		conv := agent.Conversation{UserID: sender.User, GroupID: msg.Info.Chat.String()}
+		c.forwardPost(ctx, conv, msg.Info.Chat.User, msg.Info.ID, msg.Info.Timestamp, text, "announcement")
+	default:
+		c.log.Debugf("Ignoring community announcement %s in %s", msg.Info.ID, msg.Info.Chat)
+	}
+	return true
+}
+
+// storeAnnouncementPost records a community announcement apart from user
+// conversations.
+func (c *Client) storeAnnouncementPost(ctx context.Context, msg *events.Message) {
+	if c.store == nil {
+		return
+	}
+	path := fmt.Sprintf("announcements/%s/%s", msg.Info.Chat.User, msg.Info.ID)
+	metadata := map[string]interface{}{
+		"mime_type": "text/plain",
+		"metadata": map[string]interface{}{
+			"group":  msg.Info.Chat.String(),
+			"sender": msg.Info.Sender.String(),
+		},
+	}
+	if err := c.store.PutFile(ctx, path, metadata, []byte(extractText(msg)), msg.Info.Timestamp); err != nil {
+		c.log.Errorf("Failed to store community announcement to filesys: %v", err)
+	}
+}
+
+// forwardPost lets the agent follow a channel or announcement post. Nobody
+// there can read a reply, so the agent's answer is stored under chatUser
+// with contextType and never sent.
+func (c *Client) forwardPost(ctx context.Context, conv agent.Conversation, chatUser, uniqueID string, ts time.Time, text, contextType string) {
+	if text == "" {
+		return
+	}
+	if c.adkClient == nil {
+		c.log.Warnf("No agent configured, dropping %s %s", contextType, uniqueID)
+		return
+	}
+	c.storeRequest(ctx, chatUser, uniqueID, []byte(text), ts, "text/plain", false)
+	start := time.Now()
+	parts, err := c.adkClient.ChatConversation(ctx, conv, []agent.Part{{Text: text}}, nil)
+	c.stats.agentCall(time.Since(start), err)
+	if err != nil {
+		c.log.Errorf("Failed to forward %s %s to the agent: %v", contextType, uniqueID, err)
+		c.storeResponse(ctx, chatUser, uniqueID, nil, time.Now(), err.Error(), contextType, "")
+		return
+	}
+	var reply []string
+	for _, p := range parts {
+		if p.Text != "" {
+			reply = append(reply, p.Text)
+		}
+	}
+	c.storeResponse(ctx, chatUser, uniqueID, []byte(strings.Join(reply, "\n")), time.Now(), "", contextType, "")
+}
@@ -0,0 +1,41 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestAnnouncementGroups(t *testing.T) {
+	announce := types.NewJID("120363000000000001", types.GroupServer)
+	plain := types.NewJID("120363000000000002", types.GroupServer)
+	fetches := 0
+	failing := true
+	a := newAnnouncementGroups(func(_ context.Context, jid types.JID) (*types.GroupInfo, error) {
+		fetches++
+		if jid == plain && failing {
+			return nil, errors.New("timed out")
+		}
+		return &types.GroupInfo{JID: jid, GroupIsDefaultSub: types.GroupIsDefaultSub{IsDefaultSubGroup: jid == announce}}, nil
+	})
+	ctx := context.Background()
+
+	if ok, err := a.is(ctx, plain); ok || err == nil {
+		t.Fatalf("failed lookup = %v, %v; want error", ok, err)
+	}
+	failing = false
+	for i := 0; i < 2; i++ {
+		if ok, err := a.is(ctx, announce); !ok || err != nil {
+			t.Errorf("announcement group = %v, %v; want true", ok, err)
+		}
+		if ok, err := a.is(ctx, plain); ok || err != nil {
+			t.Errorf("plain group = %v, %v; want false", ok, err)
+		}
+	}
+	// The failed lookup is retried; later answers come from the cache.
+	if fetches != 3 {
+		t.Errorf("fetches = %d, want 3", fetches)
+	}
+}
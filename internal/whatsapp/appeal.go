@@ -0,0 +1,67 @@
+package whatsapp
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// appealStore keeps the latest appeal per number.
+type appealStore interface {
+	GetAppeal(ctx context.Context, phone string) (*store.Appeal, error)
+	PutAppeal(ctx context.Context, a store.Appeal) error
+}
+
+// appealDesk lets blacklisted users contest their block with a command.
+// Appeals are recorded for admin review; nothing is unblocked here.
+type appealDesk struct {
+	store    appealStore
+	command  string
+	interval time.Duration
+	ack      string
+	limited  string
+	now      func() time.Time
+}
+
+func newAppealDesk(s appealStore, command string, interval time.Duration, ack, limited string) *appealDesk {
+	return &appealDesk{store: s, command: command, interval: interval, ack: ack, limited: limited, now: time.Now}
+}
+
+// parse returns the reason given with an appeal command, and whether text
+// is one.
+func (d *appealDesk) parse(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if len(text) < len(d.command) || !strings.EqualFold(text[:len(d.command)], d.command) {
+		return "", false
+	}
+	rest := text[len(d.command):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\n' {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// handle records an appeal from phone if text is an appeal command and
+// returns the reply to send. A number may appeal once per interval; later
+// attempts get the rate-limited reply and leave the stored appeal as is.
+func (d *appealDesk) handle(ctx context.Context, phone, text string) (string, bool, error) {
+	reason, ok := d.parse(text)
+	if !ok {
+		return "", false, nil
+	}
+	prev, err := d.store.GetAppeal(ctx, phone)
+	if err != nil {
+		return "", true, err
+	}
+	now := d.now().UTC()
+	if prev != nil && now.Sub(prev.CreatedAt) < d.interval {
+		return d.limited, true, nil
+	}
+	appeal := store.Appeal{Phone: phone, Reason: reason, Status: store.AppealPending, CreatedAt: now}
+	if err := d.store.PutAppeal(ctx, appeal); err != nil {
+		return "", true, err
+	}
+	return d.ack, true, nil
+}
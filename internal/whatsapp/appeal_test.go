@@ -0,0 +1,87 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+type fakeAppealStore struct {
+	appeals map[string]store.Appeal
+}
+
+func (f *fakeAppealStore) GetAppeal(_ context.Context, phone string) (*store.Appeal, error) {
+	a, ok := f.appeals[phone]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func (f *fakeAppealStore) PutAppeal(_ context.Context, a store.Appeal) error {
+	f.appeals[a.Phone] = a
+	return nil
+}
+
+func TestAppealDeskParse(t *testing.T) {
+	d := newAppealDesk(nil, "/appeal", time.Hour, "", "")
+	tests := []struct {
+		text       string
+		wantReason string
+		wantOK     bool
+	}{
+		{"/appeal I was blocked by mistake", "I was blocked by mistake", true},
+		{"/APPEAL  wrong number ", "wrong number", true},
+		{"/appeal", "", true},
+		{"/appealing", "", false},
+		{"hello /appeal", "", false},
+		{"hi", "", false},
+	}
+	for _, tt := range tests {
+		reason, ok := d.parse(tt.text)
+		if reason != tt.wantReason || ok != tt.wantOK {
+			t.Errorf("parse(%q) = (%q, %v), want (%q, %v)", tt.text, reason, ok, tt.wantReason, tt.wantOK)
+		}
+	}
+}
+
+func TestAppealDeskRecordsAndRateLimits(t *testing.T) {
+	fs := &fakeAppealStore{appeals: map[string]store.Appeal{}}
+	d := newAppealDesk(fs, "/appeal", 24*time.Hour, "Appeal received.", "Already appealed.")
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	reply, handled, err := d.handle(ctx, "919876543210", "/appeal wrong person")
+	if err != nil || !handled || reply != "Appeal received." {
+		t.Fatalf("first appeal = (%q, %v, %v)", reply, handled, err)
+	}
+	got := fs.appeals["919876543210"]
+	if got.Reason != "wrong person" || got.Status != store.AppealPending || !got.CreatedAt.Equal(now) {
+		t.Errorf("stored appeal = %+v", got)
+	}
+
+	now = now.Add(time.Hour)
+	reply, handled, err = d.handle(ctx, "919876543210", "/appeal please")
+	if err != nil || !handled || reply != "Already appealed." {
+		t.Fatalf("repeat appeal = (%q, %v, %v)", reply, handled, err)
+	}
+	if fs.appeals["919876543210"].Reason != "wrong person" {
+		t.Errorf("rate-limited appeal overwrote the stored one: %+v", fs.appeals["919876543210"])
+	}
+
+	now = now.Add(24 * time.Hour)
+	reply, _, err = d.handle(ctx, "919876543210", "/appeal second try")
+	if err != nil || reply != "Appeal received." {
+		t.Fatalf("appeal after interval = (%q, %v)", reply, err)
+	}
+	if fs.appeals["919876543210"].Reason != "second try" {
+		t.Errorf("appeal after interval not recorded: %+v", fs.appeals["919876543210"])
+	}
+
+	if _, handled, _ := d.handle(ctx, "919876543210", "hello"); handled {
+		t.Error("ordinary text handled as an appeal")
+	}
+}
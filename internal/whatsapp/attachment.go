@@ -0,0 +1,46 @@
+package whatsapp
+
+import (
+	"strings"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// attachmentKind names the attachment of msg that is forwarded to the
+// agent: "image", "audio", "video" or "document". Stickers are never
+// forwarded and, like messages without media, return "".
+func attachmentKind(msg *waE2E.Message) string {
+	switch {
+	case msg == nil:
+		return ""
+	case msg.ImageMessage != nil:
+		return "image"
+	case msg.AudioMessage != nil:
+		return "audio"
+	case msg.VideoMessage != nil:
+		return "video"
+	case msg.DocumentMessage != nil:
+		return "document"
+	}
+	return ""
+}
+
+// stickerReply returns the canned reply for msg if it is a sticker and
+// stickers are answered under cfg, or "".
+func stickerReply(cfg config.StickersConfig, msg *waE2E.Message) string {
+	if msg.GetStickerMessage() == nil || cfg.Mode != config.StickerModeReply {
+		return ""
+	}
+	return cfg.Reply
+}
+
+// mediaErrorReply returns whatsapp.media_error_reply for an attachment of
+// kind that could not be read, or "" when no reply should be sent.
+func mediaErrorReply(template, kind string) string {
+	if template == "" || kind == "" {
+		return ""
+	}
+	return strings.ReplaceAll(template, "{type}", kind)
+}
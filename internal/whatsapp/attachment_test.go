@@ -0,0 +1,63 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestAttachmentKind(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *waE2E.Message
+		want string
+	}{
+		{"nil", nil, ""},
+		{"text", &waE2E.Message{Conversation: proto.String("hi")}, ""},
+		{"image", &waE2E.Message{ImageMessage: &waE2E.ImageMessage{Caption: proto.String("look")}}, "image"},
+		{"voice note", &waE2E.Message{AudioMessage: &waE2E.AudioMessage{}}, "audio"},
+		{"video", &waE2E.Message{VideoMessage: &waE2E.VideoMessage{}}, "video"},
+		{"document", &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{}}, "document"},
+		{"sticker", &waE2E.Message{StickerMessage: &waE2E.StickerMessage{}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attachmentKind(tt.msg); got != tt.want {
+				t.Errorf("attachmentKind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediaErrorReply(t *testing.T) {
+	if got := mediaErrorReply("Sorry, I couldn't open that {type}.", "image"); got != "Sorry, I couldn't open that image." {
+		t.Errorf("got %q", got)
+	}
+	if got := mediaErrorReply("", "image"); got != "" {
+		t.Errorf("disabled reply = %q", got)
+	}
+	if got := mediaErrorReply("Sorry.", ""); got != "" {
+		t.Errorf("reply without attachment = %q", got)
+	}
+}
+
+func TestStickerReply(t *testing.T) {
+	sticker := &waE2E.Message{StickerMessage: &waE2E.StickerMessage{}}
+	text := &waE2E.Message{Conversation: proto.String("hi")}
+	reply := config.StickersConfig{Mode: config.StickerModeReply, Reply: "Nice!"}
+
+	if got := stickerReply(reply, sticker); got != "Nice!" {
+		t.Errorf("reply mode = %q", got)
+	}
+	if got := stickerReply(reply, text); got != "" {
+		t.Errorf("reply for text message = %q", got)
+	}
+	for _, mode := range []string{config.StickerModeIgnore, config.StickerModeForward} {
+		if got := stickerReply(config.StickersConfig{Mode: mode, Reply: "Nice!"}, sticker); got != "" {
+			t.Errorf("%s mode = %q", mode, got)
+		}
+	}
+}
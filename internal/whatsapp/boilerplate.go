@@ -0,0 +1,43 @@
+package whatsapp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// buildBoilerplateStripper returns the strip_boilerplate step: fixed texts
+// and patterns are removed where they open or close a reply, never from
+// the middle. A reply made of nothing but boilerplate is left unchanged.
+func buildBoilerplateStripper(texts, patterns []string) (func(string) string, error) {
+	var leading, trailing []*regexp.Regexp
+	for _, t := range texts {
+		if strings.TrimSpace(t) == "" {
+			continue
+		}
+		q := regexp.QuoteMeta(strings.TrimSpace(t))
+		leading = append(leading, regexp.MustCompile(`^\s*`+q))
+		trailing = append(trailing, regexp.MustCompile(q+`\s*$`))
+	}
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return nil, fmt.Errorf("invalid strip_boilerplate pattern %q: %w", p, err)
+		}
+		leading = append(leading, regexp.MustCompile(`^\s*(?:`+p+`)`))
+		trailing = append(trailing, regexp.MustCompile(`(?:`+p+`)\s*$`))
+	}
+	return func(text string) string {
+		stripped := text
+		for _, re := range leading {
+			stripped = re.ReplaceAllString(stripped, "")
+		}
+		for _, re := range trailing {
+			stripped = re.ReplaceAllString(stripped, "")
+		}
+		stripped = strings.TrimSpace(stripped)
+		if stripped == "" {
+			return text
+		}
+		return stripped
+	}, nil
+}
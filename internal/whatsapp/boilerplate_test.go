@@ -0,0 +1,95 @@
+package whatsapp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestStripBoilerplate(t *testing.T) {
+	const disclaimer = "Note: I am an AI assistant and may make mistakes."
+	tests := []struct {
+		name     string
+		texts    []string
+		patterns []string
+		in       string
+		want     string
+	}{
+		{
+			name:  "leading disclaimer",
+			texts: []string{disclaimer},
+			in:    disclaimer + "\n\nYour order ships tomorrow.",
+			want:  "Your order ships tomorrow.",
+		},
+		{
+			name:  "trailing disclaimer",
+			texts: []string{disclaimer},
+			in:    "Your order ships tomorrow.\n" + disclaimer,
+			want:  "Your order ships tomorrow.",
+		},
+		{
+			name:  "disclaimer in the middle is kept",
+			texts: []string{disclaimer},
+			in:    "Quoting the footer: " + disclaimer + " Anything else?",
+			want:  "Quoting the footer: " + disclaimer + " Anything else?",
+		},
+		{
+			name:  "regex metacharacters in text are literal",
+			texts: []string{"(beta) answer:"},
+			in:    "(beta) answer: 42",
+			want:  "42",
+		},
+		{
+			name:     "pattern at start and end",
+			patterns: []string{`\[Generated by [^\]]+\]`},
+			in:       "[Generated by ShopBot v2] Hello!\n[Generated by ShopBot v3]",
+			want:     "Hello!",
+		},
+		{
+			name:  "reply that is only boilerplate is kept",
+			texts: []string{disclaimer},
+			in:    disclaimer,
+			want:  disclaimer,
+		},
+		{
+			name:  "other replies untouched",
+			texts: []string{disclaimer},
+			in:    "  Hi there  ",
+			want:  "Hi there",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strip, err := buildBoilerplateStripper(tt.texts, tt.patterns)
+			if err != nil {
+				t.Fatalf("buildBoilerplateStripper() error: %v", err)
+			}
+			if got := strip(tt.in); got != tt.want {
+				t.Errorf("strip(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripBoilerplateInvalidPattern(t *testing.T) {
+	if _, err := buildBoilerplateStripper(nil, []string{"("}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestStripBoilerplateStep(t *testing.T) {
+	p, err := buildOutboundPipeline(config.OutboundPipelineConfig{
+		Steps:            []string{StepBoilerplate, StepSplit},
+		Delimiter:        "\n---\n",
+		StripBoilerplate: config.StripBoilerplateConfig{Texts: []string{"DISCLAIMER"}},
+	})
+	if err != nil {
+		t.Fatalf("buildOutboundPipeline() error: %v", err)
+	}
+	got := p.apply("DISCLAIMER\nPart one\n---\nPart two")
+	want := []string{"Part one", "Part two"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("apply() = %q, want %q", got, want)
+	}
+}
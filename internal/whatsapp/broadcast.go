@@ -0,0 +1,180 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// broadcastStore persists broadcasts and their per-recipient status.
+type broadcastStore interface {
+	GetBroadcast(ctx context.Context, id string) (*store.Broadcast, error)
+	ListBroadcastDeliveries(ctx context.Context, id string) ([]store.BroadcastDelivery, error)
+	PutBroadcastDelivery(ctx context.Context, d store.BroadcastDelivery) error
+	IsBlacklisted(ctx context.Context, phone string) (bool, error)
+}
+
+// broadcaster sends stored broadcasts one recipient at a time, at most one
+// message per interval. Only pending recipients are sent to, so a
+// broadcast interrupted by a restart resumes where it stopped when started
+// again.
+type broadcaster struct {
+	store    broadcastStore
+	send     func(ctx context.Context, jid, broadcastID, text string) (string, error)
+	interval time.Duration
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func newBroadcaster(s broadcastStore, send func(ctx context.Context, jid, broadcastID, text string) (string, error), interval time.Duration) *broadcaster {
+	return &broadcaster{store: s, send: send, interval: interval, now: time.Now, sleep: sleepCtx, running: make(map[string]bool)}
+}
+
+// sleepCtx waits for d or until ctx ends.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// start checks that broadcast id exists and sends it in the background,
+// reporting the outcome to done. Starting a broadcast that is already
+// being sent is an error.
+func (b *broadcaster) start(ctx context.Context, id string, done func(error)) error {
+	bc, err := b.store.GetBroadcast(ctx, id)
+	if err != nil {
+		return err
+	}
+	if bc == nil {
+		return fmt.Errorf("broadcast %q not found", id)
+	}
+	tmpl, err := template.New(id).Option("missingkey=error").Parse(bc.Template)
+	if err != nil {
+		return fmt.Errorf("invalid template of broadcast %s: %w", id, err)
+	}
+
+	b.mu.Lock()
+	if b.running[id] {
+		b.mu.Unlock()
+		return fmt.Errorf("broadcast %s is already being sent", id)
+	}
+	b.running[id] = true
+	b.mu.Unlock()
+
+	go func() {
+		err := b.run(ctx, id, tmpl)
+		b.mu.Lock()
+		delete(b.running, id)
+		b.mu.Unlock()
+		done(err)
+	}()
+	return nil
+}
+
+// run sends tmpl to every pending recipient of broadcast id. A recipient
+// that cannot be rendered or sent to is marked failed and the broadcast
+// goes on; only store errors and cancellation stop it.
+func (b *broadcaster) run(ctx context.Context, id string, tmpl *template.Template) error {
+	deliveries, err := b.store.ListBroadcastDeliveries(ctx, id)
+	if err != nil {
+		return err
+	}
+	sent := false
+	for _, d := range deliveries {
+		if d.Status != store.BroadcastPending {
+			continue
+		}
+		if sent {
+			if err := b.sleep(ctx, b.interval); err != nil {
+				return err
+			}
+		}
+		d, sent = b.deliver(ctx, tmpl, d)
+		d.UpdatedAt = b.now().UTC()
+		if err := b.store.PutBroadcastDelivery(ctx, d); err != nil {
+			return fmt.Errorf("record delivery of broadcast %s to %s: %w", id, d.JID, err)
+		}
+	}
+	return nil
+}
+
+// deliver renders and sends d's message and returns d with its new status,
+// and whether a message was sent.
+func (b *broadcaster) deliver(ctx context.Context, tmpl *template.Template, d store.BroadcastDelivery) (store.BroadcastDelivery, bool) {
+	fail := func(err error) (store.BroadcastDelivery, bool) {
+		d.Status, d.Error = store.BroadcastFailed, err.Error()
+		return d, false
+	}
+	phone, _, _ := strings.Cut(d.JID, "@")
+	blocked, err := b.store.IsBlacklisted(ctx, phone)
+	if err != nil {
+		return fail(fmt.Errorf("check blacklist: %w", err))
+	}
+	if blocked {
+		return fail(fmt.Errorf("recipient is blacklisted"))
+	}
+
+	var text strings.Builder
+	if err := tmpl.Execute(&text, d.Vars); err != nil {
+		return fail(fmt.Errorf("render template: %w", err))
+	}
+	msgID, err := b.send(ctx, d.JID, d.BroadcastID, text.String())
+	if err != nil {
+		// The send was attempted, so the interval still applies.
+		d.Status, d.Error = store.BroadcastFailed, err.Error()
+		return d, true
+	}
+	d.Status, d.MessageID, d.Error = store.BroadcastSent, msgID, ""
+	return d, true
+}
+
+// startBroadcast sends broadcast id in the background, logging when it
+// ends.
+func (c *Client) startBroadcast(ctx context.Context, id string) error {
+	if c.broadcasts == nil {
+		return fmt.Errorf("broadcasts need the gateway store")
+	}
+	return c.broadcasts.start(ctx, id, func(err error) {
+		if err != nil {
+			c.log.Errorf("Broadcast %s stopped: %v", id, err)
+			return
+		}
+		c.log.Infof("Broadcast %s finished", id)
+	})
+}
+
+// sendBroadcastText sends one broadcast message to jid, which may be a bare
+// phone number, and logs it like any other response.
+func (c *Client) sendBroadcastText(ctx context.Context, jidStr, broadcastID, text string) (string, error) {
+	if !strings.Contains(jidStr, "@") {
+		jidStr += "@" + types.DefaultUserServer
+	}
+	jid, err := types.ParseJID(jidStr)
+	if err != nil {
+		return "", fmt.Errorf("invalid JID: %w", err)
+	}
+	uniqueID := "broadcast_" + broadcastID
+	resp, err := c.wac.SendMessage(ctx, jid, c.textMessage(ctx, text, ""))
+	if err != nil {
+		c.storeResponse(ctx, jid.User, uniqueID, []byte(text), time.Now(), err.Error(), "notification", "")
+		return "", err
+	}
+	c.countUsage(jid.User, usageOutbound)
+	c.storeResponse(ctx, jid.User, uniqueID, []byte(text), resp.Timestamp, "", "notification", "")
+	return resp.ID, nil
+}
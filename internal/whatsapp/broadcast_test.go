@@ -0,0 +1,114 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+type fakeBroadcastStore struct {
+	broadcast   *store.Broadcast
+	deliveries  []store.BroadcastDelivery
+	blacklisted map[string]bool
+}
+
+func (f *fakeBroadcastStore) GetBroadcast(_ context.Context, id string) (*store.Broadcast, error) {
+	if f.broadcast == nil || f.broadcast.ID != id {
+		return nil, nil
+	}
+	return f.broadcast, nil
+}
+
+func (f *fakeBroadcastStore) ListBroadcastDeliveries(_ context.Context, _ string) ([]store.BroadcastDelivery, error) {
+	return append([]store.BroadcastDelivery(nil), f.deliveries...), nil
+}
+
+func (f *fakeBroadcastStore) PutBroadcastDelivery(_ context.Context, d store.BroadcastDelivery) error {
+	for i := range f.deliveries {
+		if f.deliveries[i].JID == d.JID {
+			f.deliveries[i] = d
+		}
+	}
+	return nil
+}
+
+func (f *fakeBroadcastStore) IsBlacklisted(_ context.Context, phone string) (bool, error) {
+	return f.blacklisted[phone], nil
+}
+
+func TestBroadcasterRun(t *testing.T) {
+	pending := func(jid string, vars map[string]string) store.BroadcastDelivery {
+		return store.BroadcastDelivery{BroadcastID: "b1", JID: jid, Vars: vars, Status: store.BroadcastPending}
+	}
+	s := &fakeBroadcastStore{
+		broadcast: &store.Broadcast{ID: "b1", Template: "Hi {{.name}}, we are down at 10pm."},
+		deliveries: []store.BroadcastDelivery{
+			{BroadcastID: "b1", JID: "919800000000", Status: store.BroadcastSent},
+			pending("919811111111", map[string]string{"name": "Asha"}),
+			pending("919822222222", nil),
+			pending("919833333333@s.whatsapp.net", map[string]string{"name": "Ravi"}),
+			pending("919844444444", map[string]string{"name": "Spam"}),
+			pending("919855555555", map[string]string{"name": "Offline"}),
+		},
+		blacklisted: map[string]bool{"919844444444": true},
+	}
+	var sent []string
+	send := func(_ context.Context, jid, id, text string) (string, error) {
+		if jid == "919855555555" {
+			return "", errors.New("not connected")
+		}
+		sent = append(sent, jid+": "+text)
+		return "m" + jid[len(jid)-1:], nil
+	}
+	b := newBroadcaster(s, send, 2*time.Second)
+	var slept []time.Duration
+	b.sleep = func(_ context.Context, d time.Duration) error { slept = append(slept, d); return nil }
+
+	done := make(chan error, 1)
+	if err := b.start(context.Background(), "b1", func(err error) { done <- err }); err != nil {
+		t.Fatalf("start() error: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+
+	want := []string{
+		"919811111111: Hi Asha, we are down at 10pm.",
+		"919833333333@s.whatsapp.net: Hi Ravi, we are down at 10pm.",
+	}
+	if len(sent) != len(want) || sent[0] != want[0] || sent[1] != want[1] {
+		t.Errorf("sent = %q, want %q", sent, want)
+	}
+	// Sleeps come before the second and third send attempts only.
+	if len(slept) != 2 {
+		t.Errorf("slept %d times, want 2", len(slept))
+	}
+
+	status := make(map[string]store.BroadcastDelivery)
+	for _, d := range s.deliveries {
+		status[d.JID] = d
+	}
+	checks := []struct {
+		jid, status, msgID, err string
+	}{
+		{"919800000000", store.BroadcastSent, "", ""},
+		{"919811111111", store.BroadcastSent, "m1", ""},
+		{"919822222222", store.BroadcastFailed, "", `render template: `},
+		{"919844444444", store.BroadcastFailed, "", "recipient is blacklisted"},
+		{"919855555555", store.BroadcastFailed, "", "not connected"},
+	}
+	for _, c := range checks {
+		d := status[c.jid]
+		if d.Status != c.status || d.MessageID != c.msgID || !strings.HasPrefix(d.Error, c.err) || (c.err == "") != (d.Error == "") {
+			t.Errorf("%s: got status %q, id %q, error %q; want %q, %q, %q", c.jid, d.Status, d.MessageID, d.Error, c.status, c.msgID, c.err)
+		}
+	}
+
+	if err := b.start(context.Background(), "missing", func(error) {}); err == nil {
+		t.Error("expected error for unknown broadcast")
+	}
+}
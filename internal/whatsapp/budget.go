@@ -0,0 +1,117 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/innomon/whatsadk/internal/agent"
+	"github.com/innomon/whatsadk/internal/config"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// budgetSessionSuffix names the side sessions used to shorten replies, so
+// the user's conversation never sees the request.
+const budgetSessionSuffix = "-budget"
+
+// budgetSession names the throwaway session shortening the reply to
+// uniqueID; each reply gets its own, so earlier ones are not replayed.
+func budgetSession(userID, uniqueID string) string {
+	return userID + budgetSessionSuffix + "-" + uniqueID
+}
+
+// overBudget reports whether text is longer than maxChars characters. A
+// zero budget is unlimited.
+func overBudget(text string, maxChars int) bool {
+	return maxChars > 0 && utf8.RuneCountInString(text) > maxChars
+}
+
+// truncateToBudget cuts text at a natural boundary so that, with notice
+// appended on its own paragraph, it fits in maxChars.
+func truncateToBudget(text string, maxChars int, notice string) string {
+	if !overBudget(text, maxChars) {
+		return text
+	}
+	room := maxChars - utf8.RuneCountInString(notice) - 2
+	if notice == "" || room <= 0 {
+		head, _ := splitReply(text, maxChars)
+		return head
+	}
+	head, _ := splitReply(text, room)
+	return head + "\n\n" + notice
+}
+
+// budgetDocument wraps text as a plain-text attachment.
+func budgetDocument(text string) *agent.InlineData {
+	return &agent.InlineData{MimeType: "text/plain", Data: base64.StdEncoding.EncodeToString([]byte(text))}
+}
+
+// summarizePrompt fills {max} in prompt and appends the reply to shorten.
+func summarizePrompt(prompt string, maxChars int, reply string) string {
+	return strings.ReplaceAll(prompt, "{max}", strconv.Itoa(maxChars)) + "\n\n" + reply
+}
+
+// budgetPlan is how an agent reply is sent under whatsapp.reply_budget.
+type budgetPlan struct {
+	// text is sent as the reply, paged as usual.
+	text string
+	// document, when set, is sent instead of text; text is the fallback if
+	// the document cannot be sent.
+	document *agent.InlineData
+}
+
+// planBudget applies budget to body. shorten asks the agent for a shorter
+// version under ReplyBudgetSummarize; when it fails, or its answer is still
+// over budget, the reply is truncated instead.
+func planBudget(budget config.ReplyBudgetConfig, body string, shorten func(prompt string) (string, error)) (budgetPlan, error) {
+	if !overBudget(body, budget.MaxChars) {
+		return budgetPlan{text: body}, nil
+	}
+	truncated := truncateToBudget(body, budget.MaxChars, budget.Notice)
+	switch budget.Policy {
+	case config.ReplyBudgetSummarize:
+		short, err := shorten(summarizePrompt(budget.SummarizePrompt, budget.MaxChars, body))
+		if err != nil {
+			return budgetPlan{text: truncated}, err
+		}
+		if strings.TrimSpace(short) == "" {
+			return budgetPlan{text: truncated}, nil
+		}
+		return budgetPlan{text: truncateToBudget(short, budget.MaxChars, budget.Notice)}, nil
+	case config.ReplyBudgetDocument:
+		return budgetPlan{text: truncated, document: budgetDocument(body)}, nil
+	default:
+		return budgetPlan{text: truncated}, nil
+	}
+}
+
+// sendBudgetedText sends the text of an agent reply, applying
+// whatsapp.reply_budget. adk shortens replies under the summarize policy.
+func (c *Client) sendBudgetedText(ctx context.Context, adk *agent.Client, turn *replyTurn, chat types.JID, userID, uniqueID, body string) {
+	budget := c.cfg.WhatsApp.ReplyBudget
+	plan, err := planBudget(budget, body, func(prompt string) (string, error) {
+		if adk == nil {
+			return "", fmt.Errorf("no agent to summarize with")
+		}
+		parts, err := adk.ChatOnce(ctx, userID, budgetSession(userID, uniqueID), prompt)
+		if err != nil {
+			return "", err
+		}
+		_, short := planReply(parts)
+		return short, nil
+	})
+	if err != nil {
+		c.log.Warnf("Failed to shorten reply for %s, truncating: %v", userID, err)
+	}
+	if plan.document != nil {
+		err := c.sendMediaPart(ctx, turn, chat, userID, uniqueID, plan.document, budget.DocumentCaption, "response", uniqueID)
+		if err == nil {
+			return
+		}
+		c.log.Warnf("Failed to send long reply to %s as a document, truncating: %v", userID, err)
+	}
+	c.sendAgentText(ctx, turn, chat, userID, uniqueID, c.pageReply(userID, plan.text))
+}
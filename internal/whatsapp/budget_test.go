@@ -0,0 +1,116 @@
+package whatsapp
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestPlanBudget(t *testing.T) {
+	long := strings.Repeat("word ", 40) // 200 characters
+	budget := func(policy string) config.ReplyBudgetConfig {
+		return config.ReplyBudgetConfig{
+			MaxChars:        50,
+			Policy:          policy,
+			Notice:          "(cut)",
+			SummarizePrompt: "Shorten to {max}:",
+		}
+	}
+	noShorten := func(string) (string, error) {
+		t.Error("shorten called")
+		return "", nil
+	}
+
+	t.Run("under budget passes through", func(t *testing.T) {
+		for _, policy := range []string{config.ReplyBudgetTruncate, config.ReplyBudgetSummarize, config.ReplyBudgetDocument} {
+			plan, err := planBudget(budget(policy), "short reply", noShorten)
+			if err != nil || plan.text != "short reply" || plan.document != nil {
+				t.Errorf("%s: plan = %+v, %v", policy, plan, err)
+			}
+		}
+	})
+
+	t.Run("disabled budget passes through", func(t *testing.T) {
+		b := budget(config.ReplyBudgetTruncate)
+		b.MaxChars = 0
+		plan, err := planBudget(b, long, noShorten)
+		if err != nil || plan.text != long {
+			t.Errorf("plan = %+v, %v", plan, err)
+		}
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		plan, err := planBudget(budget(config.ReplyBudgetTruncate), long, noShorten)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(plan.text, "\n\n(cut)") || utf8.RuneCountInString(plan.text) > 50 {
+			t.Errorf("text = %q", plan.text)
+		}
+	})
+
+	t.Run("summarize", func(t *testing.T) {
+		var gotPrompt string
+		plan, err := planBudget(budget(config.ReplyBudgetSummarize), long, func(prompt string) (string, error) {
+			gotPrompt = prompt
+			return "a short summary", nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(gotPrompt, "Shorten to 50:\n\n") || !strings.HasSuffix(gotPrompt, long) {
+			t.Errorf("prompt = %q", gotPrompt)
+		}
+		if plan.text != "a short summary" {
+			t.Errorf("text = %q", plan.text)
+		}
+	})
+
+	t.Run("summarize still too long is truncated", func(t *testing.T) {
+		plan, err := planBudget(budget(config.ReplyBudgetSummarize), long, func(string) (string, error) {
+			return long, nil
+		})
+		if err != nil || !strings.HasSuffix(plan.text, "(cut)") {
+			t.Errorf("plan = %+v, %v", plan, err)
+		}
+	})
+
+	t.Run("summarize failure falls back to truncate", func(t *testing.T) {
+		plan, err := planBudget(budget(config.ReplyBudgetSummarize), long, func(string) (string, error) {
+			return "", errors.New("agent down")
+		})
+		if err == nil || !strings.HasSuffix(plan.text, "(cut)") {
+			t.Errorf("plan = %+v, %v", plan, err)
+		}
+	})
+
+	t.Run("document", func(t *testing.T) {
+		plan, err := planBudget(budget(config.ReplyBudgetDocument), long, noShorten)
+		if err != nil || plan.document == nil {
+			t.Fatalf("plan = %+v, %v", plan, err)
+		}
+		if plan.document.MimeType != "text/plain" {
+			t.Errorf("mime = %q", plan.document.MimeType)
+		}
+		data, err := base64.StdEncoding.DecodeString(plan.document.Data)
+		if err != nil || string(data) != long {
+			t.Errorf("document = %q, %v", data, err)
+		}
+		if !strings.HasSuffix(plan.text, "(cut)") {
+			t.Errorf("fallback text = %q", plan.text)
+		}
+	})
+}
+
+func TestBudgetSessionIsPerReply(t *testing.T) {
+	if got := budgetSession("919876543210", "MSG1"); got != "919876543210-budget-MSG1" {
+		t.Errorf("budgetSession() = %q, want 919876543210-budget-MSG1", got)
+	}
+	if budgetSession("919876543210", "MSG1") == budgetSession("919876543210", "MSG2") {
+		t.Error("replies share a budget session")
+	}
+}
@@ -0,0 +1,130 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+// WhatsApp's limits on quick-reply buttons.
+const (
+	maxReplyButtons   = 3
+	maxButtonTitleLen = 20
+)
+
+// buttonsSpec is the JSON an agent sends, base64-encoded, in an
+// agent.MimeTypeButtons part.
+type buttonsSpec struct {
+	Text    string       `json:"text"`
+	Footer  string       `json:"footer,omitempty"`
+	Buttons []buttonSpec `json:"buttons"`
+}
+
+type buttonSpec struct {
+	// ID is reported back when the button is tapped; defaults to Title.
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title"`
+}
+
+// splitButtonsSpecs removes button parts from parts and decodes them.
+// Invalid specs are dropped and reported in errs.
+func splitButtonsSpecs(parts []agent.Part) (specs []buttonsSpec, rest []agent.Part, errs []error) {
+	for _, part := range parts {
+		if part.InlineData == nil || part.InlineData.MimeType != agent.MimeTypeButtons {
+			rest = append(rest, part)
+			continue
+		}
+		spec, err := decodeButtonsSpec(part.InlineData.Data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	return specs, rest, errs
+}
+
+func decodeButtonsSpec(data string) (buttonsSpec, error) {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return buttonsSpec{}, fmt.Errorf("decode buttons: %w", err)
+	}
+	var spec buttonsSpec
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return buttonsSpec{}, fmt.Errorf("decode buttons: %w", err)
+	}
+	if strings.TrimSpace(spec.Text) == "" {
+		return buttonsSpec{}, fmt.Errorf("buttons have no text")
+	}
+	if n := len(spec.Buttons); n == 0 || n > maxReplyButtons {
+		return buttonsSpec{}, fmt.Errorf("%d buttons (want 1 to %d)", n, maxReplyButtons)
+	}
+	seen := make(map[string]bool, len(spec.Buttons))
+	for i, b := range spec.Buttons {
+		title := strings.TrimSpace(b.Title)
+		if title == "" || utf8.RuneCountInString(title) > maxButtonTitleLen {
+			return buttonsSpec{}, fmt.Errorf("button title %q must be 1 to %d characters", b.Title, maxButtonTitleLen)
+		}
+		if b.ID == "" {
+			spec.Buttons[i].ID = title
+		}
+		if seen[spec.Buttons[i].ID] {
+			return buttonsSpec{}, fmt.Errorf("duplicate button id %q", spec.Buttons[i].ID)
+		}
+		seen[spec.Buttons[i].ID] = true
+	}
+	return spec, nil
+}
+
+// buttonsMessage renders spec as a quick-reply ButtonsMessage.
+func buttonsMessage(spec buttonsSpec) *waE2E.Message {
+	buttons := make([]*waE2E.ButtonsMessage_Button, len(spec.Buttons))
+	for i, b := range spec.Buttons {
+		buttons[i] = &waE2E.ButtonsMessage_Button{
+			ButtonID:   proto.String(b.ID),
+			ButtonText: &waE2E.ButtonsMessage_Button_ButtonText{DisplayText: proto.String(b.Title)},
+			Type:       waE2E.ButtonsMessage_Button_RESPONSE.Enum(),
+		}
+	}
+	bm := &waE2E.ButtonsMessage{
+		ContentText: proto.String(spec.Text),
+		Buttons:     buttons,
+		HeaderType:  waE2E.ButtonsMessage_EMPTY.Enum(),
+	}
+	if spec.Footer != "" {
+		bm.FooterText = proto.String(spec.Footer)
+	}
+	return &waE2E.Message{ButtonsMessage: bm}
+}
+
+// buttonsFallbackText lists spec's choices as plain text, for when the
+// buttons cannot be sent.
+func buttonsFallbackText(spec buttonsSpec) string {
+	var b strings.Builder
+	b.WriteString(spec.Text)
+	b.WriteString("\n")
+	for i, btn := range spec.Buttons {
+		fmt.Fprintf(&b, "\n%d. %s", i+1, btn.Title)
+	}
+	return b.String()
+}
+
+// sendButtons sends spec as quick-reply buttons, falling back to a numbered
+// text list if WhatsApp rejects them.
+func (c *Client) sendButtons(ctx context.Context, turn *replyTurn, chat types.JID, userID, uniqueID string, spec buttonsSpec) {
+	if _, err := c.wac.SendMessage(ctx, chat, buttonsMessage(spec)); err != nil {
+		c.log.Warnf("Failed to send buttons to %s, sending them as text: %v", userID, err)
+		c.sendAgentText(ctx, turn, chat, userID, uniqueID, buttonsFallbackText(spec))
+		return
+	}
+	c.countUsage(userID, usageOutbound)
+}
@@ -0,0 +1,84 @@
+package whatsapp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+func buttonsPart(t *testing.T, spec any) agent.Part {
+	t.Helper()
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("marshal buttons: %v", err)
+	}
+	return agent.Part{InlineData: &agent.InlineData{MimeType: agent.MimeTypeButtons, Data: base64.StdEncoding.EncodeToString(raw)}}
+}
+
+func TestSplitButtonsSpecs(t *testing.T) {
+	parts := []agent.Part{
+		{Text: "Your order is ready."},
+		buttonsPart(t, buttonsSpec{Text: "Confirm?", Buttons: []buttonSpec{{ID: "yes", Title: "Yes"}, {Title: "No"}}}),
+		buttonsPart(t, buttonsSpec{Text: "Too many", Buttons: []buttonSpec{{Title: "A"}, {Title: "B"}, {Title: "C"}, {Title: "D"}}}),
+		buttonsPart(t, buttonsSpec{Buttons: []buttonSpec{{Title: "A"}}}),
+		buttonsPart(t, buttonsSpec{Text: "Long", Buttons: []buttonSpec{{Title: "This title is far too long"}}}),
+		buttonsPart(t, buttonsSpec{Text: "Dupes", Buttons: []buttonSpec{{Title: "A"}, {ID: "A", Title: "B"}}}),
+		{InlineData: &agent.InlineData{MimeType: agent.MimeTypeButtons, Data: "%%%"}},
+	}
+
+	specs, rest, errs := splitButtonsSpecs(parts)
+	if len(specs) != 1 || specs[0].Buttons[0].ID != "yes" || specs[0].Buttons[1].ID != "No" {
+		t.Fatalf("specs = %+v", specs)
+	}
+	if len(rest) != 1 || rest[0].Text != "Your order is ready." {
+		t.Errorf("rest = %+v", rest)
+	}
+	if len(errs) != 5 {
+		t.Errorf("errs = %v, want 5", errs)
+	}
+}
+
+func TestButtonsMessage(t *testing.T) {
+	spec := buttonsSpec{Text: "Confirm?", Footer: "Tap one", Buttons: []buttonSpec{{ID: "yes", Title: "Yes"}, {ID: "no", Title: "No"}}}
+
+	bm := buttonsMessage(spec).GetButtonsMessage()
+	if bm.GetContentText() != "Confirm?" || bm.GetFooterText() != "Tap one" || len(bm.GetButtons()) != 2 {
+		t.Fatalf("message = %v", bm)
+	}
+	if b := bm.GetButtons()[1]; b.GetButtonID() != "no" || b.GetButtonText().GetDisplayText() != "No" || b.GetType() != waE2E.ButtonsMessage_Button_RESPONSE {
+		t.Errorf("button = %v", b)
+	}
+	if got, want := buttonsFallbackText(spec), "Confirm?\n\n1. Yes\n2. No"; got != want {
+		t.Errorf("fallback = %q, want %q", got, want)
+	}
+}
+
+func TestSelectionText(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *waE2E.Message
+		want string
+	}{
+		{"button", &waE2E.Message{ButtonsResponseMessage: &waE2E.ButtonsResponseMessage{
+			SelectedButtonID: proto.String("yes"),
+			Response:         &waE2E.ButtonsResponseMessage_SelectedDisplayText{SelectedDisplayText: "Yes"},
+		}}, "Yes"},
+		{"button without display text", &waE2E.Message{ButtonsResponseMessage: &waE2E.ButtonsResponseMessage{
+			SelectedButtonID: proto.String("yes"),
+		}}, "yes"},
+		{"template button", &waE2E.Message{TemplateButtonReplyMessage: &waE2E.TemplateButtonReplyMessage{
+			SelectedID: proto.String("menu"), SelectedDisplayText: proto.String("Menu"),
+		}}, "Menu"},
+		{"plain text", &waE2E.Message{Conversation: proto.String("hi")}, ""},
+	}
+	for _, tt := range tests {
+		if got := selectionText(tt.msg); got != tt.want {
+			t.Errorf("%s: selectionText() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -4,14 +4,15 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
-	"github.com/mdp/qrterminal/v3"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/store/sqlstore"
@@ -26,20 +27,57 @@ import (
 	"github.com/innomon/whatsadk/internal/auth"
 	"github.com/innomon/whatsadk/internal/config"
 	"github.com/innomon/whatsadk/internal/store"
+	"github.com/innomon/whatsadk/internal/transcribe"
+	"github.com/innomon/whatsadk/internal/tts"
 	"github.com/innomon/whatsadk/internal/verification"
 )
 
-const indiaCountryCode = "91"
-
 type Client struct {
 	wac           *whatsmeow.Client
 	adkClient     *agent.Client
-	verifyHandler *verification.Handler
+	downloader    mediaDownloader
+	verifier      tokenVerifier
 	oauthHandler  *auth.OAuthHandler
 	store         *store.Store
 	mediaProc     *Processor
 	cfg           *config.Config
 	log           waLog.Logger
+	resend        *resendRequester
+	pager         *replyPager
+	flood         *floodGuard
+	cooldown      *errorCooldown
+	nudger        *onboardingNudger
+	forms         *formCollector
+	appeals       *appealDesk
+	admin         *adminDispatcher
+	broadcasts    *broadcaster
+	announcements *announcementGroups
+	agentLimit    *agentLimiter
+	links         *linkFilter
+	summaries     *summaryScheduler
+	businessADK   *agent.Client
+	inbound       inboundPipeline
+	outbound      outboundPipeline
+	revokes       *revokeHandler
+	reactions     *reactionHandler
+	sendq         *sendQueue
+	outbox        *outbox
+	usage         *usageBatcher
+	state         *stateKeeper
+	transcriber   transcribe.Transcriber
+	speaker       tts.Synthesizer
+	images        *imageFetcher
+	typingEvery   time.Duration
+	quotes        *replyTargets
+	previews      *linkPreviewer
+	deliveries    *deliveryReporter
+	stats         *statsCollector
+
+	// inflight counts messages currently being handled, so scheduled
+	// reconnects can wait for them to drain.
+	inflight    atomic.Int64
+	connectedAt atomic.Int64 // unix nanos of the last successful connect
+	received    atomic.Int64 // messages since the last heartbeat
 }
 
 func New(ctx context.Context, cfg *config.Config, adkClient *agent.Client, verifyHandler *verification.Handler, oauthHandler *auth.OAuthHandler, gatewayStore *store.Store) (*Client, error) {
@@ -63,18 +101,258 @@ func New(ctx context.Context, cfg *config.Config, adkClient *agent.Client, verif
 	wac := whatsmeow.NewClient(deviceStore, log)
 
 	client := &Client{
-		wac:           wac,
-		adkClient:     adkClient,
-		verifyHandler: verifyHandler,
-		oauthHandler:  oauthHandler,
-		store:         gatewayStore,
-		mediaProc:     NewProcessor(),
-		cfg:           cfg,
-		log:           log,
+		wac:          wac,
+		adkClient:    adkClient,
+		oauthHandler: oauthHandler,
+		store:        gatewayStore,
+		mediaProc:    NewProcessor(),
+		cfg:          cfg,
+		log:          log,
+		stats:        newStatsCollector(time.Now()),
+	}
+	client.downloader = wac
+	if verifyHandler != nil {
+		client.verifier = verifyHandler
+	}
+
+	if cfg.WhatsApp.UndecryptableReply != "" {
+		interval, err := time.ParseDuration(cfg.WhatsApp.UndecryptableReplyInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid undecryptable_reply_interval: %w", err)
+		}
+		client.resend = newResendRequester(cfg.WhatsApp.UndecryptableReply, interval, func(ctx context.Context, chat types.JID, msgID, text string) {
+			client.sendTextMessage(ctx, chat, chat.User, msgID, text, "system", msgID)
+		})
+	}
+
+	if flood := cfg.WhatsApp.Flood; flood.MaxRepeats > 0 {
+		window, err := time.ParseDuration(flood.Window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flood window: %w", err)
+		}
+		var exempt []string
+		if cfg.WhatsApp.ReplyPaging.MaxLength > 0 {
+			// Paging through a long reply legitimately repeats the command.
+			exempt = append(exempt, cfg.WhatsApp.ReplyPaging.Command)
+		}
+		client.flood = newFloodGuard(flood.MaxRepeats, window, flood.Message != "", exempt...)
+	}
+
+	if window := cfg.WhatsApp.ErrorCooldown.Window; window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error_cooldown window: %w", err)
+		}
+		client.cooldown = newErrorCooldown(d, cfg.WhatsApp.ErrorCooldown.Message != "")
+	}
+
+	if lf := cfg.WhatsApp.LinkFilter; lf.Enabled() {
+		client.links, err = newLinkFilter(lf.BlockedDomains, lf.Patterns)
+		if err != nil {
+			return nil, err
+		}
+		if ab := lf.AutoBlacklist; ab.Threshold > 0 && gatewayStore != nil {
+			window, err := time.ParseDuration(ab.Window)
+			if err != nil {
+				return nil, fmt.Errorf("invalid link_filter auto_blacklist window: %w", err)
+			}
+			client.links.threshold, client.links.window, client.links.reason = ab.Threshold, window, ab.Reason
+			client.links.store = gatewayStore
+		}
+	}
+
+	if rl := cfg.WhatsApp.AgentRateLimit; rl.MaxCalls > 0 {
+		window, err := time.ParseDuration(rl.Window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid agent_rate_limit window: %w", err)
+		}
+		var exempt []string
+		if rl.ExemptWhitelisted {
+			exempt = append(exempt, cfg.WhatsApp.WhitelistedUsers...)
+		}
+		if rl.ExemptDevOps {
+			exempt = append(exempt, cfg.Verification.DevOpsNumbers...)
+		}
+		client.agentLimit = newAgentLimiter(rl.MaxCalls, window, exempt)
+	}
+
+	if forms := cfg.WhatsApp.Forms; forms.Enabled && gatewayStore != nil {
+		ttl, err := time.ParseDuration(forms.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid forms ttl: %w", err)
+		}
+		client.forms = newFormCollector(gatewayStore, ttl, forms.CancelCommand, forms.CancelledMessage)
+	}
+
+	if appeals := cfg.Blacklist.Appeals; appeals.Enabled && gatewayStore != nil {
+		interval, err := time.ParseDuration(appeals.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blacklist appeals interval: %w", err)
+		}
+		client.appeals = newAppealDesk(gatewayStore, appeals.Command, interval, appeals.AckMessage, appeals.RateLimitedMessage)
+	}
+
+	if gatewayStore != nil {
+		interval, err := time.ParseDuration(cfg.WhatsApp.Broadcasts.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid broadcasts interval: %w", err)
+		}
+		client.broadcasts = newBroadcaster(gatewayStore, client.sendBroadcastText, interval)
+	}
+
+	// Only allowed groups are checked, so there is nothing to look up
+	// while groups are off.
+	if cfg.WhatsApp.Groups.Enabled {
+		client.announcements = newAnnouncementGroups(wac.GetGroupInfo)
+	}
+
+	if cfg.WhatsApp.AdminCommands.Enabled && gatewayStore != nil {
+		client.admin = newAdminDispatcher(cfg.Verification.DevOpsNumbers, gatewayStore, client.RemoteBlock, client.RemoteUnblock, client.adminStats)
+	}
+
+	if cfg.WhatsApp.OnboardingNudge.Enabled && gatewayStore != nil {
+		client.nudger = &onboardingNudger{store: gatewayStore, message: cfg.WhatsApp.OnboardingNudge.Message, log: log}
+	}
+
+	client.inbound, err = buildInboundPipeline(cfg.WhatsApp.InboundPipeline, client.mentionNames)
+	if err != nil {
+		return nil, err
+	}
+	client.outbound, err = buildOutboundPipeline(cfg.WhatsApp.OutboundPipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	client.revokes = &revokeHandler{
+		audit:  cfg.WhatsApp.RevokeMode == config.RevokeModeAudit,
+		record: client.recordRevoke,
+		log:    log,
+	}
+	client.reactions = &reactionHandler{
+		cfg:    cfg.WhatsApp.Reactions,
+		record: client.recordReaction,
+		log:    log,
+	}
+
+	if q := cfg.WhatsApp.SendQueue; q.MaxDepth > 0 {
+		client.sendq = newSendQueue(q.MaxDepth, q.Overflow, q.WarnDepth, func(depth int) {
+			log.Warnf("Outbound send queue backing up: %d messages waiting (max %d)", depth, q.MaxDepth)
+		})
+		if q.Persist && gatewayStore != nil {
+			maxAge, err := time.ParseDuration(q.ResumeMaxAge)
+			if err != nil {
+				return nil, fmt.Errorf("invalid send_queue resume_max_age: %w", err)
+			}
+			client.outbox = newOutbox(gatewayStore, maxAge)
+		}
+	}
+
+	outboundTLS, err := cfg.TLS.TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls config: %w", err)
+	}
+
+	transcriber, err := transcribe.New(cfg.WhatsApp.Transcription, outboundTLS)
+	if err != nil {
+		return nil, err
+	}
+	client.transcriber = transcriber
+
+	speaker, err := tts.New(cfg.WhatsApp.VoiceReplies, outboundTLS)
+	if err != nil {
+		return nil, err
+	}
+	client.speaker = speaker
+
+	if lp := cfg.WhatsApp.LinkPreviews; lp.Mode == config.LinkPreviewGenerate {
+		timeout, err := time.ParseDuration(lp.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid link_previews timeout: %w", err)
+		}
+		client.previews = newLinkPreviewer(newFetchClient(timeout, outboundTLS), lp.AllowedHosts)
+	}
+
+	if cfg.WhatsApp.QuoteReplies {
+		client.quotes = newReplyTargets(maxReplyTargets)
+	}
+
+	if t := cfg.WhatsApp.TypingIndicator; t.Enabled {
+		every, err := time.ParseDuration(t.Refresh)
+		if err != nil {
+			return nil, fmt.Errorf("invalid typing_indicator refresh: %w", err)
+		}
+		client.typingEvery = every
+	}
+
+	if l := cfg.WhatsApp.ImageLinks; l.Enabled {
+		timeout, err := time.ParseDuration(l.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image_links timeout: %w", err)
+		}
+		client.images = newImageFetcher(newFetchClient(timeout, outboundTLS), l.MaxBytes, l.AllowedHosts)
+	}
+
+	if u := cfg.WhatsApp.Usage; u.Enabled && gatewayStore != nil {
+		interval, err := time.ParseDuration(u.FlushInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid usage flush_interval: %w", err)
+		}
+		client.usage = newUsageBatcher(gatewayStore, u.BatchSize, interval)
+	}
+
+	if dc := cfg.ADK.DeliveryConfirmation; dc.Enabled && adkClient != nil {
+		timeout, err := time.ParseDuration(dc.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delivery_confirmation timeout: %w", err)
+		}
+		client.deliveries = &deliveryReporter{confirmer: adkClient, timeout: timeout}
+	}
+
+	// Group members share the group's session; direct chats keep one
+	// session per user.
+	if adkClient != nil {
+		adkClient.SetSessionKey(agent.PerGroupSession)
+		if gm := cfg.ADK.GroupMetadata; gm.Enabled {
+			meta := newGroupMetadata(wac.GetGroupInfo, client.contactName, gm.StateKey)
+			adkClient.SetSessionStater(meta.state)
+		}
+	}
+
+	// Registered before ForApp below so the business client seeds too.
+	if every := cfg.ADK.Summary.EveryTurns; every > 0 && gatewayStore != nil && adkClient != nil {
+		client.summaries = newSummaryScheduler(every)
+		adkClient.SetSessionSeeder(client.seedSummary)
+	}
+
+	if biz := cfg.WhatsApp.BusinessAccounts; biz.Mode == config.BusinessModeAgent && biz.AppName != "" && adkClient != nil {
+		client.businessADK = adkClient.ForApp(biz.AppName)
+	}
+
+	if paging := cfg.WhatsApp.ReplyPaging; paging.MaxLength > 0 {
+		ttl, err := time.ParseDuration(paging.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reply_paging ttl: %w", err)
+		}
+		client.pager = newReplyPager(paging.MaxLength, paging.Prompt, ttl)
 	}
 
 	wac.AddEventHandler(client.handleEvent)
 
+	if names := cfg.WhatsApp.PersistState; len(names) > 0 && gatewayStore != nil {
+		client.state = newStateKeeper(gatewayStore)
+		parts := client.stateParts()
+		for _, name := range names {
+			client.state.add(name, parts[name])
+		}
+		restored, err := client.state.load(ctx)
+		if err != nil {
+			log.Warnf("Failed to restore state saved at shutdown: %v", err)
+		}
+		if len(restored) > 0 {
+			log.Infof("Restored %s state saved at shutdown", strings.Join(restored, ", "))
+		}
+	}
+
 	return client, nil
 }
 
@@ -97,6 +375,23 @@ func (c *Client) storeRequest(ctx context.Context, userID, uniqueID string, cont
 	}
 }
 
+// storeNewsletterPost records a channel post apart from user conversations.
+func (c *Client) storeNewsletterPost(ctx context.Context, msg *events.Message) {
+	if c.store == nil {
+		return
+	}
+	path := fmt.Sprintf("newsletters/%s/%s", msg.Info.Chat.User, msg.Info.ID)
+	metadata := map[string]interface{}{
+		"mime_type": "text/plain",
+		"metadata": map[string]interface{}{
+			"channel": msg.Info.Chat.String(),
+		},
+	}
+	if err := c.store.PutFile(ctx, path, metadata, []byte(extractText(msg)), msg.Info.Timestamp); err != nil {
+		c.log.Errorf("Failed to store channel post to filesys: %v", err)
+	}
+}
+
 func (c *Client) storeResponse(ctx context.Context, userID, uniqueID string, content []byte, ts time.Time, errStr string, contextType, msgRef string) {
 	if c.store == nil {
 		return
@@ -135,9 +430,7 @@ func (c *Client) Connect(ctx context.Context) error {
 		for evt := range qrChan {
 			switch evt.Event {
 			case "code":
-				fmt.Println("\n📱 Scan this QR code with WhatsApp:")
-				qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
-				fmt.Println()
+				printQR(evt.Code)
 			case "success":
 				fmt.Println("✅ Successfully logged in!")
 				return nil
@@ -168,6 +461,35 @@ func (c *Client) Run(ctx context.Context) error {
 	// Start command processor
 	go c.processCommands(ctx)
 
+	if c.sendq != nil {
+		go c.sendq.run(ctx)
+		if c.outbox != nil {
+			go c.resumeOutbox(ctx)
+		}
+	}
+
+	if c.usage != nil {
+		go c.usage.run(ctx, func(err error) {
+			c.log.Warnf("Failed to flush usage counts: %v", err)
+		})
+	}
+
+	if c.cfg.WhatsApp.MaxConnectionAge != "" {
+		maxAge, err := time.ParseDuration(c.cfg.WhatsApp.MaxConnectionAge)
+		if err != nil {
+			return fmt.Errorf("invalid max_connection_age: %w", err)
+		}
+		go c.reconnectLoop(ctx, maxAge)
+	}
+
+	if c.cfg.Gateway.HeartbeatInterval != "" {
+		interval, err := time.ParseDuration(c.cfg.Gateway.HeartbeatInterval)
+		if err != nil {
+			return fmt.Errorf("invalid heartbeat_interval: %w", err)
+		}
+		go runHeartbeat(ctx, interval, c.wac.IsConnected, &c.received, c.emitHeartbeat)
+	}
+
 	select {
 	case <-ctx.Done():
 		c.log.Infof("Context cancelled, disconnecting...")
@@ -176,9 +498,32 @@ func (c *Client) Run(ctx context.Context) error {
 	}
 
 	c.wac.Disconnect()
+	if c.usage != nil {
+		if err := c.usage.flush(context.Background()); err != nil {
+			c.log.Errorf("Failed to flush usage counts on shutdown: %v", err)
+		}
+	}
+	if c.state != nil {
+		if err := c.state.save(context.Background()); err != nil {
+			c.log.Errorf("Failed to save state on shutdown: %v", err)
+		}
+	}
 	return nil
 }
 
+// countUsage records a usage event for phone, flushing in the background
+// once the batch is full.
+func (c *Client) countUsage(phone string, kind usageKind) {
+	if c.usage == nil || !c.usage.add(phone, kind) {
+		return
+	}
+	go func() {
+		if err := c.usage.flush(context.Background()); err != nil {
+			c.log.Warnf("Failed to flush usage counts: %v", err)
+		}
+	}()
+}
+
 func (c *Client) processCommands(ctx context.Context) {
 	if c.store == nil {
 		return
@@ -228,6 +573,14 @@ func (c *Client) handleCommand(ctx context.Context, cmd store.Command) {
 		}
 	case "get_blocklist":
 		result, err = c.RemoteGetBlocklist()
+	case "broadcast":
+		var payload struct {
+			ID string `json:"id"`
+		}
+		if err = json.Unmarshal(cmd.Payload, &payload); err == nil {
+			err = c.startBroadcast(ctx, payload.ID)
+			result = map[string]string{"id": payload.ID, "status": "started"}
+		}
 	case "send_message":
 		var payload struct {
 			JID         string             `json:"jid"`
@@ -256,6 +609,20 @@ func (c *Client) handleCommand(ctx context.Context, cmd store.Command) {
 }
 
 func (c *Client) RemoteBlock(jidStr string) error {
+	jid, err := blockJID(jidStr)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.wac.UpdateBlocklist(context.Background(), jid, events.BlocklistChangeActionBlock)
+	if err != nil {
+		return fmt.Errorf("whatsmeow error: %w", err)
+	}
+	return nil
+}
+
+// blockJID parses the user JID RemoteBlock acts on.
+func blockJID(jidStr string) (types.JID, error) {
 	jid, err := types.ParseJID(jidStr)
 	if err != nil {
 		// Try appending server if missing
@@ -263,19 +630,14 @@ func (c *Client) RemoteBlock(jidStr string) error {
 			jid, err = types.ParseJID(jidStr + "@" + types.DefaultUserServer)
 		}
 		if err != nil {
-			return fmt.Errorf("invalid JID: %w", err)
+			return types.JID{}, fmt.Errorf("invalid JID: %w", err)
 		}
 	}
 
 	if jid.Server != types.DefaultUserServer {
-		return fmt.Errorf("can only block individual users (s.whatsapp.net)")
-	}
-
-	_, err = c.wac.UpdateBlocklist(context.Background(), jid, events.BlocklistChangeActionBlock)
-	if err != nil {
-		return fmt.Errorf("whatsmeow error: %w", err)
+		return types.JID{}, fmt.Errorf("can only block individual users (s.whatsapp.net)")
 	}
-	return nil
+	return jid, nil
 }
 
 func (c *Client) RemoteUnblock(jidStr string) error {
@@ -320,7 +682,7 @@ func (c *Client) SendMessage(ctx context.Context, jidStr string, text string, me
 
 	// Send media if provided
 	for _, m := range media {
-		err := c.sendMediaPart(ctx, jid, userID, uniqueID, &m, "", contextType, msgRef)
+		err := c.sendMediaPart(ctx, nil, jid, userID, uniqueID, &m, "", contextType, msgRef)
 		if err != nil {
 			return fmt.Errorf("failed to send media: %w", err)
 		}
@@ -346,14 +708,37 @@ func (c *Client) handleEvent(evt interface{}) {
 	switch v := evt.(type) {
 	case *events.Message:
 		c.handleMessage(v)
+	case *events.UndecryptableMessage:
+		c.handleUndecryptable(v)
 	case *events.HistorySync:
 		c.handleHistorySync(v)
 	case *events.Connected:
+		c.connectedAt.Store(time.Now().UnixNano())
 		c.log.Infof("Connected to WhatsApp")
+		if c.adkClient != nil && c.wac.Store.ID != nil {
+			c.adkClient.SetSessionTag(agent.TagBotJID, c.wac.Store.ID.ToNonAD().String())
+		}
+		// WhatsApp only shows chat presence from accounts that are online.
+		if c.typingEvery > 0 {
+			if err := c.wac.SendPresence(context.Background(), types.PresenceAvailable); err != nil {
+				c.log.Warnf("Failed to mark account online for typing indicators: %v", err)
+			}
+		}
 	case *events.Disconnected:
 		c.log.Infof("Disconnected from WhatsApp")
 	case *events.LoggedOut:
 		c.log.Warnf("Logged out from WhatsApp")
+	case *events.NewsletterJoin:
+		c.log.Infof("Joined channel %s (%s)", v.ID, v.ThreadMeta.Name.Text)
+	case *events.NewsletterLeave:
+		c.log.Infof("Left channel %s", v.ID)
+	case *events.NewsletterMuteChange:
+		c.log.Debugf("Channel %s mute changed to %s", v.ID, v.Mute)
+	case *events.NewsletterLiveUpdate:
+		// Live updates only follow channels subscribed to with
+		// NewsletterSubscribeLiveUpdates, which the gateway never does;
+		// posts arrive as messages.
+		c.log.Debugf("Ignoring %d live updates of channel %s", len(v.Messages), v.JID)
 	}
 }
 
@@ -370,6 +755,10 @@ func (c *Client) handleHistorySync(v *events.HistorySync) {
 			if err != nil {
 				continue
 			}
+			msg = withViewOnceUnwrapped(msg)
+			if msg.IsViewOnce && c.cfg.WhatsApp.ViewOnce.Refuse {
+				continue
+			}
 
 			text := extractText(msg)
 			ctx := context.Background()
@@ -390,12 +779,58 @@ func (c *Client) handleHistorySync(v *events.HistorySync) {
 }
 
 func (c *Client) handleMessage(msg *events.Message) {
-	if msg.Info.IsGroup {
+	c.inflight.Add(1)
+	defer c.inflight.Add(-1)
+	c.received.Add(1)
+
+	switch routeNewsletter(c.cfg.WhatsApp.Newsletters, msg.Info) {
+	case newsletterIgnore:
+		c.log.Debugf("Ignoring channel post %s from %s", msg.Info.ID, msg.Info.Chat.String())
+		return
+	case newsletterStore:
+		c.storeNewsletterPost(context.Background(), msg)
+		return
+	case newsletterAgent:
+		// Each channel has its own session, keyed by its JID.
+		conv := agent.Conversation{UserID: msg.Info.Chat.String()}
+		c.forwardPost(context.Background(), conv, msg.Info.Chat.User, msg.Info.ID, msg.Info.Timestamp, extractText(msg), "newsletter")
+		return
+	}
+
+	group := msg.Info.IsGroup
+	if group && !c.acceptGroupMessage(context.Background(), msg) {
+		return
+	}
+
+	// Revokes carry no user input; never route them to auth or the agent.
+	if c.revokes.handle(context.Background(), msg) {
 		return
 	}
 
+	msg = withViewOnceUnwrapped(msg)
 	text := extractText(msg)
 
+	// Edits replace the message with its corrected text, as configured.
+	if targetID, edited, ok := editedText(msg.Message); ok {
+		text = editTurnText(c.cfg.WhatsApp.Edits, edited)
+		c.log.Infof("User %s edited message %s (%s)", msg.Info.Sender.String(), targetID, c.cfg.WhatsApp.Edits.Mode)
+		if text == "" {
+			return
+		}
+	}
+
+	// Reactions are recorded and, if configured, reach the agent as feedback.
+	if feedback, ok := c.reactions.handle(context.Background(), msg); ok {
+		if feedback == "" {
+			return
+		}
+		text = feedback
+	}
+
+	// Replies normally go back to the chat the message came from.
+	chat := msg.Info.Chat
+	authOnly := false
+
 	// Handle messages sent from me (e.g., from another device)
 	if msg.Info.IsFromMe {
 		userID := msg.Info.Chat.User
@@ -403,9 +838,16 @@ func (c *Client) handleMessage(msg *events.Message) {
 		ctx := context.Background()
 		c.storeResponse(ctx, userID, uniqueID, []byte(text), msg.Info.Timestamp, "", "", "")
 
-		// If message is sent to ourselves (Note to Self), allow it to be processed
-		if c.wac.Store.ID != nil && userID != c.wac.Store.ID.User {
+		// Notes to self are processed; with self_auth, so are verification
+		// and AUTH messages sent from this account to any chat.
+		noteToSelf := c.wac.Store.ID == nil || userID == c.wac.Store.ID.User
+		switch routeSelfMessage(noteToSelf, c.cfg.WhatsApp.SelfAuth, isAuthFlowText(text, c.verifier != nil, c.oauthHandler != nil)) {
+		case selfIgnore:
 			return
+		case selfAuthOnly:
+			// Never answer into someone else's chat.
+			authOnly = true
+			chat = c.wac.Store.ID.ToNonAD()
 		}
 	}
 
@@ -425,18 +867,40 @@ func (c *Client) handleMessage(msg *events.Message) {
 	displayID := sender.String()
 	uniqueID := msg.Info.ID
 
+	// Trim, strip mentions/prefixes etc. as configured, before the text is
+	// logged or stored.
+	text = c.inbound.apply(text)
+	if group {
+		text = c.stripBotMention(text)
+	}
+
 	c.log.Infof("Received message from %s: %s", displayID, truncate(text, 80))
 
 	// Global Blacklist Check
 	if c.store != nil {
 		ctx := context.Background()
 		// Check both raw ID and full JID string
-		blocked, _ := c.store.IsBlacklisted(ctx, userID)
-		if !blocked {
-			blocked, _ = c.store.IsBlacklisted(ctx, displayID)
+		blocked, err := blacklisted(ctx, c.store.IsBlacklisted, userID, displayID)
+		if err != nil {
+			if !c.cfg.Store.FailOpen() {
+				c.log.Errorf("Blacklist check failed for %s, dropping message: %v", displayID, err)
+				return
+			}
+			c.log.Warnf("Blacklist check failed for %s, continuing in degraded mode: %v", displayID, err)
 		}
 
 		if blocked {
+			if c.appeals != nil {
+				reply, handled, err := c.appeals.handle(ctx, userID, text)
+				if err != nil {
+					c.log.Errorf("Failed to record appeal from %s: %v", displayID, err)
+				}
+				if handled && reply != "" {
+					c.log.Infof("Appeal command from blacklisted user %s", displayID)
+					c.sendTextMessage(ctx, chat, userID, uniqueID, reply, "system", uniqueID)
+					return
+				}
+			}
 			c.log.Warnf("Blocking message from blacklisted user: %s", displayID)
 			return
 		}
@@ -444,39 +908,137 @@ func (c *Client) handleMessage(msg *events.Message) {
 
 	// Store the incoming request (text if available)
 	ctx := context.Background()
+	switch c.cfg.WhatsApp.ReadReceipts {
+	case config.ReadReceiptsImmediate:
+		c.markRead(ctx, msg)
+	case config.ReadReceiptsAfterReply:
+		defer c.markRead(ctx, msg)
+	}
+	if c.quotes != nil {
+		c.quotes.remember(uniqueID, msg.Info.Sender, msg.Message)
+	}
+	c.countUsage(userID, usageInbound)
+	c.stats.message()
+	if msg.IsViewOnce && c.cfg.WhatsApp.ViewOnce.Refuse {
+		c.log.Infof("Refusing view-once message from %s", displayID)
+		if reply := c.cfg.WhatsApp.ViewOnce.Message; reply != "" {
+			c.sendTextMessage(ctx, chat, userID, uniqueID, reply, "system", uniqueID)
+		}
+		return
+	}
+	// Votes on the agent's polls reach it as text naming the options.
+	if vote := c.pollVote(ctx, msg); vote != "" {
+		c.log.Infof("Poll vote from %s", displayID)
+		text = vote
+	}
 	if text != "" {
 		c.storeRequest(ctx, userID, uniqueID, []byte(text), msg.Info.Timestamp, "text/plain", msg.Info.IsFromMe)
 	}
 
-	// Process media and documents
-	mediaParts := c.processAndStoreMedia(ctx, userID, uniqueID, msg)
+	if c.flood != nil {
+		switch c.flood.check(userID, text) {
+		case floodWarn:
+			c.log.Warnf("Flood detected from %s, suppressing repeated messages", displayID)
+			c.sendTextMessage(ctx, chat, userID, uniqueID, c.cfg.WhatsApp.Flood.Message, "system", uniqueID)
+			return
+		case floodSuppress:
+			c.log.Infof("Suppressed repeated message from %s", displayID)
+			return
+		}
+	}
 
-	if c.verifyHandler != nil && auth.IsVerificationToken(text) != nil {
-		response := c.verifyHandler.Handle(ctx, userID, text)
-		if response != "" {
-			c.sendTextMessage(ctx, msg.Info.Chat, userID, uniqueID, response, "system", uniqueID)
+	if c.links != nil && text != "" {
+		if hit := c.links.match(text); hit != "" {
+			c.log.Warnf("Blocked message from %s linking to %q", displayID, hit)
+			blacklisted, err := c.links.strike(ctx, userID)
+			if err != nil {
+				c.log.Errorf("Failed to auto-blacklist %s: %v", displayID, err)
+			} else if blacklisted {
+				c.log.Warnf("Auto-blacklisted %s after repeated blocked links", displayID)
+			}
+			if msg := c.cfg.WhatsApp.LinkFilter.Message; msg != "" {
+				c.sendTextMessage(ctx, chat, userID, uniqueID, msg, "system", uniqueID)
+			}
 			return
 		}
 	}
 
-	if c.oauthHandler != nil && auth.IsAuthCommand(text) {
-		response, err := c.oauthHandler.Handle(userID, text)
+	// Process media and documents
+	mediaParts, mediaData := c.processAndStoreMedia(ctx, userID, uniqueID, msg)
+	if c.transcriber != nil && msg.Message.AudioMessage != nil {
+		mediaParts = c.transcribeVoiceNote(ctx, userID, mediaParts)
+	}
+
+	if c.verifier != nil && auth.IsVerificationToken(text) == nil {
+		if token := documentToken(msg.Message, mediaData); token != "" {
+			c.log.Infof("Verification token received as document from %s", userID)
+			text = token
+		} else if c.cfg.WhatsApp.InteractiveTokens {
+			if token := interactiveToken(msg.Message); token != "" {
+				c.log.Infof("Verification token received as interactive reply from %s", userID)
+				text = token
+			}
+		}
+	}
+
+	// Verification and AUTH run before the allowlist check; the auth policy
+	// decides whether non-allowed users may use them at all. At most one of
+	// them handles the message.
+	flow, flowText := classifyAuthFlow(text, c.verifier != nil, c.oauthHandler != nil, c.cfg.WhatsApp.AuthPrecedence)
+	isAuthFlow := flow != flowAgent
+	if isAuthFlow && !authFlowPermitted(c.cfg.WhatsApp.AuthPolicy, func() bool { return c.isUserAllowed(msg.Info.Sender) }) {
+		c.log.Infof("Rejected verification/AUTH from non-allowed user %s", displayID)
+		c.sendTextMessage(ctx, chat, userID, uniqueID, c.cfg.WhatsApp.AuthRejectedMessage, "system", uniqueID)
+		return
+	}
+
+	switch flow {
+	case flowVerify:
+		if response, ok := verifyToken(ctx, c.verifier, userID, flowText); ok {
+			c.sendTextMessage(ctx, chat, userID, uniqueID, response, "system", uniqueID)
+			if emoji := verificationAck(c.cfg.WhatsApp.AckReactions, response, c.cfg.Verification.Messages.Success); emoji != "" {
+				c.ackReact(ctx, msg, emoji)
+			}
+			return
+		}
+	case flowAuth:
+		response, err := c.oauthHandler.Handle(ctx, userID, flowText)
 		if err != nil {
 			c.log.Errorf("OAuth handler error: %v", err)
 			response = "⚠️ Something went wrong processing your AUTH request. Please try again."
 		}
 		if response != "" {
-			c.sendTextMessage(ctx, msg.Info.Chat, userID, uniqueID, response, "system", uniqueID)
+			c.sendTextMessage(ctx, chat, userID, uniqueID, response, "system", uniqueID)
 		}
 		return
 	}
 
-	if !c.isUserAllowed(msg.Info.Sender) {
+	// Self-messages admitted for verification/AUTH never reach the agent.
+	if authOnly {
+		return
+	}
+
+	// Admin commands are only taken in direct chats, never from a group.
+	if c.admin != nil && !group && len(mediaParts) == 0 {
+		if reply, ok := c.admin.dispatch(ctx, userID, text); ok {
+			c.log.Infof("Admin command from %s: %s", displayID, strings.Fields(text)[0])
+			c.sendTextMessage(ctx, chat, userID, uniqueID, reply, "system", uniqueID)
+			return
+		}
+	}
+
+	reason := c.allowReasonFor(msg.Info.Sender)
+	if reason == allowDenied {
 		c.log.Infof("Blocked message from non-allowed user %s", msg.Info.Sender.String())
 		response := "Sorry, we only entertain friends from India."
-		c.sendTextMessage(ctx, msg.Info.Chat, userID, uniqueID, response, "system", uniqueID)
+		c.sendTextMessage(ctx, chat, userID, uniqueID, response, "system", uniqueID)
 		return
 	}
+	if c.nudger != nil {
+		if nudge := c.nudger.nudge(ctx, userID, reason); nudge != "" {
+			c.sendTextMessage(ctx, chat, userID, uniqueID, nudge, "system", uniqueID)
+		}
+	}
 
 	// Autonomous Mode Check: If ADK is disabled, we stop here.
 	// External agents will pick up the request from filesys and reply via SendMessage.
@@ -485,7 +1047,77 @@ func (c *Client) handleMessage(msg *events.Message) {
 		return
 	}
 
-	// Construct parts for ADK
+	// A group shares one session, so its replies are confirmed there.
+	turn := &replyTurn{conv: agent.Conversation{UserID: userID}}
+	if group {
+		turn.conv.GroupID = msg.Info.Chat.String()
+	}
+
+	adkClient := c.adkClient
+	switch routeForSender(c.cfg.WhatsApp.BusinessAccounts, isBusinessSender(msg.Info)) {
+	case routeIgnore:
+		c.log.Infof("Not forwarding message from business account %s to the agent", displayID)
+		return
+	case routeBusinessAgent:
+		adkClient = c.businessADK
+	}
+	// Replies are confirmed by the client of the app that produced them.
+	if adkClient != c.adkClient {
+		turn.agent = adkClient
+	}
+
+	if c.pager != nil && len(mediaParts) == 0 && strings.EqualFold(text, c.cfg.WhatsApp.ReplyPaging.Command) {
+		if next, ok := c.pager.more(userID); ok {
+			c.sendAgentText(ctx, turn, chat, userID, uniqueID, next)
+			return
+		}
+	}
+
+	switch forwardedPolicy(c.cfg.WhatsApp.Forwarded.Policy, msg.Message) {
+	case config.ForwardedPrompt:
+		c.log.Infof("Forwarded message from %s, asking before calling the agent", displayID)
+		c.sendTextMessage(ctx, chat, userID, uniqueID, c.cfg.WhatsApp.Forwarded.Message, "system", uniqueID)
+		return
+	case config.ForwardedIgnore:
+		c.log.Infof("Not forwarding forwarded message from %s to the agent", displayID)
+		return
+	}
+
+	if c.cooldown != nil {
+		switch c.cooldown.check(userID) {
+		case cooldownNotice:
+			c.log.Infof("Agent error cooldown active for %s, sending notice", displayID)
+			c.sendTextMessage(ctx, chat, userID, uniqueID, c.cfg.WhatsApp.ErrorCooldown.Message, "system", uniqueID)
+			return
+		case cooldownSuppress:
+			c.log.Infof("Agent error cooldown active for %s, not processing message", displayID)
+			return
+		}
+	}
+
+	// While a form is open, text answers its fields instead of going to
+	// the agent; the completed form is forwarded in their place.
+	if c.forms != nil && text != "" && len(mediaParts) == 0 {
+		step, err := c.forms.answer(ctx, userID, text)
+		if err != nil {
+			c.log.Errorf("Form handling failed for %s: %v", displayID, err)
+		}
+		if step.reply != "" {
+			c.sendTextMessage(ctx, chat, userID, uniqueID, step.reply, "system", uniqueID)
+			return
+		}
+		if step.forward != "" {
+			c.log.Infof("Form completed by %s, forwarding to agent", displayID)
+			text = step.forward
+		}
+	}
+
+	// Construct parts for ADK. A group shares one session, so each turn
+	// names its sender.
+	conv := turn.conv
+	if group && text != "" {
+		text = groupTurnText(msg.Info.PushName, userID, text)
+	}
 	var parts []agent.Part
 	if text != "" {
 		parts = append(parts, agent.Part{Text: text})
@@ -493,58 +1125,96 @@ func (c *Client) handleMessage(msg *events.Message) {
 	parts = append(parts, mediaParts...)
 
 	if len(parts) == 0 {
+		if reply := stickerReply(c.cfg.WhatsApp.Stickers, msg.Message); reply != "" {
+			c.log.Infof("Sticker from %s, sending canned reply", displayID)
+			c.sendTextMessage(ctx, chat, userID, uniqueID, reply, "system", uniqueID)
+			return
+		}
+		// An attachment that could not be downloaded or converted would
+		// otherwise go unanswered.
+		if reply := mediaErrorReply(c.cfg.WhatsApp.MediaErrorReply, attachmentKind(msg.Message)); reply != "" {
+			c.sendTextMessage(ctx, chat, userID, uniqueID, reply, "system", uniqueID)
+		}
 		return
 	}
+	if qc := c.cfg.ADK.QuotedContext; qc.Enabled {
+		parts = withQuotedContext(parts, quotedText(msg.Message), qc.Prefix, qc.MaxChars)
+	}
+
+	if c.agentLimit != nil {
+		switch c.agentLimit.allow(userID) {
+		case agentLimitNotice:
+			c.log.Infof("Agent rate limit reached for %s, sending notice", displayID)
+			c.sendTextMessage(ctx, chat, userID, uniqueID, c.cfg.WhatsApp.AgentRateLimit.Message, "system", uniqueID)
+			return
+		case agentLimitSuppress:
+			c.log.Infof("Agent rate limit reached for %s, not calling the agent", displayID)
+			return
+		}
+	}
 
-	adkResponseParts, err := c.adkClient.ChatParts(ctx, userID, parts)
+	state := withMetadata(c.profileStateFor(ctx, userID), c.cfg.ADK.MessageMetadata.StateKey,
+		messageMetadata(c.cfg.ADK.MessageMetadata, msg.Info, msg.Message, userID))
+	c.countUsage(userID, usageAgentCall)
+	if emoji := c.cfg.WhatsApp.AckReactions.Received; emoji != "" {
+		c.ackReact(ctx, msg, emoji)
+	}
+	stopTyping := c.startTyping(ctx, chat)
+	start := time.Now()
+	adkResponseParts, err := adkClient.ChatConversation(ctx, conv, parts, state)
+	c.stats.agentCall(time.Since(start), err)
 	if err != nil {
+		stopTyping()
 		c.log.Errorf("Failed to get agent response: %v", err)
-		c.sendTextMessage(ctx, msg.Info.Chat, userID, uniqueID, "Sorry, I encountered an error processing your message. Please try again.", "system", uniqueID)
+		reply := "Sorry, I encountered an error processing your message. Please try again."
+		var rl *agent.RateLimitedError
+		if errors.As(err, &rl) {
+			reply = c.cfg.ADK.RateLimit.BusyMessage
+		}
+		c.sendTextMessage(ctx, chat, userID, uniqueID, reply, "system", uniqueID)
+		c.settleAckReact(ctx, msg, c.cfg.WhatsApp.AckReactions.Failed)
+		if c.cooldown != nil {
+			c.cooldown.failed(userID)
+		}
 		return
 	}
+	if c.cooldown != nil {
+		c.cooldown.succeeded(userID)
+	}
 
-	if len(adkResponseParts) == 0 {
-		return
+	if c.speaker != nil && isVoiceNote(msg.Message) {
+		adkResponseParts = c.voiceReply(ctx, userID, adkResponseParts)
+	}
+	if len(adkResponseParts) > 0 {
+		c.sendADKParts(ctx, adkClient, turn, chat, userID, uniqueID, adkResponseParts)
 	}
+	stopTyping()
+	c.settleAckReact(ctx, msg, c.cfg.WhatsApp.AckReactions.Replied)
 
-	c.sendADKParts(ctx, msg.Info.Chat, userID, uniqueID, adkResponseParts)
+	// Summaries run after the reply is sent, on the same event goroutine, so
+	// at most one refresh per session is ever in flight. They are kept per
+	// ADK session user, so a group session's summary covers the group.
+	if c.summaries != nil {
+		sessionUser, _ := adkClient.SessionFor(conv)
+		if refs, due := c.summaries.turn(sessionUser, summaryRef{phone: userID, uniqueID: uniqueID}); due {
+			c.refreshSummary(ctx, adkClient, sessionUser, refs)
+		}
+	}
 }
 
-func (c *Client) processAndStoreMedia(ctx context.Context, userID, uniqueID string, msg *events.Message) []agent.Part {
+// processAndStoreMedia downloads, stores and converts the message's
+// attachment. The downloaded bytes are returned so callers can inspect them
+// without fetching the attachment again.
+func (c *Client) processAndStoreMedia(ctx context.Context, userID, uniqueID string, msg *events.Message) ([]agent.Part, []byte) {
 	m := msg.Message
-	if m == nil {
-		return nil
-	}
 
-	var data []byte
-	var err error
-	var mimeType string
-	var media whatsmeow.DownloadableMessage
-
-	// Step 1: Identify Media and Metadata
-	switch {
-	case m.ImageMessage != nil:
-		media, mimeType = m.ImageMessage, "image/jpeg"
-	case m.AudioMessage != nil:
-		media, mimeType = m.AudioMessage, "audio/wav"
-	case m.VideoMessage != nil:
-		media, mimeType = m.VideoMessage, "video/mp4"
-	case m.DocumentMessage != nil:
-		media, mimeType = m.DocumentMessage, m.DocumentMessage.GetMimetype()
-	case m.StickerMessage != nil:
-		media, mimeType = m.StickerMessage, m.StickerMessage.GetMimetype()
-	default:
-		return nil
-	}
-
-	// Step 2: Download
-	data, err = c.wac.Download(ctx, media)
+	data, mimeType, err := downloadMedia(ctx, c.downloader, m)
 	if err != nil {
 		c.log.Errorf("Failed to download media for %s: %v", uniqueID, err)
-		return nil
+		return nil, nil
 	}
 	if data == nil {
-		return nil
+		return nil, nil
 	}
 
 	// Step 3: Store raw media
@@ -552,41 +1222,52 @@ func (c *Client) processAndStoreMedia(ctx context.Context, userID, uniqueID stri
 
 	// Process media for ADK
 	if c.mediaProc == nil {
-		return nil
+		return nil, data
 	}
 
 	pCtx, cancel := context.WithTimeout(ctx, ProcessTimeout)
 	defer cancel()
 
 	var parts []agent.Part
-	// Step 4: Process for ADK (Explicitly excluding Stickers)
+	var pErr error
+	// Step 4: Process for ADK (stickers only when configured to forward)
 	switch {
 	case m.ImageMessage != nil:
-		part, pErr := c.mediaProc.ProcessImage(pCtx, data)
-		if pErr == nil {
+		var part *agent.Part
+		if part, pErr = c.mediaProc.ProcessImage(pCtx, data); pErr == nil {
 			parts = append(parts, *part)
 		}
 	case m.AudioMessage != nil:
-		part, pErr := c.mediaProc.ProcessAudio(pCtx, data)
-		if pErr == nil {
+		var part *agent.Part
+		if part, pErr = c.mediaProc.ProcessAudio(pCtx, data); pErr == nil {
 			parts = append(parts, *part)
 		}
 	case m.VideoMessage != nil:
-		vParts, pErr := c.mediaProc.ProcessVideo(pCtx, data)
-		if pErr == nil {
+		var vParts []agent.Part
+		v := c.cfg.WhatsApp.Video
+		if vParts, pErr = c.mediaProc.videoParts(pCtx, v.Mode, v.Note, m.VideoMessage, data); pErr == nil {
 			parts = append(parts, vParts...)
 		}
+	case m.StickerMessage != nil && c.cfg.WhatsApp.Stickers.Mode == config.StickerModeForward:
+		var part *agent.Part
+		if part, pErr = c.mediaProc.ProcessImage(pCtx, data); pErr == nil {
+			parts = append(parts, *part)
+		}
 	case m.DocumentMessage != nil:
-		part, pErr := c.mediaProc.ProcessDocument(pCtx, data, mimeType)
-		if pErr == nil {
+		var part *agent.Part
+		if part, pErr = c.mediaProc.ProcessDocument(pCtx, data, mimeType); pErr == nil {
+			part.InlineData.DisplayName = m.DocumentMessage.GetFileName()
 			parts = append(parts, *part)
 		}
 	}
+	if pErr != nil {
+		c.log.Errorf("Failed to process media for %s: %v", uniqueID, pErr)
+	}
 
-	return parts
+	return parts, data
 }
 
-func (c *Client) sendADKParts(ctx context.Context, chat types.JID, userID string, uniqueID string, parts []agent.Part) {
+func (c *Client) sendADKParts(ctx context.Context, adk *agent.Client, turn *replyTurn, chat types.JID, userID string, uniqueID string, parts []agent.Part) {
 	// Pre-check for silent ignore instruction
 	for _, part := range parts {
 		if part.InlineData != nil && part.InlineData.MimeType == agent.MimeTypeSilentIgnore {
@@ -602,58 +1283,212 @@ func (c *Client) sendADKParts(ctx context.Context, chat types.JID, userID string
 		}
 	}
 
-	var caption string
-	hasSentMedia := false
-	for _, part := range parts {
-		if part.Text != "" {
-			if !hasSentMedia {
-				if caption != "" {
-					caption += "\n"
-				}
-				caption += part.Text
-			} else {
-				// Already sent media, or this is additional text after media
-				// Send as separate message
-				c.sendTextMessage(ctx, chat, userID, uniqueID, part.Text, "response", uniqueID)
-			}
-			continue
-		}
+	spec, parts, err := splitFormSpec(parts)
+	if err != nil {
+		c.log.Warnf("Ignoring invalid form from agent for %s: %v", userID, err)
+	}
+	if spec != nil && c.forms == nil {
+		c.log.Warnf("Agent sent form %q for %s but forms are disabled", spec.ID, userID)
+		spec = nil
+	}
+	// The form's first prompt follows whatever else the agent said.
+	if spec != nil {
+		defer c.startForm(ctx, turn, chat, userID, uniqueID, spec)
+	}
 
-		if part.InlineData != nil {
-			// Process media
-			err := c.sendMediaPart(ctx, chat, userID, uniqueID, part.InlineData, caption, "response", uniqueID)
-			if err != nil {
-				c.log.Errorf("Failed to send media part: %v", err)
-				// If media fails, maybe at least send the caption if it's the first media
-				if !hasSentMedia && caption != "" {
-					c.sendTextMessage(ctx, chat, userID, uniqueID, caption, "response", uniqueID)
-				}
+	polls, parts, pollErrs := splitPollSpecs(parts)
+	for _, err := range pollErrs {
+		c.log.Warnf("Ignoring invalid poll from agent for %s: %v", userID, err)
+	}
+
+	buttons, parts, buttonErrs := splitButtonsSpecs(parts)
+	for _, err := range buttonErrs {
+		c.log.Warnf("Ignoring invalid buttons from agent for %s: %v", userID, err)
+	}
+
+	lists, parts, listErrs := splitListSpecs(parts)
+	for _, err := range listErrs {
+		c.log.Warnf("Ignoring invalid list from agent for %s: %v", userID, err)
+	}
+
+	if c.images != nil {
+		parts = c.expandReplyImages(ctx, userID, parts)
+	}
+
+	media, body := planReply(parts)
+	for _, m := range media {
+		// Captions go through the outbound pipeline like any other text.
+		first, rest := c.outbound.caption(m.caption)
+		err := c.sendMediaPart(ctx, turn, chat, userID, uniqueID, m.data, first, "response", uniqueID)
+		if err != nil {
+			c.log.Errorf("Failed to send media part: %v", err)
+			// If media fails, at least send the caption as text
+			if first != "" {
+				rest = append([]string{first}, rest...)
 			}
-			caption = "" // Reset caption after it's used
-			hasSentMedia = true
+			if notice := mediaFallbackText(c.cfg.WhatsApp.MediaFallbackText, err, m.data.MimeType, first); notice != "" {
+				c.log.Infof("Media rejected for %s, sending plain-text fallback", userID)
+				rest = append(rest, notice)
+			}
+		}
+		for _, msg := range rest {
+			c.sendText(ctx, turn, chat, userID, uniqueID, msg, "response", uniqueID)
 		}
 	}
 
-	// If we have remaining caption and no media was ever sent
-	if !hasSentMedia && caption != "" {
-		c.sendTextMessage(ctx, chat, userID, uniqueID, caption, "response", uniqueID)
+	if body != "" {
+		c.sendBudgetedText(ctx, adk, turn, chat, userID, uniqueID, body)
+	}
+	for _, b := range buttons {
+		c.sendButtons(ctx, turn, chat, userID, uniqueID, b)
+	}
+	for _, l := range lists {
+		c.sendList(ctx, turn, chat, userID, uniqueID, l)
+	}
+	for _, p := range polls {
+		c.sendPoll(ctx, chat, userID, p)
+	}
+}
+
+// startForm opens spec for userID and asks its first field.
+func (c *Client) startForm(ctx context.Context, turn *replyTurn, chat types.JID, userID, uniqueID string, spec *formSpec) {
+	prompt, err := c.forms.start(ctx, userID, spec)
+	if err != nil {
+		c.log.Errorf("Failed to start form %q for %s: %v", spec.ID, userID, err)
+		return
 	}
+	c.sendText(ctx, turn, chat, userID, uniqueID, prompt, "response", uniqueID)
 }
 
+func (c *Client) sendAgentText(ctx context.Context, turn *replyTurn, chat types.JID, userID, uniqueID, text string) {
+	for _, m := range c.outbound.apply(text) {
+		c.sendText(ctx, turn, chat, userID, uniqueID, m, "response", uniqueID)
+	}
+}
+
+// pageReply truncates text to one page when reply paging is enabled.
+func (c *Client) pageReply(userID, text string) string {
+	if c.pager == nil {
+		return text
+	}
+	return c.pager.page(userID, text)
+}
+
+// sendTextMessage sends text, through the send queue when one is
+// configured.
 func (c *Client) sendTextMessage(ctx context.Context, chat types.JID, userID string, uniqueID string, text string, contextType, msgRef string) {
-	resp, err := c.wac.SendMessage(ctx, chat, &waE2E.Message{
-		Conversation: proto.String(text),
+	c.sendText(ctx, nil, chat, userID, uniqueID, text, contextType, msgRef)
+}
+
+// sendText is sendTextMessage for a reply to turn, which may be nil.
+func (c *Client) sendText(ctx context.Context, turn *replyTurn, chat types.JID, userID, uniqueID, text, contextType, msgRef string) {
+	if c.sendq == nil {
+		c.sendTextNow(ctx, turn, chat, userID, uniqueID, text, contextType, msgRef)
+		return
+	}
+	m := store.OutboxMessage{Chat: chat.String(), Phone: userID, UniqueID: uniqueID, Text: text, ContextType: contextType, MsgRef: msgRef}
+	if turn != nil {
+		m.GroupID = turn.conv.GroupID
+		m.BusinessAgent = turn.agent != nil && turn.agent == deliveryConfirmer(c.businessADK)
+	}
+	if c.outbox != nil {
+		persisted, err := c.outbox.add(ctx, m)
+		if err != nil {
+			c.log.Warnf("Failed to persist queued message to %s, it will not survive a restart: %v", userID, err)
+		} else {
+			m = persisted
+		}
+	}
+	c.queueText(ctx, chat, m)
+}
+
+// queueText pushes m onto the send queue. A persisted m (one with an ID)
+// is claimed and marked attempted just before sending, and leaves the
+// outbox once sent or dropped.
+func (c *Client) queueText(ctx context.Context, chat types.JID, m store.OutboxMessage) {
+	c.sendq.push(ctx, sendJob{
+		send: func(ctx context.Context) {
+			if m.ID != "" {
+				claimed, err := c.outbox.attempt(ctx, m)
+				if err != nil {
+					c.log.Warnf("Failed to mark queued message %s as sent: %v", m.ID, err)
+				} else if !claimed {
+					c.log.Infof("Not sending queued message %s: another gateway instance resent it", m.ID)
+					return
+				}
+			}
+			c.sendTextNow(ctx, c.outboxTurn(m), chat, m.Phone, m.UniqueID, m.Text, m.ContextType, m.MsgRef)
+			c.forgetOutbox(m.ID)
+		},
+		drop: func() {
+			c.log.Warnf("Dropped outbound message to %s: send queue full", m.Phone)
+			c.storeResponse(context.Background(), m.Phone, m.UniqueID, []byte(m.Text), time.Now(), "dropped: send queue full", m.ContextType, m.MsgRef)
+			c.forgetOutbox(m.ID)
+		},
 	})
+}
+
+// outboxTurn returns the turn a queued message answers.
+func (c *Client) outboxTurn(m store.OutboxMessage) *replyTurn {
+	turn := &replyTurn{conv: agent.Conversation{UserID: m.Phone, GroupID: m.GroupID}}
+	if m.BusinessAgent && c.businessADK != nil {
+		turn.agent = c.businessADK
+	}
+	return turn
+}
+
+// forgetOutbox removes a handled message from the outbox.
+func (c *Client) forgetOutbox(id string) {
+	if id == "" {
+		return
+	}
+	if err := c.outbox.done(context.Background(), id); err != nil {
+		c.log.Warnf("Failed to remove queued message %s from the outbox: %v", id, err)
+	}
+}
+
+// resumeOutbox requeues messages left in the outbox by an earlier run.
+func (c *Client) resumeOutbox(ctx context.Context) {
+	r, err := c.outbox.resume(ctx)
+	if err != nil {
+		c.log.Errorf("Failed to resume the outbound queue: %v", err)
+		return
+	}
+	for _, m := range r.stale {
+		c.log.Warnf("Not resending message to %s queued at %s: older than resume_max_age", m.Phone, m.QueuedAt.Format(time.RFC3339))
+		c.storeResponse(ctx, m.Phone, m.UniqueID, []byte(m.Text), time.Now(), "dropped: stale after restart", m.ContextType, m.MsgRef)
+	}
+	for _, m := range r.attempted {
+		c.log.Warnf("Not resending message to %s: its send was interrupted and may have been delivered", m.Phone)
+	}
+	for _, m := range r.resend {
+		chat, err := types.ParseJID(m.Chat)
+		if err != nil {
+			c.log.Errorf("Dropping queued message %s with invalid chat %q: %v", m.ID, m.Chat, err)
+			c.forgetOutbox(m.ID)
+			continue
+		}
+		c.queueText(ctx, chat, m)
+	}
+	if len(r.resend) > 0 {
+		c.log.Infof("Resumed %d queued outbound messages from before the restart", len(r.resend))
+	}
+}
+
+func (c *Client) sendTextNow(ctx context.Context, turn *replyTurn, chat types.JID, userID string, uniqueID string, text string, contextType, msgRef string) {
+	resp, err := c.wac.SendMessage(ctx, chat, c.textMessage(ctx, text, msgRef))
 	if err != nil {
 		c.log.Errorf("Failed to send text message: %v", err)
 		c.storeResponse(ctx, userID, uniqueID, []byte(text), time.Now(), err.Error(), contextType, msgRef)
 	} else {
-		c.log.Infof("Sent text response to %s: %s", userID, truncate(text, 50))
+		c.log.Infof("Sent text response to %s: %s", userID, truncate(auth.RedactURLTokens(text), 50))
+		c.countUsage(userID, usageOutbound)
 		c.storeResponse(ctx, userID, uniqueID, []byte(text), resp.Timestamp, "", contextType, msgRef)
 	}
+	c.confirmDelivery(ctx, turn, userID, contextType, resp, err)
 }
 
-func (c *Client) sendMediaPart(ctx context.Context, chat types.JID, userID string, uniqueID string, media *agent.InlineData, caption string, contextType, msgRef string) error {
+func (c *Client) sendMediaPart(ctx context.Context, turn *replyTurn, chat types.JID, userID string, uniqueID string, media *agent.InlineData, caption string, contextType, msgRef string) error {
 	data, err := base64.StdEncoding.DecodeString(media.Data)
 	if err != nil {
 		return fmt.Errorf("failed to decode base64 media: %w", err)
@@ -677,6 +1512,7 @@ func (c *Client) sendMediaPart(ctx context.Context, chat types.JID, userID strin
 		return fmt.Errorf("failed to upload media: %w", err)
 	}
 
+	quote := c.quoteContext(msgRef)
 	var msg waE2E.Message
 	switch waMediaType {
 	case whatsmeow.MediaImage:
@@ -688,6 +1524,7 @@ func (c *Client) sendMediaPart(ctx context.Context, chat types.JID, userID strin
 			FileEncSHA256: resp.FileEncSHA256,
 			FileSHA256:    resp.FileSHA256,
 			FileLength:    proto.Uint64(uint64(len(data))),
+			ContextInfo:   quote,
 			Caption:       proto.String(caption),
 		}
 	case whatsmeow.MediaAudio:
@@ -699,6 +1536,12 @@ func (c *Client) sendMediaPart(ctx context.Context, chat types.JID, userID strin
 			FileEncSHA256: resp.FileEncSHA256,
 			FileSHA256:    resp.FileSHA256,
 			FileLength:    proto.Uint64(uint64(len(data))),
+			ContextInfo:   quote,
+		}
+		// Synthesized speech goes out as a voice note rather than a file.
+		if mimeType == tts.MimeType {
+			msg.AudioMessage.PTT = proto.Bool(true)
+			msg.AudioMessage.Seconds = proto.Uint32(oggDuration(data))
 		}
 	case whatsmeow.MediaVideo:
 		msg.VideoMessage = &waE2E.VideoMessage{
@@ -709,6 +1552,7 @@ func (c *Client) sendMediaPart(ctx context.Context, chat types.JID, userID strin
 			FileEncSHA256: resp.FileEncSHA256,
 			FileSHA256:    resp.FileSHA256,
 			FileLength:    proto.Uint64(uint64(len(data))),
+			ContextInfo:   quote,
 			Caption:       proto.String(caption),
 		}
 	case whatsmeow.MediaDocument:
@@ -720,22 +1564,44 @@ func (c *Client) sendMediaPart(ctx context.Context, chat types.JID, userID strin
 			FileEncSHA256: resp.FileEncSHA256,
 			FileSHA256:    resp.FileSHA256,
 			FileLength:    proto.Uint64(uint64(len(data))),
+			ContextInfo:   quote,
 			Caption:       proto.String(caption),
 		}
 	}
 
 	waResp, err := c.wac.SendMessage(ctx, chat, &msg)
+	c.confirmDelivery(ctx, turn, userID, contextType, waResp, err)
 	if err != nil {
 		return fmt.Errorf("failed to send media message: %w", err)
 	}
 
 	c.log.Infof("Sent %s response to %s", waMediaType, userID)
+	c.countUsage(userID, usageOutbound)
 	c.storeResponse(ctx, userID, uniqueID, []byte(fmt.Sprintf("[%s]", waMediaType)), waResp.Timestamp, "", contextType, msgRef)
 
 	return nil
 }
 
+// confirmDelivery reports a reply to ADK when delivery confirmation is
+// enabled and sendErr is nil. Without a turn the reply is confirmed in
+// userID's own conversation with the default agent client. Confirmation failures are logged only.
+func (c *Client) confirmDelivery(ctx context.Context, turn *replyTurn, userID, contextType string, resp whatsmeow.SendResponse, sendErr error) {
+	conv := agent.Conversation{UserID: userID}
+	var via deliveryConfirmer
+	if turn != nil {
+		conv, via = turn.conv, turn.agent
+	}
+	if err := c.deliveries.report(ctx, via, conv, contextType, resp.ID, resp.Timestamp, sendErr); err != nil {
+		c.log.Warnf("Failed to confirm delivery of %s to ADK: %v", resp.ID, err)
+	}
+}
+
 func (c *Client) isUserAllowed(jid types.JID) bool {
+	return c.allowReasonFor(jid) != allowDenied
+}
+
+// allowReasonFor is isUserAllowed reporting which allowlist rule matched.
+func (c *Client) allowReasonFor(jid types.JID) allowReason {
 	// If it's a LID, try resolving it to PN first for better whitelist/country checking
 	if jid.Server == types.HiddenUserServer {
 		ctx := context.Background()
@@ -745,28 +1611,30 @@ func (c *Client) isUserAllowed(jid types.JID) bool {
 		}
 	}
 
-	// 1. If whitelisting is active, check it
-	if len(c.cfg.WhatsApp.WhitelistedUsers) > 0 {
-		if c.cfg.IsUserWhitelisted(jid.User) || c.cfg.IsUserWhitelisted(jid.String()) {
-			return true
-		}
-	} else {
-		// 2. If NO whitelisting is active, we allow everyone except if we want to enforce 91 prefix
-		return true
-	}
-
-	// 3. Fallback to country check if whitelist exists but user is not in it
-	if jid.Server == types.DefaultUserServer && strings.HasPrefix(jid.User, indiaCountryCode) {
-		return true
+	reason := allowedBy(c.cfg, jid)
+	// A country-code match only differs from a whitelist match in whether
+	// the onboarding nudge is sent, so skip the lookup without a nudger.
+	if (reason == allowDenied || (reason == allowCountry && c.nudger != nil)) && c.storeWhitelisted(jid) {
+		reason = allowWhitelist
 	}
-
-	// 4. Handle LID (Linked ID) if still not resolved
-	if jid.Server == types.HiddenUserServer {
+	if reason == allowUnresolvedLID {
 		c.log.Infof("LID detected and unresolved: %s. Allowing LID for whitelisted mode.", jid.String())
-		return true
 	}
+	return reason
+}
 
-	return false
+// storeWhitelisted reports whether jid's number was whitelisted at runtime
+// in the store. A failed lookup is logged and counts as not whitelisted.
+func (c *Client) storeWhitelisted(jid types.JID) bool {
+	if c.store == nil || jid.Server != types.DefaultUserServer {
+		return false
+	}
+	ok, err := c.store.IsWhitelisted(context.Background(), jid.User)
+	if err != nil {
+		c.log.Warnf("Failed to check runtime whitelist for %s: %v", jid, err)
+		return false
+	}
+	return ok
 }
 
 func extractText(msg *events.Message) string {
@@ -793,7 +1661,7 @@ func extractText(msg *events.Message) string {
 		return *msg.Message.DocumentMessage.Caption
 	}
 
-	return ""
+	return selectionText(msg.Message)
 }
 
 func truncate(s string, maxLen int) string {
@@ -0,0 +1,92 @@
+package whatsapp
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCooldownEntries bounds the per-user cooldown map before expired
+// entries are pruned.
+const maxCooldownEntries = 4096
+
+type cooldownVerdict int
+
+const (
+	cooldownAllow cooldownVerdict = iota
+	// cooldownNotice is returned once per cooldown, when the "still having
+	// trouble" notice should be sent.
+	cooldownNotice
+	cooldownSuppress
+)
+
+// errorCooldown stops a user's rapid retries from hammering a failing
+// agent. After an error reply, messages from that user are not processed
+// until window has passed; a successful agent turn clears the cooldown.
+type errorCooldown struct {
+	window time.Duration
+	notice bool
+	now    func() time.Time
+
+	mu    sync.Mutex
+	users map[string]*cooldownState
+}
+
+type cooldownState struct {
+	until    time.Time
+	notified bool
+}
+
+func newErrorCooldown(window time.Duration, notice bool) *errorCooldown {
+	return &errorCooldown{
+		window: window,
+		notice: notice,
+		now:    time.Now,
+		users:  make(map[string]*cooldownState),
+	}
+}
+
+// failed starts (or restarts) the cooldown after an error reply to user.
+func (c *errorCooldown) failed(user string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, ok := c.users[user]; !ok && len(c.users) >= maxCooldownEntries {
+		c.prune(now)
+	}
+	c.users[user] = &cooldownState{until: now.Add(c.window)}
+}
+
+// succeeded clears any cooldown for user.
+func (c *errorCooldown) succeeded(user string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.users, user)
+}
+
+func (c *errorCooldown) check(user string) cooldownVerdict {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st, ok := c.users[user]
+	if !ok {
+		return cooldownAllow
+	}
+	if !c.now().Before(st.until) {
+		delete(c.users, user)
+		return cooldownAllow
+	}
+	if c.notice && !st.notified {
+		st.notified = true
+		return cooldownNotice
+	}
+	return cooldownSuppress
+}
+
+func (c *errorCooldown) prune(now time.Time) {
+	for u, st := range c.users {
+		if !now.Before(st.until) {
+			delete(c.users, u)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package whatsapp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestErrorCooldownSuppressesRetries(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := newErrorCooldown(30*time.Second, true)
+	c.now = func() time.Time { return now }
+	const user = "919876543210"
+
+	if got := c.check(user); got != cooldownAllow {
+		t.Fatalf("before any error: verdict = %v, want allow", got)
+	}
+	c.failed(user)
+
+	want := []cooldownVerdict{cooldownNotice, cooldownSuppress, cooldownSuppress}
+	for i, w := range want {
+		now = now.Add(time.Second)
+		if got := c.check(user); got != w {
+			t.Errorf("retry %d: verdict = %v, want %v", i, got, w)
+		}
+	}
+	if got := c.check("910000000000"); got != cooldownAllow {
+		t.Errorf("other user: verdict = %v, want allow", got)
+	}
+
+	now = now.Add(30 * time.Second)
+	if got := c.check(user); got != cooldownAllow {
+		t.Errorf("after window: verdict = %v, want allow", got)
+	}
+}
+
+func TestErrorCooldownResetsOnSuccess(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := newErrorCooldown(time.Minute, true)
+	c.now = func() time.Time { return now }
+	const user = "919876543210"
+
+	c.failed(user)
+	if got := c.check(user); got != cooldownNotice {
+		t.Fatalf("verdict = %v, want notice", got)
+	}
+	c.succeeded(user)
+	if got := c.check(user); got != cooldownAllow {
+		t.Errorf("after success: verdict = %v, want allow", got)
+	}
+
+	// A new failure starts a fresh cooldown with its own notice.
+	c.failed(user)
+	if got := c.check(user); got != cooldownNotice {
+		t.Errorf("after new failure: verdict = %v, want notice", got)
+	}
+}
+
+func TestErrorCooldownWithoutNotice(t *testing.T) {
+	c := newErrorCooldown(time.Minute, false)
+	c.failed("u")
+	if got := c.check("u"); got != cooldownSuppress {
+		t.Errorf("verdict = %v, want suppress", got)
+	}
+}
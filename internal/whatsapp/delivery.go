@@ -0,0 +1,44 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+// deliveryConfirmer reports delivered replies back to ADK; *agent.Client
+// implements it.
+type deliveryConfirmer interface {
+	ConfirmDelivery(ctx context.Context, conv agent.Conversation, d agent.Delivery) error
+}
+
+// deliveryReporter confirms agent replies once WhatsApp has accepted them.
+// A nil reporter confirms nothing.
+type deliveryReporter struct {
+	confirmer deliveryConfirmer
+	timeout   time.Duration
+}
+
+// replyTurn is the agent turn a reply answers. The reply's delivery is
+// confirmed in the session that turn ran in, with the agent client that
+// produced it; a nil agent means the reporter's default client.
+type replyTurn struct {
+	conv  agent.Conversation
+	agent deliveryConfirmer
+}
+
+// report confirms one send of a reply in conv, through via when it is
+// non-nil. Failed sends and anything other than agent replies (context
+// type "response") are skipped.
+func (r *deliveryReporter) report(ctx context.Context, via deliveryConfirmer, conv agent.Conversation, contextType, messageID string, ts time.Time, sendErr error) error {
+	if r == nil || sendErr != nil || contextType != "response" {
+		return nil
+	}
+	if via == nil {
+		via = r.confirmer
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return via.ConfirmDelivery(ctx, conv, agent.Delivery{MessageID: messageID, Timestamp: ts})
+}
@@ -0,0 +1,106 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+
+	"github.com/innomon/whatsadk/internal/agent"
+	"github.com/innomon/whatsadk/internal/config"
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+type fakeConfirmer struct {
+	got   []agent.Delivery
+	convs []agent.Conversation
+}
+
+func (f *fakeConfirmer) ConfirmDelivery(ctx context.Context, conv agent.Conversation, d agent.Delivery) error {
+	f.got = append(f.got, d)
+	f.convs = append(f.convs, conv)
+	return nil
+}
+
+func TestDeliveryReporterOnlyConfirmsSuccessfulReplies(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name        string
+		contextType string
+		sendErr     error
+		want        bool
+	}{
+		{"delivered reply", "response", nil, true},
+		{"failed send", "response", errors.New("not connected"), false},
+		{"system message", "system", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeConfirmer{}
+			r := &deliveryReporter{confirmer: fake, timeout: time.Second}
+			if err := r.report(context.Background(), nil, agent.Conversation{UserID: "919876543210"}, tt.contextType, "MSG1", ts, tt.sendErr); err != nil {
+				t.Fatalf("report() error: %v", err)
+			}
+			if got := len(fake.got) == 1; got != tt.want {
+				t.Fatalf("confirmed = %v, want %v", got, tt.want)
+			}
+			if tt.want && (fake.got[0].MessageID != "MSG1" || !fake.got[0].Timestamp.Equal(ts)) {
+				t.Errorf("delivery = %+v", fake.got[0])
+			}
+		})
+	}
+}
+
+func TestNilDeliveryReporter(t *testing.T) {
+	var r *deliveryReporter
+	if err := r.report(context.Background(), nil, agent.Conversation{UserID: "u"}, "response", "MSG1", time.Now(), nil); err != nil {
+		t.Errorf("report() error: %v", err)
+	}
+}
+
+func TestDeliveryConfirmedInTurnConversation(t *testing.T) {
+	fake := &fakeConfirmer{}
+	c := &Client{deliveries: &deliveryReporter{confirmer: fake, timeout: time.Second}}
+	group := agent.Conversation{UserID: "919876543210", GroupID: "120363012345678901@g.us"}
+
+	c.confirmDelivery(context.Background(), &replyTurn{conv: group}, "919876543210", "response", whatsmeow.SendResponse{ID: "MSG1"}, nil)
+	c.confirmDelivery(context.Background(), nil, "919876543210", "response", whatsmeow.SendResponse{ID: "MSG2"}, nil)
+	if len(fake.convs) != 2 {
+		t.Fatalf("confirmed %d deliveries, want 2", len(fake.convs))
+	}
+	if fake.convs[0] != group {
+		t.Errorf("group reply confirmed in %+v, want %+v", fake.convs[0], group)
+	}
+	if want := (agent.Conversation{UserID: "919876543210"}); fake.convs[1] != want {
+		t.Errorf("reply without a turn confirmed in %+v, want %+v", fake.convs[1], want)
+	}
+}
+
+func TestDeliveryConfirmedByTurnAgent(t *testing.T) {
+	def, biz := &fakeConfirmer{}, &fakeConfirmer{}
+	c := &Client{deliveries: &deliveryReporter{confirmer: def, timeout: time.Second}}
+	conv := agent.Conversation{UserID: "919876543210"}
+
+	c.confirmDelivery(context.Background(), &replyTurn{conv: conv, agent: biz}, "919876543210", "response", whatsmeow.SendResponse{ID: "MSG1"}, nil)
+	c.confirmDelivery(context.Background(), &replyTurn{conv: conv}, "919876543210", "response", whatsmeow.SendResponse{ID: "MSG2"}, nil)
+	if len(biz.got) != 1 || biz.got[0].MessageID != "MSG1" {
+		t.Errorf("turn agent confirmed %+v, want MSG1", biz.got)
+	}
+	if len(def.got) != 1 || def.got[0].MessageID != "MSG2" {
+		t.Errorf("default client confirmed %+v, want MSG2", def.got)
+	}
+}
+
+func TestOutboxTurnKeepsBusinessAgent(t *testing.T) {
+	adk := agent.NewClient(&config.ADKConfig{AppName: "main"}, nil)
+	c := &Client{adkClient: adk, businessADK: adk.ForApp("biz")}
+
+	if turn := c.outboxTurn(store.OutboxMessage{Phone: "919876543210", BusinessAgent: true}); turn.agent != deliveryConfirmer(c.businessADK) {
+		t.Errorf("business reply confirmed by %v, want the business client", turn.agent)
+	}
+	if turn := c.outboxTurn(store.OutboxMessage{Phone: "919876543210"}); turn.agent != nil {
+		t.Errorf("reply confirmed by %v, want the default client", turn.agent)
+	}
+}
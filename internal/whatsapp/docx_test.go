@@ -0,0 +1,55 @@
+package whatsapp
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func buildDOCX(t *testing.T, documentXML string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("word/document.xml")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := w.Write([]byte(documentXML)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDocxText(t *testing.T) {
+	data := buildDOCX(t, `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Invoice</w:t></w:r><w:r><w:t xml:space="preserve"> #42</w:t></w:r></w:p>
+<w:p><w:r><w:t>Total</w:t><w:tab/><w:t>₹1,200</w:t></w:r></w:p>
+</w:body></w:document>`)
+
+	got, err := docxText(data)
+	if err != nil {
+		t.Fatalf("docxText() error: %v", err)
+	}
+	if want := "Invoice #42\nTotal\t₹1,200"; got != want {
+		t.Errorf("docxText() = %q, want %q", got, want)
+	}
+}
+
+func TestDocxTextRejectsNonDOCX(t *testing.T) {
+	if _, err := docxText([]byte("not a zip")); err == nil {
+		t.Error("expected error for non-zip data")
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := docxText(buf.Bytes()); err == nil {
+		t.Error("expected error for zip without document.xml")
+	}
+}
@@ -0,0 +1,44 @@
+package whatsapp
+
+import (
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// editedText returns the ID of the message an edit protocol message
+// changes and its new text or caption. ok is false for anything else.
+func editedText(m *waE2E.Message) (targetID, text string, ok bool) {
+	pm := m.GetProtocolMessage()
+	if pm == nil || pm.Type == nil || pm.GetType() != waE2E.ProtocolMessage_MESSAGE_EDIT {
+		return "", "", false
+	}
+	e := pm.GetEditedMessage()
+	switch {
+	case e.GetConversation() != "":
+		text = e.GetConversation()
+	case e.GetExtendedTextMessage().GetText() != "":
+		text = e.GetExtendedTextMessage().GetText()
+	case e.GetImageMessage().GetCaption() != "":
+		text = e.GetImageMessage().GetCaption()
+	case e.GetVideoMessage().GetCaption() != "":
+		text = e.GetVideoMessage().GetCaption()
+	case e.GetDocumentMessage().GetCaption() != "":
+		text = e.GetDocumentMessage().GetCaption()
+	}
+	return pm.GetKey().GetID(), text, true
+}
+
+// editTurnText is the text sent to the agent for an edit under mode, or ""
+// when the edit is not forwarded.
+func editTurnText(cfg config.EditsConfig, text string) string {
+	switch {
+	case text == "":
+		return ""
+	case cfg.Mode == config.EditModeRerun:
+		return text
+	case cfg.Mode == config.EditModeAppend:
+		return cfg.Prefix + "\n" + text
+	}
+	return ""
+}
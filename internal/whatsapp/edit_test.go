@@ -0,0 +1,58 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestEditedText(t *testing.T) {
+	edit := func(edited *waE2E.Message) *waE2E.Message {
+		return &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{
+			Type:          waE2E.ProtocolMessage_MESSAGE_EDIT.Enum(),
+			Key:           &waCommon.MessageKey{ID: proto.String("ORIG1")},
+			EditedMessage: edited,
+		}}
+	}
+
+	id, text, ok := editedText(edit(&waE2E.Message{Conversation: proto.String("order #42, not #24")}))
+	if !ok || id != "ORIG1" || text != "order #42, not #24" {
+		t.Errorf("text edit = %q, %q, %v", id, text, ok)
+	}
+	_, text, ok = editedText(edit(&waE2E.Message{ImageMessage: &waE2E.ImageMessage{Caption: proto.String("the blue one")}}))
+	if !ok || text != "the blue one" {
+		t.Errorf("caption edit = %q, %v", text, ok)
+	}
+	if _, _, ok := editedText(&waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{
+		Type: waE2E.ProtocolMessage_REVOKE.Enum(),
+		Key:  &waCommon.MessageKey{ID: proto.String("ORIG1")},
+	}}); ok {
+		t.Error("revoke reported as edit")
+	}
+	if _, _, ok := editedText(&waE2E.Message{Conversation: proto.String("hi")}); ok {
+		t.Error("text message reported as edit")
+	}
+}
+
+func TestEditTurnText(t *testing.T) {
+	tests := []struct {
+		mode string
+		text string
+		want string
+	}{
+		{config.EditModeIgnore, "fixed", ""},
+		{config.EditModeRerun, "fixed", "fixed"},
+		{config.EditModeAppend, "fixed", "Corrected:\nfixed"},
+		{config.EditModeAppend, "", ""},
+	}
+	for _, tt := range tests {
+		cfg := config.EditsConfig{Mode: tt.mode, Prefix: "Corrected:"}
+		if got := editTurnText(cfg, tt.text); got != tt.want {
+			t.Errorf("editTurnText(%s, %q) = %q, want %q", tt.mode, tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package whatsapp
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxFetchRedirects bounds the redirects followed by outbound fetches.
+const maxFetchRedirects = 5
+
+// newFetchClient returns an HTTP client for fetching agent-linked content,
+// applying the gateway's outbound TLS restrictions when tlsCfg is set. It
+// connects directly, never through a proxy, and only to public addresses,
+// so agent replies cannot make the gateway read its own network.
+func newFetchClient(timeout time.Duration, tlsCfg *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   refusePrivateDial,
+	}).DialContext
+	if tlsCfg != nil {
+		transport.TLSClientConfig = tlsCfg.Clone()
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// refusePrivateDial is a net.Dialer Control that refuses loopback,
+// link-local, private and unspecified addresses. It runs on the resolved
+// address of every connection, so redirects and DNS rebinding cannot get
+// past it.
+func refusePrivateDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("refusing to fetch from %q: not an IP address", host)
+	}
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate() || ip.IsUnspecified() {
+		return fmt.Errorf("refusing to fetch from non-public address %s", ip)
+	}
+	return nil
+}
+
+// hostAllowlist holds the only hosts a fetcher may contact. An empty list
+// allows every host.
+type hostAllowlist struct {
+	setting string
+	hosts   map[string]bool
+}
+
+// newHostAllowlist builds the allowlist for hosts, named by setting in
+// errors (e.g. "link_previews.allowed_hosts").
+func newHostAllowlist(setting string, hosts []string) hostAllowlist {
+	a := hostAllowlist{setting: setting, hosts: make(map[string]bool, len(hosts))}
+	for _, h := range hosts {
+		a.hosts[strings.ToLower(h)] = true
+	}
+	return a
+}
+
+// check returns an error if u's host is not allowed.
+func (a hostAllowlist) check(u *url.URL) error {
+	if len(a.hosts) > 0 && !a.hosts[strings.ToLower(u.Hostname())] {
+		return fmt.Errorf("host %q is not in %s", u.Hostname(), a.setting)
+	}
+	return nil
+}
+
+// guard makes client re-check every redirect target, so that a 3xx from an
+// allowed host cannot lead outside the list.
+func (a hostAllowlist) guard(client *http.Client) {
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxFetchRedirects {
+			return fmt.Errorf("stopped after %d redirects", len(via))
+		}
+		if err := a.check(req.URL); err != nil {
+			return fmt.Errorf("redirect refused: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,32 @@
+package whatsapp
+
+import (
+	"net"
+	"testing"
+)
+
+func TestRefusePrivateDial(t *testing.T) {
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"10.0.0.5", false},
+		{"172.16.3.4", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		err := refusePrivateDial("tcp", net.JoinHostPort(tt.addr, "443"), nil)
+		if (err == nil) != tt.allowed {
+			t.Errorf("refusePrivateDial(%s) = %v, want allowed %v", tt.addr, err, tt.allowed)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package whatsapp
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxFloodEntries bounds the per-user flood map before expired entries are
+// pruned.
+const maxFloodEntries = 4096
+
+type floodVerdict int
+
+const (
+	floodAllow floodVerdict = iota
+	// floodWarn is returned once per flood, when a warning should be sent.
+	floodWarn
+	floodSuppress
+)
+
+// floodGuard throttles users who send the same text over and over as
+// distinct messages. Once a normalized text is seen more than maxRepeats
+// times within window, further copies are suppressed until the user sends
+// something different or the window lapses. Exempt texts, such as the reply
+// paging command, are never counted.
+type floodGuard struct {
+	maxRepeats int
+	window     time.Duration
+	warn       bool
+	exempt     map[string]bool
+	now        func() time.Time
+
+	mu    sync.Mutex
+	users map[string]*floodState
+}
+
+type floodState struct {
+	text   string
+	start  time.Time
+	count  int
+	warned bool
+}
+
+func newFloodGuard(maxRepeats int, window time.Duration, warn bool, exempt ...string) *floodGuard {
+	g := &floodGuard{
+		maxRepeats: maxRepeats,
+		window:     window,
+		warn:       warn,
+		exempt:     make(map[string]bool),
+		now:        time.Now,
+		users:      make(map[string]*floodState),
+	}
+	for _, text := range exempt {
+		if norm := normalizeFloodText(text); norm != "" {
+			g.exempt[norm] = true
+		}
+	}
+	return g
+}
+
+// normalizeFloodText folds case and whitespace so trivial variations of
+// the same message count as repeats.
+func normalizeFloodText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+func (g *floodGuard) check(user, text string) floodVerdict {
+	norm := normalizeFloodText(text)
+	if norm == "" || g.exempt[norm] {
+		return floodAllow
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	st, ok := g.users[user]
+	if !ok || st.text != norm || now.Sub(st.start) >= g.window {
+		if !ok && len(g.users) >= maxFloodEntries {
+			g.prune(now)
+		}
+		g.users[user] = &floodState{text: norm, start: now, count: 1}
+		return floodAllow
+	}
+
+	st.count++
+	if st.count <= g.maxRepeats {
+		return floodAllow
+	}
+	if g.warn && !st.warned {
+		st.warned = true
+		return floodWarn
+	}
+	return floodSuppress
+}
+
+func (g *floodGuard) prune(now time.Time) {
+	for u, st := range g.users {
+		if now.Sub(st.start) >= g.window {
+			delete(g.users, u)
+		}
+	}
+}
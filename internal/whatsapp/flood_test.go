@@ -0,0 +1,84 @@
+package whatsapp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFloodGuard(t *testing.T) {
+	tests := []struct {
+		name  string
+		warn  bool
+		texts []string
+		want  []floodVerdict
+	}{
+		{
+			name:  "flood suppressed after threshold",
+			texts: []string{"hi", "hi", "hi", "hi", "hi"},
+			want:  []floodVerdict{floodAllow, floodAllow, floodAllow, floodSuppress, floodSuppress},
+		},
+		{
+			name:  "warns once then suppresses",
+			warn:  true,
+			texts: []string{"hi", "hi", "hi", "hi", "hi"},
+			want:  []floodVerdict{floodAllow, floodAllow, floodAllow, floodWarn, floodSuppress},
+		},
+		{
+			name:  "normalized variants count as repeats",
+			texts: []string{"Hello  there", "hello there", " HELLO there ", "hello\tthere"},
+			want:  []floodVerdict{floodAllow, floodAllow, floodAllow, floodSuppress},
+		},
+		{
+			name:  "different text resets",
+			texts: []string{"a", "a", "a", "b", "a"},
+			want:  []floodVerdict{floodAllow, floodAllow, floodAllow, floodAllow, floodAllow},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newFloodGuard(3, time.Minute, tt.warn)
+			for i, text := range tt.texts {
+				if got := g.check("919876543210", text); got != tt.want[i] {
+					t.Errorf("message %d (%q): verdict = %v, want %v", i, text, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFloodGuardWindowAndUsers(t *testing.T) {
+	now := time.Unix(0, 0)
+	g := newFloodGuard(1, time.Minute, false)
+	g.now = func() time.Time { return now }
+
+	if got := g.check("alice", "spam"); got != floodAllow {
+		t.Fatalf("first message = %v, want allow", got)
+	}
+	if got := g.check("alice", "spam"); got != floodSuppress {
+		t.Fatalf("repeat within window = %v, want suppress", got)
+	}
+	if got := g.check("bob", "spam"); got != floodAllow {
+		t.Errorf("other user = %v, want allow", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got := g.check("alice", "spam"); got != floodAllow {
+		t.Errorf("after window = %v, want allow", got)
+	}
+}
+
+func TestFloodGuardExemptsPagingCommand(t *testing.T) {
+	g := newFloodGuard(1, time.Minute, true, "more")
+	for i, text := range []string{"more", "MORE", " more ", "more"} {
+		if got := g.check("alice", text); got != floodAllow {
+			t.Errorf("message %d (%q): verdict = %v, want allow", i, text, got)
+		}
+	}
+	if got := g.check("alice", "spam"); got != floodAllow {
+		t.Fatalf("first spam = %v, want allow", got)
+	}
+	if got := g.check("alice", "spam"); got != floodWarn {
+		t.Errorf("repeated spam = %v, want warn", got)
+	}
+}
@@ -0,0 +1,142 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/agent"
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// formStore persists forms in progress, one per user.
+type formStore interface {
+	PutForm(ctx context.Context, phone string, f store.FormState) error
+	GetForm(ctx context.Context, phone string) (*store.FormState, error)
+	DeleteForm(ctx context.Context, phone string) error
+}
+
+// formSpec is the JSON an agent sends, base64-encoded, in an
+// agent.MimeTypeForm part to start collecting fields.
+type formSpec struct {
+	ID     string            `json:"id"`
+	Fields []store.FormField `json:"fields"`
+}
+
+// formResult is forwarded to the agent as text once every field is answered.
+type formResult struct {
+	FormID string            `json:"form_id"`
+	Values map[string]string `json:"values"`
+}
+
+// splitFormSpec removes the first form part from parts and decodes it.
+func splitFormSpec(parts []agent.Part) (*formSpec, []agent.Part, error) {
+	for i, part := range parts {
+		if part.InlineData == nil || part.InlineData.MimeType != agent.MimeTypeForm {
+			continue
+		}
+		rest := append(append([]agent.Part{}, parts[:i]...), parts[i+1:]...)
+		raw, err := base64.StdEncoding.DecodeString(part.InlineData.Data)
+		if err != nil {
+			return nil, rest, fmt.Errorf("decode form: %w", err)
+		}
+		var spec formSpec
+		if err := json.Unmarshal(raw, &spec); err != nil {
+			return nil, rest, fmt.Errorf("decode form: %w", err)
+		}
+		if len(spec.Fields) == 0 {
+			return nil, rest, fmt.Errorf("form %q declares no fields", spec.ID)
+		}
+		for _, f := range spec.Fields {
+			if f.Name == "" || f.Prompt == "" {
+				return nil, rest, fmt.Errorf("form %q has a field without name or prompt", spec.ID)
+			}
+		}
+		return &spec, rest, nil
+	}
+	return nil, parts, nil
+}
+
+// formStep is what the gateway does with a message while a form is open.
+// The zero value means no form is open and the message goes on as usual.
+type formStep struct {
+	// reply is sent to the user instead of calling the agent: the next
+	// prompt or a cancellation notice.
+	reply string
+	// forward, when set, is sent to the agent in place of the message.
+	forward string
+}
+
+// formCollector asks an agent's form fields one per turn and hands the
+// answers back to the agent when the form is complete.
+type formCollector struct {
+	store     formStore
+	ttl       time.Duration
+	cancel    string
+	cancelled string
+	now       func() time.Time
+}
+
+func newFormCollector(s formStore, ttl time.Duration, cancelCommand, cancelledMessage string) *formCollector {
+	return &formCollector{store: s, ttl: ttl, cancel: cancelCommand, cancelled: cancelledMessage, now: time.Now}
+}
+
+// start opens spec for phone and returns the first prompt.
+func (f *formCollector) start(ctx context.Context, phone string, spec *formSpec) (string, error) {
+	state := store.FormState{
+		ID:        spec.ID,
+		Fields:    spec.Fields,
+		Values:    make(map[string]string, len(spec.Fields)),
+		ExpiresAt: f.now().Add(f.ttl),
+	}
+	if err := f.store.PutForm(ctx, phone, state); err != nil {
+		return "", err
+	}
+	return spec.Fields[0].Prompt, nil
+}
+
+// answer records text as the answer to phone's current field, if a form is
+// open. An expired form is discarded and the message handled normally.
+func (f *formCollector) answer(ctx context.Context, phone, text string) (formStep, error) {
+	state, err := f.store.GetForm(ctx, phone)
+	if err != nil || state == nil {
+		return formStep{}, err
+	}
+	if state.Values == nil {
+		state.Values = make(map[string]string, len(state.Fields))
+	}
+	if !f.now().Before(state.ExpiresAt) {
+		return formStep{}, f.store.DeleteForm(ctx, phone)
+	}
+	if f.cancel != "" && strings.EqualFold(strings.TrimSpace(text), f.cancel) {
+		if err := f.store.DeleteForm(ctx, phone); err != nil {
+			return formStep{}, err
+		}
+		return formStep{reply: f.cancelled}, nil
+	}
+	if state.Next < 0 || state.Next >= len(state.Fields) {
+		return formStep{}, f.store.DeleteForm(ctx, phone)
+	}
+
+	state.Values[state.Fields[state.Next].Name] = strings.TrimSpace(text)
+	state.Next++
+	state.ExpiresAt = f.now().Add(f.ttl)
+	if state.Next < len(state.Fields) {
+		if err := f.store.PutForm(ctx, phone, *state); err != nil {
+			return formStep{}, err
+		}
+		return formStep{reply: state.Fields[state.Next].Prompt}, nil
+	}
+
+	if err := f.store.DeleteForm(ctx, phone); err != nil {
+		return formStep{}, err
+	}
+	result, err := json.Marshal(formResult{FormID: state.ID, Values: state.Values})
+	if err != nil {
+		return formStep{}, fmt.Errorf("encode form result: %w", err)
+	}
+	return formStep{forward: string(result)}, nil
+}
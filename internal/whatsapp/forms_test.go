@@ -0,0 +1,157 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/agent"
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// memFormStore keeps forms in memory, round-tripping through JSON like the
+// real store.
+type memFormStore struct {
+	forms map[string][]byte
+}
+
+func (m *memFormStore) PutForm(_ context.Context, phone string, f store.FormState) error {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	m.forms[phone] = b
+	return nil
+}
+
+func (m *memFormStore) GetForm(_ context.Context, phone string) (*store.FormState, error) {
+	b, ok := m.forms[phone]
+	if !ok {
+		return nil, nil
+	}
+	var f store.FormState
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (m *memFormStore) DeleteForm(_ context.Context, phone string) error {
+	delete(m.forms, phone)
+	return nil
+}
+
+func formPart(t *testing.T, spec formSpec) agent.Part {
+	t.Helper()
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("marshal spec: %v", err)
+	}
+	return agent.Part{InlineData: &agent.InlineData{MimeType: agent.MimeTypeForm, Data: base64.StdEncoding.EncodeToString(raw)}}
+}
+
+var signupForm = formSpec{ID: "signup", Fields: []store.FormField{
+	{Name: "name", Prompt: "What's your name?"},
+	{Name: "email", Prompt: "And your email?"},
+}}
+
+func TestFormCollectsFieldsAcrossTurns(t *testing.T) {
+	ctx := context.Background()
+	const user = "919876543210"
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	fs := &memFormStore{forms: map[string][]byte{}}
+	f := newFormCollector(fs, 10*time.Minute, "cancel", "Cancelled.")
+	f.now = func() time.Time { return now }
+
+	spec, rest, err := splitFormSpec([]agent.Part{{Text: "Let's get you registered."}, formPart(t, signupForm)})
+	if err != nil || spec == nil {
+		t.Fatalf("splitFormSpec() = %v, %v", spec, err)
+	}
+	if len(rest) != 1 || rest[0].Text != "Let's get you registered." {
+		t.Errorf("remaining parts = %+v, want the text only", rest)
+	}
+
+	prompt, err := f.start(ctx, user, spec)
+	if err != nil || prompt != "What's your name?" {
+		t.Fatalf("start() = %q, %v", prompt, err)
+	}
+
+	now = now.Add(time.Minute)
+	step, err := f.answer(ctx, user, "  Asha ")
+	if err != nil || step.reply != "And your email?" || step.forward != "" {
+		t.Fatalf("first answer = %+v, %v", step, err)
+	}
+
+	now = now.Add(time.Minute)
+	step, err = f.answer(ctx, user, "asha@example.com")
+	if err != nil || step.reply != "" {
+		t.Fatalf("last answer = %+v, %v", step, err)
+	}
+	var got formResult
+	if err := json.Unmarshal([]byte(step.forward), &got); err != nil {
+		t.Fatalf("forward is not a form result: %q", step.forward)
+	}
+	if got.FormID != "signup" || got.Values["name"] != "Asha" || got.Values["email"] != "asha@example.com" {
+		t.Errorf("form result = %+v", got)
+	}
+
+	// The form is closed; the next message is not captured.
+	if step, err := f.answer(ctx, user, "thanks"); err != nil || step != (formStep{}) {
+		t.Errorf("after completion: answer = %+v, %v", step, err)
+	}
+}
+
+func TestFormCancelAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	const user = "919876543210"
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	fs := &memFormStore{forms: map[string][]byte{}}
+	f := newFormCollector(fs, 10*time.Minute, "cancel", "Cancelled.")
+	f.now = func() time.Time { return now }
+
+	if _, err := f.start(ctx, user, &signupForm); err != nil {
+		t.Fatalf("start() error: %v", err)
+	}
+	step, err := f.answer(ctx, user, "CANCEL")
+	if err != nil || step.reply != "Cancelled." || step.forward != "" {
+		t.Fatalf("cancel = %+v, %v", step, err)
+	}
+	if _, ok := fs.forms[user]; ok {
+		t.Error("cancelled form still stored")
+	}
+
+	if _, err := f.start(ctx, user, &signupForm); err != nil {
+		t.Fatalf("start() error: %v", err)
+	}
+	now = now.Add(10 * time.Minute)
+	step, err = f.answer(ctx, user, "Asha")
+	if err != nil || step != (formStep{}) {
+		t.Fatalf("expired form: answer = %+v, %v; want message passed through", step, err)
+	}
+	if _, ok := fs.forms[user]; ok {
+		t.Error("expired form still stored")
+	}
+}
+
+func TestSplitFormSpecInvalid(t *testing.T) {
+	bad := agent.Part{InlineData: &agent.InlineData{MimeType: agent.MimeTypeForm, Data: "not base64!"}}
+	spec, rest, err := splitFormSpec([]agent.Part{{Text: "hi"}, bad})
+	if err == nil || spec != nil {
+		t.Errorf("splitFormSpec() = %v, %v; want error", spec, err)
+	}
+	if len(rest) != 1 {
+		t.Errorf("invalid form part not removed: %+v", rest)
+	}
+
+	empty := formPart(t, formSpec{ID: "empty"})
+	if _, _, err := splitFormSpec([]agent.Part{empty}); err == nil {
+		t.Error("expected error for a form without fields")
+	}
+
+	plain := []agent.Part{{Text: "hi"}}
+	if spec, rest, err := splitFormSpec(plain); spec != nil || err != nil || len(rest) != 1 {
+		t.Errorf("splitFormSpec(no form) = %v, %+v, %v", spec, rest, err)
+	}
+}
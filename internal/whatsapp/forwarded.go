@@ -0,0 +1,30 @@
+package whatsapp
+
+import (
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// isForwarded reports whether WhatsApp flagged msg as forwarded from
+// another chat.
+func isForwarded(msg *waE2E.Message) bool {
+	if msg == nil {
+		return false
+	}
+	for _, ci := range contextInfos(msg) {
+		if ci.GetIsForwarded() {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedPolicy returns the configured policy for msg:
+// config.ForwardedProcess for messages that were not forwarded.
+func forwardedPolicy(policy string, msg *waE2E.Message) string {
+	if policy == "" || !isForwarded(msg) {
+		return config.ForwardedProcess
+	}
+	return policy
+}
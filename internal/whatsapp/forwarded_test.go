@@ -0,0 +1,47 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestForwardedPolicy(t *testing.T) {
+	forwardedText := &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+		Text:        proto.String("check this out"),
+		ContextInfo: &waE2E.ContextInfo{IsForwarded: proto.Bool(true), ForwardingScore: proto.Uint32(1)},
+	}}
+	forwardedImage := &waE2E.Message{ImageMessage: &waE2E.ImageMessage{
+		ContextInfo: &waE2E.ContextInfo{IsForwarded: proto.Bool(true)},
+	}}
+	reply := &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+		Text:        proto.String("yes"),
+		ContextInfo: &waE2E.ContextInfo{StanzaID: proto.String("ORIG1")},
+	}}
+	plain := &waE2E.Message{Conversation: proto.String("hi")}
+
+	tests := []struct {
+		name   string
+		policy string
+		msg    *waE2E.Message
+		want   string
+	}{
+		{"forwarded text prompts", config.ForwardedPrompt, forwardedText, config.ForwardedPrompt},
+		{"forwarded image ignored", config.ForwardedIgnore, forwardedImage, config.ForwardedIgnore},
+		{"forwarded text processed", config.ForwardedProcess, forwardedText, config.ForwardedProcess},
+		{"reply is not forwarded", config.ForwardedPrompt, reply, config.ForwardedProcess},
+		{"plain text is not forwarded", config.ForwardedIgnore, plain, config.ForwardedProcess},
+		{"nil message", config.ForwardedPrompt, nil, config.ForwardedProcess},
+		{"unset policy", "", forwardedText, config.ForwardedProcess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forwardedPolicy(tt.policy, tt.msg); got != tt.want {
+				t.Errorf("forwardedPolicy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
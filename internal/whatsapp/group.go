@@ -0,0 +1,128 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// groupAllowed reports whether the config lets the agent take part in
+// group chat. Allowed groups may be listed with or without the "@g.us"
+// server.
+func groupAllowed(cfg config.GroupsConfig, chat types.JID) bool {
+	if !cfg.Enabled {
+		return false
+	}
+	for _, g := range cfg.Allowed {
+		if g == chat.String() || g == chat.User {
+			return true
+		}
+	}
+	return false
+}
+
+// addressedTo reports whether msg @-mentions one of bot or replies to a
+// message sent by one of them. bot holds user parts of JIDs, so the bot's
+// phone number and LID both match.
+func addressedTo(msg *waE2E.Message, bot []string) bool {
+	isBot := func(jid string) bool {
+		parsed, err := types.ParseJID(jid)
+		if err != nil {
+			return false
+		}
+		for _, b := range bot {
+			if b != "" && parsed.User == b {
+				return true
+			}
+		}
+		return false
+	}
+	for _, ci := range contextInfos(msg) {
+		for _, m := range ci.GetMentionedJID() {
+			if isBot(m) {
+				return true
+			}
+		}
+		if ci.GetQuotedMessage() != nil && isBot(ci.GetParticipant()) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupTurnText labels text with its sender, since everyone in a group
+// shares one agent session.
+func groupTurnText(pushName, phone, text string) string {
+	if pushName == "" {
+		return fmt.Sprintf("+%s: %s", phone, text)
+	}
+	return fmt.Sprintf("%s (+%s): %s", pushName, phone, text)
+}
+
+// botUsers returns the user parts of the bot's phone number and LID.
+func (c *Client) botUsers() []string {
+	if c.wac == nil || c.wac.Store.ID == nil {
+		return nil
+	}
+	return []string{c.wac.Store.ID.User, c.wac.Store.LID.User}
+}
+
+// groupEnabled reports whether chat is listed in whatsapp.groups.allowed
+// or was allowed at runtime in the store. A failed store lookup is logged
+// and the group treated as not allowed.
+func (c *Client) groupEnabled(ctx context.Context, chat types.JID) bool {
+	if groupAllowed(c.cfg.WhatsApp.Groups, chat) {
+		return true
+	}
+	if !c.cfg.WhatsApp.Groups.Enabled || c.store == nil {
+		return false
+	}
+	ok, err := c.store.IsGroupAllowed(ctx, chat.String())
+	if err != nil {
+		c.log.Warnf("Failed to check allowed groups for %s: %v", chat, err)
+		return false
+	}
+	return ok
+}
+
+// acceptGroupMessage reports whether a group message should be handled:
+// it must come from someone else in an allowed group and, with
+// mention_only, be addressed to the bot. Community announcements are
+// routed as configured and never accepted.
+func (c *Client) acceptGroupMessage(ctx context.Context, msg *events.Message) bool {
+	cfg := c.cfg.WhatsApp.Groups
+	if !c.groupEnabled(ctx, msg.Info.Chat) {
+		c.log.Debugf("Ignoring message %s in group %s: group not allowed", msg.Info.ID, msg.Info.Chat)
+		return false
+	}
+	if msg.Info.IsFromMe {
+		return false
+	}
+	// Nobody can be answered in a community's announcement group.
+	if c.announcements != nil && c.handleAnnouncement(ctx, msg) {
+		return false
+	}
+	if cfg.MentionOnly && !addressedTo(msg.Message, c.botUsers()) {
+		c.log.Debugf("Ignoring message %s in group %s: bot not mentioned", msg.Info.ID, msg.Info.Chat)
+		return false
+	}
+	return true
+}
+
+// stripBotMention removes a leading @-mention of the bot from group text,
+// which WhatsApp renders as "@" followed by the bot's number or LID.
+func (c *Client) stripBotMention(text string) string {
+	var names []string
+	for _, u := range c.botUsers() {
+		if u != "" {
+			names = append(names, u)
+		}
+	}
+	return strings.TrimSpace(stripLeadingMention(text, names))
+}
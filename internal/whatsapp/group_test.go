@@ -0,0 +1,60 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestGroupAllowed(t *testing.T) {
+	chat := types.NewJID("120363012345678901", types.GroupServer)
+	cfg := config.GroupsConfig{Enabled: true, Allowed: []string{"120363012345678901@g.us"}}
+	if !groupAllowed(cfg, chat) {
+		t.Error("listed group not allowed")
+	}
+	if !groupAllowed(config.GroupsConfig{Enabled: true, Allowed: []string{"120363012345678901"}}, chat) {
+		t.Error("group listed without server not allowed")
+	}
+	if groupAllowed(config.GroupsConfig{Enabled: true}, chat) {
+		t.Error("unlisted group allowed")
+	}
+	cfg.Enabled = false
+	if groupAllowed(cfg, chat) {
+		t.Error("group allowed while groups are disabled")
+	}
+}
+
+func TestAddressedTo(t *testing.T) {
+	bot := []string{"919800000000", "123456789"}
+	tests := []struct {
+		name string
+		ci   *waE2E.ContextInfo
+		want bool
+	}{
+		{"mentions bot number", &waE2E.ContextInfo{MentionedJID: []string{"919800000000@s.whatsapp.net"}}, true},
+		{"mentions bot LID", &waE2E.ContextInfo{MentionedJID: []string{"123456789@lid"}}, true},
+		{"mentions someone else", &waE2E.ContextInfo{MentionedJID: []string{"919811111111@s.whatsapp.net"}}, false},
+		{"replies to bot", &waE2E.ContextInfo{Participant: proto.String("919800000000@s.whatsapp.net"), QuotedMessage: &waE2E.Message{Conversation: proto.String("Hi!")}}, true},
+		{"replies to someone else", &waE2E.ContextInfo{Participant: proto.String("919811111111@s.whatsapp.net"), QuotedMessage: &waE2E.Message{Conversation: proto.String("Hi!")}}, false},
+		{"plain message", nil, false},
+	}
+	for _, tt := range tests {
+		msg := &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{Text: proto.String("@919800000000 hours?"), ContextInfo: tt.ci}}
+		if got := addressedTo(msg, bot); got != tt.want {
+			t.Errorf("%s: addressedTo() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGroupTurnText(t *testing.T) {
+	if got := groupTurnText("Asha", "919811111111", "hours?"); got != "Asha (+919811111111): hours?" {
+		t.Errorf("with name = %q", got)
+	}
+	if got := groupTurnText("", "919811111111", "hours?"); got != "+919811111111: hours?" {
+		t.Errorf("without name = %q", got)
+	}
+}
@@ -0,0 +1,108 @@
+package whatsapp
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+// groupMetadataTTL is how long a group's fetched subject and members are
+// reused. Sessions are ensured on every turn, so this bounds group info
+// queries to one per group per TTL.
+const groupMetadataTTL = 10 * time.Minute
+
+// groupMetadata supplies the initial ADK session state of group
+// conversations: the group's subject and participants.
+type groupMetadata struct {
+	fetch func(ctx context.Context, jid types.JID) (*types.GroupInfo, error)
+	// name returns a participant's saved or push name; empty if unknown.
+	name func(ctx context.Context, jid types.JID) string
+	key  string
+	now  func() time.Time
+
+	mu    sync.Mutex
+	cache map[types.JID]cachedGroupState
+}
+
+type cachedGroupState struct {
+	state     map[string]any
+	fetchedAt time.Time
+}
+
+func newGroupMetadata(fetch func(context.Context, types.JID) (*types.GroupInfo, error), name func(context.Context, types.JID) string, key string) *groupMetadata {
+	return &groupMetadata{fetch: fetch, name: name, key: key, now: time.Now, cache: make(map[types.JID]cachedGroupState)}
+}
+
+// state is an agent.SessionStater. Direct chats get no state.
+func (g *groupMetadata) state(ctx context.Context, conv agent.Conversation) (map[string]any, error) {
+	if conv.GroupID == "" {
+		return nil, nil
+	}
+	jid, err := types.ParseJID(conv.GroupID)
+	if err != nil {
+		return nil, fmt.Errorf("parse group JID %q: %w", conv.GroupID, err)
+	}
+
+	g.mu.Lock()
+	cached, ok := g.cache[jid]
+	g.mu.Unlock()
+	if ok && g.now().Sub(cached.fetchedAt) < groupMetadataTTL {
+		return cached.state, nil
+	}
+
+	info, err := g.fetch(ctx, jid)
+	if err != nil {
+		return nil, fmt.Errorf("fetch info of group %s: %w", jid, err)
+	}
+	state := map[string]any{g.key: groupState(info, func(p types.JID) string { return g.name(ctx, p) })}
+	g.mu.Lock()
+	g.cache[jid] = cachedGroupState{state: state, fetchedAt: g.now()}
+	g.mu.Unlock()
+	return state, nil
+}
+
+// groupState describes info for the agent. Participants known only by LID
+// have no phone.
+func groupState(info *types.GroupInfo, name func(types.JID) string) map[string]any {
+	participants := make([]map[string]any, 0, len(info.Participants))
+	for _, p := range info.Participants {
+		entry := map[string]any{"admin": p.IsAdmin || p.IsSuperAdmin}
+		pn := p.PhoneNumber
+		if pn.IsEmpty() && p.JID.Server == types.DefaultUserServer {
+			pn = p.JID
+		}
+		if !pn.IsEmpty() {
+			entry["phone"] = pn.User
+			if n := name(pn); n != "" {
+				entry["name"] = n
+			}
+		}
+		participants = append(participants, entry)
+	}
+	state := map[string]any{
+		"jid":          info.JID.String(),
+		"subject":      info.Name,
+		"participants": participants,
+	}
+	if info.Topic != "" {
+		state["description"] = info.Topic
+	}
+	return state
+}
+
+// contactName returns the best known name of jid from the device's
+// contact store.
+func (c *Client) contactName(ctx context.Context, jid types.JID) string {
+	contact, err := c.wac.Store.Contacts.GetContact(ctx, jid)
+	if err != nil {
+		c.log.Debugf("Failed to look up contact %s: %v", jid, err)
+		return ""
+	}
+	return cmp.Or(contact.FullName, contact.PushName, contact.BusinessName)
+}
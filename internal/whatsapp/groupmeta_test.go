@@ -0,0 +1,81 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+func TestGroupMetadataState(t *testing.T) {
+	group := types.NewJID("120363012345678901", types.GroupServer)
+	info := &types.GroupInfo{
+		JID:        group,
+		GroupName:  types.GroupName{Name: "Book club"},
+		GroupTopic: types.GroupTopic{Topic: "One book a month"},
+		Participants: []types.GroupParticipant{
+			{JID: types.NewJID("919811111111", types.DefaultUserServer), IsSuperAdmin: true},
+			{JID: types.NewJID("123456789", types.HiddenUserServer), PhoneNumber: types.NewJID("919822222222", types.DefaultUserServer)},
+			{JID: types.NewJID("987654321", types.HiddenUserServer)},
+		},
+	}
+	fetches := 0
+	fetch := func(_ context.Context, jid types.JID) (*types.GroupInfo, error) {
+		fetches++
+		if jid != group {
+			return nil, errors.New("unknown group")
+		}
+		return info, nil
+	}
+	names := map[string]string{"919811111111": "Asha"}
+	name := func(_ context.Context, jid types.JID) string { return names[jid.User] }
+
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	g := newGroupMetadata(fetch, name, "group")
+	g.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if st, err := g.state(ctx, agent.Conversation{UserID: "919811111111"}); st != nil || err != nil {
+		t.Fatalf("direct chat state = %v, %v; want none", st, err)
+	}
+
+	st, err := g.state(ctx, agent.Conversation{UserID: "919811111111", GroupID: group.String()})
+	if err != nil {
+		t.Fatalf("state() error: %v", err)
+	}
+	meta, ok := st["group"].(map[string]any)
+	if !ok {
+		t.Fatalf("state = %v, want group key", st)
+	}
+	if meta["subject"] != "Book club" || meta["description"] != "One book a month" || meta["jid"] != group.String() {
+		t.Errorf("group state = %v", meta)
+	}
+	parts, ok := meta["participants"].([]map[string]any)
+	if !ok || len(parts) != 3 {
+		t.Fatalf("participants = %v", meta["participants"])
+	}
+	if parts[0]["phone"] != "919811111111" || parts[0]["name"] != "Asha" || parts[0]["admin"] != true {
+		t.Errorf("first participant = %v", parts[0])
+	}
+	if parts[1]["phone"] != "919822222222" || parts[1]["admin"] != false {
+		t.Errorf("LID participant with phone = %v", parts[1])
+	}
+	if _, ok := parts[2]["phone"]; ok {
+		t.Errorf("LID-only participant has a phone: %v", parts[2])
+	}
+
+	if _, err := g.state(ctx, agent.Conversation{GroupID: group.String()}); err != nil || fetches != 1 {
+		t.Errorf("cached state: err %v, fetches %d; want 1 fetch", err, fetches)
+	}
+	now = now.Add(groupMetadataTTL)
+	if _, err := g.state(ctx, agent.Conversation{GroupID: group.String()}); err != nil || fetches != 2 {
+		t.Errorf("expired state: err %v, fetches %d; want 2 fetches", err, fetches)
+	}
+	if _, err := g.state(ctx, agent.Conversation{GroupID: "120363000000000000@g.us"}); err == nil {
+		t.Error("expected error for unknown group")
+	}
+}
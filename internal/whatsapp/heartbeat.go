@@ -0,0 +1,71 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// beat is one heartbeat sample.
+type beat struct {
+	at        time.Time
+	connected bool
+	messages  int64
+	// queued and dropped describe the send queue; hasQueue is false when
+	// sends are inline.
+	hasQueue bool
+	queued   int
+	dropped  int64
+}
+
+func (b beat) String() string {
+	status := "disconnected"
+	if b.connected {
+		status = "connected"
+	}
+	s := fmt.Sprintf("heartbeat status=%s messages=%d", status, b.messages)
+	if b.hasQueue {
+		s += fmt.Sprintf(" send_queue_depth=%d send_queue_dropped=%d", b.queued, b.dropped)
+	}
+	return s
+}
+
+// runHeartbeat emits a beat every interval while connected reports true,
+// carrying the number of messages counted since the previous beat. Ticks
+// while disconnected are skipped so that watchdogs see the gap.
+func runHeartbeat(ctx context.Context, interval time.Duration, connected func() bool, messages *atomic.Int64, emit func(beat)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if !connected() {
+				continue
+			}
+			emit(beat{at: now, connected: true, messages: messages.Swap(0)})
+		}
+	}
+}
+
+// emitHeartbeat logs b and, when configured, records its timestamp in the
+// heartbeat file.
+func (c *Client) emitHeartbeat(b beat) {
+	if c.sendq != nil {
+		b.hasQueue = true
+		b.queued = c.sendq.depth()
+		b.dropped = c.sendq.dropped.Load()
+	}
+	c.log.Infof("%s", b)
+	path := c.cfg.Gateway.HeartbeatFile
+	if path == "" {
+		return
+	}
+	if err := os.WriteFile(path, []byte(b.at.UTC().Format(time.RFC3339)+"\n"), 0644); err != nil {
+		c.log.Warnf("Failed to write heartbeat file %s: %v", path, err)
+	}
+}
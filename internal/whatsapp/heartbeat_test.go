@@ -0,0 +1,65 @@
+package whatsapp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBeatString(t *testing.T) {
+	tests := []struct {
+		name string
+		b    beat
+		want string
+	}{
+		{name: "connected", b: beat{connected: true, messages: 7}, want: "heartbeat status=connected messages=7"},
+		{name: "disconnected", b: beat{}, want: "heartbeat status=disconnected messages=0"},
+		{name: "with send queue", b: beat{connected: true, messages: 2, hasQueue: true, queued: 5, dropped: 1}, want: "heartbeat status=connected messages=2 send_queue_depth=5 send_queue_dropped=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunHeartbeat(t *testing.T) {
+	var connected atomic.Bool
+	var messages atomic.Int64
+	beats := make(chan beat, 16)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runHeartbeat(ctx, 10*time.Millisecond, connected.Load, &messages, func(b beat) { beats <- b })
+
+	// No beats while disconnected.
+	messages.Add(3)
+	select {
+	case b := <-beats:
+		t.Fatalf("unexpected beat while disconnected: %v", b)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	connected.Store(true)
+	select {
+	case b := <-beats:
+		if !b.connected || b.messages != 3 {
+			t.Errorf("first beat = %+v, want connected with 3 messages", b)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no beat while connected")
+	}
+
+	// The counter resets after each beat.
+	select {
+	case b := <-beats:
+		if b.messages != 0 {
+			t.Errorf("second beat messages = %d, want 0", b.messages)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no second beat")
+	}
+}
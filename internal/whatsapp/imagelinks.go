@@ -0,0 +1,124 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+// markdownImage matches ![alt](https://...) image links in agent text.
+var markdownImage = regexp.MustCompile(`!\[([^\]]*)\]\((https://[^\s)]+)\)`)
+
+// expandImageLinks turns the markdown image links in text into image parts,
+// each preceded by its alt text as the caption, followed by the remaining
+// text. Links that fetch fails on are left in the text.
+func expandImageLinks(text string, fetch func(link string) (*agent.InlineData, error)) ([]agent.Part, []error) {
+	matches := markdownImage.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return []agent.Part{{Text: text}}, nil
+	}
+
+	var parts []agent.Part
+	var errs []error
+	var rest strings.Builder
+	last := 0
+	for _, m := range matches {
+		alt, link := text[m[2]:m[3]], text[m[4]:m[5]]
+		data, err := fetch(link)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("image %s: %w", link, err))
+			continue
+		}
+		rest.WriteString(text[last:m[0]])
+		last = m[1]
+		if alt = strings.TrimSpace(alt); alt != "" {
+			parts = append(parts, agent.Part{Text: alt})
+		}
+		parts = append(parts, agent.Part{InlineData: data})
+	}
+	rest.WriteString(text[last:])
+	if r := strings.TrimSpace(rest.String()); r != "" {
+		parts = append(parts, agent.Part{Text: r})
+	}
+	return parts, errs
+}
+
+// imageFetcher downloads images linked from agent replies.
+type imageFetcher struct {
+	client   *http.Client
+	maxBytes int64
+	// hosts limits every fetch, including redirects.
+	hosts hostAllowlist
+}
+
+func newImageFetcher(client *http.Client, maxBytes int64, hosts []string) *imageFetcher {
+	f := &imageFetcher{client: client, maxBytes: maxBytes, hosts: newHostAllowlist("image_links.allowed_hosts", hosts)}
+	f.hosts.guard(client)
+	return f
+}
+
+func (f *imageFetcher) fetch(ctx context.Context, link string) (*agent.InlineData, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("only https links are fetched")
+	}
+	if err := f.hosts.check(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	mimeType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mimeType, "image/") {
+		return nil, fmt.Errorf("not an image (Content-Type %q)", resp.Header.Get("Content-Type"))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > f.maxBytes {
+		return nil, fmt.Errorf("image larger than %d bytes", f.maxBytes)
+	}
+	return &agent.InlineData{MimeType: mimeType, Data: base64.StdEncoding.EncodeToString(data)}, nil
+}
+
+// expandReplyImages replaces markdown image links in the text parts of an
+// agent reply with the images themselves.
+func (c *Client) expandReplyImages(ctx context.Context, userID string, parts []agent.Part) []agent.Part {
+	var out []agent.Part
+	for _, p := range parts {
+		if p.Text == "" {
+			out = append(out, p)
+			continue
+		}
+		expanded, errs := expandImageLinks(p.Text, func(link string) (*agent.InlineData, error) {
+			return c.images.fetch(ctx, link)
+		})
+		for _, err := range errs {
+			c.log.Warnf("Keeping image link in reply to %s: %v", userID, err)
+		}
+		out = append(out, expanded...)
+	}
+	return out
+}
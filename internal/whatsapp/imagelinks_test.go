@@ -0,0 +1,132 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+func TestExpandImageLinks(t *testing.T) {
+	fetch := func(link string) (*agent.InlineData, error) {
+		if strings.Contains(link, "broken") {
+			return nil, errors.New("404")
+		}
+		return &agent.InlineData{MimeType: "image/png", Data: link}, nil
+	}
+
+	parts, errs := expandImageLinks("Here is your chart:\n![Sales by month](https://cdn.example/chart.png)\nAnything else?", fetch)
+	if len(errs) != 0 {
+		t.Fatalf("errs = %v", errs)
+	}
+	if len(parts) != 3 || parts[0].Text != "Sales by month" || parts[1].InlineData.Data != "https://cdn.example/chart.png" ||
+		parts[2].Text != "Here is your chart:\n\nAnything else?" {
+		t.Errorf("parts = %+v", parts)
+	}
+
+	parts, errs = expandImageLinks("![](https://cdn.example/qr.png) and ![x](https://cdn.example/broken.png)", fetch)
+	if len(errs) != 1 {
+		t.Errorf("errs = %v, want 1", errs)
+	}
+	if len(parts) != 2 || parts[0].InlineData == nil || parts[1].Text != "and ![x](https://cdn.example/broken.png)" {
+		t.Errorf("parts = %+v", parts)
+	}
+
+	parts, errs = expandImageLinks("no images, just [a link](https://example.com) and ![plain](http://insecure/x.png)", fetch)
+	if len(errs) != 0 || len(parts) != 1 || parts[0].InlineData != nil {
+		t.Errorf("unchanged text = %+v, %v", parts, errs)
+	}
+}
+
+func TestImageFetcher(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chart.png":
+			w.Header().Set("Content-Type", "image/png")
+			if _, err := w.Write([]byte("\x89PNG....")); err != nil {
+				t.Errorf("write: %v", err)
+			}
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+		case "/big.png":
+			w.Header().Set("Content-Type", "image/png")
+			if _, err := w.Write(make([]byte, 100)); err != nil {
+				t.Errorf("write: %v", err)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	f := newImageFetcher(srv.Client(), 64, nil)
+	data, err := f.fetch(ctx, srv.URL+"/chart.png")
+	if err != nil || data.MimeType != "image/png" {
+		t.Errorf("fetch() = %+v, %v", data, err)
+	}
+	for _, path := range []string{"/page", "/big.png", "/missing.png"} {
+		if _, err := f.fetch(ctx, srv.URL+path); err == nil {
+			t.Errorf("fetch(%s) succeeded", path)
+		}
+	}
+
+	restricted := newImageFetcher(srv.Client(), 64, []string{"cdn.example"})
+	if _, err := restricted.fetch(ctx, srv.URL+"/chart.png"); err == nil {
+		t.Error("fetch from host outside allowed_hosts succeeded")
+	}
+}
+
+func TestImageFetcherRefusesRedirectOffAllowedHosts(t *testing.T) {
+	var internalHits int
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "cdn.example.com" {
+			internalHits++
+		}
+		switch r.URL.Path {
+		case "/chart.png":
+			http.Redirect(w, r, "https://internal.example.com/secret.png", http.StatusFound)
+		case "/secret.png":
+			w.Header().Set("Content-Type", "image/png")
+			if _, err := w.Write([]byte("\x89PNG....")); err != nil {
+				t.Errorf("write: %v", err)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	f := newImageFetcher(hostRoutedClient(srv), 64, []string{"cdn.example.com"})
+	if _, err := f.fetch(context.Background(), "https://cdn.example.com/chart.png"); err == nil || !strings.Contains(err.Error(), "redirect refused") {
+		t.Errorf("fetch() through redirect error = %v, want redirect refused", err)
+	}
+	if internalHits != 0 {
+		t.Errorf("%d requests reached a host outside allowed_hosts", internalHits)
+	}
+}
+
+func TestImageFetcherRefusesLocalAddresses(t *testing.T) {
+	var hits int
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "image/png")
+		if _, err := w.Write([]byte("\x89PNG....")); err != nil {
+			t.Errorf("write: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	// The client trusts the test server, so only the dial guard refuses it.
+	trusted := srv.Client().Transport.(*http.Transport).TLSClientConfig
+	f := newImageFetcher(newFetchClient(time.Second, trusted), 64, nil)
+	if _, err := f.fetch(context.Background(), srv.URL+"/chart.png"); err == nil || !strings.Contains(err.Error(), "non-public") {
+		t.Errorf("fetch from a loopback address = %v, want refused", err)
+	}
+	if hits != 0 {
+		t.Errorf("loopback server got %d requests", hits)
+	}
+}
@@ -0,0 +1,60 @@
+package whatsapp
+
+import (
+	"cmp"
+	"strings"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"github.com/innomon/whatsadk/internal/auth"
+)
+
+// interactivePayloads returns the values a user selected in a button, list
+// or template reply: ids before display texts, since an app that embeds a
+// token usually puts it in the id.
+func interactivePayloads(m *waE2E.Message) []string {
+	if m == nil {
+		return nil
+	}
+	var payloads []string
+	if r := m.GetButtonsResponseMessage(); r != nil {
+		payloads = append(payloads, r.GetSelectedButtonID(), r.GetSelectedDisplayText())
+	}
+	if r := m.GetListResponseMessage(); r != nil {
+		payloads = append(payloads, r.GetSingleSelectReply().GetSelectedRowID(), r.GetTitle())
+	}
+	if r := m.GetTemplateButtonReplyMessage(); r != nil {
+		payloads = append(payloads, r.GetSelectedID(), r.GetSelectedDisplayText())
+	}
+	if r := m.GetInteractiveResponseMessage(); r != nil {
+		payloads = append(payloads, r.GetBody().GetText())
+	}
+	return payloads
+}
+
+// selectionText is the text a user's tap on one of the gateway's buttons
+// or list rows stands for: its display text, or its id if that is missing.
+func selectionText(m *waE2E.Message) string {
+	if r := m.GetListResponseMessage(); r != nil {
+		return cmp.Or(r.GetTitle(), r.GetSingleSelectReply().GetSelectedRowID())
+	}
+	if r := m.GetButtonsResponseMessage(); r != nil {
+		return cmp.Or(r.GetSelectedDisplayText(), r.GetSelectedButtonID())
+	}
+	if r := m.GetTemplateButtonReplyMessage(); r != nil {
+		return cmp.Or(r.GetSelectedDisplayText(), r.GetSelectedID())
+	}
+	return ""
+}
+
+// interactiveToken returns the first selected payload that is a
+// verification token, or "".
+func interactiveToken(m *waE2E.Message) string {
+	for _, p := range interactivePayloads(m) {
+		p = strings.TrimSpace(p)
+		if p != "" && auth.IsVerificationToken(p) != nil {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestInteractiveReplyReachesVerifier(t *testing.T) {
+	token := signDocumentTestToken(t)
+
+	tests := []struct {
+		name string
+		msg  *waE2E.Message
+		want string
+	}{
+		{
+			name: "button id",
+			msg: &waE2E.Message{ButtonsResponseMessage: &waE2E.ButtonsResponseMessage{
+				SelectedButtonID: proto.String(token),
+				Response:         &waE2E.ButtonsResponseMessage_SelectedDisplayText{SelectedDisplayText: "Verify"},
+			}},
+			want: token,
+		},
+		{
+			name: "button text",
+			msg: &waE2E.Message{ButtonsResponseMessage: &waE2E.ButtonsResponseMessage{
+				SelectedButtonID: proto.String("verify"),
+				Response:         &waE2E.ButtonsResponseMessage_SelectedDisplayText{SelectedDisplayText: " " + token + " "},
+			}},
+			want: token,
+		},
+		{
+			name: "list row id",
+			msg: &waE2E.Message{ListResponseMessage: &waE2E.ListResponseMessage{
+				Title:             proto.String("Verify"),
+				SingleSelectReply: &waE2E.ListResponseMessage_SingleSelectReply{SelectedRowID: proto.String(token)},
+			}},
+			want: token,
+		},
+		{
+			name: "template button id",
+			msg: &waE2E.Message{TemplateButtonReplyMessage: &waE2E.TemplateButtonReplyMessage{
+				SelectedID:          proto.String(token),
+				SelectedDisplayText: proto.String("Verify"),
+			}},
+			want: token,
+		},
+		{
+			name: "button without token",
+			msg: &waE2E.Message{ButtonsResponseMessage: &waE2E.ButtonsResponseMessage{
+				SelectedButtonID: proto.String("yes"),
+				Response:         &waE2E.ButtonsResponseMessage_SelectedDisplayText{SelectedDisplayText: "Yes"},
+			}},
+		},
+		{
+			name: "plain text",
+			msg:  &waE2E.Message{Conversation: proto.String(token)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &fakeVerifier{}
+			text := interactiveToken(tt.msg)
+			response, ok := verifyToken(context.Background(), v, "910987654321", text)
+			if tt.want == "" {
+				if ok || v.token != "" {
+					t.Errorf("verifier called with %q, want no call", v.token)
+				}
+				return
+			}
+			if !ok || response != "verified" || v.token != tt.want {
+				t.Errorf("verifyToken() = %q, %v with token %q, want verified", response, ok, v.token)
+			}
+		})
+	}
+}
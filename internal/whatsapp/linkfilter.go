@@ -0,0 +1,131 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostPattern finds hostnames in text, with or without a scheme.
+var hostPattern = regexp.MustCompile(`(?i)(?:https?://)?((?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,})(?::\d+)?(?:[/?#]\S*)?`)
+
+// maxStrikeEntries bounds the per-user strike map before expired entries
+// are pruned.
+const maxStrikeEntries = 4096
+
+// linkHosts returns the lower-cased hostnames mentioned in text.
+func linkHosts(text string) []string {
+	var hosts []string
+	for _, m := range hostPattern.FindAllStringSubmatch(text, -1) {
+		hosts = append(hosts, strings.ToLower(m[1]))
+	}
+	return hosts
+}
+
+// abuseBlacklister blacklists repeat offenders.
+type abuseBlacklister interface {
+	AddBlacklist(ctx context.Context, phone, reason string) error
+}
+
+// linkFilter blocks messages with links to blocklisted domains, or text
+// matching blocklisted patterns. With a threshold set, each blocked
+// message counts toward auto-blacklisting the sender.
+type linkFilter struct {
+	domains  []string
+	patterns []*regexp.Regexp
+
+	// threshold blocked messages within window blacklist the sender; 0
+	// disables auto-blacklisting.
+	threshold int
+	window    time.Duration
+	reason    string
+	store     abuseBlacklister
+	now       func() time.Time
+
+	mu      sync.Mutex
+	strikes map[string][]time.Time
+}
+
+func newLinkFilter(domains, patterns []string) (*linkFilter, error) {
+	f := &linkFilter{now: time.Now, strikes: make(map[string][]time.Time)}
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "."))
+		if d != "" {
+			f.domains = append(f.domains, d)
+		}
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid link_filter pattern %q: %w", p, err)
+		}
+		f.patterns = append(f.patterns, re)
+	}
+	return f, nil
+}
+
+// match returns what made text blocked, or "" if it is allowed. A domain
+// also blocks its subdomains.
+func (f *linkFilter) match(text string) string {
+	for _, host := range linkHosts(text) {
+		for _, d := range f.domains {
+			if host == d || strings.HasSuffix(host, "."+d) {
+				return host
+			}
+		}
+	}
+	for _, re := range f.patterns {
+		if m := re.FindString(text); m != "" {
+			return m
+		}
+	}
+	return ""
+}
+
+// strike counts a blocked message from user and blacklists them once the
+// threshold is reached. It reports whether user was blacklisted.
+func (f *linkFilter) strike(ctx context.Context, user string) (bool, error) {
+	if f.threshold <= 0 || f.store == nil {
+		return false, nil
+	}
+	f.mu.Lock()
+	now := f.now()
+	cutoff := now.Add(-f.window)
+	if _, ok := f.strikes[user]; !ok && len(f.strikes) >= maxStrikeEntries {
+		f.prune(cutoff)
+	}
+	valid := f.strikes[user][:0]
+	for _, t := range f.strikes[user] {
+		if t.After(cutoff) {
+			valid = append(valid, t)
+		}
+	}
+	valid = append(valid, now)
+	reached := len(valid) >= f.threshold
+	if reached {
+		delete(f.strikes, user)
+	} else {
+		f.strikes[user] = valid
+	}
+	f.mu.Unlock()
+
+	if !reached {
+		return false, nil
+	}
+	if err := f.store.AddBlacklist(ctx, user, f.reason); err != nil {
+		return false, fmt.Errorf("auto-blacklist %s: %w", user, err)
+	}
+	return true, nil
+}
+
+// prune drops users whose strikes all happened before cutoff.
+func (f *linkFilter) prune(cutoff time.Time) {
+	for u, times := range f.strikes {
+		if !times[len(times)-1].After(cutoff) {
+			delete(f.strikes, u)
+		}
+	}
+}
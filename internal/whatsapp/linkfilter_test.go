@@ -0,0 +1,122 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLinkFilterMatch(t *testing.T) {
+	f, err := newLinkFilter([]string{"phish.example", ".bad-bank.com"}, []string{`(?i)free\s+crypto`})
+	if err != nil {
+		t.Fatalf("newLinkFilter() error: %v", err)
+	}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"claim at https://phish.example/login now", "phish.example"},
+		{"see WWW.PHISH.EXAMPLE", "www.phish.example"},
+		{"login.bad-bank.com/verify?x=1", "login.bad-bank.com"},
+		{"get FREE  crypto today", "FREE  crypto"},
+		{"docs at https://example.com/help", ""},
+		{"notphish.example is fine", ""},
+		{"phish.example.org is a different site", ""},
+		{"no links here.", ""},
+	}
+	for _, tt := range tests {
+		if got := f.match(tt.text); got != tt.want {
+			t.Errorf("match(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLinkFilterInvalidPattern(t *testing.T) {
+	if _, err := newLinkFilter(nil, []string{"("}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+type fakeAbuseBlacklist struct {
+	added map[string]string
+}
+
+func (f *fakeAbuseBlacklist) AddBlacklist(_ context.Context, phone, reason string) error {
+	f.added[phone] = reason
+	return nil
+}
+
+func TestLinkFilterAbuseCounter(t *testing.T) {
+	bl := &fakeAbuseBlacklist{added: map[string]string{}}
+	f, err := newLinkFilter([]string{"phish.example"}, nil)
+	if err != nil {
+		t.Fatalf("newLinkFilter() error: %v", err)
+	}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	f.threshold, f.window, f.reason, f.store = 3, time.Hour, "spam links", bl
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if listed, err := f.strike(ctx, "919876543210"); listed || err != nil {
+			t.Fatalf("strike %d = (%v, %v), want not blacklisted", i+1, listed, err)
+		}
+		now = now.Add(10 * time.Minute)
+	}
+	// Strikes are counted per user.
+	if listed, _ := f.strike(ctx, "919811111111"); listed {
+		t.Error("other user blacklisted after one strike")
+	}
+	listed, err := f.strike(ctx, "919876543210")
+	if !listed || err != nil {
+		t.Fatalf("third strike = (%v, %v), want blacklisted", listed, err)
+	}
+	if bl.added["919876543210"] != "spam links" {
+		t.Errorf("blacklist = %v", bl.added)
+	}
+
+	// Old strikes expire.
+	now = now.Add(2 * time.Hour)
+	if listed, _ := f.strike(ctx, "919811111111"); listed {
+		t.Error("expired strike counted")
+	}
+}
+
+func TestLinkFilterNoAutoBlacklist(t *testing.T) {
+	bl := &fakeAbuseBlacklist{added: map[string]string{}}
+	f, err := newLinkFilter([]string{"phish.example"}, nil)
+	if err != nil {
+		t.Fatalf("newLinkFilter() error: %v", err)
+	}
+	f.store = bl
+	for i := 0; i < 5; i++ {
+		if listed, _ := f.strike(context.Background(), "919876543210"); listed {
+			t.Fatal("blacklisted with threshold disabled")
+		}
+	}
+}
+
+func TestLinkFilterPrunesExpiredStrikes(t *testing.T) {
+	f, err := newLinkFilter([]string{"phish.example"}, nil)
+	if err != nil {
+		t.Fatalf("newLinkFilter() error: %v", err)
+	}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	f.threshold, f.window, f.store = 3, time.Hour, &fakeAbuseBlacklist{added: map[string]string{}}
+	ctx := context.Background()
+
+	for i := range maxStrikeEntries {
+		if _, err := f.strike(ctx, fmt.Sprintf("91%010d", i)); err != nil {
+			t.Fatalf("strike: %v", err)
+		}
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := f.strike(ctx, "919876543210"); err != nil {
+		t.Fatalf("strike: %v", err)
+	}
+	if len(f.strikes) != 1 {
+		t.Errorf("%d users tracked after their strikes expired, want 1", len(f.strikes))
+	}
+}
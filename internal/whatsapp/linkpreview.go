@@ -0,0 +1,224 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"image"
+	"image/jpeg"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/nfnt/resize"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+// Limits on what is downloaded to build a link preview.
+const (
+	maxPreviewPage      = 512 << 10
+	maxPreviewImage     = 2 << 20
+	previewThumbnailDim = 200
+)
+
+var (
+	firstLink  = regexp.MustCompile(`https?://[^\s<>"]+`)
+	metaTag    = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	tagAttr    = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*("([^"]*)"|'([^']*)')`)
+	titleTag   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	linkSuffix = ".,;:!?)]}'"
+)
+
+// findLink returns the first http(s) URL in text, without trailing
+// punctuation, or "".
+func findLink(text string) string {
+	return strings.TrimRight(firstLink.FindString(text), linkSuffix)
+}
+
+// pageMeta is what a link preview shows about a page.
+type pageMeta struct {
+	title       string
+	description string
+	image       string
+}
+
+// parsePageMeta reads Open Graph tags from page, falling back to <title>
+// and the description meta tag.
+func parsePageMeta(page []byte) pageMeta {
+	var m pageMeta
+	var description string
+	for _, tag := range metaTag.FindAll(page, -1) {
+		attrs := make(map[string]string)
+		for _, a := range tagAttr.FindAllSubmatch(tag, -1) {
+			attrs[strings.ToLower(string(a[1]))] = string(a[3]) + string(a[4])
+		}
+		key := strings.ToLower(attrs["property"] + attrs["name"])
+		content := strings.TrimSpace(html.UnescapeString(attrs["content"]))
+		switch key {
+		case "og:title":
+			m.title = content
+		case "og:description":
+			m.description = content
+		case "og:image":
+			m.image = content
+		case "description":
+			description = content
+		}
+	}
+	if m.title == "" {
+		if t := titleTag.FindSubmatch(page); t != nil {
+			m.title = strings.TrimSpace(html.UnescapeString(string(t[1])))
+		}
+	}
+	if m.description == "" {
+		m.description = description
+	}
+	return m
+}
+
+// previewThumbnail scales an image down to a small JPEG for JPEGThumbnail.
+func previewThumbnail(data []byte) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	img = resize.Thumbnail(previewThumbnailDim, previewThumbnailDim, img, resize.Lanczos3)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 75}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// linkPreviewer builds link previews for links in outgoing text.
+type linkPreviewer struct {
+	client *http.Client
+	// hosts limits every fetch, including preview images and redirects.
+	hosts hostAllowlist
+}
+
+func newLinkPreviewer(client *http.Client, hosts []string) *linkPreviewer {
+	p := &linkPreviewer{client: client, hosts: newHostAllowlist("link_previews.allowed_hosts", hosts)}
+	p.hosts.guard(client)
+	return p
+}
+
+// preview returns text as an ExtendedTextMessage previewing its first
+// link, or nil if text has no link the previewer may fetch.
+func (p *linkPreviewer) preview(ctx context.Context, text string) (*waE2E.ExtendedTextMessage, error) {
+	link := findLink(text)
+	if link == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, nil
+	}
+	if p.hosts.check(u) != nil {
+		return nil, nil
+	}
+
+	page, mimeType, err := p.get(ctx, link, maxPreviewPage)
+	if err != nil {
+		return nil, err
+	}
+	if mimeType != "text/html" {
+		return nil, nil
+	}
+	meta := parsePageMeta(page)
+	if meta.title == "" {
+		return nil, nil
+	}
+
+	ext := &waE2E.ExtendedTextMessage{
+		Text:        proto.String(text),
+		MatchedText: proto.String(link),
+		Title:       proto.String(meta.title),
+		PreviewType: waE2E.ExtendedTextMessage_NONE.Enum(),
+	}
+	if meta.description != "" {
+		ext.Description = proto.String(meta.description)
+	}
+	if meta.image != "" {
+		if thumb, err := p.thumbnail(ctx, u, meta.image); err == nil {
+			ext.JPEGThumbnail = thumb
+		}
+	}
+	return ext, nil
+}
+
+// thumbnail fetches the page's preview image, resolved against page.
+func (p *linkPreviewer) thumbnail(ctx context.Context, page *url.URL, ref string) ([]byte, error) {
+	imgURL, err := page.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	data, mimeType, err := p.get(ctx, imgURL.String(), maxPreviewImage)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, fmt.Errorf("preview image is %q", mimeType)
+	}
+	return previewThumbnail(data)
+}
+
+// get downloads up to limit bytes of link and returns them with the
+// response's media type. link must be on an allowed host.
+func (p *linkPreviewer) get(ctx context.Context, link string, limit int64) ([]byte, string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := p.hosts.check(u); err != nil {
+		return nil, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	mimeType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid Content-Type: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, mimeType, nil
+}
+
+// textMessage builds the message for an outgoing text: a plain
+// conversation, or an extended text when it quotes a message or carries a
+// link preview.
+func (c *Client) textMessage(ctx context.Context, text, msgRef string) *waE2E.Message {
+	quote := c.quoteContext(msgRef)
+	var ext *waE2E.ExtendedTextMessage
+	if c.previews != nil {
+		var err error
+		if ext, err = c.previews.preview(ctx, text); err != nil {
+			c.log.Warnf("No link preview for reply: %v", err)
+		}
+	}
+	if ext == nil && quote == nil {
+		return &waE2E.Message{Conversation: proto.String(text)}
+	}
+	if ext == nil {
+		ext = &waE2E.ExtendedTextMessage{Text: proto.String(text)}
+	}
+	ext.ContextInfo = quote
+	return &waE2E.Message{ExtendedTextMessage: ext}
+}
@@ -0,0 +1,181 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFindLink(t *testing.T) {
+	tests := map[string]string{
+		"Track it at https://shop.example/orders/42.": "https://shop.example/orders/42",
+		"(see http://example.com/a?b=c)":              "http://example.com/a?b=c",
+		"no links here":                               "",
+	}
+	for text, want := range tests {
+		if got := findLink(text); got != want {
+			t.Errorf("findLink(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestParsePageMeta(t *testing.T) {
+	page := []byte(`<html><head><title>Fallback</title>
+<meta property="og:title" content="Order #42 &amp; more">
+<meta name='description' content='Plain description'>
+<meta content="/img/card.png" property="og:image" />
+</head></html>`)
+	m := parsePageMeta(page)
+	if m.title != "Order #42 & more" || m.description != "Plain description" || m.image != "/img/card.png" {
+		t.Errorf("meta = %+v", m)
+	}
+
+	m = parsePageMeta([]byte(`<title> Just a title </title>`))
+	if m.title != "Just a title" || m.description != "" || m.image != "" {
+		t.Errorf("meta = %+v", m)
+	}
+}
+
+func TestLinkPreviewer(t *testing.T) {
+	var card bytes.Buffer
+	if err := png.Encode(&card, image.NewRGBA(image.Rect(0, 0, 600, 300))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/order":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if _, err := w.Write([]byte(`<meta property="og:title" content="Order #42"><meta property="og:description" content="Out for delivery"><meta property="og:image" content="/card.png">`)); err != nil {
+				t.Errorf("write: %v", err)
+			}
+		case "/card.png":
+			w.Header().Set("Content-Type", "image/png")
+			if _, err := w.Write(card.Bytes()); err != nil {
+				t.Errorf("write: %v", err)
+			}
+		case "/file.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	p := newLinkPreviewer(srv.Client(), nil)
+	text := "Your order: " + srv.URL + "/order"
+	ext, err := p.preview(ctx, text)
+	if err != nil || ext == nil {
+		t.Fatalf("preview() = %v, %v", ext, err)
+	}
+	if ext.GetText() != text || ext.GetMatchedText() != srv.URL+"/order" || ext.GetTitle() != "Order #42" || ext.GetDescription() != "Out for delivery" {
+		t.Errorf("preview = %v", ext)
+	}
+	thumb, _, err := image.Decode(bytes.NewReader(ext.GetJPEGThumbnail()))
+	if err != nil {
+		t.Fatalf("thumbnail: %v", err)
+	}
+	if b := thumb.Bounds(); b.Dx() > previewThumbnailDim || b.Dy() > previewThumbnailDim {
+		t.Errorf("thumbnail is %v", b)
+	}
+
+	for _, text := range []string{"no link", "a file " + srv.URL + "/file.pdf"} {
+		if ext, err := p.preview(ctx, text); err != nil || ext != nil {
+			t.Errorf("preview(%q) = %v, %v, want none", text, ext, err)
+		}
+	}
+	if _, err := p.preview(ctx, srv.URL+"/missing"); err == nil {
+		t.Error("preview of missing page succeeded")
+	}
+
+	restricted := newLinkPreviewer(srv.Client(), []string{"shop.example"})
+	if ext, err := restricted.preview(ctx, text); err != nil || ext != nil {
+		t.Errorf("preview outside allowed_hosts = %v, %v", ext, err)
+	}
+}
+
+func TestLinkPreviewerRefusesLocalAddresses(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/html")
+		if _, err := w.Write([]byte(`<title>Internal dashboard</title>`)); err != nil {
+			t.Errorf("write: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	// No allowed_hosts: only the dial guard stands between the agent's
+	// link and the gateway's own network.
+	p := newLinkPreviewer(newFetchClient(time.Second, nil), nil)
+	ext, err := p.preview(context.Background(), "See "+srv.URL+"/admin")
+	if err == nil || ext != nil {
+		t.Errorf("preview of a loopback link = %v, %v, want refused", ext, err)
+	}
+	if hits != 0 {
+		t.Errorf("loopback server got %d requests", hits)
+	}
+}
+
+// hostRoutedClient returns a client that sends every request to srv,
+// whatever host the URL names, so tests can use distinct host names.
+func hostRoutedClient(srv *httptest.Server) *http.Client {
+	client := srv.Client()
+	transport := client.Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+	}
+	client.Transport = transport
+	return client
+}
+
+func TestLinkPreviewerStaysOnAllowedHosts(t *testing.T) {
+	var card bytes.Buffer
+	if err := png.Encode(&card, image.NewRGBA(image.Rect(0, 0, 10, 10))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	var internalHits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "shop.example" {
+			internalHits++
+		}
+		switch r.URL.Path {
+		case "/order":
+			w.Header().Set("Content-Type", "text/html")
+			if _, err := w.Write([]byte(`<meta property="og:title" content="Order"><meta property="og:image" content="http://internal.example/card.png">`)); err != nil {
+				t.Errorf("write: %v", err)
+			}
+		case "/moved":
+			http.Redirect(w, r, "http://internal.example/order", http.StatusFound)
+		case "/card.png":
+			w.Header().Set("Content-Type", "image/png")
+			if _, err := w.Write(card.Bytes()); err != nil {
+				t.Errorf("write: %v", err)
+			}
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	p := newLinkPreviewer(hostRoutedClient(srv), []string{"shop.example"})
+
+	ext, err := p.preview(ctx, "see http://shop.example/order")
+	if err != nil || ext == nil {
+		t.Fatalf("preview() = %v, %v", ext, err)
+	}
+	if len(ext.GetJPEGThumbnail()) != 0 {
+		t.Error("thumbnail fetched from a host outside allowed_hosts")
+	}
+
+	if ext, err := p.preview(ctx, "see http://shop.example/moved"); err == nil || ext != nil {
+		t.Errorf("preview() through redirect = %v, %v, want error", ext, err)
+	}
+	if internalHits != 0 {
+		t.Errorf("%d requests reached a host outside allowed_hosts", internalHits)
+	}
+}
@@ -0,0 +1,178 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+// WhatsApp's limits on list messages.
+const (
+	maxListRows        = 10
+	maxListRowTitleLen = 24
+	maxListRowDescLen  = 72
+	maxListButtonLen   = 20
+)
+
+// listSpec is the JSON an agent sends, base64-encoded, in an
+// agent.MimeTypeList part.
+type listSpec struct {
+	Title    string        `json:"title,omitempty"`
+	Text     string        `json:"text"`
+	Footer   string        `json:"footer,omitempty"`
+	Button   string        `json:"button"`
+	Sections []listSection `json:"sections"`
+}
+
+type listSection struct {
+	Title string    `json:"title,omitempty"`
+	Rows  []listRow `json:"rows"`
+}
+
+type listRow struct {
+	// ID is reported back when the row is selected; defaults to Title.
+	ID          string `json:"id,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// splitListSpecs removes list parts from parts and decodes them. Invalid
+// specs are dropped and reported in errs.
+func splitListSpecs(parts []agent.Part) (specs []listSpec, rest []agent.Part, errs []error) {
+	for _, part := range parts {
+		if part.InlineData == nil || part.InlineData.MimeType != agent.MimeTypeList {
+			rest = append(rest, part)
+			continue
+		}
+		spec, err := decodeListSpec(part.InlineData.Data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	return specs, rest, errs
+}
+
+func decodeListSpec(data string) (listSpec, error) {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return listSpec{}, fmt.Errorf("decode list: %w", err)
+	}
+	var spec listSpec
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return listSpec{}, fmt.Errorf("decode list: %w", err)
+	}
+	if strings.TrimSpace(spec.Text) == "" {
+		return listSpec{}, fmt.Errorf("list has no text")
+	}
+	if b := strings.TrimSpace(spec.Button); b == "" || utf8.RuneCountInString(b) > maxListButtonLen {
+		return listSpec{}, fmt.Errorf("list button %q must be 1 to %d characters", spec.Button, maxListButtonLen)
+	}
+	rows := 0
+	seen := make(map[string]bool)
+	for si, section := range spec.Sections {
+		if len(section.Rows) == 0 {
+			return listSpec{}, fmt.Errorf("list section %q has no rows", section.Title)
+		}
+		for ri, row := range section.Rows {
+			title := strings.TrimSpace(row.Title)
+			if title == "" || utf8.RuneCountInString(title) > maxListRowTitleLen {
+				return listSpec{}, fmt.Errorf("list row title %q must be 1 to %d characters", row.Title, maxListRowTitleLen)
+			}
+			if utf8.RuneCountInString(row.Description) > maxListRowDescLen {
+				return listSpec{}, fmt.Errorf("list row %q description is over %d characters", row.Title, maxListRowDescLen)
+			}
+			if row.ID == "" {
+				spec.Sections[si].Rows[ri].ID = title
+			}
+			id := spec.Sections[si].Rows[ri].ID
+			if seen[id] {
+				return listSpec{}, fmt.Errorf("duplicate list row id %q", id)
+			}
+			seen[id] = true
+			rows++
+		}
+	}
+	if rows == 0 || rows > maxListRows {
+		return listSpec{}, fmt.Errorf("list has %d rows (want 1 to %d)", rows, maxListRows)
+	}
+	return spec, nil
+}
+
+// listMessage renders spec as a single-select ListMessage.
+func listMessage(spec listSpec) *waE2E.Message {
+	sections := make([]*waE2E.ListMessage_Section, len(spec.Sections))
+	for i, s := range spec.Sections {
+		rows := make([]*waE2E.ListMessage_Row, len(s.Rows))
+		for j, r := range s.Rows {
+			rows[j] = &waE2E.ListMessage_Row{RowID: proto.String(r.ID), Title: proto.String(r.Title)}
+			if r.Description != "" {
+				rows[j].Description = proto.String(r.Description)
+			}
+		}
+		sections[i] = &waE2E.ListMessage_Section{Rows: rows}
+		if s.Title != "" {
+			sections[i].Title = proto.String(s.Title)
+		}
+	}
+	lm := &waE2E.ListMessage{
+		Description: proto.String(spec.Text),
+		ButtonText:  proto.String(spec.Button),
+		ListType:    waE2E.ListMessage_SINGLE_SELECT.Enum(),
+		Sections:    sections,
+	}
+	if spec.Title != "" {
+		lm.Title = proto.String(spec.Title)
+	}
+	if spec.Footer != "" {
+		lm.FooterText = proto.String(spec.Footer)
+	}
+	return &waE2E.Message{ListMessage: lm}
+}
+
+// listFallbackText lists spec's rows as numbered text, for when the list
+// cannot be sent.
+func listFallbackText(spec listSpec) string {
+	var b strings.Builder
+	if spec.Title != "" {
+		b.WriteString(spec.Title)
+		b.WriteString("\n")
+	}
+	b.WriteString(spec.Text)
+	b.WriteString("\n")
+	n := 0
+	for _, s := range spec.Sections {
+		if s.Title != "" {
+			fmt.Fprintf(&b, "\n%s", s.Title)
+		}
+		for _, r := range s.Rows {
+			n++
+			fmt.Fprintf(&b, "\n%d. %s", n, r.Title)
+			if r.Description != "" {
+				fmt.Fprintf(&b, " - %s", r.Description)
+			}
+		}
+	}
+	return b.String()
+}
+
+// sendList sends spec as a list message, falling back to a numbered text
+// list if WhatsApp rejects it.
+func (c *Client) sendList(ctx context.Context, turn *replyTurn, chat types.JID, userID, uniqueID string, spec listSpec) {
+	if _, err := c.wac.SendMessage(ctx, chat, listMessage(spec)); err != nil {
+		c.log.Warnf("Failed to send list to %s, sending it as text: %v", userID, err)
+		c.sendAgentText(ctx, turn, chat, userID, uniqueID, listFallbackText(spec))
+		return
+	}
+	c.countUsage(userID, usageOutbound)
+}
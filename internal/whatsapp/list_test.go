@@ -0,0 +1,90 @@
+package whatsapp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+func listPart(t *testing.T, spec any) agent.Part {
+	t.Helper()
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("marshal list: %v", err)
+	}
+	return agent.Part{InlineData: &agent.InlineData{MimeType: agent.MimeTypeList, Data: base64.StdEncoding.EncodeToString(raw)}}
+}
+
+func TestSplitListSpecs(t *testing.T) {
+	var eleven []listRow
+	for i := range 11 {
+		eleven = append(eleven, listRow{Title: fmt.Sprintf("Row %d", i)})
+	}
+	menu := listSpec{Text: "What would you like?", Button: "View dishes", Sections: []listSection{
+		{Title: "Mains", Rows: []listRow{{ID: "dal", Title: "Dal makhani", Description: "Black lentils"}, {Title: "Paneer tikka"}}},
+		{Title: "Drinks", Rows: []listRow{{Title: "Lassi"}}},
+	}}
+	parts := []agent.Part{
+		{Text: "Here's the menu."},
+		listPart(t, menu),
+		listPart(t, listSpec{Text: "No button", Sections: menu.Sections}),
+		listPart(t, listSpec{Text: "Too many", Button: "Open", Sections: []listSection{{Rows: eleven}}}),
+		listPart(t, listSpec{Text: "Empty", Button: "Open", Sections: []listSection{{Title: "Nothing"}}}),
+		listPart(t, listSpec{Text: "Dupes", Button: "Open", Sections: []listSection{{Rows: []listRow{{Title: "A"}}}, {Rows: []listRow{{Title: "A"}}}}}),
+		{InlineData: &agent.InlineData{MimeType: agent.MimeTypeList, Data: "%%%"}},
+	}
+
+	specs, rest, errs := splitListSpecs(parts)
+	if len(specs) != 1 {
+		t.Fatalf("specs = %+v", specs)
+	}
+	if rows := specs[0].Sections[0].Rows; rows[0].ID != "dal" || rows[1].ID != "Paneer tikka" {
+		t.Errorf("row ids = %q, %q", rows[0].ID, rows[1].ID)
+	}
+	if len(rest) != 1 || rest[0].Text != "Here's the menu." {
+		t.Errorf("rest = %+v", rest)
+	}
+	if len(errs) != 5 {
+		t.Errorf("errs = %v, want 5", errs)
+	}
+}
+
+func TestListMessage(t *testing.T) {
+	spec := listSpec{Title: "Menu", Text: "What would you like?", Button: "View dishes", Sections: []listSection{
+		{Title: "Mains", Rows: []listRow{{ID: "dal", Title: "Dal makhani", Description: "Black lentils"}}},
+		{Rows: []listRow{{ID: "lassi", Title: "Lassi"}}},
+	}}
+
+	lm := listMessage(spec).GetListMessage()
+	if lm.GetTitle() != "Menu" || lm.GetDescription() != "What would you like?" || lm.GetButtonText() != "View dishes" ||
+		lm.GetListType() != waE2E.ListMessage_SINGLE_SELECT || len(lm.GetSections()) != 2 {
+		t.Fatalf("message = %v", lm)
+	}
+	if r := lm.GetSections()[0].GetRows()[0]; r.GetRowID() != "dal" || r.GetDescription() != "Black lentils" {
+		t.Errorf("row = %v", r)
+	}
+	want := "Menu\nWhat would you like?\n\nMains\n1. Dal makhani - Black lentils\n2. Lassi"
+	if got := listFallbackText(spec); got != want {
+		t.Errorf("fallback = %q, want %q", got, want)
+	}
+}
+
+func TestSelectionTextList(t *testing.T) {
+	msg := &waE2E.Message{ListResponseMessage: &waE2E.ListResponseMessage{
+		Title:             proto.String("Dal makhani"),
+		SingleSelectReply: &waE2E.ListResponseMessage_SingleSelectReply{SelectedRowID: proto.String("dal")},
+	}}
+	if got := selectionText(msg); got != "Dal makhani" {
+		t.Errorf("selectionText() = %q, want row title", got)
+	}
+	msg.ListResponseMessage.Title = nil
+	if got := selectionText(msg); got != "dal" {
+		t.Errorf("selectionText() = %q, want row id", got)
+	}
+}
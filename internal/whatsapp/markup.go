@@ -0,0 +1,66 @@
+package whatsapp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Built-in markup presets for the strip_markup outbound step, selectable by
+// name in whatsapp.outbound_pipeline.strip_markup.presets.
+const (
+	// MarkupBracketTools strips [tool_call]…[/tool_call] style blocks.
+	MarkupBracketTools = "bracket_tools"
+	// MarkupXMLTools strips <tool_call>…</tool_call> style blocks.
+	MarkupXMLTools = "xml_tools"
+	// MarkupCitations strips inline citation markers such as 【4:0†source】
+	// and [cite: 1, 2].
+	MarkupCitations = "citations"
+)
+
+var markupPresets = map[string][]*regexp.Regexp{
+	MarkupBracketTools: {
+		regexp.MustCompile(`(?s)\[(tool_call|tool_result|tool_use|function_call)\].*?\[/(?:tool_call|tool_result|tool_use|function_call)\]`),
+	},
+	MarkupXMLTools: {
+		regexp.MustCompile(`(?s)<(tool_call|tool_result|tool_use|tool_code|function_call|function_calls)(?:\s[^>]*)?>.*?</(?:tool_call|tool_result|tool_use|tool_code|function_call|function_calls)>`),
+	},
+	MarkupCitations: {
+		regexp.MustCompile(`[ \t]*【[^】]*】`),
+		regexp.MustCompile(`[ \t]*\[cite:[^\]]*\]`),
+	},
+}
+
+// buildMarkupStripper compiles the selected presets and custom patterns
+// into one strip function. Whitespace left behind by a removed block is
+// collapsed.
+func buildMarkupStripper(presets, patterns []string) (func(string) string, error) {
+	var res []*regexp.Regexp
+	for _, name := range presets {
+		preset, ok := markupPresets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown strip_markup preset %q", name)
+		}
+		res = append(res, preset...)
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid strip_markup pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return func(text string) string {
+		if len(res) == 0 {
+			return text
+		}
+		for _, re := range res {
+			text = re.ReplaceAllString(text, "")
+		}
+		text = collapseSpaces.ReplaceAllString(text, " ")
+		return strings.TrimSpace(excessBlankLines.ReplaceAllString(text, "\n\n"))
+	}, nil
+}
+
+// collapseSpaces matches runs of spaces left where inline markup was cut.
+var collapseSpaces = regexp.MustCompile(` {2,}`)
@@ -0,0 +1,89 @@
+package whatsapp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestStripMarkupPresets(t *testing.T) {
+	tests := []struct {
+		name     string
+		presets  []string
+		patterns []string
+		in       string
+		want     string
+	}{
+		{
+			name:    "bracket tools",
+			presets: []string{MarkupBracketTools},
+			in:      "Let me check.\n[tool_call]{\"name\":\"weather\",\"city\":\"Pune\"}[/tool_call]\n[tool_result]31C[/tool_result]\nIt is 31°C in Pune.",
+			want:    "Let me check.\n\nIt is 31°C in Pune.",
+		},
+		{
+			name:    "xml tools",
+			presets: []string{MarkupXMLTools},
+			in:      "<tool_call id=\"1\">\n{\"name\": \"search\"}\n</tool_call>Here you go: <tool_code>print(1)</tool_code>done",
+			want:    "Here you go: done",
+		},
+		{
+			name:    "citations",
+			presets: []string{MarkupCitations},
+			in:      "Pune is in Maharashtra 【4:0†source】. It is large [cite: 1, 2].",
+			want:    "Pune is in Maharashtra. It is large.",
+		},
+		{
+			name:     "custom pattern",
+			patterns: []string{`(?s)<<ctx>>.*?<</ctx>>`},
+			in:       "Hi <<ctx>>user=42<</ctx>> there",
+			want:     "Hi there",
+		},
+		{
+			name:    "preset leaves other brackets alone",
+			presets: []string{MarkupBracketTools, MarkupCitations},
+			in:      "Reply [yes] or [no]",
+			want:    "Reply [yes] or [no]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := buildOutboundPipeline(config.OutboundPipelineConfig{
+				Steps:       []string{StepStripMarkup},
+				StripMarkup: config.StripMarkupConfig{Presets: tt.presets, Patterns: tt.patterns},
+			})
+			if err != nil {
+				t.Fatalf("buildOutboundPipeline() error: %v", err)
+			}
+			if got := p.apply(tt.in); !reflect.DeepEqual(got, []string{tt.want}) {
+				t.Errorf("apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripMarkupOnlyMarkup(t *testing.T) {
+	p, err := buildOutboundPipeline(config.OutboundPipelineConfig{
+		Steps:       []string{StepStripMarkup},
+		StripMarkup: config.StripMarkupConfig{Presets: []string{MarkupBracketTools}},
+	})
+	if err != nil {
+		t.Fatalf("buildOutboundPipeline() error: %v", err)
+	}
+	if got := p.apply("[tool_call]{}[/tool_call]"); len(got) != 0 {
+		t.Errorf("apply() = %q, want no messages", got)
+	}
+}
+
+func TestStripMarkupConfigErrors(t *testing.T) {
+	tests := []config.StripMarkupConfig{
+		{Presets: []string{"langchain"}},
+		{Patterns: []string{`[unclosed`}},
+	}
+	for _, sm := range tests {
+		_, err := buildOutboundPipeline(config.OutboundPipelineConfig{Steps: []string{StepStripMarkup}, StripMarkup: sm})
+		if err == nil {
+			t.Errorf("buildOutboundPipeline(%+v) succeeded, want error", sm)
+		}
+	}
+}
@@ -171,6 +171,20 @@ func (p *Processor) ProcessDocument(ctx context.Context, data []byte, mimeType s
 		return nil, fmt.Errorf("document too large: %d bytes", len(data))
 	}
 
+	// Word documents are reduced to their text, which agents can read.
+	if mimeType == MimeTypeDOCX {
+		text, err := docxText(data)
+		if err != nil {
+			return nil, err
+		}
+		return &agent.Part{
+			InlineData: &agent.InlineData{
+				MimeType: "text/plain",
+				Data:     base64.StdEncoding.EncodeToString([]byte(text)),
+			},
+		}, nil
+	}
+
 	// For now, we only support passing through PDF, TXT, CSV
 	allowedMimes := map[string]bool{
 		"application/pdf": true,
@@ -65,6 +65,19 @@ func TestProcessor_ProcessDocument(t *testing.T) {
 			wantErr:  false,
 			wantMime: "text/plain",
 		},
+		{
+			name:     "DOCX as text",
+			data:     buildDOCX(t, `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>hi</w:t></w:r></w:p></w:body></w:document>`),
+			mime:     MimeTypeDOCX,
+			wantErr:  false,
+			wantMime: "text/plain",
+		},
+		{
+			name:    "Corrupt DOCX",
+			data:    []byte("not a zip"),
+			mime:    MimeTypeDOCX,
+			wantErr: true,
+		},
 		{
 			name:    "Unsupported type",
 			data:    []byte("binary data"),
@@ -0,0 +1,52 @@
+package whatsapp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stripLeadingMention removes a leading self-mention such as "@bot" or
+// "Bot," from a direct message. Names are matched case-insensitively and
+// only on a word boundary, so "Botanical" is not treated as a mention of "Bot".
+func stripLeadingMention(text string, names []string) string {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		for _, candidate := range []string{name, "@" + strings.TrimPrefix(name, "@")} {
+			if len(trimmed) < len(candidate) || !strings.EqualFold(trimmed[:len(candidate)], candidate) {
+				continue
+			}
+			rest := trimmed[len(candidate):]
+			if rest != "" {
+				r := []rune(rest)[0]
+				if unicode.IsLetter(r) || unicode.IsDigit(r) {
+					continue
+				}
+			}
+			rest = strings.TrimLeftFunc(rest, func(r rune) bool {
+				return unicode.IsSpace(r) || strings.ContainsRune(",:;-", r)
+			})
+			if rest == "" {
+				return text
+			}
+			return rest
+		}
+	}
+	return text
+}
+
+// mentionNames returns the configured bot names plus the bot's own phone
+// number, which is how WhatsApp renders an @-mention in plain text.
+func (c *Client) mentionNames() []string {
+	names := c.cfg.WhatsApp.MentionNames
+	if len(names) == 0 {
+		return nil
+	}
+	if c.wac != nil && c.wac.Store.ID != nil {
+		names = append(append([]string(nil), names...), c.wac.Store.ID.User)
+	}
+	return names
+}
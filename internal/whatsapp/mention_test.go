@@ -0,0 +1,38 @@
+package whatsapp
+
+import "testing"
+
+func TestStripLeadingMention(t *testing.T) {
+	names := []string{"@bot", "Shopper", "919000000000"}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"at mention", "@bot what is my balance?", "what is my balance?"},
+		{"bare name with comma", "Shopper, show my cart", "show my cart"},
+		{"case insensitive", "SHOPPER: hi", "hi"},
+		{"at prefix added to name", "@shopper hi", "hi"},
+		{"phone mention", "@919000000000 hello", "hello"},
+		{"leading whitespace", "  @bot  hello", "hello"},
+		{"word boundary", "Shoppers are welcome", "Shoppers are welcome"},
+		{"unrelated text", "hello @bot", "hello @bot"},
+		{"only mention is kept", "@bot", "@bot"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripLeadingMention(tt.text, names); got != tt.want {
+				t.Errorf("stripLeadingMention(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripLeadingMention_NoNames(t *testing.T) {
+	if got := stripLeadingMention("@bot hi", nil); got != "@bot hi" {
+		t.Errorf("expected text unchanged without configured names, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package whatsapp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// messageMetadata collects the configured metadata fields for one inbound
+// message, keyed by field name. phone is the resolved sender number. It
+// returns nil when no field is configured.
+func messageMetadata(cfg config.MessageMetadataConfig, info types.MessageInfo, msg *waE2E.Message, phone string) map[string]any {
+	if len(cfg.Fields) == 0 {
+		return nil
+	}
+	meta := make(map[string]any, len(cfg.Fields))
+	for _, field := range cfg.Fields {
+		switch field {
+		case config.MetadataTimestamp:
+			meta[field] = info.Timestamp.UTC().Format(time.RFC3339)
+		case config.MetadataMessageID:
+			meta[field] = info.ID
+		case config.MetadataPushName:
+			if info.PushName != "" {
+				meta[field] = info.PushName
+			}
+		case config.MetadataIsReply:
+			meta[field] = quotedMessageID(msg) != ""
+		case config.MetadataQuotedID:
+			if id := quotedMessageID(msg); id != "" {
+				meta[field] = id
+			}
+		case config.MetadataSenderPhone:
+			if cfg.HashPhone {
+				sum := sha256.Sum256([]byte(phone))
+				meta[field] = hex.EncodeToString(sum[:])
+			} else {
+				meta[field] = phone
+			}
+		}
+	}
+	return meta
+}
+
+// contextInfos returns the context info of each message kind that can
+// carry one; kinds not present in msg yield nil entries.
+func contextInfos(msg *waE2E.Message) []*waE2E.ContextInfo {
+	return []*waE2E.ContextInfo{
+		msg.GetExtendedTextMessage().GetContextInfo(),
+		msg.GetImageMessage().GetContextInfo(),
+		msg.GetVideoMessage().GetContextInfo(),
+		msg.GetAudioMessage().GetContextInfo(),
+		msg.GetDocumentMessage().GetContextInfo(),
+		msg.GetStickerMessage().GetContextInfo(),
+	}
+}
+
+// quotedMessageID returns the ID of the message msg replies to, or "".
+func quotedMessageID(msg *waE2E.Message) string {
+	if msg == nil {
+		return ""
+	}
+	for _, ci := range contextInfos(msg) {
+		if id := ci.GetStanzaID(); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// withMetadata adds meta to state under key, allocating state if needed.
+func withMetadata(state map[string]any, key string, meta map[string]any) map[string]any {
+	if len(meta) == 0 {
+		return state
+	}
+	if state == nil {
+		state = make(map[string]any, 1)
+	}
+	state[key] = meta
+	return state
+}
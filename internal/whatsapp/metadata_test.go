@@ -0,0 +1,97 @@
+package whatsapp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestMessageMetadata(t *testing.T) {
+	info := types.MessageInfo{
+		ID:        "MSG1",
+		Timestamp: time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC),
+		PushName:  "Asha",
+	}
+	reply := &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+		Text:        proto.String("yes"),
+		ContextInfo: &waE2E.ContextInfo{StanzaID: proto.String("ORIG1")},
+	}}
+	plain := &waE2E.Message{Conversation: proto.String("hi")}
+	sum := sha256.Sum256([]byte("919876543210"))
+	hashed := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name string
+		cfg  config.MessageMetadataConfig
+		msg  *waE2E.Message
+		want map[string]any
+	}{
+		{"disabled", config.MessageMetadataConfig{}, reply, nil},
+		{
+			"all fields on a reply",
+			config.MessageMetadataConfig{Fields: []string{"timestamp", "message_id", "push_name", "is_reply", "quoted_id", "sender_phone"}},
+			reply,
+			map[string]any{
+				"timestamp":    "2026-10-01T12:30:00Z",
+				"message_id":   "MSG1",
+				"push_name":    "Asha",
+				"is_reply":     true,
+				"quoted_id":    "ORIG1",
+				"sender_phone": "919876543210",
+			},
+		},
+		{
+			"only enabled fields",
+			config.MessageMetadataConfig{Fields: []string{"push_name", "is_reply"}},
+			plain,
+			map[string]any{"push_name": "Asha", "is_reply": false},
+		},
+		{
+			"hashed phone",
+			config.MessageMetadataConfig{Fields: []string{"sender_phone"}, HashPhone: true},
+			plain,
+			map[string]any{"sender_phone": hashed},
+		},
+		{
+			"quoted id omitted when not a reply",
+			config.MessageMetadataConfig{Fields: []string{"quoted_id"}},
+			plain,
+			map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := messageMetadata(tt.cfg, info, tt.msg, "919876543210")
+			if len(got) != len(tt.want) || (got == nil) != (tt.want == nil) {
+				t.Fatalf("messageMetadata() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMetadata(t *testing.T) {
+	meta := map[string]any{"push_name": "Asha"}
+	if got := withMetadata(nil, "message_metadata", nil); got != nil {
+		t.Errorf("withMetadata(nil, nil) = %v, want nil", got)
+	}
+	got := withMetadata(nil, "message_metadata", meta)
+	if m, ok := got["message_metadata"].(map[string]any); !ok || m["push_name"] != "Asha" {
+		t.Errorf("withMetadata(nil, meta) = %v", got)
+	}
+	got = withMetadata(map[string]any{"user_tier": "gold"}, "meta", meta)
+	if got["user_tier"] != "gold" || got["meta"] == nil {
+		t.Errorf("withMetadata kept profile state? %v", got)
+	}
+}
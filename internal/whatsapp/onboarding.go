@@ -0,0 +1,43 @@
+package whatsapp
+
+import (
+	"context"
+
+	waLog "go.mau.fi/whatsmeow/util/log"
+)
+
+// onboardingStore remembers who has already been nudged.
+type onboardingStore interface {
+	OnboardingNudged(ctx context.Context, phone string) (bool, error)
+	MarkOnboardingNudged(ctx context.Context, phone string) error
+}
+
+// onboardingNudger sends a one-time message to users admitted only by the
+// country-code rule, e.g. inviting them to register.
+type onboardingNudger struct {
+	store   onboardingStore
+	message string
+	log     waLog.Logger
+}
+
+// nudge returns the message to send to phone, or "" when none is due. The
+// nudge is recorded before it is returned, so a store failure skips it
+// rather than repeating it on every message.
+func (n *onboardingNudger) nudge(ctx context.Context, phone string, reason allowReason) string {
+	if reason != allowCountry {
+		return ""
+	}
+	nudged, err := n.store.OnboardingNudged(ctx, phone)
+	if err != nil {
+		n.log.Warnf("Skipping onboarding nudge for %s: %v", phone, err)
+		return ""
+	}
+	if nudged {
+		return ""
+	}
+	if err := n.store.MarkOnboardingNudged(ctx, phone); err != nil {
+		n.log.Warnf("Skipping onboarding nudge for %s: %v", phone, err)
+		return ""
+	}
+	return n.message
+}
@@ -0,0 +1,65 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mau.fi/whatsmeow/types"
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+type fakeOnboardingStore struct {
+	nudged  map[string]bool
+	readErr error
+}
+
+func (f *fakeOnboardingStore) OnboardingNudged(_ context.Context, phone string) (bool, error) {
+	return f.nudged[phone], f.readErr
+}
+
+func (f *fakeOnboardingStore) MarkOnboardingNudged(_ context.Context, phone string) error {
+	f.nudged[phone] = true
+	return nil
+}
+
+func TestOnboardingNudgeCountryOnly(t *testing.T) {
+	cfg := &config.Config{WhatsApp: config.WhatsAppConfig{WhitelistedUsers: []string{"15550001111", "919800000000"}}}
+	pn := func(user string) types.JID { return types.NewJID(user, types.DefaultUserServer) }
+
+	tests := []struct {
+		name  string
+		phone string
+		want  []string // nudge for the first and second message
+	}{
+		{"country-only user nudged once", "919876543210", []string{"welcome", ""}},
+		{"whitelisted user not nudged", "15550001111", []string{"", ""}},
+		{"whitelisted user from allowed country not nudged", "919800000000", []string{"", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeOnboardingStore{nudged: map[string]bool{}}
+			n := &onboardingNudger{store: store, message: "welcome", log: waLog.Noop}
+			reason := allowedBy(cfg, pn(tt.phone))
+			for i, want := range tt.want {
+				if got := n.nudge(context.Background(), tt.phone, reason); got != want {
+					t.Errorf("message %d: nudge = %q, want %q", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOnboardingNudgeSkippedOnStoreError(t *testing.T) {
+	store := &fakeOnboardingStore{nudged: map[string]bool{}, readErr: errors.New("db down")}
+	n := &onboardingNudger{store: store, message: "welcome", log: waLog.Noop}
+	if got := n.nudge(context.Background(), "919876543210", allowCountry); got != "" {
+		t.Errorf("nudge = %q, want none when the store fails", got)
+	}
+	if store.nudged["919876543210"] {
+		t.Error("nudge recorded despite store failure")
+	}
+}
@@ -0,0 +1,171 @@
+package whatsapp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// Built-in outbound transform steps, referenced by name in
+// whatsapp.outbound_pipeline.steps.
+const (
+	StepMarkdown    = "markdown"
+	StepBranding    = "branding"
+	StepSanitize    = "sanitize"
+	StepSplit       = "split"
+	StepChunk       = "chunk"
+	StepStripMarkup = "strip_markup"
+	StepBoilerplate = "strip_boilerplate"
+)
+
+// outboundPipeline is an ordered list of transforms that turn one agent
+// text reply into the WhatsApp messages actually sent. Steps work on a
+// slice because split and chunk may produce several messages.
+type outboundPipeline []func(msgs []string) []string
+
+func (p outboundPipeline) apply(text string) []string {
+	msgs := []string{text}
+	for _, step := range p {
+		msgs = step(msgs)
+	}
+	out := msgs[:0]
+	for _, m := range msgs {
+		if strings.TrimSpace(m) != "" {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// caption runs text through the pipeline for use as a media caption. A
+// caption is a single message, so anything the pipeline splits off is
+// returned separately to be sent after the media.
+func (p outboundPipeline) caption(text string) (string, []string) {
+	if strings.TrimSpace(text) == "" {
+		return "", nil
+	}
+	msgs := p.apply(text)
+	if len(msgs) == 0 {
+		return "", nil
+	}
+	return msgs[0], msgs[1:]
+}
+
+func buildOutboundPipeline(cfg config.OutboundPipelineConfig) (outboundPipeline, error) {
+	pipeline := make(outboundPipeline, 0, len(cfg.Steps))
+	for _, name := range cfg.Steps {
+		var step func([]string) []string
+		switch name {
+		case StepMarkdown:
+			step = eachMessage(markdownToWhatsApp)
+		case StepBranding:
+			step = func(msgs []string) []string { return brand(msgs, cfg.Prefix, cfg.Suffix) }
+		case StepSanitize:
+			step = eachMessage(sanitizeOutbound)
+		case StepSplit:
+			step = func(msgs []string) []string { return splitOnDelimiter(msgs, cfg.Delimiter) }
+		case StepChunk:
+			step = func(msgs []string) []string { return chunkMessages(msgs, cfg.ChunkSize) }
+		case StepStripMarkup:
+			strip, err := buildMarkupStripper(cfg.StripMarkup.Presets, cfg.StripMarkup.Patterns)
+			if err != nil {
+				return nil, err
+			}
+			step = eachMessage(strip)
+		case StepBoilerplate:
+			strip, err := buildBoilerplateStripper(cfg.StripBoilerplate.Texts, cfg.StripBoilerplate.Patterns)
+			if err != nil {
+				return nil, err
+			}
+			step = eachMessage(strip)
+		default:
+			return nil, fmt.Errorf("unknown outbound pipeline step %q", name)
+		}
+		pipeline = append(pipeline, step)
+	}
+	return pipeline, nil
+}
+
+func eachMessage(fn func(string) string) func([]string) []string {
+	return func(msgs []string) []string {
+		for i, m := range msgs {
+			msgs[i] = fn(m)
+		}
+		return msgs
+	}
+}
+
+var (
+	mdBold    = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	mdStrike  = regexp.MustCompile(`~~(.+?)~~`)
+	mdHeading = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+	mdLink    = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+)
+
+// markdownToWhatsApp rewrites common Markdown into WhatsApp formatting.
+func markdownToWhatsApp(text string) string {
+	text = mdHeading.ReplaceAllString(text, "*$1*")
+	text = mdBold.ReplaceAllString(text, "*$1$2*")
+	text = mdStrike.ReplaceAllString(text, "~$1~")
+	text = mdLink.ReplaceAllString(text, "$1 ($2)")
+	return text
+}
+
+// brand adds prefix to the first message and suffix to the last.
+func brand(msgs []string, prefix, suffix string) []string {
+	if len(msgs) == 0 {
+		return msgs
+	}
+	if prefix != "" {
+		msgs[0] = prefix + msgs[0]
+	}
+	if suffix != "" {
+		msgs[len(msgs)-1] += suffix
+	}
+	return msgs
+}
+
+var excessBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// sanitizeOutbound drops control characters (other than newlines and tabs)
+// and collapses runs of blank lines.
+func sanitizeOutbound(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, text)
+	return strings.TrimSpace(excessBlankLines.ReplaceAllString(text, "\n\n"))
+}
+
+func splitOnDelimiter(msgs []string, delimiter string) []string {
+	if delimiter == "" {
+		return msgs
+	}
+	var out []string
+	for _, m := range msgs {
+		for _, part := range strings.Split(m, delimiter) {
+			out = append(out, strings.TrimSpace(part))
+		}
+	}
+	return out
+}
+
+func chunkMessages(msgs []string, size int) []string {
+	if size <= 0 {
+		return msgs
+	}
+	var out []string
+	for _, m := range msgs {
+		for m != "" {
+			head, rest := splitReply(m, size)
+			out = append(out, head)
+			m = rest
+		}
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package whatsapp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestOutboundPipeline(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.OutboundPipelineConfig
+		in   string
+		want []string
+	}{
+		{
+			name: "empty pipeline is identity",
+			in:   "**hi**",
+			want: []string{"**hi**"},
+		},
+		{
+			name: "markdown",
+			cfg:  config.OutboundPipelineConfig{Steps: []string{StepMarkdown}},
+			in:   "## Title\n**bold** and ~~gone~~, see [docs](https://example.com)",
+			want: []string{"*Title*\n*bold* and ~gone~, see docs (https://example.com)"},
+		},
+		{
+			name: "sanitize",
+			cfg:  config.OutboundPipelineConfig{Steps: []string{StepSanitize}},
+			in:   "a\x00b\n\n\n\nc\t",
+			want: []string{"ab\n\nc"},
+		},
+		{
+			name: "split then brand",
+			cfg: config.OutboundPipelineConfig{
+				Steps:     []string{StepSplit, StepBranding},
+				Delimiter: "---",
+				Prefix:    "[Shop] ",
+				Suffix:    " -- Shop",
+			},
+			in:   "one --- two --- three",
+			want: []string{"[Shop] one", "two", "three -- Shop"},
+		},
+		{
+			name: "empty messages dropped",
+			cfg:  config.OutboundPipelineConfig{Steps: []string{StepSplit}, Delimiter: "---"},
+			in:   "one ------ two",
+			want: []string{"one", "two"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := buildOutboundPipeline(tt.cfg)
+			if err != nil {
+				t.Fatalf("buildOutboundPipeline() error: %v", err)
+			}
+			if got := p.apply(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutboundPipelineBrandingBeforeChunking(t *testing.T) {
+	text := strings.Repeat("word ", 10)
+	cfg := config.OutboundPipelineConfig{Suffix: " -- sent by Shop", ChunkSize: 20}
+
+	cfg.Steps = []string{StepBranding, StepChunk}
+	p, err := buildOutboundPipeline(cfg)
+	if err != nil {
+		t.Fatalf("buildOutboundPipeline() error: %v", err)
+	}
+	for _, m := range p.apply(text) {
+		if utf8.RuneCountInString(m) > 20 {
+			t.Errorf("branding before chunking must keep chunks within size, got %q", m)
+		}
+	}
+
+	cfg.Steps = []string{StepChunk, StepBranding}
+	p, err = buildOutboundPipeline(cfg)
+	if err != nil {
+		t.Fatalf("buildOutboundPipeline() error: %v", err)
+	}
+	msgs := p.apply(text)
+	if last := msgs[len(msgs)-1]; !strings.HasSuffix(last, " -- sent by Shop") || utf8.RuneCountInString(last) <= 20 {
+		t.Errorf("chunking before branding should append the suffix after chunking, got %q", last)
+	}
+}
+
+func TestOutboundPipelineCaption(t *testing.T) {
+	p, err := buildOutboundPipeline(config.OutboundPipelineConfig{
+		Steps:     []string{StepSanitize, StepBranding, StepSplit},
+		Prefix:    "[Shop] ",
+		Delimiter: "---",
+	})
+	if err != nil {
+		t.Fatalf("buildOutboundPipeline() error: %v", err)
+	}
+
+	caption, rest := p.caption("chart\x00 --- details")
+	if caption != "[Shop] chart" {
+		t.Errorf("caption = %q, want branded and sanitized", caption)
+	}
+	if !reflect.DeepEqual(rest, []string{"details"}) {
+		t.Errorf("rest = %q, want [details]", rest)
+	}
+
+	if caption, rest := p.caption(""); caption != "" || rest != nil {
+		t.Errorf("empty caption = %q, %q, want none", caption, rest)
+	}
+}
+
+func TestBuildOutboundPipelineUnknownStep(t *testing.T) {
+	if _, err := buildOutboundPipeline(config.OutboundPipelineConfig{Steps: []string{"emojify"}}); err == nil {
+		t.Fatal("expected error for unknown step")
+	}
+}
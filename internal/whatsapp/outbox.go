@@ -0,0 +1,116 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// outboxStore persists queued outbound messages.
+type outboxStore interface {
+	PutOutbox(ctx context.Context, m store.OutboxMessage) error
+	ListOutbox(ctx context.Context) ([]store.OutboxMessage, error)
+	DeleteOutbox(ctx context.Context, id string) error
+	TakeOutbox(ctx context.Context, id string) (bool, error)
+}
+
+// outbox mirrors the send queue in the store so messages still queued when
+// the gateway stops are sent after it restarts. A message is claimed
+// (taken from the store) before it is sent or resent, so during a rolling
+// restart the old and new gateway never both send it.
+type outbox struct {
+	store  outboxStore
+	maxAge time.Duration
+	now    func() time.Time
+	// started separates this run's messages from those left by an earlier
+	// one, which resume may safely requeue.
+	started time.Time
+	seq     atomic.Int64
+}
+
+func newOutbox(s outboxStore, maxAge time.Duration) *outbox {
+	o := &outbox{store: s, maxAge: maxAge, now: time.Now}
+	o.started = o.now()
+	return o
+}
+
+// add persists m, assigning its ID and queue time.
+func (o *outbox) add(ctx context.Context, m store.OutboxMessage) (store.OutboxMessage, error) {
+	m.QueuedAt = o.now().UTC()
+	m.ID = o.newID()
+	m.Attempted = false
+	return m, o.store.PutOutbox(ctx, m)
+}
+
+// newID returns an outbox ID not used by this or an earlier run.
+func (o *outbox) newID() string {
+	return fmt.Sprintf("%d-%d", o.now().UnixNano(), o.seq.Add(1))
+}
+
+// attempt claims m and marks it as being sent. Call it right before the
+// send, and skip the send if it reports that another gateway claimed m.
+func (o *outbox) attempt(ctx context.Context, m store.OutboxMessage) (bool, error) {
+	claimed, err := o.store.TakeOutbox(ctx, m.ID)
+	if err != nil || !claimed {
+		return claimed, err
+	}
+	m.Attempted = true
+	return true, o.store.PutOutbox(ctx, m)
+}
+
+// done removes a message once it was sent or dropped.
+func (o *outbox) done(ctx context.Context, id string) error {
+	return o.store.DeleteOutbox(ctx, id)
+}
+
+// outboxResume is what resume found in the outbox.
+type outboxResume struct {
+	// resend are still persisted and must be passed to done once handled.
+	resend []store.OutboxMessage
+	// stale were queued longer than the resume age ago; attempted may have
+	// reached WhatsApp before the restart. Both are removed, not resent.
+	stale     []store.OutboxMessage
+	attempted []store.OutboxMessage
+}
+
+// resume sorts out the messages an earlier run left queued, oldest first.
+// Messages queued by this run are skipped so they are never sent twice, as
+// are those another gateway claims first.
+func (o *outbox) resume(ctx context.Context) (outboxResume, error) {
+	msgs, err := o.store.ListOutbox(ctx)
+	if err != nil {
+		return outboxResume{}, err
+	}
+	var r outboxResume
+	now := o.now()
+	for _, m := range msgs {
+		if !m.QueuedAt.Before(o.started) {
+			continue
+		}
+		claimed, err := o.store.TakeOutbox(ctx, m.ID)
+		if err != nil {
+			return r, err
+		}
+		if !claimed {
+			continue
+		}
+		switch {
+		case m.Attempted:
+			r.attempted = append(r.attempted, m)
+		case o.maxAge > 0 && now.Sub(m.QueuedAt) > o.maxAge:
+			r.stale = append(r.stale, m)
+		default:
+			// Persist it again under a new ID, so the run that queued it
+			// cannot claim it any more.
+			m.ID = o.newID()
+			if err := o.store.PutOutbox(ctx, m); err != nil {
+				return r, err
+			}
+			r.resend = append(r.resend, m)
+		}
+	}
+	return r, nil
+}
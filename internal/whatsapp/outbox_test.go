@@ -0,0 +1,224 @@
+package whatsapp
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// fakeOutboxStore outlives the outboxes built on it, standing in for the
+// database across a restart.
+type fakeOutboxStore struct {
+	mu   sync.Mutex
+	msgs map[string]store.OutboxMessage
+}
+
+func (f *fakeOutboxStore) PutOutbox(_ context.Context, m store.OutboxMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.msgs == nil {
+		f.msgs = make(map[string]store.OutboxMessage)
+	}
+	f.msgs[m.ID] = m
+	return nil
+}
+
+func (f *fakeOutboxStore) ListOutbox(context.Context) ([]store.OutboxMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var msgs []store.OutboxMessage
+	for _, m := range f.msgs {
+		msgs = append(msgs, m)
+	}
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].QueuedAt.Before(msgs[j].QueuedAt) })
+	return msgs, nil
+}
+
+func (f *fakeOutboxStore) DeleteOutbox(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.msgs, id)
+	return nil
+}
+
+func (f *fakeOutboxStore) TakeOutbox(_ context.Context, id string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.msgs[id]
+	delete(f.msgs, id)
+	return ok, nil
+}
+
+func texts(msgs []store.OutboxMessage) []string {
+	var out []string
+	for _, m := range msgs {
+		out = append(out, m.Text)
+	}
+	return out
+}
+
+func TestOutboxPersistsUntilDone(t *testing.T) {
+	ctx := t.Context()
+	fs := &fakeOutboxStore{}
+	o := newOutbox(fs, 10*time.Minute)
+
+	m, err := o.add(ctx, store.OutboxMessage{Phone: "919876543210", Text: "hello"})
+	if err != nil {
+		t.Fatalf("add() error: %v", err)
+	}
+	if m.ID == "" || m.QueuedAt.IsZero() {
+		t.Fatalf("add() = %+v, want ID and queue time set", m)
+	}
+	if got := fs.msgs[m.ID]; got.Text != "hello" || got.Attempted {
+		t.Errorf("persisted %+v", got)
+	}
+
+	if claimed, err := o.attempt(ctx, m); err != nil || !claimed {
+		t.Fatalf("attempt() = %v, %v, want claimed", claimed, err)
+	}
+	if !fs.msgs[m.ID].Attempted {
+		t.Error("attempt() did not mark the message")
+	}
+	if err := o.done(ctx, m.ID); err != nil {
+		t.Fatalf("done() error: %v", err)
+	}
+	if len(fs.msgs) != 0 {
+		t.Errorf("outbox not empty after done: %v", fs.msgs)
+	}
+}
+
+func TestOutboxResumeAfterRestart(t *testing.T) {
+	ctx := t.Context()
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	fs := &fakeOutboxStore{}
+
+	before := newOutbox(fs, 10*time.Minute)
+	before.now = func() time.Time { return now }
+	before.started = now
+	add := func(text string) store.OutboxMessage {
+		now = now.Add(time.Minute)
+		m, err := before.add(ctx, store.OutboxMessage{Phone: "919876543210", Text: text})
+		if err != nil {
+			t.Fatalf("add(%q) error: %v", text, err)
+		}
+		return m
+	}
+	stale := add("stale")
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Minute)
+	}
+	add("first")
+	inFlight := add("in flight")
+	if claimed, err := before.attempt(ctx, inFlight); err != nil || !claimed {
+		t.Fatalf("attempt() = %v, %v, want claimed", claimed, err)
+	}
+	add("second")
+	sent := add("sent")
+	if err := before.done(ctx, sent.ID); err != nil {
+		t.Fatalf("done() error: %v", err)
+	}
+
+	// Restart: a new outbox over the same store, a minute later.
+	now = now.Add(time.Minute)
+	after := newOutbox(fs, 10*time.Minute)
+	after.now = func() time.Time { return now }
+	after.started = now
+	fresh, err := after.add(ctx, store.OutboxMessage{Phone: "919876543210", Text: "after restart"})
+	if err != nil {
+		t.Fatalf("add() error: %v", err)
+	}
+
+	r, err := after.resume(ctx)
+	if err != nil {
+		t.Fatalf("resume() error: %v", err)
+	}
+	if got := texts(r.resend); len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("resend = %v, want [first second]", got)
+	}
+	if got := texts(r.stale); len(got) != 1 || got[0] != "stale" {
+		t.Errorf("stale = %v, want [stale]", got)
+	}
+	if got := texts(r.attempted); len(got) != 1 || got[0] != "in flight" {
+		t.Errorf("attempted = %v, want [in flight]", got)
+	}
+
+	// Stale and attempted messages are gone; resent ones stay until sent,
+	// and this run's own message is left alone.
+	if _, ok := fs.msgs[stale.ID]; ok {
+		t.Error("stale message still persisted")
+	}
+	if _, ok := fs.msgs[inFlight.ID]; ok {
+		t.Error("attempted message still persisted")
+	}
+	if _, ok := fs.msgs[fresh.ID]; !ok {
+		t.Error("message queued after the restart was removed")
+	}
+	if len(fs.msgs) != 3 {
+		t.Errorf("persisted %d messages, want 3", len(fs.msgs))
+	}
+
+	// A second resume in the same run must not requeue anything twice.
+	for _, m := range r.resend {
+		if err := after.done(ctx, m.ID); err != nil {
+			t.Fatalf("done() error: %v", err)
+		}
+	}
+	again, err := after.resume(ctx)
+	if err != nil {
+		t.Fatalf("resume() error: %v", err)
+	}
+	if len(again.resend)+len(again.stale)+len(again.attempted) != 0 {
+		t.Errorf("second resume = %+v, want nothing", again)
+	}
+}
+
+func TestOutboxRollingRestart(t *testing.T) {
+	ctx := t.Context()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fs := &fakeOutboxStore{}
+
+	old := newOutbox(fs, 10*time.Minute)
+	old.now = func() time.Time { return now }
+	old.started = now
+	now = now.Add(time.Minute)
+	sending, err := old.add(ctx, store.OutboxMessage{Phone: "919876543210", Text: "sending"})
+	if err != nil {
+		t.Fatalf("add() error: %v", err)
+	}
+	queued, err := old.add(ctx, store.OutboxMessage{Phone: "919876543210", Text: "queued"})
+	if err != nil {
+		t.Fatalf("add() error: %v", err)
+	}
+	if claimed, err := old.attempt(ctx, sending); err != nil || !claimed {
+		t.Fatalf("attempt() = %v, %v, want claimed", claimed, err)
+	}
+
+	// The new instance starts while the old one still has both queued.
+	now = now.Add(time.Minute)
+	next := newOutbox(fs, 10*time.Minute)
+	next.now = func() time.Time { return now }
+	next.started = now
+	r, err := next.resume(ctx)
+	if err != nil {
+		t.Fatalf("resume() error: %v", err)
+	}
+	if got := texts(r.resend); len(got) != 1 || got[0] != "queued" {
+		t.Errorf("resend = %v, want [queued]", got)
+	}
+	if got := texts(r.attempted); len(got) != 1 || got[0] != "sending" {
+		t.Errorf("attempted = %v, want [sending]", got)
+	}
+
+	// The old instance must now leave the resent message alone.
+	if claimed, err := old.attempt(ctx, queued); err != nil || claimed {
+		t.Errorf("old attempt() = %v, %v, want not claimed", claimed, err)
+	}
+	if claimed, err := next.attempt(ctx, r.resend[0]); err != nil || !claimed {
+		t.Errorf("new attempt() = %v, %v, want claimed", claimed, err)
+	}
+}
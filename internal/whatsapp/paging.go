@@ -0,0 +1,139 @@
+package whatsapp
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+// replyPager truncates long agent replies to a single page and keeps the
+// remainder per user so it can be served on the "more" command.
+type replyPager struct {
+	maxLen int
+	prompt string
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]pendingReply
+}
+
+type pendingReply struct {
+	rest    string
+	expires time.Time
+}
+
+func newReplyPager(maxLen int, prompt string, ttl time.Duration) *replyPager {
+	return &replyPager{
+		maxLen:  maxLen,
+		prompt:  prompt,
+		ttl:     ttl,
+		now:     time.Now,
+		pending: make(map[string]pendingReply),
+	}
+}
+
+// page returns the text to send for reply. If reply is too long, the first
+// page plus the continuation prompt is returned and the rest is kept for
+// user, replacing any older remainder.
+func (p *replyPager) page(user, reply string) string {
+	head, rest := splitReply(reply, p.maxLen)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if rest == "" {
+		delete(p.pending, user)
+		return head
+	}
+	p.pending[user] = pendingReply{rest: rest, expires: p.now().Add(p.ttl)}
+	p.prune()
+	return head + "\n\n" + p.prompt
+}
+
+// more returns the next page for user. ok is false if nothing is pending or
+// the remainder has expired.
+func (p *replyPager) more(user string) (text string, ok bool) {
+	p.mu.Lock()
+	pr, found := p.pending[user]
+	if found {
+		delete(p.pending, user)
+	}
+	p.mu.Unlock()
+
+	if !found || !p.now().Before(pr.expires) {
+		return "", false
+	}
+	return p.page(user, pr.rest), true
+}
+
+// prune drops expired remainders; callers hold p.mu.
+func (p *replyPager) prune() {
+	now := p.now()
+	for user, pr := range p.pending {
+		if !now.Before(pr.expires) {
+			delete(p.pending, user)
+		}
+	}
+}
+
+// splitReply cuts text to at most maxLen runes, preferring a paragraph,
+// line or word boundary in the second half of the page. rest is empty if
+// the text fits.
+func splitReply(text string, maxLen int) (head, rest string) {
+	if maxLen <= 0 || utf8.RuneCountInString(text) <= maxLen {
+		return text, ""
+	}
+
+	// Byte offset of the maxLen-th rune.
+	cut := 0
+	for i := range text {
+		if maxLen == 0 {
+			cut = i
+			break
+		}
+		maxLen--
+	}
+	window := text[:cut]
+
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if i := strings.LastIndex(window, sep); i > len(window)/2 {
+			return strings.TrimRight(window[:i], " \n"), strings.TrimLeft(text[i:], " \n")
+		}
+	}
+	return strings.TrimRight(window, " \n"), strings.TrimLeft(text[cut:], " \n")
+}
+
+// captionedMedia is a media part of an agent reply with the caption it is
+// sent with.
+type captionedMedia struct {
+	data    *agent.InlineData
+	caption string
+}
+
+// planReply groups the parts of one agent reply for sending. Text before
+// the first media part becomes its caption; all other text is joined into
+// a single body, so a long reply is paged once rather than once per part.
+func planReply(parts []agent.Part) (media []captionedMedia, body string) {
+	var caption, texts []string
+	for _, part := range parts {
+		switch {
+		case part.Text != "":
+			if len(media) == 0 {
+				caption = append(caption, part.Text)
+			} else {
+				texts = append(texts, part.Text)
+			}
+		case part.InlineData != nil:
+			media = append(media, captionedMedia{data: part.InlineData, caption: strings.Join(caption, "\n")})
+			caption = nil
+		}
+	}
+	if len(media) == 0 {
+		texts = caption
+	}
+	return media, strings.Join(texts, "\n")
+}
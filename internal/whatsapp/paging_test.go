@@ -0,0 +1,127 @@
+package whatsapp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+func TestSplitReply(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxLen   int
+		wantHead string
+		wantRest string
+	}{
+		{"fits", "short reply", 20, "short reply", ""},
+		{"disabled", "short reply", 0, "short reply", ""},
+		{"paragraph boundary", "first paragraph\n\nsecond one", 20, "first paragraph", "second one"},
+		{"line boundary", "line one here\nline two here", 20, "line one here", "line two here"},
+		{"word boundary", "the quick brown fox jumps", 12, "the quick", "brown fox jumps"},
+		{"hard cut", "abcdefghijklmnop", 5, "abcde", "fghijklmnop"},
+		{"multibyte", "नमस्ते दुनिया", 6, "नमस्ते", "दुनिया"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, rest := splitReply(tt.text, tt.maxLen)
+			if head != tt.wantHead || rest != tt.wantRest {
+				t.Errorf("splitReply() = (%q, %q), want (%q, %q)", head, rest, tt.wantHead, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestReplyPagerMore(t *testing.T) {
+	p := newReplyPager(10, `Reply "more" to continue.`, time.Hour)
+
+	first := p.page("919876543210", "aaaa bbbb cccc dddd eeee")
+	if !strings.HasPrefix(first, "aaaa bbbb\n\n") || !strings.HasSuffix(first, `Reply "more" to continue.`) {
+		t.Fatalf("unexpected first page: %q", first)
+	}
+
+	second, ok := p.more("919876543210")
+	if !ok || !strings.HasPrefix(second, "cccc dddd") || !strings.Contains(second, "more") {
+		t.Fatalf("unexpected second page: %q (ok=%v)", second, ok)
+	}
+
+	third, ok := p.more("919876543210")
+	if !ok || third != "eeee" {
+		t.Fatalf("unexpected last page: %q (ok=%v)", third, ok)
+	}
+
+	if _, ok := p.more("919876543210"); ok {
+		t.Error("expected nothing pending after the last page")
+	}
+	if _, ok := p.more("911111111111"); ok {
+		t.Error("expected nothing pending for another user")
+	}
+}
+
+func TestReplyPagerShortReplyClearsPending(t *testing.T) {
+	p := newReplyPager(10, "more?", time.Hour)
+	p.page("919876543210", "aaaa bbbb cccc dddd")
+
+	if got := p.page("919876543210", "short"); got != "short" {
+		t.Fatalf("page() = %q, want short reply unchanged", got)
+	}
+	if _, ok := p.more("919876543210"); ok {
+		t.Error("a new short reply should discard the old remainder")
+	}
+}
+
+func TestReplyPagerExpiry(t *testing.T) {
+	clock := time.Now()
+	p := newReplyPager(10, "more?", time.Hour)
+	p.now = func() time.Time { return clock }
+
+	p.page("919876543210", "aaaa bbbb cccc dddd")
+	clock = clock.Add(time.Hour)
+
+	if _, ok := p.more("919876543210"); ok {
+		t.Error("expected remainder to expire after the TTL")
+	}
+}
+
+func TestPlanReply(t *testing.T) {
+	img := &agent.InlineData{MimeType: "image/png"}
+	doc := &agent.InlineData{MimeType: "application/pdf"}
+
+	media, body := planReply([]agent.Part{
+		{Text: "chart"}, {Text: "of sales"}, {InlineData: img},
+		{Text: "first"}, {InlineData: doc}, {Text: "second"},
+	})
+	if len(media) != 2 || media[0].data != img || media[1].data != doc {
+		t.Fatalf("media = %+v, want image then document", media)
+	}
+	if media[0].caption != "chart\nof sales" || media[1].caption != "" {
+		t.Errorf("captions = %q, %q, want text before the first media only", media[0].caption, media[1].caption)
+	}
+	if body != "first\nsecond" {
+		t.Errorf("body = %q, want remaining text joined", body)
+	}
+
+	if media, body := planReply([]agent.Part{{Text: "a"}, {Text: "b"}}); len(media) != 0 || body != "a\nb" {
+		t.Errorf("text only = %+v, %q, want one body", media, body)
+	}
+}
+
+func TestReplyPagerKeepsAllTextParts(t *testing.T) {
+	p := newReplyPager(10, "more?", time.Hour)
+	_, body := planReply([]agent.Part{{Text: strings.Repeat("a", 15)}, {Text: strings.Repeat("b", 15)}})
+
+	p.page("alice", body)
+	var got strings.Builder
+	for {
+		next, ok := p.more("alice")
+		if !ok {
+			break
+		}
+		got.WriteString(strings.TrimSuffix(next, "\n\nmore?"))
+	}
+	if n := strings.Count(got.String(), "b"); n != 15 {
+		t.Errorf("later pages carry %d of 15 runes from the second part: %q", n, got.String())
+	}
+}
@@ -0,0 +1,86 @@
+package whatsapp
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// Built-in inbound transform steps, referenced by name in
+// whatsapp.inbound_pipeline.steps.
+const (
+	StepTrim               = "trim"
+	StepStripMention       = "strip_mention"
+	StepStripCommandPrefix = "strip_command_prefix"
+	StepMaxLength          = "max_length"
+)
+
+type inboundStep struct {
+	name  string
+	apply func(text string) string
+}
+
+// inboundPipeline is an ordered list of text transforms applied to every
+// inbound message before verification, AUTH and agent routing.
+type inboundPipeline []inboundStep
+
+func (p inboundPipeline) apply(text string) string {
+	for _, step := range p {
+		text = step.apply(text)
+	}
+	return text
+}
+
+// buildInboundPipeline resolves the configured step names. mentionNames is
+// called on every message because the bot's own number is only known
+// after login.
+func buildInboundPipeline(cfg config.InboundPipelineConfig, mentionNames func() []string) (inboundPipeline, error) {
+	pipeline := make(inboundPipeline, 0, len(cfg.Steps))
+	for _, name := range cfg.Steps {
+		var fn func(string) string
+		switch name {
+		case StepTrim:
+			fn = normalizeInbound
+		case StepStripMention:
+			fn = func(text string) string { return stripLeadingMention(text, mentionNames()) }
+		case StepStripCommandPrefix:
+			prefixes := cfg.CommandPrefixes
+			fn = func(text string) string { return stripCommandPrefix(text, prefixes) }
+		case StepMaxLength:
+			maxLen := cfg.MaxLength
+			fn = func(text string) string { return truncateRunes(text, maxLen) }
+		default:
+			return nil, fmt.Errorf("unknown inbound pipeline step %q", name)
+		}
+		pipeline = append(pipeline, inboundStep{name: name, apply: fn})
+	}
+	return pipeline, nil
+}
+
+// stripCommandPrefix removes the first matching prefix (e.g. "/ask") from
+// the start of text, case-insensitively and only on a word boundary.
+func stripCommandPrefix(text string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if prefix == "" || len(text) < len(prefix) || !strings.EqualFold(text[:len(prefix)], prefix) {
+			continue
+		}
+		rest := text[len(prefix):]
+		if rest != "" && rest[0] != ' ' && rest[0] != '\n' && rest[0] != '\t' {
+			continue
+		}
+		if rest = strings.TrimSpace(rest); rest != "" {
+			return rest
+		}
+	}
+	return text
+}
+
+// truncateRunes limits text to maxLen runes; maxLen <= 0 disables it.
+func truncateRunes(text string, maxLen int) string {
+	if maxLen <= 0 || utf8.RuneCountInString(text) <= maxLen {
+		return text
+	}
+	return string([]rune(text)[:maxLen])
+}
@@ -0,0 +1,106 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func botNames() []string { return []string{"@bot"} }
+
+func TestInboundPipeline(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.InboundPipelineConfig
+		in   string
+		want string
+	}{
+		{
+			name: "empty pipeline is identity",
+			cfg:  config.InboundPipelineConfig{},
+			in:   "  @bot hi\n",
+			want: "  @bot hi\n",
+		},
+		{
+			name: "default steps",
+			cfg:  config.InboundPipelineConfig{Steps: []string{StepTrim, StepStripMention}},
+			in:   "  @bot  what's the weather?\n",
+			want: "what's the weather?",
+		},
+		{
+			name: "command prefix before mention strips both",
+			cfg: config.InboundPipelineConfig{
+				Steps:           []string{StepTrim, StepStripCommandPrefix, StepStripMention},
+				CommandPrefixes: []string{"/ask"},
+			},
+			in:   "/ask @bot hi",
+			want: "hi",
+		},
+		{
+			name: "mention before command prefix leaves mention",
+			cfg: config.InboundPipelineConfig{
+				Steps:           []string{StepTrim, StepStripMention, StepStripCommandPrefix},
+				CommandPrefixes: []string{"/ask"},
+			},
+			in:   "/ask @bot hi",
+			want: "@bot hi",
+		},
+		{
+			name: "max length after mention strip",
+			cfg: config.InboundPipelineConfig{
+				Steps:     []string{StepStripMention, StepMaxLength},
+				MaxLength: 5,
+			},
+			in:   "@bot hello world",
+			want: "hello",
+		},
+		{
+			name: "max length before mention strip",
+			cfg: config.InboundPipelineConfig{
+				Steps:     []string{StepMaxLength, StepStripMention},
+				MaxLength: 5,
+			},
+			in:   "@bot hello world",
+			want: "@bot ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := buildInboundPipeline(tt.cfg, botNames)
+			if err != nil {
+				t.Fatalf("buildInboundPipeline() error: %v", err)
+			}
+			if got := p.apply(tt.in); got != tt.want {
+				t.Errorf("apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildInboundPipelineUnknownStep(t *testing.T) {
+	if _, err := buildInboundPipeline(config.InboundPipelineConfig{Steps: []string{StepTrim, "shout"}}, botNames); err == nil {
+		t.Fatal("expected error for unknown step")
+	}
+}
+
+func TestStripCommandPrefix(t *testing.T) {
+	prefixes := []string{"/ask", "!bot"}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"/ask what time is it", "what time is it"},
+		{"/ASK hi", "hi"},
+		{"!bot hi", "hi"},
+		{"/asking hi", "/asking hi"},
+		{"/ask", "/ask"},
+		{"hello /ask", "hello /ask"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := stripCommandPrefix(tt.in, prefixes); got != tt.want {
+				t.Errorf("stripCommandPrefix(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package whatsapp
+
+import (
+	"context"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"github.com/innomon/whatsadk/internal/config"
+	"github.com/innomon/whatsadk/internal/region"
+)
+
+// allowedCountry is the ISO country whose numbers pass the allowlist
+// fallback check.
+const allowedCountry = "IN"
+
+// authFlowPermitted reports whether a sender may use the verification and
+// AUTH flows under the given policy. isAllowed is only consulted for the
+// "allowed" policy, since it may need a network round-trip to resolve LIDs.
+func authFlowPermitted(policy string, isAllowed func() bool) bool {
+	if policy == config.AuthPolicyAllowed {
+		return isAllowed()
+	}
+	return true
+}
+
+// allowReason records which allowlist rule admitted a sender.
+type allowReason int
+
+const (
+	allowDenied allowReason = iota
+	allowAll
+	allowWhitelist
+	allowCountry
+	allowUnresolvedLID
+)
+
+// userAllowed applies the allowlist policy to a JID whose LID, if any, has
+// already been resolved. AllowAllUsers bypasses both the whitelist and the
+// country check; the blacklist is enforced separately and always applies.
+func userAllowed(cfg *config.Config, jid types.JID) bool {
+	return allowedBy(cfg, jid) != allowDenied
+}
+
+// allowedBy is userAllowed reporting which rule matched.
+func allowedBy(cfg *config.Config, jid types.JID) allowReason {
+	if cfg.WhatsApp.AllowAllUsers {
+		return allowAll
+	}
+	// Without a whitelist everyone is allowed.
+	if len(cfg.WhatsApp.WhitelistedUsers) == 0 {
+		return allowAll
+	}
+	if cfg.IsUserWhitelisted(jid.User) || cfg.IsUserWhitelisted(jid.String()) {
+		return allowWhitelist
+	}
+	if jid.Server == types.DefaultUserServer && region.Is(jid.User, allowedCountry) {
+		return allowCountry
+	}
+	// Unresolved LIDs carry no phone number to check, so they are let through.
+	if jid.Server == types.HiddenUserServer {
+		return allowUnresolvedLID
+	}
+	return allowDenied
+}
+
+// blacklisted reports whether any of ids is on the blacklist, stopping at the
+// first hit or lookup error.
+func blacklisted(ctx context.Context, lookup func(context.Context, string) (bool, error), ids ...string) (bool, error) {
+	for _, id := range ids {
+		blocked, err := lookup(ctx, id)
+		if err != nil || blocked {
+			return blocked, err
+		}
+	}
+	return false, nil
+}
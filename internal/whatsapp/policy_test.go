@@ -0,0 +1,127 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestAuthFlowPermitted(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		allowed   bool
+		want      bool
+		wantCheck bool
+	}{
+		{"any policy, non-allowed user", config.AuthPolicyAny, false, true, false},
+		{"empty policy defaults to any", "", false, true, false},
+		{"allowed policy, non-allowed user", config.AuthPolicyAllowed, false, false, true},
+		{"allowed policy, allowed user", config.AuthPolicyAllowed, true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checked := false
+			got := authFlowPermitted(tt.policy, func() bool {
+				checked = true
+				return tt.allowed
+			})
+			if got != tt.want {
+				t.Errorf("authFlowPermitted() = %v, want %v", got, tt.want)
+			}
+			if checked != tt.wantCheck {
+				t.Errorf("allowlist consulted = %v, want %v", checked, tt.wantCheck)
+			}
+		})
+	}
+}
+
+func TestUserAllowed(t *testing.T) {
+	pn := func(user string) types.JID { return types.NewJID(user, types.DefaultUserServer) }
+	whitelist := []string{"15550001111"}
+
+	tests := []struct {
+		name      string
+		allowAll  bool
+		whitelist []string
+		jid       types.JID
+		want      bool
+	}{
+		{"no whitelist allows everyone", false, nil, pn("15559998888"), true},
+		{"whitelisted user", false, whitelist, pn("15550001111"), true},
+		{"allowed country passes", false, whitelist, pn("919876543210"), true},
+		{"other country rejected", false, whitelist, pn("15559998888"), false},
+		{"allow all bypasses whitelist and country", true, whitelist, pn("15559998888"), true},
+		{"unresolved LID passes", false, whitelist, types.NewJID("123456", types.HiddenUserServer), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{WhatsApp: config.WhatsAppConfig{
+				AllowAllUsers:    tt.allowAll,
+				WhitelistedUsers: tt.whitelist,
+			}}
+			if got := userAllowed(cfg, tt.jid); got != tt.want {
+				t.Errorf("userAllowed(%s) = %v, want %v", tt.jid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowAllUsersStillBlacklisted(t *testing.T) {
+	cfg := &config.Config{WhatsApp: config.WhatsAppConfig{
+		AllowAllUsers:    true,
+		WhitelistedUsers: []string{"15550001111"},
+	}}
+	sender := types.NewJID("15559998888", types.DefaultUserServer)
+	blocked := map[string]bool{sender.String(): true}
+	lookup := func(_ context.Context, id string) (bool, error) { return blocked[id], nil }
+
+	if !userAllowed(cfg, sender) {
+		t.Fatal("allow_all_users should admit the sender")
+	}
+	got, err := blacklisted(context.Background(), lookup, sender.User, sender.String())
+	if err != nil {
+		t.Fatalf("blacklisted: %v", err)
+	}
+	if !got {
+		t.Error("blacklisted sender must stay blocked under allow_all_users")
+	}
+}
+
+func TestBlacklisted(t *testing.T) {
+	errDown := errors.New("store down")
+	tests := []struct {
+		name    string
+		blocked map[string]bool
+		err     error
+		want    bool
+	}{
+		{"no hit", map[string]bool{}, nil, false},
+		{"hit on second id", map[string]bool{"b": true}, nil, true},
+		{"lookup error", nil, errDown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookup := func(_ context.Context, id string) (bool, error) {
+				if tt.err != nil {
+					return false, tt.err
+				}
+				return tt.blocked[id], nil
+			}
+			got, err := blacklisted(context.Background(), lookup, "a", "b")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("blacklisted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
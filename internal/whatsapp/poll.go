@@ -0,0 +1,147 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"github.com/innomon/whatsadk/internal/agent"
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// WhatsApp's limits on poll options.
+const (
+	minPollOptions = 2
+	maxPollOptions = 12
+)
+
+// pollSpec is the JSON an agent sends, base64-encoded, in an
+// agent.MimeTypePoll part.
+type pollSpec struct {
+	Name    string   `json:"name"`
+	Options []string `json:"options"`
+	// Selectable is how many options a user may pick; 0 allows any number.
+	// Defaults to 1 when omitted.
+	Selectable *int `json:"selectable,omitempty"`
+}
+
+// splitPollSpecs removes poll parts from parts and decodes them. Invalid
+// polls are dropped and reported in errs.
+func splitPollSpecs(parts []agent.Part) (polls []pollSpec, rest []agent.Part, errs []error) {
+	for _, part := range parts {
+		if part.InlineData == nil || part.InlineData.MimeType != agent.MimeTypePoll {
+			rest = append(rest, part)
+			continue
+		}
+		spec, err := decodePollSpec(part.InlineData.Data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		polls = append(polls, spec)
+	}
+	return polls, rest, errs
+}
+
+func decodePollSpec(data string) (pollSpec, error) {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return pollSpec{}, fmt.Errorf("decode poll: %w", err)
+	}
+	var spec pollSpec
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return pollSpec{}, fmt.Errorf("decode poll: %w", err)
+	}
+	if strings.TrimSpace(spec.Name) == "" {
+		return pollSpec{}, fmt.Errorf("poll has no name")
+	}
+	if n := len(spec.Options); n < minPollOptions || n > maxPollOptions {
+		return pollSpec{}, fmt.Errorf("poll %q has %d options (want %d to %d)", spec.Name, n, minPollOptions, maxPollOptions)
+	}
+	seen := make(map[string]bool, len(spec.Options))
+	for _, o := range spec.Options {
+		if strings.TrimSpace(o) == "" || seen[o] {
+			return pollSpec{}, fmt.Errorf("poll %q has an empty or duplicate option", spec.Name)
+		}
+		seen[o] = true
+	}
+	return spec, nil
+}
+
+// selectable returns the number of options a user may pick.
+func (p pollSpec) selectable() int {
+	if p.Selectable == nil {
+		return 1
+	}
+	return *p.Selectable
+}
+
+// pollVoteText describes a vote on poll for the agent. Votes carry SHA-256
+// hashes of the chosen option names; an empty selection is a retracted vote.
+func pollVoteText(poll store.Poll, selected [][]byte) string {
+	chosen := make(map[string]bool, len(selected))
+	for _, h := range selected {
+		chosen[string(h)] = true
+	}
+	var names []string
+	for i, h := range whatsmeow.HashPollOptions(poll.Options) {
+		if chosen[string(h)] {
+			names = append(names, poll.Options[i])
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Sprintf("Poll %q: vote withdrawn", poll.Name)
+	}
+	return fmt.Sprintf("Poll %q: voted for %s", poll.Name, strings.Join(names, ", "))
+}
+
+// sendPoll sends spec as a native poll and remembers its options so votes
+// can be read back.
+func (c *Client) sendPoll(ctx context.Context, chat types.JID, userID string, spec pollSpec) {
+	resp, err := c.wac.SendMessage(ctx, chat, c.wac.BuildPollCreation(spec.Name, spec.Options, spec.selectable()))
+	if err != nil {
+		c.log.Errorf("Failed to send poll %q to %s: %v", spec.Name, userID, err)
+		return
+	}
+	c.countUsage(userID, usageOutbound)
+	if c.store == nil {
+		c.log.Warnf("No gateway store, votes on poll %q from %s will not reach the agent", spec.Name, userID)
+		return
+	}
+	poll := store.Poll{ID: resp.ID, Phone: userID, Name: spec.Name, Options: spec.Options, CreatedAt: time.Now().UTC()}
+	if err := c.store.PutPoll(ctx, poll); err != nil {
+		c.log.Errorf("Failed to store poll %q for %s: %v", spec.Name, userID, err)
+	}
+}
+
+// pollVote returns the text forwarded to the agent for a poll vote, or ""
+// if msg is not a vote on one of the gateway's polls.
+func (c *Client) pollVote(ctx context.Context, msg *events.Message) string {
+	update := msg.Message.GetPollUpdateMessage()
+	if update == nil || c.store == nil {
+		return ""
+	}
+	pollID := update.GetPollCreationMessageKey().GetID()
+	poll, err := c.store.GetPoll(ctx, pollID)
+	if err != nil {
+		c.log.Errorf("Failed to look up poll %s: %v", pollID, err)
+		return ""
+	}
+	if poll == nil {
+		c.log.Infof("Ignoring vote on unknown poll %s", pollID)
+		return ""
+	}
+	vote, err := c.wac.DecryptPollVote(ctx, msg)
+	if err != nil {
+		c.log.Errorf("Failed to decrypt vote on poll %s: %v", pollID, err)
+		return ""
+	}
+	return pollVoteText(*poll, vote.GetSelectedOptions())
+}
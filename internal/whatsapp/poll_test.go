@@ -0,0 +1,60 @@
+package whatsapp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"go.mau.fi/whatsmeow"
+
+	"github.com/innomon/whatsadk/internal/agent"
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+func pollPart(t *testing.T, spec any) agent.Part {
+	t.Helper()
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("marshal poll: %v", err)
+	}
+	return agent.Part{InlineData: &agent.InlineData{MimeType: agent.MimeTypePoll, Data: base64.StdEncoding.EncodeToString(raw)}}
+}
+
+func TestSplitPollSpecs(t *testing.T) {
+	two := 2
+	parts := []agent.Part{
+		{Text: "Quick question:"},
+		pollPart(t, pollSpec{Name: "Size?", Options: []string{"S", "M", "L"}}),
+		pollPart(t, pollSpec{Name: "Toppings?", Options: []string{"Paneer", "Onion", "Corn"}, Selectable: &two}),
+		pollPart(t, pollSpec{Name: "Too few", Options: []string{"Yes"}}),
+		pollPart(t, pollSpec{Name: "Dupes", Options: []string{"A", "A"}}),
+		pollPart(t, pollSpec{Options: []string{"A", "B"}}),
+		{InlineData: &agent.InlineData{MimeType: agent.MimeTypePoll, Data: "%%%"}},
+	}
+
+	polls, rest, errs := splitPollSpecs(parts)
+	if len(polls) != 2 || polls[0].Name != "Size?" || polls[1].Name != "Toppings?" {
+		t.Fatalf("polls = %+v", polls)
+	}
+	if polls[0].selectable() != 1 || polls[1].selectable() != 2 {
+		t.Errorf("selectable = %d, %d", polls[0].selectable(), polls[1].selectable())
+	}
+	if len(rest) != 1 || rest[0].Text != "Quick question:" {
+		t.Errorf("rest = %+v", rest)
+	}
+	if len(errs) != 4 {
+		t.Errorf("errs = %v, want 4", errs)
+	}
+}
+
+func TestPollVoteText(t *testing.T) {
+	poll := store.Poll{Name: "Toppings?", Options: []string{"Paneer", "Onion", "Corn"}}
+
+	got := pollVoteText(poll, whatsmeow.HashPollOptions([]string{"Corn", "Paneer"}))
+	if want := `Poll "Toppings?": voted for Paneer, Corn`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := pollVoteText(poll, nil); got != `Poll "Toppings?": vote withdrawn` {
+		t.Errorf("withdrawn = %q", got)
+	}
+}
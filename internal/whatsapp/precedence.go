@@ -0,0 +1,55 @@
+package whatsapp
+
+import (
+	"strings"
+
+	"github.com/innomon/whatsadk/internal/auth"
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// authFlow is the single handler chosen for an inbound message.
+type authFlow int
+
+const (
+	flowAgent authFlow = iota
+	flowVerify
+	flowAuth
+)
+
+// classifyAuthFlow picks exactly one handler for text and returns the text
+// that handler should see. A message that is a verification token goes to
+// verification and an AUTH command to the OAuth handler. An AUTH command
+// that also carries a verification token as one of its words matches both;
+// precedence (config.AuthPrecedence*) decides, and verification gets just
+// the token.
+func classifyAuthFlow(text string, verify, oauth bool, precedence string) (authFlow, string) {
+	isAuth := oauth && auth.IsAuthCommand(text)
+	token := ""
+	if verify {
+		if auth.IsVerificationToken(text) != nil {
+			token = text
+		} else if isAuth {
+			token = embeddedVerificationToken(text)
+		}
+	}
+	switch {
+	case token != "" && isAuth && precedence == config.AuthPrecedenceAuth:
+		return flowAuth, text
+	case token != "":
+		return flowVerify, token
+	case isAuth:
+		return flowAuth, text
+	}
+	return flowAgent, text
+}
+
+// embeddedVerificationToken returns the first word of text that parses as
+// a verification token, or "".
+func embeddedVerificationToken(text string) string {
+	for _, word := range strings.Fields(text) {
+		if auth.IsVerificationToken(word) != nil {
+			return word
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestClassifyAuthFlow(t *testing.T) {
+	token := signDocumentTestToken(t)
+	authCmd := "AUTH v1 nonce-123 app-1"
+	mixed := "AUTH v1 " + token + " app-1"
+
+	tests := []struct {
+		name       string
+		text       string
+		verify     bool
+		oauth      bool
+		precedence string
+		want       authFlow
+		wantText   string
+	}{
+		{"plain text", "hello", true, true, config.AuthPrecedenceVerification, flowAgent, "hello"},
+		{"token only", token, true, true, config.AuthPrecedenceVerification, flowVerify, token},
+		{"auth only", authCmd, true, true, config.AuthPrecedenceVerification, flowAuth, authCmd},
+		{"both, verification first", mixed, true, true, config.AuthPrecedenceVerification, flowVerify, token},
+		{"both, auth first", mixed, true, true, config.AuthPrecedenceAuth, flowAuth, mixed},
+		{"both, verification disabled", mixed, false, true, config.AuthPrecedenceVerification, flowAuth, mixed},
+		{"both, auth disabled", mixed, true, false, config.AuthPrecedenceAuth, flowAgent, mixed},
+		{"token inside ordinary text", "my token " + token, true, true, config.AuthPrecedenceVerification, flowAgent, "my token " + token},
+		{"token, auth first", token, true, true, config.AuthPrecedenceAuth, flowVerify, token},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotText := classifyAuthFlow(tt.text, tt.verify, tt.oauth, tt.precedence)
+			if got != tt.want || gotText != tt.wantText {
+				t.Errorf("classifyAuthFlow() = (%v, %q), want (%v, %q)", got, gotText, tt.want, tt.wantText)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package whatsapp
+
+import (
+	"context"
+)
+
+// profileState picks the attributes listed in mapping out of profile and
+// renames them to their session state keys. It returns nil when nothing
+// is forwarded.
+func profileState(profile map[string]any, mapping map[string]string) map[string]any {
+	if len(profile) == 0 || len(mapping) == 0 {
+		return nil
+	}
+
+	state := make(map[string]any)
+	for attr, key := range mapping {
+		value, ok := profile[attr]
+		if !ok || value == nil {
+			continue
+		}
+		if key == "" {
+			key = attr
+		}
+		state[key] = value
+	}
+	if len(state) == 0 {
+		return nil
+	}
+	return state
+}
+
+// profileStateFor loads the stored profile for userID and maps it to the
+// configured ADK state keys. Lookup failures are logged and ignored so
+// enrichment never blocks a reply.
+func (c *Client) profileStateFor(ctx context.Context, userID string) map[string]any {
+	if c.store == nil || len(c.cfg.ADK.ProfileState) == 0 {
+		return nil
+	}
+
+	profile, err := c.store.UserProfile(ctx, userID)
+	if err != nil {
+		c.log.Warnf("Failed to load profile for %s: %v", userID, err)
+		return nil
+	}
+	return profileState(profile, c.cfg.ADK.ProfileState)
+}
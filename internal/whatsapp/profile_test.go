@@ -0,0 +1,62 @@
+package whatsapp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProfileState(t *testing.T) {
+	profile := map[string]any{
+		"name":      "Asha",
+		"tier":      "gold",
+		"ticket_id": "T-42",
+		"internal":  "secret",
+		"empty":     nil,
+	}
+
+	tests := []struct {
+		name    string
+		profile map[string]any
+		mapping map[string]string
+		want    map[string]any
+	}{
+		{
+			name:    "configured fields forwarded",
+			profile: profile,
+			mapping: map[string]string{"name": "user_name", "tier": "user_tier"},
+			want:    map[string]any{"user_name": "Asha", "user_tier": "gold"},
+		},
+		{
+			name:    "empty key keeps attribute name",
+			profile: profile,
+			mapping: map[string]string{"ticket_id": ""},
+			want:    map[string]any{"ticket_id": "T-42"},
+		},
+		{
+			name:    "missing and nil attributes skipped",
+			profile: profile,
+			mapping: map[string]string{"email": "user_email", "empty": "user_empty"},
+			want:    nil,
+		},
+		{
+			name:    "no mapping",
+			profile: profile,
+			mapping: nil,
+			want:    nil,
+		},
+		{
+			name:    "no profile",
+			profile: nil,
+			mapping: map[string]string{"name": "user_name"},
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := profileState(tt.profile, tt.mapping)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("profileState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
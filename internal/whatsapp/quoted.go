@@ -0,0 +1,67 @@
+package whatsapp
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+// quotedText returns the text of the message msg replies to: its body or
+// caption, or a placeholder such as "[image]" for media without one. It
+// returns "" when msg is not a reply or WhatsApp sent no copy of the quote.
+func quotedText(msg *waE2E.Message) string {
+	if msg == nil {
+		return ""
+	}
+	for _, ci := range contextInfos(msg) {
+		q := ci.GetQuotedMessage()
+		if q == nil {
+			continue
+		}
+		switch {
+		case q.GetConversation() != "":
+			return q.GetConversation()
+		case q.GetExtendedTextMessage().GetText() != "":
+			return q.GetExtendedTextMessage().GetText()
+		case q.GetImageMessage() != nil:
+			return captionOr(q.GetImageMessage().GetCaption(), "[image]")
+		case q.GetVideoMessage() != nil:
+			return captionOr(q.GetVideoMessage().GetCaption(), "[video]")
+		case q.GetDocumentMessage() != nil:
+			return captionOr(q.GetDocumentMessage().GetCaption(), "[document]")
+		case q.GetAudioMessage() != nil:
+			return "[audio]"
+		case q.GetStickerMessage() != nil:
+			return "[sticker]"
+		case q.GetPollCreationMessage() != nil:
+			return "[poll] " + q.GetPollCreationMessage().GetName()
+		}
+	}
+	return ""
+}
+
+func captionOr(caption, placeholder string) string {
+	if caption != "" {
+		return caption
+	}
+	return placeholder
+}
+
+// withQuotedContext prepends the quoted message, introduced by prefix and
+// cut to maxChars characters (0 for no limit), as a separate text part.
+func withQuotedContext(parts []agent.Part, quoted, prefix string, maxChars int) []agent.Part {
+	quoted = strings.TrimSpace(quoted)
+	if quoted == "" {
+		return parts
+	}
+	if maxChars > 0 && utf8.RuneCountInString(quoted) > maxChars {
+		quoted = string([]rune(quoted)[:maxChars]) + "…"
+	}
+	if prefix != "" {
+		quoted = prefix + "\n" + quoted
+	}
+	return append([]agent.Part{{Text: quoted}}, parts...)
+}
@@ -0,0 +1,63 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+func TestQuotedText(t *testing.T) {
+	reply := func(quoted *waE2E.Message) *waE2E.Message {
+		return &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text:        proto.String("what about this?"),
+			ContextInfo: &waE2E.ContextInfo{StanzaID: proto.String("BOT1"), QuotedMessage: quoted},
+		}}
+	}
+	tests := []struct {
+		name string
+		msg  *waE2E.Message
+		want string
+	}{
+		{"nil", nil, ""},
+		{"not a reply", &waE2E.Message{Conversation: proto.String("hi")}, ""},
+		{"reply without copy", &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text: proto.String("?"), ContextInfo: &waE2E.ContextInfo{StanzaID: proto.String("BOT1")},
+		}}, ""},
+		{"conversation", reply(&waE2E.Message{Conversation: proto.String("Your order ships Monday.")}), "Your order ships Monday."},
+		{"extended text", reply(&waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{Text: proto.String("Plan A or B?")}}), "Plan A or B?"},
+		{"captioned image", reply(&waE2E.Message{ImageMessage: &waE2E.ImageMessage{Caption: proto.String("Menu")}}), "Menu"},
+		{"bare image", reply(&waE2E.Message{ImageMessage: &waE2E.ImageMessage{}}), "[image]"},
+		{"poll", reply(&waE2E.Message{PollCreationMessage: &waE2E.PollCreationMessage{Name: proto.String("Size?")}}), "[poll] Size?"},
+		{"image reply", &waE2E.Message{ImageMessage: &waE2E.ImageMessage{
+			ContextInfo: &waE2E.ContextInfo{QuotedMessage: &waE2E.Message{Conversation: proto.String("Send a photo")}},
+		}}, "Send a photo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quotedText(tt.msg); got != tt.want {
+				t.Errorf("quotedText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithQuotedContext(t *testing.T) {
+	parts := []agent.Part{{Text: "what about this?"}}
+
+	got := withQuotedContext(parts, "Your order ships Monday.", "Replying to:", 0)
+	if len(got) != 2 || got[0].Text != "Replying to:\nYour order ships Monday." || got[1].Text != "what about this?" {
+		t.Errorf("with prefix = %+v", got)
+	}
+
+	got = withQuotedContext(parts, "abcdef", "", 3)
+	if len(got) != 2 || got[0].Text != "abc…" {
+		t.Errorf("truncated = %+v", got)
+	}
+
+	if got := withQuotedContext(parts, " ", "Replying to:", 0); len(got) != 1 {
+		t.Errorf("empty quote = %+v", got)
+	}
+}
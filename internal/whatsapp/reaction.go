@@ -0,0 +1,90 @@
+package whatsapp
+
+import (
+	"context"
+	"strings"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types/events"
+	waLog "go.mau.fi/whatsmeow/util/log"
+
+	"github.com/innomon/whatsadk/internal/config"
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// reactionOf returns the reaction carried by m. onReply reports whether
+// the reacted-to message was sent by the other side of the chat, i.e. by
+// the gateway for a reaction received from a user.
+func reactionOf(m *waE2E.Message) (r store.Reaction, ok bool) {
+	rm := m.GetReactionMessage()
+	if rm == nil || rm.GetKey().GetID() == "" {
+		return store.Reaction{}, false
+	}
+	return store.Reaction{
+		MessageID: rm.GetKey().GetID(),
+		Emoji:     rm.GetText(),
+		OnReply:   !rm.GetKey().GetFromMe(),
+	}, true
+}
+
+// reactionHandler intercepts emoji reactions so they are never treated as
+// empty user messages. They are recorded for satisfaction tracking and,
+// when configured, reactions to the gateway's replies are forwarded to the
+// agent as feedback.
+type reactionHandler struct {
+	cfg    config.ReactionsConfig
+	record func(ctx context.Context, r store.Reaction) error
+	log    waLog.Logger
+}
+
+// handle reports whether msg was a reaction. feedback, when not empty, is
+// the text to forward to the agent in its place.
+func (h *reactionHandler) handle(ctx context.Context, msg *events.Message) (feedback string, handled bool) {
+	r, ok := reactionOf(msg.Message)
+	if !ok {
+		return "", false
+	}
+	if msg.Info.IsFromMe {
+		return "", true
+	}
+	r.Phone = msg.Info.Sender.User
+	r.ReactedAt = msg.Info.Timestamp
+
+	h.log.Infof("User %s reacted %q to message %s", msg.Info.Sender.String(), r.Emoji, r.MessageID)
+	if h.cfg.Record && h.record != nil {
+		if err := h.record(ctx, r); err != nil {
+			h.log.Errorf("Failed to record reaction to %s: %v", r.MessageID, err)
+		}
+	}
+	return reactionFeedback(h.cfg, r), true
+}
+
+// reactionFeedback returns the text forwarded to the agent for r, or "" if
+// it should not be forwarded: removals, reactions to the user's own
+// messages and emojis outside forward_emojis.
+func reactionFeedback(cfg config.ReactionsConfig, r store.Reaction) string {
+	if !cfg.Forward || r.Emoji == "" || !r.OnReply {
+		return ""
+	}
+	if len(cfg.ForwardEmojis) > 0 {
+		allowed := false
+		for _, e := range cfg.ForwardEmojis {
+			if e == r.Emoji {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return ""
+		}
+	}
+	return strings.ReplaceAll(cfg.ForwardText, "{emoji}", r.Emoji)
+}
+
+// recordReaction stores a reaction in the gateway store.
+func (c *Client) recordReaction(ctx context.Context, r store.Reaction) error {
+	if c.store == nil {
+		return nil
+	}
+	return c.store.PutReaction(ctx, r)
+}
@@ -0,0 +1,60 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/config"
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+func TestReactionOf(t *testing.T) {
+	react := func(id string, fromMe bool, emoji string) *waE2E.Message {
+		return &waE2E.Message{ReactionMessage: &waE2E.ReactionMessage{
+			Key:  &waCommon.MessageKey{ID: proto.String(id), FromMe: proto.Bool(fromMe)},
+			Text: proto.String(emoji),
+		}}
+	}
+
+	r, ok := reactionOf(react("BOT1", false, "👍"))
+	if !ok || r.MessageID != "BOT1" || r.Emoji != "👍" || !r.OnReply {
+		t.Errorf("reaction to reply = %+v, %v", r, ok)
+	}
+	r, ok = reactionOf(react("USER1", true, ""))
+	if !ok || r.OnReply || r.Emoji != "" {
+		t.Errorf("removed reaction to own message = %+v, %v", r, ok)
+	}
+	if _, ok := reactionOf(&waE2E.Message{Conversation: proto.String("hi")}); ok {
+		t.Error("text message reported as reaction")
+	}
+	if _, ok := reactionOf(react("", false, "👍")); ok {
+		t.Error("reaction without target reported")
+	}
+}
+
+func TestReactionFeedback(t *testing.T) {
+	cfg := config.ReactionsConfig{Forward: true, ForwardEmojis: []string{"👍", "👎"}, ForwardText: "Feedback: {emoji}"}
+	tests := []struct {
+		name string
+		cfg  config.ReactionsConfig
+		r    store.Reaction
+		want string
+	}{
+		{"thumbs up on reply", cfg, store.Reaction{Emoji: "👍", OnReply: true}, "Feedback: 👍"},
+		{"emoji not listed", cfg, store.Reaction{Emoji: "😂", OnReply: true}, ""},
+		{"removed", cfg, store.Reaction{OnReply: true}, ""},
+		{"own message", cfg, store.Reaction{Emoji: "👍"}, ""},
+		{"forwarding off", config.ReactionsConfig{ForwardText: "x"}, store.Reaction{Emoji: "👍", OnReply: true}, ""},
+		{"any emoji", config.ReactionsConfig{Forward: true, ForwardText: "{emoji}"}, store.Reaction{Emoji: "😂", OnReply: true}, "😂"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reactionFeedback(tt.cfg, tt.r); got != tt.want {
+				t.Errorf("reactionFeedback() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// markRead sends a read receipt (blue ticks) for msg.
+func (c *Client) markRead(ctx context.Context, msg *events.Message) {
+	err := c.wac.MarkRead(ctx, []types.MessageID{msg.Info.ID}, time.Now(), msg.Info.Chat, msg.Info.Sender)
+	if err != nil {
+		c.log.Warnf("Failed to mark message %s as read: %v", msg.Info.ID, err)
+	}
+}
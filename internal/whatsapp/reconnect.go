@@ -0,0 +1,101 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	reconnectCheckInterval = time.Minute
+	reconnectDrainTimeout  = 2 * time.Minute
+	reconnectDrainPoll     = 500 * time.Millisecond
+
+	reconnectRetryBackoff    = 5 * time.Second
+	reconnectRetryMaxBackoff = 5 * time.Minute
+)
+
+// reconnectDue reports whether a connection established at connectedAt has
+// reached maxAge. A zero connectedAt means we are not connected.
+func reconnectDue(maxAge time.Duration, connectedAt, now time.Time) bool {
+	if maxAge <= 0 || connectedAt.IsZero() {
+		return false
+	}
+	return now.Sub(connectedAt) >= maxAge
+}
+
+// waitForDrain polls inflight until it reaches zero, returning false if the
+// timeout or ctx expires first.
+func waitForDrain(ctx context.Context, inflight func() int64, poll, timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		if inflight() == 0 {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-deadline.C:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// connectWithRetry calls connect until it succeeds or ctx ends, waiting
+// backoff after the first failure and doubling the wait up to maxBackoff.
+// onErr is told about each failure and the wait before the next attempt.
+func connectWithRetry(ctx context.Context, connect func() error, backoff, maxBackoff time.Duration, onErr func(err error, wait time.Duration)) error {
+	for {
+		err := connect()
+		if err == nil {
+			return nil
+		}
+		onErr(err, backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// reconnectLoop proactively recycles the WhatsApp connection once it is
+// older than maxAge. This avoids surprise drops on networks where NATs or
+// load balancers silently kill long-lived connections.
+func (c *Client) reconnectLoop(ctx context.Context, maxAge time.Duration) {
+	ticker := time.NewTicker(reconnectCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			connectedAt := c.connectedAt.Load()
+			if connectedAt == 0 || !reconnectDue(maxAge, time.Unix(0, connectedAt), time.Now()) {
+				continue
+			}
+			if !waitForDrain(ctx, c.inflight.Load, reconnectDrainPoll, reconnectDrainTimeout) {
+				c.log.Infof("Scheduled reconnect postponed: messages still in flight")
+				continue
+			}
+
+			c.log.Infof("Connection older than %s, reconnecting", maxAge)
+			c.connectedAt.Store(0)
+			c.wac.Disconnect()
+			// whatsmeow does not reconnect by itself after an explicit
+			// Disconnect, so keep trying until we are back online.
+			err := connectWithRetry(ctx, c.wac.Connect, reconnectRetryBackoff, reconnectRetryMaxBackoff, func(err error, wait time.Duration) {
+				c.log.Errorf("Scheduled reconnect failed, retrying in %s: %v", wait, err)
+			})
+			if err != nil {
+				return
+			}
+		}
+	}
+}
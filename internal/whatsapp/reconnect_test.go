@@ -0,0 +1,104 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReconnectDue(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		maxAge      time.Duration
+		connectedAt time.Time
+		want        bool
+	}{
+		{"disabled", 0, now.Add(-time.Hour), false},
+		{"not connected", time.Hour, time.Time{}, false},
+		{"young connection", time.Hour, now.Add(-30 * time.Minute), false},
+		{"exactly max age", time.Hour, now.Add(-time.Hour), true},
+		{"old connection", time.Hour, now.Add(-2 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reconnectDue(tt.maxAge, tt.connectedAt, now); got != tt.want {
+				t.Errorf("reconnectDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForDrain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("idle", func(t *testing.T) {
+		if !waitForDrain(ctx, func() int64 { return 0 }, time.Millisecond, time.Second) {
+			t.Error("expected immediate drain when nothing is in flight")
+		}
+	})
+
+	t.Run("drains", func(t *testing.T) {
+		var inflight atomic.Int64
+		inflight.Store(1)
+		time.AfterFunc(20*time.Millisecond, func() { inflight.Store(0) })
+		if !waitForDrain(ctx, inflight.Load, time.Millisecond, time.Second) {
+			t.Error("expected drain once handler finished")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		if waitForDrain(ctx, func() int64 { return 1 }, time.Millisecond, 20*time.Millisecond) {
+			t.Error("expected timeout while a handler is stuck")
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		if waitForDrain(cctx, func() int64 { return 1 }, time.Millisecond, time.Second) {
+			t.Error("expected false on cancelled context")
+		}
+	})
+}
+
+func TestConnectWithRetry(t *testing.T) {
+	t.Run("retries until connected", func(t *testing.T) {
+		var attempts int
+		var waits []time.Duration
+		connect := func() error {
+			attempts++
+			if attempts < 4 {
+				return errors.New("connection refused")
+			}
+			return nil
+		}
+		err := connectWithRetry(context.Background(), connect, time.Millisecond, 3*time.Millisecond, func(_ error, wait time.Duration) {
+			waits = append(waits, wait)
+		})
+		if err != nil || attempts != 4 {
+			t.Fatalf("connectWithRetry() = %v after %d attempts, want success on the 4th", err, attempts)
+		}
+		want := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}
+		if !slices.Equal(waits, want) {
+			t.Errorf("waits = %v, want %v", waits, want)
+		}
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var attempts int
+		connect := func() error {
+			if attempts++; attempts == 2 {
+				cancel()
+			}
+			return errors.New("connection refused")
+		}
+		if err := connectWithRetry(ctx, connect, time.Millisecond, time.Millisecond, func(error, time.Duration) {}); !errors.Is(err, context.Canceled) {
+			t.Errorf("connectWithRetry() = %v, want context.Canceled", err)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mdp/qrterminal/v3"
+)
+
+// ConnectionState describes the WhatsApp connection.
+type ConnectionState struct {
+	Connected bool
+	// LoggedIn is false without a paired session.
+	LoggedIn bool
+	// JID is the bot's own JID, empty before pairing.
+	JID string
+	// ConnectedAt is zero while disconnected.
+	ConnectedAt time.Time
+}
+
+// ConnectionState reports the current connection state.
+func (c *Client) ConnectionState() ConnectionState {
+	st := ConnectionState{Connected: c.wac.IsConnected(), LoggedIn: c.wac.IsLoggedIn()}
+	if c.wac.Store.ID != nil {
+		st.JID = c.wac.Store.ID.ToNonAD().String()
+	}
+	if at := c.connectedAt.Load(); at != 0 && st.Connected {
+		st.ConnectedAt = time.Unix(0, at)
+	}
+	return st
+}
+
+// printQR shows a pairing QR code on the terminal.
+func printQR(code string) {
+	fmt.Println("\n📱 Scan this QR code with WhatsApp:")
+	qrterminal.GenerateHalfBlock(code, qrterminal.L, os.Stdout)
+	fmt.Println()
+}
+
+// Relogin drops the connection and logs in again without a restart. With
+// a stored session it simply reconnects and returns "". Without one, e.g.
+// after the device was logged out from the phone, it starts pairing and
+// returns the first QR code; later codes are printed like at startup.
+func (c *Client) Relogin(ctx context.Context) (string, error) {
+	c.log.Infof("Re-login requested")
+	c.connectedAt.Store(0)
+	c.wac.Disconnect()
+	if c.wac.Store.ID != nil {
+		if err := c.wac.Connect(); err != nil {
+			return "", fmt.Errorf("failed to reconnect: %w", err)
+		}
+		return "", nil
+	}
+
+	// Pairing outlives the request that started it.
+	qrChan, err := c.wac.GetQRChannel(context.WithoutCancel(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to get QR channel: %w", err)
+	}
+	if err := c.wac.Connect(); err != nil {
+		return "", fmt.Errorf("failed to connect: %w", err)
+	}
+	first := make(chan string, 1)
+	go func() {
+		defer close(first)
+		sent := false
+		for evt := range qrChan {
+			switch evt.Event {
+			case "code":
+				printQR(evt.Code)
+				if !sent {
+					first <- evt.Code
+					sent = true
+				}
+			case "success":
+				c.log.Infof("Paired with WhatsApp")
+			default:
+				c.log.Warnf("Pairing ended: %s (%v)", evt.Event, evt.Error)
+			}
+		}
+	}()
+	select {
+	case code, ok := <-first:
+		if !ok {
+			return "", fmt.Errorf("pairing ended before a QR code was issued")
+		}
+		return code, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,82 @@
+package whatsapp
+
+import (
+	"sync"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxReplyTargets bounds how many unanswered inbound messages are kept for
+// quoting.
+const maxReplyTargets = 1000
+
+// replyTarget is an inbound message a reply can quote.
+type replyTarget struct {
+	sender  types.JID
+	message *waE2E.Message
+}
+
+// replyTargets remembers recent inbound messages by ID, so the first reply
+// sent for one can quote it. Each target is quoted once; the oldest are
+// forgotten when the set is full.
+type replyTargets struct {
+	mu      sync.Mutex
+	max     int
+	order   []string
+	targets map[string]replyTarget
+}
+
+func newReplyTargets(max int) *replyTargets {
+	return &replyTargets{max: max, targets: make(map[string]replyTarget)}
+}
+
+// remember records the message id from sender as a quote target.
+func (r *replyTargets) remember(id string, sender types.JID, message *waE2E.Message) {
+	if id == "" || message == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.targets[id]; !ok {
+		r.order = append(r.order, id)
+	}
+	r.targets[id] = replyTarget{sender: sender, message: message}
+	for len(r.order) > r.max {
+		delete(r.targets, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+// take returns the ContextInfo quoting message id and forgets it, or nil
+// if id is not a remembered message.
+func (r *replyTargets) take(id string) *waE2E.ContextInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.targets[id]
+	if !ok {
+		return nil
+	}
+	delete(r.targets, id)
+	for i, o := range r.order {
+		if o == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return &waE2E.ContextInfo{
+		StanzaID:      proto.String(id),
+		Participant:   proto.String(t.sender.ToNonAD().String()),
+		QuotedMessage: t.message,
+	}
+}
+
+// quoteContext returns the ContextInfo for a reply to msgRef when replies
+// are quoted, or nil.
+func (c *Client) quoteContext(msgRef string) *waE2E.ContextInfo {
+	if c.quotes == nil {
+		return nil
+	}
+	return c.quotes.take(msgRef)
+}
@@ -0,0 +1,42 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReplyTargets(t *testing.T) {
+	sender := types.NewJID("919876543210", types.DefaultUserServer)
+	sender.Device = 3
+	msg := &waE2E.Message{Conversation: proto.String("What are your hours?")}
+
+	r := newReplyTargets(2)
+	r.remember("A", sender, msg)
+
+	ci := r.take("A")
+	if ci == nil {
+		t.Fatal("take(A) = nil")
+	}
+	if ci.GetStanzaID() != "A" || ci.GetParticipant() != "919876543210@s.whatsapp.net" || ci.GetQuotedMessage().GetConversation() != "What are your hours?" {
+		t.Errorf("context info = %v", ci)
+	}
+	if r.take("A") != nil {
+		t.Error("message quoted twice")
+	}
+	if r.take("unknown") != nil {
+		t.Error("take(unknown) != nil")
+	}
+
+	r.remember("B", sender, msg)
+	r.remember("C", sender, msg)
+	r.remember("D", sender, msg)
+	if r.take("B") != nil {
+		t.Error("oldest target kept beyond the limit")
+	}
+	if r.take("C") == nil || r.take("D") == nil {
+		t.Error("recent targets forgotten")
+	}
+}
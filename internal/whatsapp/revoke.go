@@ -0,0 +1,62 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types/events"
+	waLog "go.mau.fi/whatsmeow/util/log"
+)
+
+// revokedMessageID returns the ID of the message deleted by a revoke
+// protocol message.
+func revokedMessageID(m *waE2E.Message) (string, bool) {
+	pm := m.GetProtocolMessage()
+	// GetType defaults to REVOKE, so require the type to be set explicitly.
+	if pm == nil || pm.Type == nil || pm.GetType() != waE2E.ProtocolMessage_REVOKE {
+		return "", false
+	}
+	id := pm.GetKey().GetID()
+	return id, id != ""
+}
+
+// revokeHandler intercepts "delete for everyone" messages so they are never
+// treated as user input. Revokes are always logged; in audit mode they are
+// also recorded through record.
+type revokeHandler struct {
+	audit  bool
+	record func(ctx context.Context, userID, messageID string, ts time.Time) error
+	log    waLog.Logger
+}
+
+// handle reports whether msg was a revoke and has been fully handled.
+func (h *revokeHandler) handle(ctx context.Context, msg *events.Message) bool {
+	targetID, ok := revokedMessageID(msg.Message)
+	if !ok {
+		return false
+	}
+
+	userID := msg.Info.Sender.User
+	h.log.Infof("User %s revoked message %s", msg.Info.Sender.String(), targetID)
+	if h.audit && h.record != nil {
+		if err := h.record(ctx, userID, targetID, msg.Info.Timestamp); err != nil {
+			h.log.Errorf("Failed to record revoke of %s: %v", targetID, err)
+		}
+	}
+	return true
+}
+
+// recordRevoke stores an audit entry next to the revoked request.
+func (c *Client) recordRevoke(ctx context.Context, userID, messageID string, ts time.Time) error {
+	if c.store == nil {
+		return nil
+	}
+	path := "whatsmeow/" + userID + "/" + messageID + "/revoked"
+	metadata := map[string]interface{}{
+		"user_id":    userID,
+		"message_id": messageID,
+		"revoked_at": ts,
+	}
+	return c.store.PutFile(ctx, path, metadata, nil, ts)
+}
@@ -0,0 +1,96 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	waLog "go.mau.fi/whatsmeow/util/log"
+	"google.golang.org/protobuf/proto"
+)
+
+func revokeEvent(targetID string) *events.Message {
+	jid := types.NewJID("919876543210", types.DefaultUserServer)
+	return &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{Chat: jid, Sender: jid},
+			ID:            "REVOKE1",
+			Timestamp:     time.Unix(1700000000, 0),
+		},
+		Message: &waE2E.Message{
+			ProtocolMessage: &waE2E.ProtocolMessage{
+				Type: waE2E.ProtocolMessage_REVOKE.Enum(),
+				Key:  &waCommon.MessageKey{ID: proto.String(targetID)},
+			},
+		},
+	}
+}
+
+type revokeRecord struct {
+	userID, messageID string
+}
+
+func TestRevokeHandlerAudit(t *testing.T) {
+	var records []revokeRecord
+	h := &revokeHandler{
+		audit: true,
+		record: func(_ context.Context, userID, messageID string, _ time.Time) error {
+			records = append(records, revokeRecord{userID, messageID})
+			return nil
+		},
+		log: waLog.Noop,
+	}
+
+	if !h.handle(context.Background(), revokeEvent("ORIGINAL1")) {
+		t.Fatal("expected revoke to be handled")
+	}
+	if len(records) != 1 || records[0] != (revokeRecord{"919876543210", "ORIGINAL1"}) {
+		t.Errorf("unexpected audit records: %+v", records)
+	}
+}
+
+func TestRevokeHandlerLogOnly(t *testing.T) {
+	called := false
+	h := &revokeHandler{
+		record: func(context.Context, string, string, time.Time) error {
+			called = true
+			return nil
+		},
+		log: waLog.Noop,
+	}
+
+	if !h.handle(context.Background(), revokeEvent("ORIGINAL1")) {
+		t.Fatal("expected revoke to be handled")
+	}
+	if called {
+		t.Error("expected no audit record in log mode")
+	}
+}
+
+func TestRevokeHandlerIgnoresOtherMessages(t *testing.T) {
+	h := &revokeHandler{audit: true, log: waLog.Noop}
+
+	tests := []struct {
+		name string
+		msg  *waE2E.Message
+	}{
+		{"nil message", nil},
+		{"text", &waE2E.Message{Conversation: proto.String("hi")}},
+		{"untyped protocol message", &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{}}},
+		{"edit", &waE2E.Message{ProtocolMessage: &waE2E.ProtocolMessage{
+			Type: waE2E.ProtocolMessage_MESSAGE_EDIT.Enum(),
+			Key:  &waCommon.MessageKey{ID: proto.String("X")},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if h.handle(context.Background(), &events.Message{Message: tt.msg}) {
+				t.Error("non-revoke message reported as handled")
+			}
+		})
+	}
+}
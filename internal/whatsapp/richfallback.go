@@ -0,0 +1,42 @@
+package whatsapp
+
+import (
+	"errors"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+)
+
+// richRejected reports whether err means WhatsApp refused a rich message
+// itself (an error stanza for the send, or media it cannot handle), as
+// opposed to a connection problem that a plain-text send would hit too.
+func richRejected(err error) bool {
+	return errors.Is(err, whatsmeow.ErrServerReturnedError) ||
+		errors.Is(err, whatsmeow.ErrUnknownMediaType) ||
+		errors.Is(err, whatsmeow.ErrInvalidImageFormat)
+}
+
+// mediaKind names the kind of media for mimeType as shown to users.
+func mediaKind(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "image"
+	case strings.HasPrefix(mimeType, "audio/"):
+		return "audio"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "video"
+	default:
+		return "document"
+	}
+}
+
+// mediaFallbackText returns the plain text to send after a media reply
+// failed with sendErr, or "" when none is due. A caption is already resent
+// as text, so the notice is only needed for media without one. template
+// may use {type} for the media kind; empty disables the fallback.
+func mediaFallbackText(template string, sendErr error, mimeType, caption string) string {
+	if template == "" || caption != "" || !richRejected(sendErr) {
+		return ""
+	}
+	return strings.ReplaceAll(template, "{type}", mediaKind(mimeType))
+}
@@ -0,0 +1,37 @@
+package whatsapp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.mau.fi/whatsmeow"
+)
+
+func TestMediaFallbackText(t *testing.T) {
+	const template = "(The agent sent a {type} your WhatsApp could not receive.)"
+	rejected := fmt.Errorf("failed to send media message: %w", fmt.Errorf("%w 479", whatsmeow.ErrServerReturnedError))
+
+	tests := []struct {
+		name     string
+		template string
+		err      error
+		mime     string
+		caption  string
+		want     string
+	}{
+		{"server rejected image", template, rejected, "image/png", "", "(The agent sent a image your WhatsApp could not receive.)"},
+		{"unknown media type", template, fmt.Errorf("failed to upload media: %w", whatsmeow.ErrUnknownMediaType), "application/pdf", "", "(The agent sent a document your WhatsApp could not receive.)"},
+		{"caption already resent", template, rejected, "image/png", "chart", ""},
+		{"connection problem", template, fmt.Errorf("failed to send media message: %w", whatsmeow.ErrNotConnected), "video/mp4", "", ""},
+		{"other error", template, errors.New("failed to decode base64 media"), "audio/ogg", "", ""},
+		{"disabled", "", rejected, "image/png", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mediaFallbackText(tt.template, tt.err, tt.mime, tt.caption); got != tt.want {
+				t.Errorf("mediaFallbackText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
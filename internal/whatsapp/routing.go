@@ -0,0 +1,76 @@
+package whatsapp
+
+import (
+	"go.mau.fi/whatsmeow/types"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+type senderRoute int
+
+const (
+	routeDefault senderRoute = iota
+	routeIgnore
+	routeBusinessAgent
+)
+
+// isBusinessSender reports whether the message came from a WhatsApp
+// Business account, which whatsmeow signals with a verified name.
+func isBusinessSender(info types.MessageInfo) bool {
+	return info.VerifiedName != nil
+}
+
+// routeForSender decides how a message is handed to the agent. Personal
+// senders always use the default route.
+func routeForSender(cfg config.BusinessAccountsConfig, isBusiness bool) senderRoute {
+	if !isBusiness {
+		return routeDefault
+	}
+	switch cfg.Mode {
+	case config.BusinessModeIgnore:
+		return routeIgnore
+	case config.BusinessModeAgent:
+		if cfg.AppName != "" {
+			return routeBusinessAgent
+		}
+	}
+	return routeDefault
+}
+
+type newsletterRoute int
+
+const (
+	// newsletterNone: not a newsletter; handle normally.
+	newsletterNone newsletterRoute = iota
+	newsletterIgnore
+	newsletterStore
+	// newsletterAgent: send to the agent without replying.
+	newsletterAgent
+)
+
+// isNewsletter reports whether the message was posted to a WhatsApp
+// Channel (newsletter) rather than a DM or group.
+func isNewsletter(info types.MessageInfo) bool {
+	return info.Chat.Server == types.NewsletterServer || info.Sender.Server == types.NewsletterServer
+}
+
+// routeNewsletter decides what happens to a channel post. Nobody in a
+// channel can read a reply, so posts never get one.
+func routeNewsletter(mode string, info types.MessageInfo) newsletterRoute {
+	if !isNewsletter(info) {
+		return newsletterNone
+	}
+	return postRoute(mode)
+}
+
+// postRoute maps a newsletters or community_announcements mode to its
+// route. Unknown modes ignore.
+func postRoute(mode string) newsletterRoute {
+	switch mode {
+	case config.NewsletterModeStore:
+		return newsletterStore
+	case config.NewsletterModeAgent:
+		return newsletterAgent
+	}
+	return newsletterIgnore
+}
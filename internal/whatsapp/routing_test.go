@@ -0,0 +1,70 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestIsBusinessSender(t *testing.T) {
+	if isBusinessSender(types.MessageInfo{}) {
+		t.Error("personal sender reported as business")
+	}
+	if !isBusinessSender(types.MessageInfo{VerifiedName: &types.VerifiedName{}}) {
+		t.Error("sender with verified name not reported as business")
+	}
+}
+
+func TestRouteForSender(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.BusinessAccountsConfig
+		isBusiness bool
+		want       senderRoute
+	}{
+		{"personal default", config.BusinessAccountsConfig{Mode: config.BusinessModeDefault}, false, routeDefault},
+		{"personal with ignore", config.BusinessAccountsConfig{Mode: config.BusinessModeIgnore}, false, routeDefault},
+		{"personal with agent", config.BusinessAccountsConfig{Mode: config.BusinessModeAgent, AppName: "biz"}, false, routeDefault},
+		{"business default", config.BusinessAccountsConfig{Mode: config.BusinessModeDefault}, true, routeDefault},
+		{"business ignore", config.BusinessAccountsConfig{Mode: config.BusinessModeIgnore}, true, routeIgnore},
+		{"business agent", config.BusinessAccountsConfig{Mode: config.BusinessModeAgent, AppName: "biz"}, true, routeBusinessAgent},
+		{"business agent without app", config.BusinessAccountsConfig{Mode: config.BusinessModeAgent}, true, routeDefault},
+		{"business unknown mode", config.BusinessAccountsConfig{Mode: "bogus"}, true, routeDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := routeForSender(tt.cfg, tt.isBusiness); got != tt.want {
+				t.Errorf("routeForSender() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteNewsletter(t *testing.T) {
+	channel := types.NewJID("120363000000000000", types.NewsletterServer)
+	dm := types.NewJID("919876543210", types.DefaultUserServer)
+	group := types.NewJID("120363000000000001", types.GroupServer)
+
+	tests := []struct {
+		name string
+		mode string
+		info types.MessageInfo
+		want newsletterRoute
+	}{
+		{"channel post ignored by default", config.NewsletterModeIgnore, types.MessageInfo{MessageSource: types.MessageSource{Chat: channel, Sender: channel}}, newsletterIgnore},
+		{"channel post stored", config.NewsletterModeStore, types.MessageInfo{MessageSource: types.MessageSource{Chat: channel, Sender: channel}}, newsletterStore},
+		{"channel post to agent", config.NewsletterModeAgent, types.MessageInfo{MessageSource: types.MessageSource{Chat: channel, Sender: channel}}, newsletterAgent},
+		{"empty mode ignores", "", types.MessageInfo{MessageSource: types.MessageSource{Chat: channel}}, newsletterIgnore},
+		{"direct message", config.NewsletterModeIgnore, types.MessageInfo{MessageSource: types.MessageSource{Chat: dm, Sender: dm}}, newsletterNone},
+		{"group message", config.NewsletterModeStore, types.MessageInfo{MessageSource: types.MessageSource{Chat: group, Sender: dm, IsGroup: true}}, newsletterNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := routeNewsletter(tt.mode, tt.info); got != tt.want {
+				t.Errorf("routeNewsletter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
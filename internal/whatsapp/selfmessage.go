@@ -0,0 +1,41 @@
+package whatsapp
+
+import (
+	"strings"
+
+	"github.com/innomon/whatsadk/internal/auth"
+)
+
+// selfRoute says how a message sent from the bot's own account is handled.
+type selfRoute int
+
+const (
+	// selfIgnore stores the message as a response and stops.
+	selfIgnore selfRoute = iota
+	// selfProcess handles a Note to Self like any user message.
+	selfProcess
+	// selfAuthOnly runs only the verification and AUTH flows, replying in
+	// the bot's own chat; the message never reaches the agent.
+	selfAuthOnly
+)
+
+// routeSelfMessage decides what happens to an IsFromMe message. Notes to
+// self are always processed; other self-messages are ignored unless
+// allowAuth is set and the text is a verification token or AUTH command.
+func routeSelfMessage(noteToSelf, allowAuth, authFlow bool) selfRoute {
+	switch {
+	case noteToSelf:
+		return selfProcess
+	case allowAuth && authFlow:
+		return selfAuthOnly
+	default:
+		return selfIgnore
+	}
+}
+
+// isAuthFlowText reports whether text would be handled by an enabled
+// verification or AUTH flow.
+func isAuthFlowText(text string, verify, oauth bool) bool {
+	text = strings.TrimSpace(text)
+	return (verify && auth.IsVerificationToken(text) != nil) || (oauth && auth.IsAuthCommand(text))
+}
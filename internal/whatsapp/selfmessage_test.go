@@ -0,0 +1,62 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRouteSelfMessage(t *testing.T) {
+	tests := []struct {
+		name                           string
+		noteToSelf, allowAuth, authMsg bool
+		want                           selfRoute
+	}{
+		{"note to self", true, false, false, selfProcess},
+		{"note to self with flag", true, true, true, selfProcess},
+		{"self auth without flag", false, false, true, selfIgnore},
+		{"self auth with flag", false, true, true, selfAuthOnly},
+		{"self chat message with flag", false, true, false, selfIgnore},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := routeSelfMessage(tt.noteToSelf, tt.allowAuth, tt.authMsg); got != tt.want {
+				t.Errorf("routeSelfMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelfVerificationReachesVerifier(t *testing.T) {
+	token := signDocumentTestToken(t)
+
+	for _, allow := range []bool{false, true} {
+		v := &fakeVerifier{}
+		route := routeSelfMessage(false, allow, isAuthFlowText(token, true, false))
+		if route != selfIgnore {
+			verifyToken(context.Background(), v, "910987654321", token)
+		}
+		if called := v.token == token; called != allow {
+			t.Errorf("self_auth=%v: verifier called = %v, want %v", allow, called, allow)
+		}
+	}
+}
+
+func TestIsAuthFlowText(t *testing.T) {
+	token := signDocumentTestToken(t)
+	tests := []struct {
+		text          string
+		verify, oauth bool
+		want          bool
+	}{
+		{token, true, false, true},
+		{token, false, true, false},
+		{" AUTH key nonce", false, true, true},
+		{"AUTH key nonce", true, false, false},
+		{"hello", true, true, false},
+	}
+	for _, tt := range tests {
+		if got := isAuthFlowText(tt.text, tt.verify, tt.oauth); got != tt.want {
+			t.Errorf("isAuthFlowText(%.20q, %v, %v) = %v, want %v", tt.text, tt.verify, tt.oauth, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package whatsapp
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Send queue overflow policies, referenced by whatsapp.send_queue.overflow.
+const (
+	OverflowBlock      = "block"
+	OverflowDropOldest = "drop_oldest"
+	OverflowDropNewest = "drop_newest"
+)
+
+// sendJob is one queued outbound message. drop is called instead of send
+// when the job is discarded by the overflow policy.
+type sendJob struct {
+	send func(ctx context.Context)
+	drop func()
+}
+
+// sendQueue serializes outbound sends through a single worker so a slow
+// WhatsApp connection backs up here, where it is bounded and visible,
+// rather than in unbounded blocked goroutines.
+type sendQueue struct {
+	maxDepth  int
+	overflow  string
+	warnDepth int
+	// onWarn is called once each time depth reaches warnDepth, and again
+	// only after the queue has drained below it.
+	onWarn func(depth int)
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	jobs    []sendJob
+	warned  bool
+	dropped atomic.Int64
+}
+
+func newSendQueue(maxDepth int, overflow string, warnDepth int, onWarn func(depth int)) *sendQueue {
+	q := &sendQueue{maxDepth: maxDepth, overflow: overflow, warnDepth: warnDepth, onWarn: onWarn}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push enqueues job, applying the overflow policy when the queue is full.
+// With OverflowBlock it waits for room or for ctx to end. It reports
+// whether job was queued.
+func (q *sendQueue) push(ctx context.Context, job sendJob) bool {
+	q.mu.Lock()
+	var dropped *sendJob
+	for len(q.jobs) >= q.maxDepth {
+		switch q.overflow {
+		case OverflowDropOldest:
+			oldest := q.jobs[0]
+			q.jobs = q.jobs[1:]
+			dropped = &oldest
+		case OverflowDropNewest:
+			q.mu.Unlock()
+			q.dropped.Add(1)
+			job.drop()
+			return false
+		default:
+			if ctx.Err() != nil {
+				q.mu.Unlock()
+				q.dropped.Add(1)
+				job.drop()
+				return false
+			}
+			q.wait(ctx)
+		}
+	}
+	q.jobs = append(q.jobs, job)
+	depth := len(q.jobs)
+	warn := q.warnDepth > 0 && depth >= q.warnDepth && !q.warned
+	if warn {
+		q.warned = true
+	}
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	if dropped != nil {
+		q.dropped.Add(1)
+		dropped.drop()
+	}
+	if warn && q.onWarn != nil {
+		q.onWarn(depth)
+	}
+	return true
+}
+
+// pop blocks until a job is available or ctx ends.
+func (q *sendQueue) pop(ctx context.Context) (sendJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.jobs) == 0 {
+		if ctx.Err() != nil {
+			return sendJob{}, false
+		}
+		q.wait(ctx)
+	}
+	job := q.jobs[0]
+	q.jobs = q.jobs[1:]
+	if len(q.jobs) < q.warnDepth {
+		q.warned = false
+	}
+	q.cond.Broadcast()
+	return job, true
+}
+
+// wait blocks on the condition until it is signalled or ctx ends; callers
+// hold q.mu. The wake-up takes q.mu so it cannot fire before Wait.
+func (q *sendQueue) wait(ctx context.Context) {
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	})
+	q.cond.Wait()
+	stop()
+}
+
+// run sends queued jobs in order until ctx ends.
+func (q *sendQueue) run(ctx context.Context) {
+	for {
+		job, ok := q.pop(ctx)
+		if !ok {
+			return
+		}
+		job.send(ctx)
+	}
+}
+
+// depth returns the number of queued sends.
+func (q *sendQueue) depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
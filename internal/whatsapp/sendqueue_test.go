@@ -0,0 +1,102 @@
+package whatsapp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSendQueueOverflow(t *testing.T) {
+	tests := []struct {
+		name        string
+		overflow    string
+		wantQueued  []int
+		wantDropped []int
+	}{
+		{name: "drop oldest", overflow: OverflowDropOldest, wantQueued: []int{2, 3}, wantDropped: []int{0, 1}},
+		{name: "drop newest", overflow: OverflowDropNewest, wantQueued: []int{0, 1}, wantDropped: []int{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSendQueue(2, tt.overflow, 0, nil)
+			var sent, dropped []int
+			for i := range 4 {
+				q.push(context.Background(), sendJob{
+					send: func(context.Context) { sent = append(sent, i) },
+					drop: func() { dropped = append(dropped, i) },
+				})
+			}
+			if q.depth() != 2 {
+				t.Errorf("depth = %d, want 2", q.depth())
+			}
+			for q.depth() > 0 {
+				job, _ := q.pop(context.Background())
+				job.send(context.Background())
+			}
+			if !reflect.DeepEqual(sent, tt.wantQueued) {
+				t.Errorf("sent = %v, want %v", sent, tt.wantQueued)
+			}
+			if !reflect.DeepEqual(dropped, tt.wantDropped) {
+				t.Errorf("dropped = %v, want %v", dropped, tt.wantDropped)
+			}
+			if got := q.dropped.Load(); got != 2 {
+				t.Errorf("dropped counter = %d, want 2", got)
+			}
+		})
+	}
+}
+
+func TestSendQueueBlockWaitsForRoom(t *testing.T) {
+	q := newSendQueue(1, OverflowBlock, 0, nil)
+	noop := sendJob{send: func(context.Context) {}, drop: func() {}}
+	q.push(context.Background(), noop)
+
+	pushed := make(chan bool)
+	go func() { pushed <- q.push(context.Background(), noop) }()
+
+	select {
+	case <-pushed:
+		t.Fatal("push should block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if _, ok := q.pop(context.Background()); !ok {
+		t.Fatal("pop failed")
+	}
+	if !<-pushed {
+		t.Error("blocked push should succeed once there is room")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var droppedOnCancel bool
+	go cancel()
+	if q.push(ctx, sendJob{send: func(context.Context) {}, drop: func() { droppedOnCancel = true }}) {
+		t.Error("push should give up when its context ends")
+	}
+	if !droppedOnCancel {
+		t.Error("abandoned job should be dropped")
+	}
+}
+
+func TestSendQueueWarnsOncePerBacklog(t *testing.T) {
+	var warnings []int
+	q := newSendQueue(10, OverflowDropNewest, 2, func(depth int) { warnings = append(warnings, depth) })
+	noop := sendJob{send: func(context.Context) {}, drop: func() {}}
+
+	for range 4 {
+		q.push(context.Background(), noop)
+	}
+	if !reflect.DeepEqual(warnings, []int{2}) {
+		t.Fatalf("warnings = %v, want one at depth 2", warnings)
+	}
+
+	// Drain below the threshold, then back up again.
+	for q.depth() > 0 {
+		q.pop(context.Background())
+	}
+	q.push(context.Background(), noop)
+	q.push(context.Background(), noop)
+	if !reflect.DeepEqual(warnings, []int{2, 2}) {
+		t.Errorf("warnings = %v, want a second warning after draining", warnings)
+	}
+}
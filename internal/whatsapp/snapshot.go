@@ -0,0 +1,208 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// stateStore keeps in-memory state across restarts.
+type stateStore interface {
+	PutStateSnapshot(ctx context.Context, name string, content []byte) error
+	GetStateSnapshot(ctx context.Context, name string) ([]byte, error)
+	DeleteStateSnapshot(ctx context.Context, name string) error
+}
+
+// snapshotter is in-memory state that can be saved on shutdown and
+// restored on the next start.
+type snapshotter interface {
+	snapshot() ([]byte, error)
+	restore(data []byte) error
+}
+
+// stateKeeper saves the whatsapp.persist_state components on shutdown and
+// restores them on startup. A snapshot is deleted once restored, so a
+// crash never replays state saved by an older shutdown.
+type stateKeeper struct {
+	store stateStore
+	names []string
+	parts map[string]snapshotter
+}
+
+func newStateKeeper(s stateStore) *stateKeeper {
+	return &stateKeeper{store: s, parts: make(map[string]snapshotter)}
+}
+
+// add registers part under name. Nil parts (features turned off) are skipped.
+func (k *stateKeeper) add(name string, part snapshotter) {
+	if part == nil {
+		return
+	}
+	k.names = append(k.names, name)
+	k.parts[name] = part
+}
+
+// save writes every registered component, returning all failures.
+func (k *stateKeeper) save(ctx context.Context) error {
+	var errs []error
+	for _, name := range k.names {
+		data, err := k.parts[name].snapshot()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("encode %s state: %w", name, err))
+			continue
+		}
+		if err := k.store.PutStateSnapshot(ctx, name, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// load restores every registered component that has a saved snapshot and
+// returns the names restored.
+func (k *stateKeeper) load(ctx context.Context) ([]string, error) {
+	var restored []string
+	var errs []error
+	for _, name := range k.names {
+		data, err := k.store.GetStateSnapshot(ctx, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if data == nil {
+			continue
+		}
+		if err := k.parts[name].restore(data); err != nil {
+			errs = append(errs, fmt.Errorf("decode %s state: %w", name, err))
+		} else {
+			restored = append(restored, name)
+		}
+		if err := k.store.DeleteStateSnapshot(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return restored, errors.Join(errs...)
+}
+
+// stateParts maps the whatsapp.persist_state names to c's components.
+func (c *Client) stateParts() map[string]snapshotter {
+	parts := make(map[string]snapshotter)
+	if c.agentLimit != nil {
+		parts[config.PersistAgentRateLimit] = c.agentLimit
+	}
+	if c.flood != nil {
+		parts[config.PersistFlood] = c.flood
+	}
+	if c.cooldown != nil {
+		parts[config.PersistErrorCooldown] = c.cooldown
+	}
+	return parts
+}
+
+type agentCallsSnapshot struct {
+	At       []time.Time `json:"at"`
+	Notified bool        `json:"notified"`
+}
+
+func (l *agentLimiter) snapshot() ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	users := make(map[string]agentCallsSnapshot, len(l.users))
+	for u, calls := range l.users {
+		users[u] = agentCallsSnapshot{At: calls.at, Notified: calls.notified}
+	}
+	return json.Marshal(users)
+}
+
+// restore merges saved call history, dropping calls outside the window.
+func (l *agentLimiter) restore(data []byte) error {
+	var users map[string]agentCallsSnapshot
+	if err := json.Unmarshal(data, &users); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cutoff := l.now().Add(-l.window)
+	for u, saved := range users {
+		var at []time.Time
+		for _, t := range saved.At {
+			if t.After(cutoff) {
+				at = append(at, t)
+			}
+		}
+		if len(at) > 0 {
+			l.users[u] = &agentCalls{at: at, notified: saved.Notified}
+		}
+	}
+	return nil
+}
+
+type floodSnapshot struct {
+	Text   string    `json:"text"`
+	Start  time.Time `json:"start"`
+	Count  int       `json:"count"`
+	Warned bool      `json:"warned"`
+}
+
+func (g *floodGuard) snapshot() ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	users := make(map[string]floodSnapshot, len(g.users))
+	for u, st := range g.users {
+		users[u] = floodSnapshot{Text: st.text, Start: st.start, Count: st.count, Warned: st.warned}
+	}
+	return json.Marshal(users)
+}
+
+// restore merges saved repeat counts whose window is still open.
+func (g *floodGuard) restore(data []byte) error {
+	var users map[string]floodSnapshot
+	if err := json.Unmarshal(data, &users); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	for u, saved := range users {
+		if now.Sub(saved.Start) < g.window {
+			g.users[u] = &floodState{text: saved.Text, start: saved.Start, count: saved.Count, warned: saved.Warned}
+		}
+	}
+	return nil
+}
+
+type cooldownSnapshot struct {
+	Until    time.Time `json:"until"`
+	Notified bool      `json:"notified"`
+}
+
+func (c *errorCooldown) snapshot() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	users := make(map[string]cooldownSnapshot, len(c.users))
+	for u, st := range c.users {
+		users[u] = cooldownSnapshot{Until: st.until, Notified: st.notified}
+	}
+	return json.Marshal(users)
+}
+
+// restore merges saved cooldowns that have not yet lapsed.
+func (c *errorCooldown) restore(data []byte) error {
+	var users map[string]cooldownSnapshot
+	if err := json.Unmarshal(data, &users); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for u, saved := range users {
+		if now.Before(saved.Until) {
+			c.users[u] = &cooldownState{until: saved.Until, notified: saved.Notified}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type memStateStore struct {
+	saved map[string][]byte
+}
+
+func (m *memStateStore) PutStateSnapshot(_ context.Context, name string, content []byte) error {
+	m.saved[name] = content
+	return nil
+}
+
+func (m *memStateStore) GetStateSnapshot(_ context.Context, name string) ([]byte, error) {
+	return m.saved[name], nil
+}
+
+func (m *memStateStore) DeleteStateSnapshot(_ context.Context, name string) error {
+	delete(m.saved, name)
+	return nil
+}
+
+func TestStateKeeperRoundTripsRateLimits(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	st := &memStateStore{saved: map[string][]byte{}}
+
+	before := newAgentLimiter(2, time.Minute, nil)
+	before.now = clock
+	beforeCooldown := newErrorCooldown(time.Minute, true)
+	beforeCooldown.now = clock
+	for i := 0; i < 2; i++ {
+		if v := before.allow("alice"); v != agentLimitAllow {
+			t.Fatalf("call %d = %v, want allow", i, v)
+		}
+	}
+	beforeCooldown.failed("bob")
+
+	k := newStateKeeper(st)
+	k.add("agent_rate_limit", before)
+	k.add("error_cooldown", beforeCooldown)
+	if err := k.save(ctx); err != nil {
+		t.Fatalf("save() error: %v", err)
+	}
+
+	now = now.Add(10 * time.Second)
+	after := newAgentLimiter(2, time.Minute, nil)
+	after.now = clock
+	afterCooldown := newErrorCooldown(time.Minute, true)
+	afterCooldown.now = clock
+	k = newStateKeeper(st)
+	k.add("agent_rate_limit", after)
+	k.add("error_cooldown", afterCooldown)
+	restored, err := k.load(ctx)
+	if err != nil || len(restored) != 2 {
+		t.Fatalf("load() = %v, %v", restored, err)
+	}
+	if len(st.saved) != 0 {
+		t.Errorf("snapshots left after restore: %v", st.saved)
+	}
+
+	if v := after.allow("alice"); v != agentLimitNotice {
+		t.Errorf("alice after restart = %v, want notice", v)
+	}
+	if v := after.allow("carol"); v != agentLimitAllow {
+		t.Errorf("carol after restart = %v, want allow", v)
+	}
+	if v := afterCooldown.check("bob"); v != cooldownNotice {
+		t.Errorf("bob after restart = %v, want notice", v)
+	}
+
+	// Calls saved longer ago than the window are dropped on restore.
+	if err := k.save(ctx); err != nil {
+		t.Fatalf("save() error: %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	late := newAgentLimiter(2, time.Minute, nil)
+	late.now = clock
+	k = newStateKeeper(st)
+	k.add("agent_rate_limit", late)
+	if _, err := k.load(ctx); err != nil {
+		t.Fatalf("load() error: %v", err)
+	}
+	if len(late.users) != 0 {
+		t.Errorf("expired calls restored: %v", late.users)
+	}
+}
+
+func TestStateKeeperRoundTripsFlood(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	st := &memStateStore{saved: map[string][]byte{}}
+
+	before := newFloodGuard(1, time.Minute, true)
+	before.now = func() time.Time { return now }
+	before.check("alice", "hi")
+	k := newStateKeeper(st)
+	k.add("flood", before)
+	if err := k.save(ctx); err != nil {
+		t.Fatalf("save() error: %v", err)
+	}
+
+	after := newFloodGuard(1, time.Minute, true)
+	after.now = func() time.Time { return now }
+	k = newStateKeeper(st)
+	k.add("flood", after)
+	if _, err := k.load(ctx); err != nil {
+		t.Fatalf("load() error: %v", err)
+	}
+	if v := after.check("alice", "Hi"); v != floodWarn {
+		t.Errorf("repeat after restart = %v, want warn", v)
+	}
+}
+
+func TestStateKeeperSkipsDisabledAndMissing(t *testing.T) {
+	k := newStateKeeper(&memStateStore{saved: map[string][]byte{}})
+	k.add("flood", nil)
+	k.add("error_cooldown", newErrorCooldown(time.Minute, false))
+	restored, err := k.load(context.Background())
+	if err != nil || len(restored) != 0 {
+		t.Errorf("load() = %v, %v, want nothing restored", restored, err)
+	}
+	if len(k.names) != 1 {
+		t.Errorf("registered = %v, want error_cooldown only", k.names)
+	}
+}
+
+// Forms live in the gateway store rather than memory, so a new collector
+// over the same store picks up where the old one stopped.
+func TestFormSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	const user = "919876543210"
+	fs := &memFormStore{forms: map[string][]byte{}}
+	before := newFormCollector(fs, 10*time.Minute, "cancel", "Cancelled.")
+	if _, err := before.start(ctx, user, &signupForm); err != nil {
+		t.Fatalf("start() error: %v", err)
+	}
+	if step, err := before.answer(ctx, user, "Asha"); err != nil || step.reply != "And your email?" {
+		t.Fatalf("answer() = %+v, %v", step, err)
+	}
+
+	after := newFormCollector(fs, 10*time.Minute, "cancel", "Cancelled.")
+	step, err := after.answer(ctx, user, "asha@example.com")
+	if err != nil || step.forward == "" {
+		t.Fatalf("answer() after restart = %+v, %v", step, err)
+	}
+}
@@ -0,0 +1,90 @@
+package whatsapp
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Stats are the gateway's runtime counters since it started.
+type Stats struct {
+	ConnectionState
+	StartedAt time.Time
+	// MessagesProcessed counts inbound messages that passed the allowlist
+	// and filters.
+	MessagesProcessed int64
+	AgentCalls        int64
+	AgentErrors       int64
+	// AvgAgentLatency is the mean agent call duration, zero before the
+	// first call.
+	AvgAgentLatency time.Duration
+}
+
+// statsCollector counts messages and agent calls for "/stats" and the
+// admin API. The counters live in memory and restart with the gateway.
+type statsCollector struct {
+	startedAt   time.Time
+	messages    atomic.Int64
+	agentCalls  atomic.Int64
+	agentErrors atomic.Int64
+	latency     atomic.Int64 // total nanos of all agent calls
+}
+
+func newStatsCollector(now time.Time) *statsCollector {
+	return &statsCollector{startedAt: now}
+}
+
+// message counts one processed inbound message.
+func (s *statsCollector) message() {
+	s.messages.Add(1)
+}
+
+// agentCall counts one agent call that took d and failed with err, if
+// not nil.
+func (s *statsCollector) agentCall(d time.Duration, err error) {
+	s.agentCalls.Add(1)
+	s.latency.Add(int64(d))
+	if err != nil {
+		s.agentErrors.Add(1)
+	}
+}
+
+// snapshot returns the counters; the connection state is left to the
+// caller.
+func (s *statsCollector) snapshot() Stats {
+	st := Stats{
+		StartedAt:         s.startedAt,
+		MessagesProcessed: s.messages.Load(),
+		AgentCalls:        s.agentCalls.Load(),
+		AgentErrors:       s.agentErrors.Load(),
+	}
+	if st.AgentCalls > 0 {
+		st.AvgAgentLatency = time.Duration(s.latency.Load() / st.AgentCalls)
+	}
+	return st
+}
+
+// Stats reports the runtime counters and the connection state.
+func (c *Client) Stats() Stats {
+	st := c.stats.snapshot()
+	st.ConnectionState = c.ConnectionState()
+	return st
+}
+
+// formatStats describes st for "/stats".
+func formatStats(st Stats, now time.Time) string {
+	var b strings.Builder
+	if st.Connected && !st.ConnectedAt.IsZero() {
+		fmt.Fprintf(&b, "Connected for %s", now.Sub(st.ConnectedAt).Round(time.Second))
+	} else {
+		b.WriteString("Disconnected")
+	}
+	fmt.Fprintf(&b, "\nUptime: %s", now.Sub(st.StartedAt).Round(time.Second))
+	fmt.Fprintf(&b, "\nMessages processed: %d", st.MessagesProcessed)
+	fmt.Fprintf(&b, "\nAgent calls: %d, %d failed", st.AgentCalls, st.AgentErrors)
+	if st.AgentCalls > 0 {
+		fmt.Fprintf(&b, "\nAvg agent latency: %s", st.AvgAgentLatency.Round(time.Millisecond))
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package whatsapp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsCollector(t *testing.T) {
+	started := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	s := newStatsCollector(started)
+	if st := s.snapshot(); st.AgentCalls != 0 || st.AvgAgentLatency != 0 {
+		t.Fatalf("empty snapshot = %+v", st)
+	}
+
+	s.message()
+	s.message()
+	s.agentCall(100*time.Millisecond, nil)
+	s.agentCall(300*time.Millisecond, errors.New("boom"))
+
+	st := s.snapshot()
+	if st.MessagesProcessed != 2 || st.AgentCalls != 2 || st.AgentErrors != 1 || st.AvgAgentLatency != 200*time.Millisecond {
+		t.Errorf("snapshot = %+v", st)
+	}
+
+	now := started.Add(2 * time.Hour)
+	st.ConnectionState = ConnectionState{Connected: true, ConnectedAt: started.Add(time.Hour)}
+	got := formatStats(st, now)
+	for _, want := range []string{"Connected for 1h0m0s", "Uptime: 2h0m0s", "Messages processed: 2", "Agent calls: 2, 1 failed", "Avg agent latency: 200ms"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatStats missing %q:\n%s", want, got)
+		}
+	}
+
+	st.ConnectionState = ConnectionState{}
+	if got := formatStats(st, now); !strings.HasPrefix(got, "Disconnected\n") {
+		t.Errorf("disconnected formatStats = %q", got)
+	}
+}
@@ -0,0 +1,225 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/agent"
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+const (
+	// maxSummaryEntries bounds the per-session turn counters before idle
+	// entries are pruned.
+	maxSummaryEntries = 4096
+	// summaryIdleTTL is how long a session's partial turn count is kept
+	// without new turns.
+	summaryIdleTTL = 24 * time.Hour
+	// summarySessionSuffix names the side sessions used for summarization,
+	// keeping the user's own session history free of summary prompts.
+	summarySessionSuffix = "-summary"
+)
+
+// summaryScheduler counts agent turns per ADK session user (the sender, or
+// the group with per-group sessions) and reports when a summary refresh is
+// due, every `every` turns.
+type summaryScheduler struct {
+	every int
+	now   func() time.Time
+
+	mu    sync.Mutex
+	turns map[string]*summaryTurns
+}
+
+type summaryTurns struct {
+	refs     []summaryRef
+	lastSeen time.Time
+}
+
+// summaryRef names one stored turn: the sender's request and the
+// agent's response under whatsmeow/<phone>/<uniqueID>/.
+type summaryRef struct {
+	phone    string
+	uniqueID string
+}
+
+func newSummaryScheduler(every int) *summaryScheduler {
+	return &summaryScheduler{every: every, now: time.Now, turns: make(map[string]*summaryTurns)}
+}
+
+// turn records one agent turn in user's session. When a summary is due it
+// returns the turns since the last one, oldest first, and true.
+func (s *summaryScheduler) turn(user string, ref summaryRef) ([]summaryRef, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	t, ok := s.turns[user]
+	if !ok {
+		if len(s.turns) >= maxSummaryEntries {
+			s.prune(now)
+		}
+		t = &summaryTurns{}
+		s.turns[user] = t
+	}
+	t.refs = append(t.refs, ref)
+	t.lastSeen = now
+	if len(t.refs) < s.every {
+		return nil, false
+	}
+	delete(s.turns, user)
+	return t.refs, true
+}
+
+func (s *summaryScheduler) prune(now time.Time) {
+	for u, t := range s.turns {
+		if now.Sub(t.lastSeen) >= summaryIdleTTL {
+			delete(s.turns, u)
+		}
+	}
+}
+
+// summaryState joins stored summaries, oldest first, into the state seeded
+// into a new session. It returns nil when there is nothing to seed.
+func summaryState(key string, texts []string) map[string]any {
+	var kept []string
+	for _, t := range texts {
+		if t = strings.TrimSpace(t); t != "" {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return map[string]any{key: strings.Join(kept, "\n\n")}
+}
+
+// transcriptLine is one stored message, oldest first. sender names the
+// user in group transcripts.
+type transcriptLine struct {
+	fromUser bool
+	sender   string
+	text     string
+}
+
+// summaryPrompt builds the summarization request from the previous summary
+// and the recent transcript, since the side session has no history of its
+// own.
+func summaryPrompt(prompt, previous string, lines []transcriptLine) string {
+	var b strings.Builder
+	b.WriteString(prompt)
+	if previous = strings.TrimSpace(previous); previous != "" {
+		fmt.Fprintf(&b, "\n\nPrevious summary:\n%s", previous)
+	}
+	b.WriteString("\n\nConversation:")
+	for _, l := range lines {
+		speaker := "Agent"
+		switch {
+		case l.fromUser && l.sender != "":
+			speaker = "User " + l.sender
+		case l.fromUser:
+			speaker = "User"
+		}
+		fmt.Fprintf(&b, "\n%s: %s", speaker, l.text)
+	}
+	return b.String()
+}
+
+// summarySession names the side session summarizing refs.
+func summarySession(sessionUser string, refs []summaryRef) string {
+	return sessionUser + summarySessionSuffix + "-" + refs[len(refs)-1].uniqueID
+}
+
+// seedSummary is the agent.SessionSeeder for conversation summaries.
+func (c *Client) seedSummary(ctx context.Context, userID string) (map[string]any, error) {
+	summaries, err := c.store.UserSummaries(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, 0, len(summaries))
+	for _, s := range summaries {
+		texts = append(texts, s.Text)
+	}
+	return summaryState(c.cfg.ADK.Summary.StateKey, texts), nil
+}
+
+// storedText returns the text of a stored request or response, or "" if
+// it is missing or not text.
+func storedText(f *store.FileEntry) string {
+	if f == nil || !f.Metadata.Valid {
+		return ""
+	}
+	var meta struct {
+		MimeType string `json:"mime_type"`
+	}
+	if err := json.Unmarshal([]byte(f.Metadata.String), &meta); err != nil || meta.MimeType != "text/plain" {
+		return ""
+	}
+	return strings.TrimSpace(string(f.Content))
+}
+
+// refreshSummary summarizes the turns refs of sessionUser's conversation on
+// a throwaway side session and stores the result under sessionUser, where
+// the session seeder finds it. Each refresh gets a new session, named
+// after its last turn, so earlier prompts and summaries are not replayed.
+// Nothing is sent to the user and the conversation's own ADK session is
+// not touched.
+func (c *Client) refreshSummary(ctx context.Context, adk *agent.Client, sessionUser string, refs []summaryRef) {
+	cfg := c.cfg.ADK.Summary
+
+	var lines []transcriptLine
+	for _, r := range refs {
+		for _, kind := range []string{"request", "response"} {
+			f, err := c.store.GetFile(ctx, fmt.Sprintf("whatsmeow/%s/%s/%s", r.phone, r.uniqueID, kind))
+			if err != nil {
+				c.log.Warnf("Failed to load conversation for summary of %s: %v", sessionUser, err)
+				return
+			}
+			text := storedText(f)
+			if text == "" {
+				continue
+			}
+			line := transcriptLine{fromUser: kind == "request", text: text}
+			// Group sessions mix senders; name them.
+			if line.fromUser && r.phone != sessionUser {
+				line.sender = r.phone
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	summaries, err := c.store.UserSummaries(ctx, sessionUser)
+	if err != nil {
+		c.log.Warnf("Failed to load previous summary for %s: %v", sessionUser, err)
+		return
+	}
+	var previous string
+	if len(summaries) > 0 {
+		previous = summaries[len(summaries)-1].Text
+	}
+
+	session := summarySession(sessionUser, refs)
+	parts, err := adk.ChatOnce(ctx, sessionUser, session, summaryPrompt(cfg.Prompt, previous, lines))
+	if err != nil {
+		c.log.Warnf("Failed to summarize conversation for %s: %v", sessionUser, err)
+		return
+	}
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteString(p.Text)
+	}
+	text := strings.TrimSpace(b.String())
+	if text == "" {
+		return
+	}
+	if err := c.store.AppendUserSummary(ctx, sessionUser, text, cfg.Keep); err != nil {
+		c.log.Warnf("Failed to store conversation summary for %s: %v", sessionUser, err)
+	}
+}
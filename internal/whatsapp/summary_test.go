@@ -0,0 +1,117 @@
+package whatsapp
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+func TestSummarySchedulerTurn(t *testing.T) {
+	s := newSummaryScheduler(3)
+	var due []bool
+	var got [][]summaryRef
+	for i := range 7 {
+		refs, ok := s.turn("alice", summaryRef{phone: "alice", uniqueID: fmt.Sprint(i)})
+		due = append(due, ok)
+		if ok {
+			got = append(got, refs)
+		}
+	}
+	want := []bool{false, false, true, false, false, true, false}
+	if !reflect.DeepEqual(due, want) {
+		t.Errorf("due = %v, want %v", due, want)
+	}
+	if len(got) != 2 || got[1][0].uniqueID != "3" || got[1][2].uniqueID != "5" {
+		t.Errorf("refs = %v, want turns 3-5 in the second refresh", got)
+	}
+	if _, ok := s.turn("bob", summaryRef{phone: "bob"}); ok {
+		t.Error("turns must be counted per session user")
+	}
+}
+
+func TestSummarySchedulerPrunesIdleUsers(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := newSummaryScheduler(100)
+	s.now = func() time.Time { return now }
+
+	for i := range maxSummaryEntries {
+		s.turn("user"+string(rune('a'+i%26))+strings.Repeat("x", i/26), summaryRef{})
+	}
+	if len(s.turns) != maxSummaryEntries {
+		t.Fatalf("turns = %d, want %d", len(s.turns), maxSummaryEntries)
+	}
+
+	now = now.Add(summaryIdleTTL)
+	s.turn("newcomer", summaryRef{})
+	if len(s.turns) != 1 {
+		t.Errorf("turns after prune = %d, want 1", len(s.turns))
+	}
+}
+
+func TestSummaryState(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  map[string]any
+	}{
+		{name: "none", texts: nil, want: nil},
+		{name: "blank only", texts: []string{" ", ""}, want: nil},
+		{name: "joined oldest first", texts: []string{"older ", "newer"}, want: map[string]any{"conversation_summary": "older\n\nnewer"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summaryState("conversation_summary", tt.texts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summaryState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummaryPrompt(t *testing.T) {
+	lines := []transcriptLine{{fromUser: true, text: "hi"}, {text: "hello"}}
+
+	got := summaryPrompt("Summarize.", "They like tea.", lines)
+	want := "Summarize.\n\nPrevious summary:\nThey like tea.\n\nConversation:\nUser: hi\nAgent: hello"
+	if got != want {
+		t.Errorf("summaryPrompt() = %q, want %q", got, want)
+	}
+
+	if got := summaryPrompt("Summarize.", "", lines); strings.Contains(got, "Previous summary") {
+		t.Errorf("empty previous summary should be omitted: %q", got)
+	}
+
+	group := []transcriptLine{{fromUser: true, sender: "919811111111", text: "hi all"}, {text: "hello"}}
+	if got := summaryPrompt("Summarize.", "", group); !strings.HasSuffix(got, "\nUser 919811111111: hi all\nAgent: hello") {
+		t.Errorf("group transcript should name senders: %q", got)
+	}
+}
+
+func TestSummarySessionIsPerRefresh(t *testing.T) {
+	first := summarySession("919876543210", []summaryRef{{"919876543210", "A1"}, {"919876543210", "A2"}})
+	second := summarySession("919876543210", []summaryRef{{"919876543210", "A3"}, {"919876543210", "A4"}})
+	if first != "919876543210-summary-A2" {
+		t.Errorf("summarySession() = %q, want 919876543210-summary-A2", first)
+	}
+	if first == second {
+		t.Errorf("refreshes share session %q", first)
+	}
+}
+
+func TestStoredText(t *testing.T) {
+	text := &store.FileEntry{Metadata: sql.NullString{String: `{"mime_type":"text/plain"}`, Valid: true}, Content: []byte(" hi ")}
+	media := &store.FileEntry{Metadata: sql.NullString{String: `{"mime_type":"image/jpeg"}`, Valid: true}, Content: []byte{0xff, 0xd8}}
+	if got := storedText(text); got != "hi" {
+		t.Errorf("storedText(text) = %q, want hi", got)
+	}
+	if got := storedText(media); got != "" {
+		t.Errorf("storedText(media) = %q, want empty", got)
+	}
+	if got := storedText(nil); got != "" {
+		t.Errorf("storedText(nil) = %q, want empty", got)
+	}
+}
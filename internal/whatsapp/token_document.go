@@ -0,0 +1,102 @@
+package whatsapp
+
+import (
+	"context"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"github.com/innomon/whatsadk/internal/auth"
+)
+
+// MaxTokenDocumentSize bounds the size of a text attachment inspected for a
+// verification token. Tokens are a few hundred bytes; anything larger is a
+// regular document and is left to the media pipeline.
+const MaxTokenDocumentSize = 8 * 1024
+
+// mediaDownloader fetches attachment bytes. *whatsmeow.Client implements it.
+type mediaDownloader interface {
+	Download(ctx context.Context, msg whatsmeow.DownloadableMessage) ([]byte, error)
+}
+
+// tokenVerifier handles verification tokens. *verification.Handler
+// implements it.
+type tokenVerifier interface {
+	Handle(ctx context.Context, userID, token string) string
+}
+
+// downloadMedia fetches the message's attachment once, so the token check
+// and the media pipeline share the same bytes. It returns nil data when the
+// message carries no attachment.
+func downloadMedia(ctx context.Context, dl mediaDownloader, m *waE2E.Message) (data []byte, mimeType string, err error) {
+	var media whatsmeow.DownloadableMessage
+	switch {
+	case m == nil:
+		return nil, "", nil
+	case m.ImageMessage != nil:
+		media, mimeType = m.ImageMessage, "image/jpeg"
+	case m.AudioMessage != nil:
+		media, mimeType = m.AudioMessage, "audio/wav"
+	case m.VideoMessage != nil:
+		media, mimeType = m.VideoMessage, "video/mp4"
+	case m.DocumentMessage != nil:
+		media, mimeType = m.DocumentMessage, m.DocumentMessage.GetMimetype()
+	case m.StickerMessage != nil:
+		media, mimeType = m.StickerMessage, m.StickerMessage.GetMimetype()
+	default:
+		return nil, "", nil
+	}
+	data, err = dl.Download(ctx, media)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, mimeType, nil
+}
+
+// isTokenDocumentCandidate reports whether a document attachment is small
+// enough and plain-text enough to possibly carry a verification token.
+func isTokenDocumentCandidate(mimeType string, size int) bool {
+	if size == 0 || size > MaxTokenDocumentSize {
+		return false
+	}
+	mimeType = strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]))
+	return mimeType == "text/plain"
+}
+
+// verificationTokenFromDocument returns the document contents if they look
+// like a verification JWT, or "" otherwise.
+func verificationTokenFromDocument(data []byte) string {
+	if len(data) > MaxTokenDocumentSize {
+		return ""
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(string(data), "\ufeff"))
+	if auth.IsVerificationToken(token) == nil {
+		return ""
+	}
+	return token
+}
+
+// documentToken returns the verification token carried by a downloaded text
+// attachment, if any. Some apps send the token as a .txt file rather than a
+// text message to avoid link previews. The size check uses the downloaded
+// bytes, not the sender-supplied FileLength.
+func documentToken(m *waE2E.Message, data []byte) string {
+	if m == nil || m.DocumentMessage == nil {
+		return ""
+	}
+	if !isTokenDocumentCandidate(m.DocumentMessage.GetMimetype(), len(data)) {
+		return ""
+	}
+	return verificationTokenFromDocument(data)
+}
+
+// verifyToken passes text to v when it is a verification token. It reports
+// false when the message is not a verification or v has nothing to say.
+func verifyToken(ctx context.Context, v tokenVerifier, userID, text string) (string, bool) {
+	if v == nil || auth.IsVerificationToken(text) == nil {
+		return "", false
+	}
+	response := v.Handle(ctx, userID, text)
+	return response, response != ""
+}
@@ -0,0 +1,156 @@
+package whatsapp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/auth"
+)
+
+func signDocumentTestToken(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	claims := auth.VerificationClaims{
+		Mobile:      "910987654321",
+		AppName:     "test-app",
+		CallbackURL: "https://app.example.com/callback",
+		ChallengeID: "abc-123",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		},
+	}
+	s, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return s
+}
+
+func TestIsTokenDocumentCandidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mime   string
+		length int
+		want   bool
+	}{
+		{"small text", "text/plain", 600, true},
+		{"text with charset", "text/plain; charset=utf-8", 600, true},
+		{"too large", "text/plain", MaxTokenDocumentSize + 1, false},
+		{"empty", "text/plain", 0, false},
+		{"pdf", "application/pdf", 600, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTokenDocumentCandidate(tt.mime, tt.length); got != tt.want {
+				t.Errorf("isTokenDocumentCandidate(%q, %d) = %v, want %v", tt.mime, tt.length, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerificationTokenFromDocument(t *testing.T) {
+	token := signDocumentTestToken(t)
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"plain token", []byte(token), token},
+		{"token with trailing newline", []byte(token + "\r\n"), token},
+		{"token with BOM", []byte("\ufeff" + token), token},
+		{"ordinary text", []byte("meeting notes"), ""},
+		{"oversized", []byte(token + strings.Repeat(" ", MaxTokenDocumentSize)), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verificationTokenFromDocument(tt.data); got != tt.want {
+				t.Errorf("verificationTokenFromDocument() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if auth.IsVerificationToken(verificationTokenFromDocument([]byte(token))) == nil {
+		t.Error("extracted token should be routed to the verification handler")
+	}
+}
+
+type fakeDownloader struct {
+	data  []byte
+	calls int
+}
+
+func (f *fakeDownloader) Download(_ context.Context, _ whatsmeow.DownloadableMessage) ([]byte, error) {
+	f.calls++
+	return f.data, nil
+}
+
+type fakeVerifier struct {
+	userID, token string
+}
+
+func (f *fakeVerifier) Handle(_ context.Context, userID, token string) string {
+	f.userID, f.token = userID, token
+	return "verified"
+}
+
+func TestDocumentMessageReachesVerifier(t *testing.T) {
+	token := signDocumentTestToken(t)
+
+	tests := []struct {
+		name       string
+		mime       string
+		fileLength uint64 // sender-supplied, not trusted
+		data       []byte
+		want       string
+	}{
+		{"text document", "text/plain", uint64(len(token)), []byte(token + "\n"), token},
+		{"understated length", "text/plain", 10, []byte(token + strings.Repeat(" ", MaxTokenDocumentSize)), ""},
+		{"pdf", "application/pdf", uint64(len(token)), []byte(token), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{
+				Mimetype:   proto.String(tt.mime),
+				FileLength: proto.Uint64(tt.fileLength),
+			}}
+			dl := &fakeDownloader{data: tt.data}
+			v := &fakeVerifier{}
+
+			data, _, err := downloadMedia(context.Background(), dl, msg)
+			if err != nil {
+				t.Fatalf("downloadMedia() error = %v", err)
+			}
+			text := documentToken(msg, data)
+			response, ok := verifyToken(context.Background(), v, "910987654321", text)
+
+			if dl.calls != 1 {
+				t.Errorf("downloads = %d, want 1", dl.calls)
+			}
+			if tt.want == "" {
+				if ok || v.token != "" {
+					t.Errorf("verifier called with %q, want no call", v.token)
+				}
+				return
+			}
+			if !ok || response != "verified" {
+				t.Errorf("verifyToken() = %q, %v, want verified", response, ok)
+			}
+			if v.token != tt.want || v.userID != "910987654321" {
+				t.Errorf("verifier got (%q, %q), want (910987654321, token)", v.userID, v.token)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+// withTranscript replaces the audio parts of a voice note with transcript,
+// introduced by prefix on its own line. keepAudio leaves the audio in place
+// after the transcript. An empty transcript leaves parts unchanged.
+func withTranscript(parts []agent.Part, transcript, prefix string, keepAudio bool) []agent.Part {
+	transcript = strings.TrimSpace(transcript)
+	if transcript == "" {
+		return parts
+	}
+	if prefix != "" {
+		transcript = prefix + "\n" + transcript
+	}
+	out := []agent.Part{{Text: transcript}}
+	for _, p := range parts {
+		if !keepAudio && p.InlineData != nil && strings.HasPrefix(p.InlineData.MimeType, "audio/") {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// transcribeVoiceNote sends the converted audio in parts to the configured
+// transcription backend. On failure the audio is forwarded as before.
+func (c *Client) transcribeVoiceNote(ctx context.Context, userID string, parts []agent.Part) []agent.Part {
+	for _, p := range parts {
+		if p.InlineData == nil || !strings.HasPrefix(p.InlineData.MimeType, "audio/") {
+			continue
+		}
+		wav, err := base64.StdEncoding.DecodeString(p.InlineData.Data)
+		if err != nil {
+			c.log.Errorf("Failed to decode voice note from %s: %v", userID, err)
+			return parts
+		}
+		transcript, err := c.transcriber.Transcribe(ctx, wav)
+		if err != nil {
+			c.log.Warnf("Failed to transcribe voice note from %s, forwarding audio: %v", userID, err)
+			return parts
+		}
+		if transcript == "" {
+			c.log.Infof("Empty transcript for voice note from %s, forwarding audio", userID)
+			return parts
+		}
+		t := c.cfg.WhatsApp.Transcription
+		return withTranscript(parts, transcript, t.Prefix, t.KeepAudio)
+	}
+	return parts
+}
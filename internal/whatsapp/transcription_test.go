@@ -0,0 +1,26 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/agent"
+)
+
+func TestWithTranscript(t *testing.T) {
+	audio := agent.Part{InlineData: &agent.InlineData{MimeType: "audio/wav", Data: "UklGRg=="}}
+
+	got := withTranscript([]agent.Part{audio}, " namaste, order status? ", "Voice note transcript:", false)
+	if len(got) != 1 || got[0].Text != "Voice note transcript:\nnamaste, order status?" {
+		t.Errorf("replaced = %+v", got)
+	}
+
+	got = withTranscript([]agent.Part{audio}, "hello", "", true)
+	if len(got) != 2 || got[0].Text != "hello" || got[1].InlineData == nil {
+		t.Errorf("kept audio = %+v", got)
+	}
+
+	got = withTranscript([]agent.Part{audio}, "  ", "prefix", false)
+	if len(got) != 1 || got[0].InlineData == nil {
+		t.Errorf("empty transcript = %+v, want audio unchanged", got)
+	}
+}
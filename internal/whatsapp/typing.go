@@ -0,0 +1,63 @@
+package whatsapp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// keepTyping sends "composing" now and again every interval, since WhatsApp
+// clears the indicator on its own after about 25 seconds, until the
+// returned stop sends "paused". Send errors are passed to onErr.
+func keepTyping(ctx context.Context, send func(context.Context, types.ChatPresence) error, every time.Duration, onErr func(error)) (stop func()) {
+	if err := send(ctx, types.ChatPresenceComposing); err != nil {
+		onErr(err)
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := send(ctx, types.ChatPresenceComposing); err != nil {
+					onErr(err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			if err := send(ctx, types.ChatPresencePaused); err != nil {
+				onErr(err)
+			}
+		})
+	}
+}
+
+// startTyping shows the typing indicator in chat while the agent works.
+// It is a no-op unless whatsapp.typing_indicator is enabled.
+func (c *Client) startTyping(ctx context.Context, chat types.JID) (stop func()) {
+	if c.typingEvery <= 0 {
+		return func() {}
+	}
+	send := func(ctx context.Context, state types.ChatPresence) error {
+		return c.wac.SendChatPresence(ctx, chat, state, types.ChatPresenceMediaText)
+	}
+	return keepTyping(ctx, send, c.typingEvery, func(err error) {
+		c.log.Warnf("Failed to update typing indicator for %s: %v", chat, err)
+	})
+}
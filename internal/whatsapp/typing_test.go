@@ -0,0 +1,51 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestKeepTyping(t *testing.T) {
+	var mu sync.Mutex
+	var states []types.ChatPresence
+	send := func(_ context.Context, state types.ChatPresence) error {
+		mu.Lock()
+		defer mu.Unlock()
+		states = append(states, state)
+		return nil
+	}
+
+	stop := keepTyping(context.Background(), send, 5*time.Millisecond, func(err error) { t.Errorf("onErr(%v)", err) })
+	time.Sleep(30 * time.Millisecond)
+	stop()
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(states) < 3 {
+		t.Fatalf("states = %v, want composing refreshed before paused", states)
+	}
+	for _, s := range states[:len(states)-1] {
+		if s != types.ChatPresenceComposing {
+			t.Errorf("states = %v, want only composing before the end", states)
+		}
+	}
+	if states[len(states)-1] != types.ChatPresencePaused {
+		t.Errorf("last state = %q, want paused", states[len(states)-1])
+	}
+}
+
+func TestKeepTypingReportsErrors(t *testing.T) {
+	var errs int
+	send := func(context.Context, types.ChatPresence) error { return errors.New("not connected") }
+	stop := keepTyping(context.Background(), send, time.Hour, func(error) { errs++ })
+	stop()
+	if errs != 2 {
+		t.Errorf("onErr called %d times, want 2", errs)
+	}
+}
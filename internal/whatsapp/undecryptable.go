@@ -0,0 +1,84 @@
+package whatsapp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// maxResendEntries bounds the per-user rate limit map before expired
+// entries are pruned.
+const maxResendEntries = 1024
+
+// resendRequester asks users to resend messages that could not be
+// decrypted, at most once per interval per user so a broken session
+// cannot turn into a reply loop.
+type resendRequester struct {
+	message  string
+	interval time.Duration
+	reply    func(ctx context.Context, chat types.JID, msgID, text string)
+	now      func() time.Time
+
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func newResendRequester(message string, interval time.Duration, reply func(ctx context.Context, chat types.JID, msgID, text string)) *resendRequester {
+	return &resendRequester{
+		message:  message,
+		interval: interval,
+		reply:    reply,
+		now:      time.Now,
+		last:     make(map[string]time.Time),
+	}
+}
+
+// handle sends the configured reply for evt if appropriate and reports
+// whether it did.
+func (r *resendRequester) handle(ctx context.Context, evt *events.UndecryptableMessage) bool {
+	if r.message == "" || evt.Info.IsGroup || evt.Info.IsFromMe {
+		return false
+	}
+	// Intentionally unavailable (e.g. view-once) or hidden failures are
+	// not something the user can fix by resending.
+	if evt.UnavailableType != events.UnavailableTypeUnknown || evt.DecryptFailMode == events.DecryptFailHide {
+		return false
+	}
+	if !r.allow(evt.Info.Sender.User) {
+		return false
+	}
+
+	r.reply(ctx, evt.Info.Chat, evt.Info.ID, r.message)
+	return true
+}
+
+func (r *resendRequester) allow(user string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if last, ok := r.last[user]; ok && now.Sub(last) < r.interval {
+		return false
+	}
+	if len(r.last) >= maxResendEntries {
+		for u, t := range r.last {
+			if now.Sub(t) >= r.interval {
+				delete(r.last, u)
+			}
+		}
+	}
+	r.last[user] = now
+	return true
+}
+
+func (c *Client) handleUndecryptable(evt *events.UndecryptableMessage) {
+	c.log.Warnf("Undecryptable message %s from %s (unavailable: %v, type: %q)",
+		evt.Info.ID, evt.Info.Sender.String(), evt.IsUnavailable, evt.UnavailableType)
+
+	if c.resend != nil && c.resend.handle(context.Background(), evt) {
+		c.log.Infof("Asked %s to resend undecryptable message %s", evt.Info.Sender.String(), evt.Info.ID)
+	}
+}
@@ -0,0 +1,94 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+type sentReply struct {
+	chat types.JID
+	text string
+}
+
+func newTestResendRequester(message string, clock *time.Time) (*resendRequester, *[]sentReply) {
+	var sent []sentReply
+	r := newResendRequester(message, time.Hour, func(_ context.Context, chat types.JID, _, text string) {
+		sent = append(sent, sentReply{chat: chat, text: text})
+	})
+	r.now = func() time.Time { return *clock }
+	return r, &sent
+}
+
+func undecryptableEvent(user string) *events.UndecryptableMessage {
+	jid := types.NewJID(user, types.DefaultUserServer)
+	return &events.UndecryptableMessage{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{Chat: jid, Sender: jid},
+			ID:            "MSG1",
+		},
+	}
+}
+
+func TestResendRequesterReplies(t *testing.T) {
+	clock := time.Now()
+	r, sent := newTestResendRequester("Please resend your last message.", &clock)
+
+	evt := undecryptableEvent("919876543210")
+	if !r.handle(context.Background(), evt) {
+		t.Fatal("expected a reply for an undecryptable message")
+	}
+	if len(*sent) != 1 || (*sent)[0].text != "Please resend your last message." || (*sent)[0].chat != evt.Info.Chat {
+		t.Fatalf("unexpected replies: %+v", *sent)
+	}
+}
+
+func TestResendRequesterRateLimit(t *testing.T) {
+	clock := time.Now()
+	r, sent := newTestResendRequester("Please resend.", &clock)
+	ctx := context.Background()
+
+	r.handle(ctx, undecryptableEvent("919876543210"))
+	if r.handle(ctx, undecryptableEvent("919876543210")) {
+		t.Error("expected second reply within interval to be suppressed")
+	}
+	if !r.handle(ctx, undecryptableEvent("911111111111")) {
+		t.Error("expected other users to be unaffected by the rate limit")
+	}
+
+	clock = clock.Add(time.Hour)
+	if !r.handle(ctx, undecryptableEvent("919876543210")) {
+		t.Error("expected a reply once the interval has elapsed")
+	}
+	if len(*sent) != 3 {
+		t.Errorf("expected 3 replies, got %d", len(*sent))
+	}
+}
+
+func TestResendRequesterSkips(t *testing.T) {
+	clock := time.Now()
+	tests := []struct {
+		name    string
+		message string
+		mutate  func(*events.UndecryptableMessage)
+	}{
+		{"no message configured", "", func(*events.UndecryptableMessage) {}},
+		{"group", "Please resend.", func(e *events.UndecryptableMessage) { e.Info.IsGroup = true }},
+		{"from me", "Please resend.", func(e *events.UndecryptableMessage) { e.Info.IsFromMe = true }},
+		{"view once", "Please resend.", func(e *events.UndecryptableMessage) { e.UnavailableType = events.UnavailableTypeViewOnce }},
+		{"hidden failure", "Please resend.", func(e *events.UndecryptableMessage) { e.DecryptFailMode = events.DecryptFailHide }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, sent := newTestResendRequester(tt.message, &clock)
+			evt := undecryptableEvent("919876543210")
+			tt.mutate(evt)
+			if r.handle(context.Background(), evt) || len(*sent) != 0 {
+				t.Errorf("expected no reply, got %+v", *sent)
+			}
+		})
+	}
+}
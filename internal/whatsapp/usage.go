@@ -0,0 +1,126 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+// usageStore persists usage counts.
+type usageStore interface {
+	AddUsage(ctx context.Context, delta store.UsageCounts) error
+}
+
+// usageKind is the counter a usage event increments.
+type usageKind int
+
+const (
+	usageInbound usageKind = iota
+	usageOutbound
+	usageAgentCall
+)
+
+type usageKey struct {
+	phone, day string
+}
+
+// usageBatcher buffers usage increments in memory and writes them to the
+// store in batches, so busy gateways make one write per user and day per
+// flush instead of one per message.
+type usageBatcher struct {
+	store     usageStore
+	batchSize int
+	interval  time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	pending map[usageKey]*store.UsageCounts
+	events  int
+}
+
+func newUsageBatcher(s usageStore, batchSize int, interval time.Duration) *usageBatcher {
+	return &usageBatcher{
+		store:     s,
+		batchSize: batchSize,
+		interval:  interval,
+		now:       time.Now,
+		pending:   make(map[usageKey]*store.UsageCounts),
+	}
+}
+
+// add counts one event for phone. It reports whether the buffer reached
+// the batch size and should be flushed.
+func (b *usageBatcher) add(phone string, kind usageKind) bool {
+	day := b.now().UTC().Format(time.DateOnly)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := usageKey{phone: phone, day: day}
+	counts, ok := b.pending[key]
+	if !ok {
+		counts = &store.UsageCounts{Phone: phone, Day: day}
+		b.pending[key] = counts
+	}
+	switch kind {
+	case usageInbound:
+		counts.Inbound++
+	case usageOutbound:
+		counts.Outbound++
+	case usageAgentCall:
+		counts.AgentCalls++
+	}
+	b.events++
+	return b.batchSize > 0 && b.events >= b.batchSize
+}
+
+// flush writes the buffered counts. Counts that fail to write go back into
+// the buffer for the next flush.
+func (b *usageBatcher) flush(ctx context.Context) error {
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = make(map[usageKey]*store.UsageCounts)
+	b.events = 0
+	b.mu.Unlock()
+
+	var errs []error
+	for key, counts := range batch {
+		if err := b.store.AddUsage(ctx, *counts); err != nil {
+			errs = append(errs, err)
+			b.restore(key, counts)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// restore merges counts that could not be written back into the buffer.
+func (b *usageBatcher) restore(key usageKey, counts *store.UsageCounts) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cur, ok := b.pending[key]
+	if !ok {
+		b.pending[key] = counts
+		return
+	}
+	cur.Inbound += counts.Inbound
+	cur.Outbound += counts.Outbound
+	cur.AgentCalls += counts.AgentCalls
+}
+
+// run flushes every interval until ctx ends. The final flush on shutdown
+// is left to the caller, which may outlive ctx.
+func (b *usageBatcher) run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.flush(ctx); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
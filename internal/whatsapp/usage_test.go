@@ -0,0 +1,159 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/innomon/whatsadk/internal/store"
+)
+
+type fakeUsageStore struct {
+	mu     sync.Mutex
+	writes int
+	totals map[usageKey]store.UsageCounts
+	err    error
+}
+
+func (f *fakeUsageStore) AddUsage(_ context.Context, d store.UsageCounts) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	if f.totals == nil {
+		f.totals = make(map[usageKey]store.UsageCounts)
+	}
+	key := usageKey{phone: d.Phone, day: d.Day}
+	t := f.totals[key]
+	t.Phone, t.Day = d.Phone, d.Day
+	t.Inbound += d.Inbound
+	t.Outbound += d.Outbound
+	t.AgentCalls += d.AgentCalls
+	f.totals[key] = t
+	f.writes++
+	return nil
+}
+
+func (f *fakeUsageStore) snapshot() (int, map[usageKey]store.UsageCounts) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	totals := make(map[usageKey]store.UsageCounts, len(f.totals))
+	for k, v := range f.totals {
+		totals[k] = v
+	}
+	return f.writes, totals
+}
+
+func TestUsageBatcherBatchesWrites(t *testing.T) {
+	fs := &fakeUsageStore{}
+	b := newUsageBatcher(fs, 5, time.Hour)
+	b.now = func() time.Time { return time.Date(2026, 10, 1, 23, 0, 0, 0, time.UTC) }
+
+	events := []struct {
+		phone string
+		kind  usageKind
+	}{
+		{"911", usageInbound}, {"911", usageAgentCall}, {"911", usageOutbound}, {"922", usageInbound},
+	}
+	for _, e := range events {
+		if b.add(e.phone, e.kind) {
+			t.Fatalf("add() asked for a flush before the batch size")
+		}
+	}
+	if writes, _ := fs.snapshot(); writes != 0 {
+		t.Fatalf("store written %d times before flush", writes)
+	}
+	if !b.add("911", usageInbound) {
+		t.Fatal("add() did not ask for a flush at the batch size")
+	}
+
+	if err := b.flush(t.Context()); err != nil {
+		t.Fatalf("flush() error: %v", err)
+	}
+	writes, totals := fs.snapshot()
+	if writes != 2 {
+		t.Errorf("writes = %d, want one per user and day", writes)
+	}
+	want := store.UsageCounts{Phone: "911", Day: "2026-10-01", Inbound: 2, Outbound: 1, AgentCalls: 1}
+	if got := totals[usageKey{"911", "2026-10-01"}]; got != want {
+		t.Errorf("911 totals = %+v, want %+v", got, want)
+	}
+	if b.add("922", usageOutbound) {
+		t.Error("batch count not reset by flush")
+	}
+}
+
+func TestUsageBatcherPeriodicFlush(t *testing.T) {
+	fs := &fakeUsageStore{}
+	b := newUsageBatcher(fs, 0, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go b.run(ctx, func(err error) { t.Errorf("flush error: %v", err) })
+
+	b.add("911", usageInbound)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if writes, _ := fs.snapshot(); writes == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("buffered counts were not flushed periodically")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestUsageBatcherShutdownFlush(t *testing.T) {
+	fs := &fakeUsageStore{}
+	b := newUsageBatcher(fs, 100, time.Hour)
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		b.run(ctx, func(err error) { t.Errorf("flush error: %v", err) })
+		close(done)
+	}()
+
+	for range 3 {
+		b.add("911", usageOutbound)
+	}
+	cancel()
+	<-done
+	if err := b.flush(context.Background()); err != nil {
+		t.Fatalf("flush() error: %v", err)
+	}
+	_, totals := fs.snapshot()
+	var outbound int64
+	for _, c := range totals {
+		outbound += c.Outbound
+	}
+	if outbound != 3 {
+		t.Errorf("outbound after shutdown flush = %d, want 3", outbound)
+	}
+}
+
+func TestUsageBatcherKeepsCountsOnFailure(t *testing.T) {
+	fs := &fakeUsageStore{err: errors.New("db down")}
+	b := newUsageBatcher(fs, 0, time.Hour)
+	b.add("911", usageInbound)
+	if err := b.flush(t.Context()); err == nil {
+		t.Fatal("flush() error = nil, want store error")
+	}
+
+	fs.err = nil
+	b.add("911", usageInbound)
+	if err := b.flush(t.Context()); err != nil {
+		t.Fatalf("flush() error: %v", err)
+	}
+	_, totals := fs.snapshot()
+	for _, c := range totals {
+		if c.Inbound != 2 {
+			t.Errorf("inbound = %d, want 2 after retry", c.Inbound)
+		}
+	}
+	if len(totals) != 1 {
+		t.Errorf("got %d usage rows, want 1", len(totals))
+	}
+}
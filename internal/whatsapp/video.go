@@ -0,0 +1,40 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"github.com/innomon/whatsadk/internal/agent"
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+// videoParts converts a user's video for the agent according to mode
+// (whatsapp.video.mode). The caption is forwarded separately as text.
+func (p *Processor) videoParts(ctx context.Context, mode, note string, vm *waE2E.VideoMessage, data []byte) ([]agent.Part, error) {
+	switch mode {
+	case config.VideoModeFull:
+		if len(data) > MaxMediaSize {
+			return nil, fmt.Errorf("video too large: %d bytes", len(data))
+		}
+		mimeType := vm.GetMimetype()
+		if mimeType == "" {
+			mimeType = "video/mp4"
+		}
+		return []agent.Part{{InlineData: &agent.InlineData{MimeType: mimeType, Data: base64.StdEncoding.EncodeToString(data)}}}, nil
+	case config.VideoModeThumbnail:
+		if thumb := vm.GetJPEGThumbnail(); len(thumb) > 0 {
+			return []agent.Part{
+				{Text: note},
+				{InlineData: &agent.InlineData{MimeType: "image/jpeg", Data: base64.StdEncoding.EncodeToString(thumb)}},
+			}, nil
+		}
+		return []agent.Part{{Text: note}}, nil
+	case config.VideoModeNote:
+		return []agent.Part{{Text: note}}, nil
+	default:
+		return p.ProcessVideo(ctx, data)
+	}
+}
@@ -0,0 +1,47 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/config"
+)
+
+func TestVideoParts(t *testing.T) {
+	p := NewProcessor()
+	ctx := context.Background()
+	const note = "[video]"
+	data := []byte("fake mp4")
+	thumb := []byte{0xff, 0xd8, 0xff}
+	vm := &waE2E.VideoMessage{Mimetype: proto.String("video/3gpp"), JPEGThumbnail: thumb}
+
+	parts, err := p.videoParts(ctx, config.VideoModeFull, note, vm, data)
+	if err != nil || len(parts) != 1 || parts[0].InlineData.MimeType != "video/3gpp" ||
+		parts[0].InlineData.Data != base64.StdEncoding.EncodeToString(data) {
+		t.Errorf("full = %+v, %v", parts, err)
+	}
+
+	parts, err = p.videoParts(ctx, config.VideoModeThumbnail, note, vm, data)
+	if err != nil || len(parts) != 2 || parts[0].Text != note || parts[1].InlineData.MimeType != "image/jpeg" ||
+		parts[1].InlineData.Data != base64.StdEncoding.EncodeToString(thumb) {
+		t.Errorf("thumbnail = %+v, %v", parts, err)
+	}
+
+	parts, err = p.videoParts(ctx, config.VideoModeThumbnail, note, &waE2E.VideoMessage{}, data)
+	if err != nil || len(parts) != 1 || parts[0].Text != note {
+		t.Errorf("thumbnail without preview = %+v, %v", parts, err)
+	}
+
+	parts, err = p.videoParts(ctx, config.VideoModeNote, note, vm, data)
+	if err != nil || len(parts) != 1 || parts[0].Text != note {
+		t.Errorf("note = %+v, %v", parts, err)
+	}
+
+	if _, err := p.videoParts(ctx, config.VideoModeFull, note, vm, make([]byte, MaxMediaSize+1)); err == nil {
+		t.Error("expected error for oversized video")
+	}
+}
@@ -0,0 +1,35 @@
+package whatsapp
+
+import (
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// unwrapViewOnce returns the media inside a view-once wrapper that
+// whatsmeow left in place (it unwraps live messages itself, setting
+// IsViewOnce).
+func unwrapViewOnce(m *waE2E.Message) (*waE2E.Message, bool) {
+	for _, w := range []*waE2E.FutureProofMessage{
+		m.GetViewOnceMessage(),
+		m.GetViewOnceMessageV2(),
+		m.GetViewOnceMessageV2Extension(),
+	} {
+		if inner := w.GetMessage(); inner != nil {
+			return inner, true
+		}
+	}
+	return nil, false
+}
+
+// withViewOnceUnwrapped returns msg with any remaining view-once wrapper
+// removed and IsViewOnce set, leaving the original event untouched.
+func withViewOnceUnwrapped(msg *events.Message) *events.Message {
+	inner, ok := unwrapViewOnce(msg.Message)
+	if !ok {
+		return msg
+	}
+	unwrapped := *msg
+	unwrapped.Message = inner
+	unwrapped.IsViewOnce = true
+	return &unwrapped
+}
@@ -0,0 +1,35 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWithViewOnceUnwrapped(t *testing.T) {
+	image := &waE2E.Message{ImageMessage: &waE2E.ImageMessage{Caption: proto.String("secret")}}
+	wrappers := map[string]*waE2E.Message{
+		"v1":           {ViewOnceMessage: &waE2E.FutureProofMessage{Message: image}},
+		"v2":           {ViewOnceMessageV2: &waE2E.FutureProofMessage{Message: image}},
+		"v2 extension": {ViewOnceMessageV2Extension: &waE2E.FutureProofMessage{Message: image}},
+	}
+	for name, wrapped := range wrappers {
+		t.Run(name, func(t *testing.T) {
+			orig := &events.Message{Message: wrapped}
+			got := withViewOnceUnwrapped(orig)
+			if !got.IsViewOnce || got.Message != image {
+				t.Errorf("unwrapped = %+v", got)
+			}
+			if orig.IsViewOnce || orig.Message != wrapped {
+				t.Error("original event modified")
+			}
+		})
+	}
+
+	plain := &events.Message{Message: image}
+	if got := withViewOnceUnwrapped(plain); got != plain || got.IsViewOnce {
+		t.Errorf("plain message = %+v", got)
+	}
+}
@@ -0,0 +1,71 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"github.com/innomon/whatsadk/internal/agent"
+	"github.com/innomon/whatsadk/internal/tts"
+)
+
+// opusGranuleRate is the granule position rate of Ogg Opus streams, which
+// is always 48kHz regardless of the input sample rate.
+const opusGranuleRate = 48000
+
+// isVoiceNote reports whether m is a push-to-talk voice note rather than
+// an audio file.
+func isVoiceNote(m *waE2E.Message) bool {
+	return m.GetAudioMessage().GetPTT()
+}
+
+// oggDuration returns the length in seconds of an Ogg Opus stream, read
+// from the granule position of its last page, or 0 if it cannot be read.
+func oggDuration(data []byte) uint32 {
+	i := bytes.LastIndex(data, []byte("OggS"))
+	if i < 0 || len(data) < i+14 {
+		return 0
+	}
+	granule := binary.LittleEndian.Uint64(data[i+6 : i+14])
+	return uint32((granule + opusGranuleRate - 1) / opusGranuleRate)
+}
+
+// voiceReplyParts puts audio in front of parts as a voice note. Text parts
+// are dropped unless keepText is set, in which case they follow the audio.
+func voiceReplyParts(parts []agent.Part, audio []byte, keepText bool) []agent.Part {
+	out := []agent.Part{{InlineData: &agent.InlineData{MimeType: tts.MimeType, Data: base64.StdEncoding.EncodeToString(audio)}}}
+	for _, p := range parts {
+		if p.Text != "" && !keepText {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// voiceReply replaces the text of an agent reply to a voice note with
+// synthesized speech. Replies with no text, text over max_chars or failed
+// synthesis are returned unchanged.
+func (c *Client) voiceReply(ctx context.Context, userID string, parts []agent.Part) []agent.Part {
+	var texts []string
+	for _, p := range parts {
+		if p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	text := strings.TrimSpace(strings.Join(texts, "\n\n"))
+	cfg := c.cfg.WhatsApp.VoiceReplies
+	if text == "" || len([]rune(text)) > cfg.MaxChars {
+		return parts
+	}
+	audio, err := c.speaker.Synthesize(ctx, text)
+	if err != nil {
+		c.log.Errorf("Failed to synthesize voice reply for %s, replying with text: %v", userID, err)
+		return parts
+	}
+	return voiceReplyParts(parts, audio, cfg.KeepText)
+}
@@ -0,0 +1,52 @@
+package whatsapp
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/innomon/whatsadk/internal/agent"
+	"github.com/innomon/whatsadk/internal/tts"
+)
+
+// oggPage returns a minimal Ogg page header with the given granule position.
+func oggPage(granule uint64) []byte {
+	page := append([]byte("OggS"), 0, 0)
+	page = binary.LittleEndian.AppendUint64(page, granule)
+	return append(page, make([]byte, 12)...)
+}
+
+func TestOggDuration(t *testing.T) {
+	stream := append(append(oggPage(0), oggPage(48000)...), oggPage(48000*4+100)...)
+	if got := oggDuration(stream); got != 5 {
+		t.Errorf("oggDuration() = %d, want 5", got)
+	}
+	if got := oggDuration([]byte("not ogg")); got != 0 {
+		t.Errorf("oggDuration(garbage) = %d, want 0", got)
+	}
+}
+
+func TestIsVoiceNote(t *testing.T) {
+	if !isVoiceNote(&waE2E.Message{AudioMessage: &waE2E.AudioMessage{PTT: proto.Bool(true)}}) {
+		t.Error("voice note not recognized")
+	}
+	if isVoiceNote(&waE2E.Message{AudioMessage: &waE2E.AudioMessage{}}) || isVoiceNote(&waE2E.Message{Conversation: proto.String("hi")}) {
+		t.Error("audio file or text taken for a voice note")
+	}
+}
+
+func TestVoiceReplyParts(t *testing.T) {
+	image := agent.Part{InlineData: &agent.InlineData{MimeType: "image/png", Data: "x"}}
+	parts := []agent.Part{{Text: "Here is the map."}, image}
+
+	got := voiceReplyParts(parts, []byte("OggS"), false)
+	if len(got) != 2 || got[0].InlineData.MimeType != tts.MimeType || got[0].InlineData.Data != "T2dnUw==" || got[1].InlineData != image.InlineData {
+		t.Errorf("without text = %+v", got)
+	}
+	got = voiceReplyParts(parts, []byte("OggS"), true)
+	if len(got) != 3 || got[1].Text != "Here is the map." {
+		t.Errorf("with text = %+v", got)
+	}
+}
@@ -0,0 +1,22 @@
+package whatsapp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeInbound trims leading and trailing whitespace (including the
+// zero-width characters mobile keyboards like to insert) and normalizes
+// CRLF line endings. Internal spacing and line breaks are preserved.
+func normalizeInbound(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.TrimFunc(text, isTrimmable)
+}
+
+func isTrimmable(r rune) bool {
+	switch r {
+	case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff':
+		return true
+	}
+	return unicode.IsSpace(r)
+}
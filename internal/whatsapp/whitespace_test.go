@@ -0,0 +1,46 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"github.com/innomon/whatsadk/internal/auth"
+)
+
+func TestNormalizeInbound(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "hello", "hello"},
+		{"trailing newline", "hello\n", "hello"},
+		{"leading spaces", "   hello", "hello"},
+		{"nbsp and zero width", "\u00a0\u200bhello\u200b\u00a0", "hello"},
+		{"internal formatting kept", "  line one\n\n  line two  \n", "line one\n\n  line two"},
+		{"crlf normalized", "a\r\nb\r\n", "a\nb"},
+		{"whitespace only", " \n\t ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeInbound(tt.in); got != tt.want {
+				t.Errorf("normalizeInbound(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeInboundCommands(t *testing.T) {
+	authCmd := "AUTH AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA nonce1234567890abcdef"
+	for _, in := range []string{authCmd + "\n", "  " + authCmd, "\u200b" + authCmd + " \r\n"} {
+		if !auth.IsAuthCommand(normalizeInbound(in)) {
+			t.Errorf("AUTH command %q not recognized after normalization", in)
+		}
+	}
+
+	token := signDocumentTestToken(t)
+	for _, in := range []string{token + "\n", "\n  " + token + "  ", "\ufeff" + token} {
+		if auth.IsVerificationToken(normalizeInbound(in)) == nil {
+			t.Errorf("verification token with surrounding whitespace not recognized")
+		}
+	}
+}